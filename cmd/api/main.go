@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -12,8 +15,11 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/wildanasyrof/backend-topup/internal/config"
 	"github.com/wildanasyrof/backend-topup/internal/di"
+	"github.com/wildanasyrof/backend-topup/internal/http/apidoc"
 	"github.com/wildanasyrof/backend-topup/internal/http/router"
 	"github.com/wildanasyrof/backend-topup/internal/http/server"
+	"github.com/wildanasyrof/backend-topup/pkg/metrics"
+	"github.com/wildanasyrof/backend-topup/pkg/tracing"
 )
 
 func main() {
@@ -23,13 +29,64 @@ func main() {
 		log.Fatalf("Error load config:%v:", err)
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, cfg.Server.Env)
+	if err != nil {
+		log.Fatalf("Error setup tracing:%v:", err)
+	}
+
 	di := di.InitDI(cfg)
 	app := fiber.New(
 		fiber.Config{
 			ErrorHandler: server.ErrorHandler(di.Logger),
+			// c.IP() dipakai rate limit, allowlist API key dan audit log; header proxy hanya
+			// dipercaya dari TrustedProxies
+			ProxyHeader:             cfg.Server.ProxyHeader,
+			EnableTrustedProxyCheck: cfg.Server.ProxyHeader != "",
+			TrustedProxies:          cfg.Server.TrustedProxies,
+			EnableIPValidation:      true,
 		},
 	)
 	router.SetupRouter(app, di, cfg)
+	// Route yang belum masuk katalog apidoc tidak muncul di /openapi.json
+	if cfg.Server.Env != "production" {
+		for _, r := range apidoc.Missing(app.GetRoutes(true)) {
+			di.Logger.Warn("route missing from OpenAPI spec: " + r)
+		}
+	}
+
+	// Worker outbox webhook dan notifikasi berjalan di setiap instance; baris diklaim dengan SKIP LOCKED jadi tidak dobel kirim
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	workersDone := make(chan struct{}, 4)
+	go func() {
+		defer func() { workersDone <- struct{}{} }()
+		di.WebhookDispatcher.Run(workerCtx)
+	}()
+	go func() {
+		defer func() { workersDone <- struct{}{} }()
+		di.NotifyDispatcher.Run(workerCtx)
+	}()
+	go func() {
+		defer func() { workersDone <- struct{}{} }()
+		di.MetricsCollector.Run(workerCtx)
+	}()
+	// Revoke, suspend dan ganti role di replica lain langsung membuang cache sesi di instance ini
+	go func() {
+		defer func() { workersDone <- struct{}{} }()
+		di.AccessCache.Run(workerCtx)
+	}()
+
+	// /metrics dilayani di listener terpisah agar tidak terbuka lewat port publik API
+	var metricsServer *http.Server
+	if cfg.Metrics.Enabled {
+		metricsServer = metrics.NewServer(cfg.Metrics.Addr, cfg.Metrics.Token, di.Metrics)
+		go func() {
+			di.Logger.Info(fmt.Sprintf("Starting metrics server on %s...", cfg.Metrics.Addr))
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				di.Logger.Error(err, "Metrics server error")
+			}
+		}()
+	}
+
 	// Channel to listen for OS signals
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -46,6 +103,14 @@ func main() {
 	sig := <-quit
 	di.Logger.Info(fmt.Sprintf("Received signal: %s. Shutting down server...", sig))
 
+	// Readiness gagal lebih dulu agar load balancer berhenti mengirim request baru,
+	// lalu listener ditutup setelah jeda dan request yang sedang berjalan diselesaikan
+	di.HealthService.Drain()
+	if cfg.Health.DrainDelay > 0 {
+		di.Logger.Info(fmt.Sprintf("Readiness set to draining, waiting %s before closing listener...", cfg.Health.DrainDelay))
+		time.Sleep(cfg.Health.DrainDelay)
+	}
+
 	// --- Graceful Shutdown ---
 	// Give existing requests some time to finish (e.g., 30 seconds)
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -56,6 +121,27 @@ func main() {
 		di.Logger.Error(err, "Server forced to shutdown:")
 	}
 
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(shutdownCtx); err != nil {
+			di.Logger.Error(err, "Metrics server forced to shutdown:")
+		}
+	}
+
+	// Hentikan worker sebelum koneksi database ditutup
+	stopWorkers()
+	for range cap(workersDone) {
+		<-workersDone
+	}
+
+	// Store in-memory (rate limit, state OAuth) punya goroutine pembersih; store database/Redis tidak
+	for _, store := range []any{di.RateLimiter, *di.DevStore} {
+		if c, ok := store.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				di.Logger.Error(err, "Failed to close in-memory store:")
+			}
+		}
+	}
+
 	// --- Cleanup Resources ---
 	// Close database connection (assuming GetDB method exists in DI or similar)
 	if sqlDB, err := di.GetDB().DB(); err == nil {
@@ -67,5 +153,10 @@ func main() {
 		di.Logger.Error(err, "Failed to get DB instance for closing:")
 	}
 
+	// Kirim span yang masih tertahan di batcher sebelum proses keluar
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		di.Logger.Error(err, "Failed to flush traces:")
+	}
+
 	di.Logger.Info("Server shutdown complete.")
 }
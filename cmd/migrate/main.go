@@ -0,0 +1,117 @@
+// Command migrate mengelola skema database.
+//
+//	go run ./cmd/migrate up            jalankan semua migrasi pending
+//	go run ./cmd/migrate down [n]      rollback n migrasi terakhir (default 1)
+//	go run ./cmd/migrate status        tampilkan migrasi yang sudah/belum jalan
+//	go run ./cmd/migrate create <name> buat file up/down baru di internal/db/migrations
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/config"
+	"github.com/wildanasyrof/backend-topup/internal/db"
+	"github.com/wildanasyrof/backend-topup/internal/db/migrations"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/migrate"
+)
+
+func main() {
+	dir := flag.String("dir", "internal/db/migrations", "directory for new migration files (create)")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: migrate [-dir path] up | down [n] | status | create <name>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// create tidak butuh koneksi database
+	if args[0] == "create" {
+		if len(args) < 2 {
+			log.Fatal("create: migration name is required")
+		}
+		up, down, err := migrate.Create(*dir, strings.Join(args[1:], "_"), time.Now())
+		if err != nil {
+			log.Fatalf("create: %v", err)
+		}
+		fmt.Println("created", up)
+		fmt.Println("created", down)
+		return
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Error load config:%v:", err)
+	}
+	gdb := db.Open(cfg, logger.NewZerologLogger(cfg.Server.Env))
+	sqlDB, err := gdb.DB()
+	if err != nil {
+		log.Fatalf("database handle: %v", err)
+	}
+	defer sqlDB.Close()
+
+	m, err := migrate.New(sqlDB, migrations.FS)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		done, err := m.Up(ctx)
+		report("applied", done)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(done) == 0 {
+			fmt.Println("schema is up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil {
+				log.Fatalf("down: invalid step count %q", args[1])
+			}
+		}
+		done, err := m.Down(ctx, steps)
+		report("rolled back", done)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Local().Format(time.RFC3339)
+			}
+			fmt.Printf("%d  %-40s %s\n", s.Version, s.Name, applied)
+		}
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func report(verb string, done []migrate.Migration) {
+	for _, mig := range done {
+		fmt.Printf("%s %d_%s\n", verb, mig.Version, mig.Name)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/wildanasyrof/backend-topup/pkg/money"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture bawaan: base/ selalu dimuat, lalu folder sesuai --profile
+//
+//go:embed fixtures
+var embeddedFixtures embed.FS
+
+var profiles = []string{"dev", "demo", "test"}
+
+// Fixtures adalah isi gabungan semua file fixture sebuah profile.
+// Relasi memakai natural key (nama menu, ref provider, slug kategori, SKU, nama level)
+// agar file tetap bisa dibaca dan di-diff tanpa tahu ID database.
+type Fixtures struct {
+	Levels         []LevelFixture         `yaml:"levels" json:"levels"`
+	Menus          []MenuFixture          `yaml:"menus" json:"menus"`
+	Providers      []ProviderFixture      `yaml:"providers" json:"providers"`
+	Categories     []CategoryFixture      `yaml:"categories" json:"categories"`
+	Products       []ProductFixture       `yaml:"products" json:"products"`
+	PaymentMethods []PaymentMethodFixture `yaml:"payment_methods" json:"payment_methods"`
+	Banners        []BannerFixture        `yaml:"banners" json:"banners"`
+	Settings       map[string]string      `yaml:"settings" json:"settings"`
+	Admin          *AdminFixture          `yaml:"admin" json:"admin"`
+}
+
+type LevelFixture struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+}
+
+type MenuFixture struct {
+	Name string `yaml:"name" json:"name"`
+}
+
+type ProviderFixture struct {
+	Name string `yaml:"name" json:"name"`
+	Ref  string `yaml:"ref" json:"ref"`
+}
+
+type CategoryFixture struct {
+	Name        string `yaml:"name" json:"name"`
+	Slug        string `yaml:"slug" json:"slug"`
+	Type        string `yaml:"type" json:"type"`
+	Menu        string `yaml:"menu" json:"menu"`         // nama menu
+	Provider    string `yaml:"provider" json:"provider"` // ref provider
+	Status      string `yaml:"status" json:"status"`
+	Description string `yaml:"description" json:"description"`
+	InputType   string `yaml:"input_type" json:"input_type"`
+	ImgURL      string `yaml:"img_url" json:"img_url"`
+	IsLogin     bool   `yaml:"is_login" json:"is_login"`
+}
+
+type ProductFixture struct {
+	Name        string      `yaml:"name" json:"name"`
+	SKU         string      `yaml:"sku" json:"sku"`
+	Seller      string      `yaml:"seller" json:"seller"`
+	Category    string      `yaml:"category" json:"category"` // slug kategori
+	Provider    string      `yaml:"provider" json:"provider"` // ref provider
+	Status      string      `yaml:"status" json:"status"`
+	Description string      `yaml:"description" json:"description"`
+	ImgURL      string      `yaml:"img_url" json:"img_url"`
+	StartOff    string      `yaml:"start_off" json:"start_off"`
+	EndOff      string      `yaml:"end_off" json:"end_off"`
+	Stock       int64       `yaml:"stock" json:"stock"`
+	BasePrice   money.Money `yaml:"base_price" json:"base_price"`
+	// Prices: harga jual per nama user level
+	Prices map[string]money.Money `yaml:"prices" json:"prices"`
+}
+
+type PaymentMethodFixture struct {
+	Name     string      `yaml:"name" json:"name"`
+	Type     string      `yaml:"type" json:"type"`
+	ImgURL   string      `yaml:"img_url" json:"img_url"`
+	Provider string      `yaml:"provider" json:"provider"` // ref provider
+	Fee      money.Money `yaml:"fee" json:"fee"`
+	Percent  money.Rate  `yaml:"percent" json:"percent"` // persen, mis. 0.7
+}
+
+type BannerFixture struct {
+	ImgURL string `yaml:"img_url" json:"img_url"`
+}
+
+// AdminFixture: password tidak pernah disimpan di file, lihat --admin-password
+type AdminFixture struct {
+	Name     string `yaml:"name" json:"name"`
+	Email    string `yaml:"email" json:"email"`
+	Whatsapp string `yaml:"whatsapp" json:"whatsapp"`
+	Level    string `yaml:"level" json:"level"`
+}
+
+// LoadFixtures membaca base/ lalu <profile>/ dari fsys. File dalam satu folder dimuat
+// berurutan nama, dan isinya digabung (list ditambahkan, settings/admin ditimpa file berikutnya).
+func LoadFixtures(fsys fs.FS, profile string) (*Fixtures, error) {
+	out := &Fixtures{Settings: map[string]string{}}
+	found := false
+	for _, dir := range []string{"base", profile} {
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			if dir == "base" && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("profile %q: %w", profile, err)
+		}
+		names := make([]string, 0, len(entries))
+		for _, e := range entries {
+			if !e.IsDir() {
+				names = append(names, e.Name())
+			}
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			var f Fixtures
+			file := path.Join(dir, name)
+			body, err := fs.ReadFile(fsys, file)
+			if err != nil {
+				return nil, err
+			}
+			switch strings.ToLower(path.Ext(name)) {
+			case ".yaml", ".yml":
+				err = yaml.Unmarshal(body, &f)
+			case ".json":
+				err = json.Unmarshal(body, &f)
+			default:
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file, err)
+			}
+			out.merge(&f)
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("profile %q has no fixture files", profile)
+	}
+	return out, nil
+}
+
+func (f *Fixtures) merge(o *Fixtures) {
+	f.Levels = append(f.Levels, o.Levels...)
+	f.Menus = append(f.Menus, o.Menus...)
+	f.Providers = append(f.Providers, o.Providers...)
+	f.Categories = append(f.Categories, o.Categories...)
+	f.Products = append(f.Products, o.Products...)
+	f.PaymentMethods = append(f.PaymentMethods, o.PaymentMethods...)
+	f.Banners = append(f.Banners, o.Banners...)
+	for k, v := range o.Settings {
+		f.Settings[k] = v
+	}
+	if o.Admin != nil {
+		f.Admin = o.Admin
+	}
+}
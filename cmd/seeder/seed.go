@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/service"
+	"github.com/wildanasyrof/backend-topup/pkg/hash"
+	"github.com/wildanasyrof/backend-topup/pkg/utils"
+)
+
+// seeder menulis fixture ke database dalam satu transaksi.
+// Semua baris di-upsert berdasarkan natural key, jadi aman dijalankan berulang kali.
+type seeder struct {
+	tx  *sql.Tx
+	now time.Time
+}
+
+func seedFixtures(ctx context.Context, db *sql.DB, f *Fixtures, adminPassword string) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	s := &seeder{tx: tx, now: time.Now()}
+	steps := []struct {
+		name string
+		fn   func(context.Context, *Fixtures) (int, error)
+	}{
+		{"user levels", s.levels},
+		{"menus", s.menus},
+		{"providers", s.providers},
+		{"categories", s.categories},
+		{"products", s.products},
+		{"payment methods", s.paymentMethods},
+		{"banners", s.banners},
+		{"settings", s.settings},
+	}
+	for _, step := range steps {
+		n, err := step.fn(ctx, f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", step.name, err)
+		}
+		if n > 0 {
+			fmt.Printf("  %-16s %d\n", step.name, n)
+		}
+	}
+
+	if f.Admin != nil {
+		if err := s.admin(ctx, f.Admin, adminPassword); err != nil {
+			return fmt.Errorf("admin: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *seeder) levels(ctx context.Context, f *Fixtures) (int, error) {
+	for _, l := range f.Levels {
+		if _, err := s.upsert(ctx, "user_levels", []string{"name"}, l.Name, "description", l.Description); err != nil {
+			return 0, err
+		}
+	}
+	return len(f.Levels), nil
+}
+
+func (s *seeder) menus(ctx context.Context, f *Fixtures) (int, error) {
+	for _, m := range f.Menus {
+		if _, err := s.upsert(ctx, "menus", []string{"name"}, m.Name); err != nil {
+			return 0, err
+		}
+	}
+	return len(f.Menus), nil
+}
+
+func (s *seeder) providers(ctx context.Context, f *Fixtures) (int, error) {
+	for _, p := range f.Providers {
+		if _, err := s.upsert(ctx, "providers", []string{"ref"}, p.Ref, "name", p.Name); err != nil {
+			return 0, err
+		}
+	}
+	return len(f.Providers), nil
+}
+
+func (s *seeder) categories(ctx context.Context, f *Fixtures) (int, error) {
+	for _, c := range f.Categories {
+		menuID, err := s.lookup(ctx, "menus", "name", c.Menu)
+		if err != nil {
+			return 0, fmt.Errorf("category %q: %w", c.Slug, err)
+		}
+		providerID, err := s.lookup(ctx, "providers", "ref", c.Provider)
+		if err != nil {
+			return 0, fmt.Errorf("category %q: %w", c.Slug, err)
+		}
+		if _, err := s.upsert(ctx, "categories", []string{"slug"}, c.Slug,
+			"name", c.Name,
+			"type", orDefault(c.Type, "prabayar"),
+			"menu_id", menuID,
+			"provider_id", providerID,
+			"status", orDefault(c.Status, "active"),
+			"description", c.Description,
+			"input_type", c.InputType,
+			"img_url", c.ImgURL,
+			"is_login", c.IsLogin,
+		); err != nil {
+			return 0, fmt.Errorf("category %q: %w", c.Slug, err)
+		}
+	}
+	return len(f.Categories), nil
+}
+
+func (s *seeder) products(ctx context.Context, f *Fixtures) (int, error) {
+	for _, p := range f.Products {
+		categoryID, err := s.lookup(ctx, "categories", "slug", p.Category)
+		if err != nil {
+			return 0, fmt.Errorf("product %q: %w", p.SKU, err)
+		}
+		providerID, err := s.lookup(ctx, "providers", "ref", p.Provider)
+		if err != nil {
+			return 0, fmt.Errorf("product %q: %w", p.SKU, err)
+		}
+		productID, err := s.upsert(ctx, "products", []string{"sku_code"}, p.SKU,
+			"name", p.Name,
+			"seller_name", p.Seller,
+			"category_id", categoryID,
+			"provider_id", providerID,
+			"status", orDefault(p.Status, "active"),
+			"stock", p.Stock,
+			"base_price", p.BasePrice,
+			"description", p.Description,
+			"img_url", p.ImgURL,
+			"start_off", p.StartOff,
+			"end_off", p.EndOff,
+		)
+		if err != nil {
+			return 0, fmt.Errorf("product %q: %w", p.SKU, err)
+		}
+
+		for level, amount := range p.Prices {
+			levelID, err := s.lookup(ctx, "user_levels", "name", level)
+			if err != nil {
+				return 0, fmt.Errorf("product %q price: %w", p.SKU, err)
+			}
+			if _, err := s.upsert(ctx, "prices", []string{"product_id", "user_level_id"}, productID, levelID, "amount", amount); err != nil {
+				return 0, fmt.Errorf("product %q price %q: %w", p.SKU, level, err)
+			}
+		}
+	}
+	return len(f.Products), nil
+}
+
+func (s *seeder) paymentMethods(ctx context.Context, f *Fixtures) (int, error) {
+	for _, m := range f.PaymentMethods {
+		providerID, err := s.lookup(ctx, "providers", "ref", m.Provider)
+		if err != nil {
+			return 0, fmt.Errorf("payment method %q: %w", m.Name, err)
+		}
+		if _, err := s.upsert(ctx, "payment_methods", []string{"name"}, m.Name,
+			"type", m.Type,
+			"img_url", m.ImgURL,
+			"provider_id", providerID,
+			"fee", m.Fee,
+			"percent", m.Percent,
+		); err != nil {
+			return 0, fmt.Errorf("payment method %q: %w", m.Name, err)
+		}
+	}
+	return len(f.PaymentMethods), nil
+}
+
+func (s *seeder) banners(ctx context.Context, f *Fixtures) (int, error) {
+	for _, b := range f.Banners {
+		if _, err := s.upsert(ctx, "banners", []string{"img_url"}, b.ImgURL); err != nil {
+			return 0, err
+		}
+	}
+	return len(f.Banners), nil
+}
+
+func (s *seeder) settings(ctx context.Context, f *Fixtures) (int, error) {
+	for name, value := range f.Settings {
+		// Fixture divalidasi dengan registry yang sama dengan API agar nilai tidak diam-diam diganti default
+		def, ok := service.LookupSetting(name)
+		if !ok {
+			return 0, fmt.Errorf("setting %q is not declared in the settings registry", name)
+		}
+		v, err := def.Parse(value)
+		if err != nil {
+			return 0, fmt.Errorf("setting %q: %w", name, err)
+		}
+		if _, err := s.upsert(ctx, "settings", []string{"name"}, name, "value", def.Format(v)); err != nil {
+			return 0, fmt.Errorf("setting %q: %w", name, err)
+		}
+	}
+	return len(f.Settings), nil
+}
+
+// admin membuat akun admin jika belum ada. Akun yang sudah ada hanya dipastikan ber-role admin;
+// password-nya tidak ditimpa agar menjalankan ulang seeder tidak me-reset password yang sudah diganti.
+func (s *seeder) admin(ctx context.Context, a *AdminFixture, password string) error {
+	var id uint64
+	err := s.tx.QueryRowContext(ctx, `UPDATE users SET role = 'admin', updated_at = $2 WHERE email = $1 RETURNING id`, a.Email, s.now).Scan(&id)
+	if err == nil {
+		fmt.Printf("  admin            %s (exists, password unchanged)\n", a.Email)
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if password == "" {
+		return errors.New("admin password is required: pass --admin-password or set SEED_ADMIN_PASSWORD")
+	}
+	levelID, err := s.lookup(ctx, "user_levels", "name", orDefault(a.Level, "Basic"))
+	if err != nil {
+		return err
+	}
+	hashed := hash.HashPassword(password)
+	if hashed == "" {
+		return errors.New("failed to hash admin password")
+	}
+
+	_, err = s.tx.ExecContext(ctx, `
+INSERT INTO users (name, email, password_hash, user_level_id, role, whatsapp, email_verified_at, is_verified, created_at, updated_at)
+VALUES ($1, $2, $3, $4, 'admin', $5, $6, true, $6, $6)`,
+		orDefault(a.Name, "Admin"), a.Email, hashed, levelID, utils.NormalizePhone(a.Whatsapp), s.now)
+	if err == nil {
+		fmt.Printf("  admin            %s (created)\n", a.Email)
+	}
+	return err
+}
+
+// upsert memperbarui baris dengan natural key yang sama, atau menyisipkan baris baru jika belum ada,
+// lalu mengembalikan ID-nya. Argumen: nilai key sesuai urutan keys, lalu pasangan kolom, nilai.
+// Tidak memakai ON CONFLICT karena sebagian natural key (nama menu, ref provider, ...) tidak punya unique index.
+func (s *seeder) upsert(ctx context.Context, table string, keys []string, args ...any) (uint64, error) {
+	if len(args) < len(keys) || (len(args)-len(keys))%2 != 0 {
+		return 0, fmt.Errorf("upsert %s: bad arguments", table)
+	}
+
+	cols := append([]string{}, keys...)
+	vals := append([]any{}, args[:len(keys)]...)
+	for i := len(keys); i < len(args); i += 2 {
+		cols = append(cols, args[i].(string))
+		vals = append(vals, args[i+1])
+	}
+	vals = append(vals, s.now)
+	ts := len(vals) // placeholder untuk created_at/updated_at
+
+	where := make([]string, len(keys))
+	for i, k := range keys {
+		where[i] = fmt.Sprintf("%s = $%d", k, i+1)
+	}
+	set := []string{fmt.Sprintf("updated_at = $%d", ts)}
+	for i := len(keys); i < len(cols); i++ {
+		set = append(set, fmt.Sprintf("%s = $%d", cols[i], i+1))
+	}
+
+	var id uint64
+	err := s.tx.QueryRowContext(ctx, fmt.Sprintf(`UPDATE %s SET %s WHERE %s RETURNING id`,
+		table, strings.Join(set, ", "), strings.Join(where, " AND ")), vals...).Scan(&id)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return id, err
+	}
+
+	placeholders := make([]string, len(cols))
+	for i := range cols {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	err = s.tx.QueryRowContext(ctx, fmt.Sprintf(`INSERT INTO %s (%s, created_at, updated_at) VALUES (%s, $%d, $%d) RETURNING id`,
+		table, strings.Join(cols, ", "), strings.Join(placeholders, ", "), ts, ts), vals...).Scan(&id)
+	return id, err
+}
+
+// lookup mencari ID dari natural key dengan pesan error yang jelas bila referensi fixture salah ketik.
+func (s *seeder) lookup(ctx context.Context, table, column, value string) (uint64, error) {
+	var id uint64
+	err := s.tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT id FROM %s WHERE %s = $1 ORDER BY id LIMIT 1`, table, column), value).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%s with %s %q not found", table, column, value)
+	}
+	return id, err
+}
+
+func orDefault(v, def string) string {
+	if v == "" {
+		return def
+	}
+	return v
+}
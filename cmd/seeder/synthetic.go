@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/pkg/hash"
+)
+
+// syntheticOptions: jumlah data palsu untuk load test. Data dibuat di database
+// dengan generate_series sehingga ratusan ribu baris tetap cepat.
+type syntheticOptions struct {
+	Users    int
+	Products int
+	Orders   int
+	Password string // password semua user sintetis; kosong berarti user tidak bisa login dengan password
+}
+
+func (o syntheticOptions) empty() bool {
+	return o.Users <= 0 && o.Products <= 0 && o.Orders <= 0
+}
+
+// Semua data sintetis diberi penanda (email loadtest+N@, SKU/ref SYN-N, kategori load-test)
+// sehingga idempotent lewat ON CONFLICT dan mudah dibersihkan.
+func seedSynthetic(ctx context.Context, db *sql.DB, o syntheticOptions) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	s := &seeder{tx: tx, now: time.Now()}
+
+	// Menu, provider dan kategori khusus agar produk sintetis tidak bercampur katalog asli
+	menuID, err := s.upsert(ctx, "menus", []string{"name"}, "Load Test")
+	if err != nil {
+		return err
+	}
+	providerID, err := s.upsert(ctx, "providers", []string{"ref"}, "loadtest", "name", "Load Test")
+	if err != nil {
+		return err
+	}
+	categoryID, err := s.upsert(ctx, "categories", []string{"slug"}, "load-test",
+		"name", "Load Test",
+		"type", "prabayar",
+		"menu_id", menuID,
+		"provider_id", providerID,
+		"status", "active",
+		"input_type", "customer_id",
+		"img_url", "https://example.com/img/load-test.png",
+	)
+	if err != nil {
+		return err
+	}
+
+	if o.Users > 0 {
+		var passwordHash any // NULL jika tanpa password
+		if o.Password != "" {
+			passwordHash = hash.HashPassword(o.Password)
+		}
+		res, err := tx.ExecContext(ctx, `
+INSERT INTO users (name, email, password_hash, user_level_id, role, email_verified_at, is_verified, created_at, updated_at)
+SELECT 'Load Test ' || g, 'loadtest+' || g || '@topup.local', $2,
+       (SELECT id FROM user_levels ORDER BY id LIMIT 1), 'user', $3, true, $3, $3
+FROM generate_series(1, $1::int) AS g
+ON CONFLICT (email) DO NOTHING`, o.Users, passwordHash, s.now)
+		if err != nil {
+			return fmt.Errorf("synthetic users: %w", err)
+		}
+		report("synthetic users", res)
+	}
+
+	if o.Products > 0 {
+		res, err := tx.ExecContext(ctx, `
+INSERT INTO products (name, sku_code, seller_name, category_id, provider_id, status, stock, base_price, description, img_url, created_at, updated_at)
+SELECT 'Synthetic Product ' || g, 'SYN-' || g, 'Load Test', $2, $3, 'active', 1000000,
+       1000 * (1 + g % 200), 'Generated for load testing', 'https://example.com/img/load-test.png', $4, $4
+FROM generate_series(1, $1::int) AS g
+ON CONFLICT (sku_code) DO NOTHING`, o.Products, categoryID, providerID, s.now)
+		if err != nil {
+			return fmt.Errorf("synthetic products: %w", err)
+		}
+		report("synthetic products", res)
+
+		// Harga untuk setiap level: base price dengan diskon 1% per level
+		res, err = tx.ExecContext(ctx, `
+INSERT INTO prices (product_id, user_level_id, amount, created_at, updated_at)
+SELECT p.id, l.id, round(p.base_price * (1 - 0.01 * (l.rn - 1))), $1, $1
+FROM products p
+CROSS JOIN (SELECT id, row_number() OVER (ORDER BY id) AS rn FROM user_levels) l
+WHERE p.sku_code LIKE 'SYN-%'
+AND NOT EXISTS (SELECT 1 FROM prices x WHERE x.product_id = p.id AND x.user_level_id = l.id)`, s.now)
+		if err != nil {
+			return fmt.Errorf("synthetic prices: %w", err)
+		}
+		report("synthetic prices", res)
+	}
+
+	if o.Orders > 0 {
+		// Order disebar merata ke user & produk sintetis dan ke 90 hari terakhir
+		res, err := tx.ExecContext(ctx, `
+WITH u AS (SELECT array_agg(id ORDER BY id) AS ids FROM users WHERE email LIKE 'loadtest+%@topup.local'),
+     p AS (SELECT array_agg(id ORDER BY id) AS ids, array_agg(base_price ORDER BY id) AS prices FROM products WHERE sku_code LIKE 'SYN-%')
+INSERT INTO orders (order_ref, user_id, product_id, customer_id, payment_status, status, amount, fee, created_at, updated_at)
+SELECT 'SYN-' || g,
+       u.ids[1 + g % cardinality(u.ids)],
+       p.ids[1 + (g * 7) % cardinality(p.ids)],
+       lpad((g % 100000000)::text, 10, '0'),
+       (ARRAY['success', 'success', 'processing', 'canceled'])[1 + g % 4],
+       (ARRAY['success', 'success', 'processing', 'canceled'])[1 + g % 4],
+       p.prices[1 + (g * 7) % cardinality(p.prices)], 0,
+       $2::timestamptz - (g % 90) * interval '1 day', $2
+FROM generate_series(1, $1::int) AS g, u, p
+WHERE cardinality(u.ids) > 0 AND cardinality(p.ids) > 0
+ON CONFLICT (order_ref) DO NOTHING`, o.Orders, s.now)
+		if err != nil {
+			return fmt.Errorf("synthetic orders: %w", err)
+		}
+		n := report("synthetic orders", res)
+		if n == 0 {
+			fmt.Println("  (orders need synthetic users and products; pass --synthetic-users and --synthetic-products)")
+		}
+	}
+
+	return tx.Commit()
+}
+
+func report(name string, res sql.Result) int64 {
+	n, _ := res.RowsAffected()
+	fmt.Printf("  %-20s %d new\n", name, n)
+	return n
+}
@@ -1,145 +1,297 @@
 package config
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
 	"os"
-	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
+// DefaultConfigFile dibaca jika CONFIG_FILE kosong dan file ini ada di working directory.
+const DefaultConfigFile = "config.yaml"
+
 // Config holds all configuration structs
 type Config struct {
-	Server ServerConfig
-	Db     DatabaseConfig
-	JWT    JwtConfig
-	Oauth  GoogleOauthConfig
+	Server    ServerConfig    `yaml:"server"`
+	Cors      CorsConfig      `yaml:"cors"`
+	Cookie    CookieConfig    `yaml:"cookie"`
+	Db        DatabaseConfig  `yaml:"db"`
+	JWT       JwtConfig       `yaml:"jwt"`
+	Oauth     OAuthConfig     `yaml:"oauth"`
+	Mail      MailConfig      `yaml:"mail"`
+	Whatsapp  WhatsappConfig  `yaml:"whatsapp"`
+	Digiflazz DigiflazzConfig `yaml:"digiflazz"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Metrics   MetricsConfig   `yaml:"metrics"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Health    HealthConfig    `yaml:"health"`
+	Security  SecurityConfig  `yaml:"security"`
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
-	Port           string
-	RequestTimeOut int
-	Env            string
-	UploadDir      string
-	CookieDomain   string
+	Port           string `yaml:"port"`
+	RequestTimeOut int    `yaml:"request_timeout"` // detik
+	Env            string `yaml:"env"`
+	UploadDir      string `yaml:"upload_dir"`
+
+	// ProxyHeader berisi IP client asli dan hanya dibaca dari request yang datang lewat TrustedProxies.
+	// Pakai header yang ditulis ulang oleh proxy (mis. X-Real-IP); nilai paling kiri X-Forwarded-For
+	// dikirim client dan bisa dipalsukan. Kosong berarti IP koneksi langsung.
+	ProxyHeader    string   `yaml:"proxy_header"`
+	TrustedProxies []string `yaml:"trusted_proxies"` // IP/CIDR load balancer atau reverse proxy
 }
 
-// GoogleOauthConfig holds Google OAuth specific configuration
-type GoogleOauthConfig struct {
-	ClientID     string
-	ClientSecret string
-	CallbackUrl  string
+// CorsConfig holds the origins allowed to call the API from a browser
+type CorsConfig struct {
+	AllowOrigins     []string `yaml:"allow_origins"`
+	AllowHeaders     []string `yaml:"allow_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials"` // wajib true agar cookie refresh token ikut terkirim
+	MaxAge           int      `yaml:"max_age"`           // detik, cache preflight di browser
 }
 
-// DatabaseConfig holds database connection details
-type DatabaseConfig struct {
-	Host     string
-	Port     string
-	Database string
-	Username string
-	Password string
-	// DbUrl can be constructed if needed, but keeping components is often better
-	// DbUrl string
+// CookieConfig holds the attributes of the refresh token cookie
+type CookieConfig struct {
+	Domain   string `yaml:"domain"`
+	Path     string `yaml:"path"`
+	SameSite string `yaml:"same_site"` // Lax, Strict or None
+	// Secure nil berarti otomatis: true kecuali Env=development. Selalu terisi setelah LoadConfig.
+	Secure *bool `yaml:"secure"`
 }
 
-// JwtConfig holds JWT secret and lifetime configuration
-type JwtConfig struct {
-	AccessSecret       string // Renamed from JWTSecret
-	RefreshSecret      string // Added for refresh token
-	AccessTokenMinutes int
-	RefreshTokenDays   int
+// OAuthConfig holds the enabled social login providers
+type OAuthConfig struct {
+	Providers  []OAuthProviderConfig `yaml:"providers"`
+	StateStore string                `yaml:"state_store"` // "memory" (single instance) or "database"
 }
 
-// LoadConfig reads configuration from environment variables and returns a Config struct.
-func LoadConfig() (*Config, error) {
-	// Attempt to load .env file. It's OK if it doesn't exist.
-	if err := godotenv.Load(); err != nil {
-		log.Println("No .env file found or error loading it, reading from environment variables")
-	}
+// OAuthProviderConfig holds a single OAuth/OIDC provider configuration.
+// Endpoints left empty are filled from the provider preset or from OIDC discovery on Issuer.
+type OAuthProviderConfig struct {
+	Name         string   `yaml:"name"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	CallbackUrl  string   `yaml:"callback_url"`
+	Issuer       string   `yaml:"issuer"`
+	AuthURL      string   `yaml:"auth_url"`
+	TokenURL     string   `yaml:"token_url"`
+	UserInfoURL  string   `yaml:"userinfo_url"`
+	Scopes       []string `yaml:"scopes"`
+	PKCE         bool     `yaml:"pkce"`
+}
 
-	// Helper function to get a string environment variable with a default value.
-	getEnv := func(key, defaultValue string) string {
-		if value, exists := os.LookupEnv(key); exists {
-			return value
-		}
-		log.Printf("Environment variable %s not found, using default value: %s", key, defaultValue)
-		return defaultValue
+// UnmarshalYAML mengaktifkan PKCE secara default untuk provider dari file config.
+func (p *OAuthProviderConfig) UnmarshalYAML(n *yaml.Node) error {
+	type plain OAuthProviderConfig
+	out := plain{PKCE: true}
+	if err := n.Decode(&out); err != nil {
+		return err
 	}
+	*p = OAuthProviderConfig(out)
+	return nil
+}
 
-	// Helper function to get an int environment variable with a default value and error handling.
-	getEnvAsInt := func(key string, defaultValue int) (int, error) {
-		valueStr := os.Getenv(key)
-		if valueStr == "" {
-			log.Printf("Environment variable %s not found, using default value: %d", key, defaultValue)
-			return defaultValue, nil
-		}
-		value, err := strconv.Atoi(valueStr)
-		if err != nil {
-			// Return an error instead of fatal logging
-			return 0, fmt.Errorf("invalid integer value for %s: %w", key, err)
-		}
-		return value, nil
-	}
+// MailConfig holds outgoing email configuration
+type MailConfig struct {
+	Driver   string `yaml:"driver"` // "smtp" or "memory"
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
 
-	// --- Server Config ---
-	requestTimeOut, err := getEnvAsInt("HTTP_REQUEST_TIME_OUT", 15) // Default to 15
-	if err != nil {
-		return nil, err // Return error to caller
-	}
+// WhatsappConfig holds WhatsApp gateway configuration
+type WhatsappConfig struct {
+	Driver  string `yaml:"driver"` // "fonnte" or "memory"
+	BaseURL string `yaml:"base_url"`
+	Token   string `yaml:"token"`
+}
 
-	// --- JWT Config ---
-	accessTokenMinutes, err := getEnvAsInt("ACCESS_TOKEN_MINUTES", 15) // Default to 15
-	if err != nil {
-		return nil, err
-	}
+// DigiflazzConfig holds the product supplier credentials
+type DigiflazzConfig struct {
+	BaseURL  string `yaml:"base_url"`
+	Username string `yaml:"username"`
+	APIKey   string `yaml:"api_key"`
+}
 
-	refreshTokenDays, err := getEnvAsInt("REFRESH_TOKEN_DAYS", 30) // Default to 30
-	if err != nil {
-		return nil, err
-	}
+// RateLimitConfig holds the storage backend of the rate limit middleware
+type RateLimitConfig struct {
+	Store    string `yaml:"store"`     // "memory" (single instance), "redis" or "database"
+	RedisURL string `yaml:"redis_url"` // redis://[:password@]host:port/db, required when Store=redis
+}
+
+// MetricsConfig holds the Prometheus /metrics listener, separate from the public API port
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Addr    string `yaml:"addr"`  // host:port; default hanya loopback, mis. ":9090" membuka semua interface
+	Token   string `yaml:"token"` // bearer token required from the scraper; wajib jika Addr bukan loopback
+}
+
+// TracingConfig holds the OpenTelemetry trace exporter.
+// Exporter "none" tetap membuat trace ID (untuk log) tanpa mengirim span ke mana pun.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"` // none, stdout, otlp
+	Endpoint    string  `yaml:"endpoint"` // host:port collector OTLP/HTTP, mis. "localhost:4318"
+	Insecure    bool    `yaml:"insecure"` // kirim OTLP lewat http biasa, bukan https
+	SampleRatio float64 `yaml:"sample_ratio"`
+	ServiceName string  `yaml:"service_name"`
+}
+
+// HealthConfig holds readiness checks and graceful shutdown draining
+type HealthConfig struct {
+	CheckSupplier bool          `yaml:"check_supplier"` // laporkan juga apakah supplier bisa dijangkau (tidak memengaruhi ready)
+	Timeout       time.Duration `yaml:"timeout"`        // batas waktu per check
+	// DrainDelay: jeda antara readiness menjadi false dan server berhenti menerima koneksi,
+	// agar load balancer sempat mengeluarkan instance ini dari rotasi
+	DrainDelay time.Duration `yaml:"drain_delay"`
+}
 
-	cfg := &Config{
+// SecurityConfig holds server-side keys that never leave the backend
+type SecurityConfig struct {
+	// OTPSecret adalah kunci HMAC untuk hash kode OTP; tanpa kunci ini kode 6 digit di database
+	// bisa ditebak ulang dalam hitungan detik dari dump
+	OTPSecret string `yaml:"otp_secret"`
+	// EncryptionKey mengenkripsi secret yang harus bisa dibaca ulang (seed TOTP, signing secret API key)
+	EncryptionKey string `yaml:"encryption_key"`
+}
+
+// DatabaseConfig holds database connection details
+type DatabaseConfig struct {
+	Host     string `yaml:"host"`
+	Port     string `yaml:"port"`
+	Database string `yaml:"database"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	SSLMode  string `yaml:"sslmode"`  // disable, allow, prefer, require, verify-ca or verify-full
+	TimeZone string `yaml:"timezone"` // zona waktu session postgres
+
+	MaxOpenConns    int           `yaml:"max_open_conns"` // 0 = tanpa batas
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+}
+
+// JwtConfig holds JWT secret and lifetime configuration
+type JwtConfig struct {
+	AccessSecret       string `yaml:"access_secret"` // HS256 fallback, only used when KeysDir is empty
+	KeysDir            string `yaml:"keys_dir"`      // directory of <kid>.pem signing/verification keys (RS256 or EdDSA)
+	ActiveKeyID        string `yaml:"active_kid"`    // kid used to sign new tokens
+	AccessTokenMinutes int    `yaml:"access_token_minutes"`
+	RefreshTokenDays   int    `yaml:"refresh_token_days"`
+}
+
+// defaults adalah nilai yang dipakai jika tidak diisi di file maupun environment.
+func defaults() *Config {
+	return &Config{
 		Server: ServerConfig{
-			Port:           getEnv("PORT", "8080"),
-			RequestTimeOut: requestTimeOut,
-			Env:            getEnv("ENV", "development"),
-			UploadDir:      getEnv("UPLOAD_DIR", "./uploads"),
-			CookieDomain:   getEnv("COOKIE_DOMAIN", "localhost"), // <--- TAMBAHKAN INI
+			Port:           "8080",
+			RequestTimeOut: 15,
+			Env:            "development",
+			UploadDir:      "./uploads",
+		},
+		Cors: CorsConfig{
+			AllowOrigins:     []string{"http://localhost:5173"},
+			AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
+			AllowCredentials: true,
+		},
+		Cookie: CookieConfig{
+			Domain:   "localhost",
+			Path:     "/auth", // Hanya kirim cookie ke endpoint /auth/*
+			SameSite: "Lax",
 		},
 		Db: DatabaseConfig{
-			Host:     getEnv("DB_HOST", "127.0.0.1"),
-			Port:     getEnv("DB_PORT", "5432"),
-			Database: getEnv("DB_DATABASE", ""), // No sensible default for database name
-			Username: getEnv("DB_USERNAME", "postgres"),
-			Password: getEnv("DB_PASSWORD", ""), // No default for password
-			// DbUrl: dbURL, // You can assign the constructed URL here if needed elsewhere
+			Host:            "127.0.0.1",
+			Port:            "5432",
+			Username:        "postgres",
+			SSLMode:         "disable",
+			TimeZone:        "Asia/Jakarta",
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
 		},
 		JWT: JwtConfig{
-			AccessSecret:       getEnv("ACCESS_SECRET", ""),  // No default for secrets
-			RefreshSecret:      getEnv("REFRESH_SECRET", ""), // No default for secrets
-			AccessTokenMinutes: accessTokenMinutes,
-			RefreshTokenDays:   refreshTokenDays,
+			AccessTokenMinutes: 15,
+			RefreshTokenDays:   30,
 		},
-		Oauth: GoogleOauthConfig{
-			ClientID:     getEnv("GOOGLE_OAUTH_CLIENT_ID", ""),     // No default for client ID
-			ClientSecret: getEnv("GOOGLE_OAUTH_CLIENT_SECRET", ""), // No default for client secret
-			CallbackUrl:  getEnv("GOOGLE_OAUTH_CALLBACK_URL", ""),  // No default for callback URL
+		Oauth: OAuthConfig{StateStore: "memory"},
+		Mail: MailConfig{
+			Driver: "memory",
+			Port:   "587",
+			From:   "no-reply@topup.local",
 		},
+		Whatsapp: WhatsappConfig{
+			Driver:  "memory",
+			BaseURL: "https://api.fonnte.com",
+		},
+		Digiflazz: DigiflazzConfig{
+			BaseURL: "http://localhost:3000",
+		},
+		RateLimit: RateLimitConfig{Store: "memory"},
+		Metrics:   MetricsConfig{Enabled: true, Addr: "127.0.0.1:9090"},
+		Tracing:   TracingConfig{Exporter: "none", SampleRatio: 1, ServiceName: "backend-topup"},
+		Health:    HealthConfig{Timeout: 2 * time.Second, DrainDelay: 5 * time.Second},
+	}
+}
+
+// LoadConfig membangun Config dari default, lalu file YAML (CONFIG_FILE atau ./config.yaml),
+// lalu environment variable (termasuk .env). Secret bisa dibaca dari file lewat <VAR>_FILE.
+// Semua masalah dikumpulkan dan dikembalikan sekaligus sebagai satu error.
+func LoadConfig() (*Config, error) {
+	// .env opsional; hanya error jika file ada tapi tidak bisa dibaca
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("load .env: %w", err)
 	}
 
-	// Basic validation for essential empty values
-	if cfg.JWT.AccessSecret == "" {
-		log.Println("Warning: ACCESS_SECRET is not set.")
+	cfg := defaults()
+	if err := loadFile(cfg); err != nil {
+		return nil, err
 	}
-	if cfg.JWT.RefreshSecret == "" {
-		log.Println("Warning: REFRESH_SECRET is not set.")
+
+	env := &envLoader{}
+	env.apply(cfg)
+	if len(env.problems) > 0 {
+		return nil, &ValidationError{Problems: env.problems}
 	}
-	if cfg.Db.Database == "" {
-		log.Println("Warning: DB_DATABASE is not set.")
+
+	if cfg.Cookie.Secure == nil {
+		secure := cfg.Server.Env != "development"
+		cfg.Cookie.Secure = &secure
 	}
-	// Add more checks as needed, potentially returning errors for critical missing values
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
+
+// loadFile membaca file YAML ke cfg. Key yang tidak dikenal ditolak agar typo tidak diam-diam diabaikan.
+func loadFile(cfg *Config) error {
+	path := os.Getenv("CONFIG_FILE")
+	explicit := path != ""
+	if !explicit {
+		path = DefaultConfigFile
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) && !explicit {
+			return nil
+		}
+		return fmt.Errorf("open config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envLoader menimpa nilai config dengan environment variable yang di-set.
+// Variable kosong (mis. baris "DB_PASSWORD=" di .env) diabaikan agar tidak menghapus nilai dari file config.
+// Nilai yang tidak bisa di-parse dicatat di problems, bukan langsung menghentikan proses.
+type envLoader struct {
+	problems []string
+}
+
+func (e *envLoader) apply(cfg *Config) {
+	e.str("PORT", &cfg.Server.Port)
+	e.int("HTTP_REQUEST_TIME_OUT", &cfg.Server.RequestTimeOut)
+	e.str("ENV", &cfg.Server.Env)
+	e.str("UPLOAD_DIR", &cfg.Server.UploadDir)
+	e.str("PROXY_HEADER", &cfg.Server.ProxyHeader)
+	e.list("TRUSTED_PROXIES", &cfg.Server.TrustedProxies)
+
+	e.list("CORS_ALLOW_ORIGINS", &cfg.Cors.AllowOrigins)
+	e.list("CORS_ALLOW_HEADERS", &cfg.Cors.AllowHeaders)
+	e.bool("CORS_ALLOW_CREDENTIALS", &cfg.Cors.AllowCredentials)
+	e.int("CORS_MAX_AGE", &cfg.Cors.MaxAge)
+
+	e.str("COOKIE_DOMAIN", &cfg.Cookie.Domain)
+	e.str("COOKIE_PATH", &cfg.Cookie.Path)
+	e.str("COOKIE_SAMESITE", &cfg.Cookie.SameSite)
+	if os.Getenv("COOKIE_SECURE") != "" {
+		var secure bool
+		e.bool("COOKIE_SECURE", &secure)
+		cfg.Cookie.Secure = &secure
+	}
+
+	e.str("DB_HOST", &cfg.Db.Host)
+	e.str("DB_PORT", &cfg.Db.Port)
+	e.str("DB_DATABASE", &cfg.Db.Database)
+	e.str("DB_USERNAME", &cfg.Db.Username)
+	e.secret("DB_PASSWORD", &cfg.Db.Password)
+	e.str("DB_SSLMODE", &cfg.Db.SSLMode)
+	e.str("DB_TIMEZONE", &cfg.Db.TimeZone)
+	e.int("DB_MAX_OPEN_CONNS", &cfg.Db.MaxOpenConns)
+	e.int("DB_MAX_IDLE_CONNS", &cfg.Db.MaxIdleConns)
+	e.duration("DB_CONN_MAX_LIFETIME", &cfg.Db.ConnMaxLifetime)
+	e.duration("DB_CONN_MAX_IDLE_TIME", &cfg.Db.ConnMaxIdleTime)
+
+	e.secret("ACCESS_SECRET", &cfg.JWT.AccessSecret)
+	e.str("JWT_KEYS_DIR", &cfg.JWT.KeysDir)
+	e.str("JWT_ACTIVE_KID", &cfg.JWT.ActiveKeyID)
+	e.int("ACCESS_TOKEN_MINUTES", &cfg.JWT.AccessTokenMinutes)
+	e.int("REFRESH_TOKEN_DAYS", &cfg.JWT.RefreshTokenDays)
+
+	e.oauth(&cfg.Oauth)
+
+	e.str("MAIL_DRIVER", &cfg.Mail.Driver)
+	e.str("MAIL_HOST", &cfg.Mail.Host)
+	e.str("MAIL_PORT", &cfg.Mail.Port)
+	e.str("MAIL_USERNAME", &cfg.Mail.Username)
+	e.secret("MAIL_PASSWORD", &cfg.Mail.Password)
+	e.str("MAIL_FROM", &cfg.Mail.From)
+
+	e.str("WHATSAPP_DRIVER", &cfg.Whatsapp.Driver)
+	e.str("WHATSAPP_BASE_URL", &cfg.Whatsapp.BaseURL)
+	e.secret("WHATSAPP_TOKEN", &cfg.Whatsapp.Token)
+
+	e.str("DIGIFLAZZ_BASE_URL", &cfg.Digiflazz.BaseURL)
+	e.str("DIGIFLAZZ_USERNAME", &cfg.Digiflazz.Username)
+	e.secret("DIGIFLAZZ_API_KEY", &cfg.Digiflazz.APIKey)
+
+	e.str("RATE_LIMIT_STORE", &cfg.RateLimit.Store)
+	e.secret("REDIS_URL", &cfg.RateLimit.RedisURL)
+
+	e.bool("METRICS_ENABLED", &cfg.Metrics.Enabled)
+	e.str("METRICS_ADDR", &cfg.Metrics.Addr)
+	e.secret("METRICS_TOKEN", &cfg.Metrics.Token)
+
+	e.str("TRACING_EXPORTER", &cfg.Tracing.Exporter)
+	e.str("TRACING_ENDPOINT", &cfg.Tracing.Endpoint)
+	e.bool("TRACING_INSECURE", &cfg.Tracing.Insecure)
+	e.float("TRACING_SAMPLE_RATIO", &cfg.Tracing.SampleRatio)
+	e.str("TRACING_SERVICE_NAME", &cfg.Tracing.ServiceName)
+
+	e.bool("HEALTH_CHECK_SUPPLIER", &cfg.Health.CheckSupplier)
+	e.duration("HEALTH_CHECK_TIMEOUT", &cfg.Health.Timeout)
+	e.duration("HEALTH_DRAIN_DELAY", &cfg.Health.DrainDelay)
+
+	e.secret("OTP_SECRET", &cfg.Security.OTPSecret)
+	e.secret("ENCRYPTION_KEY", &cfg.Security.EncryptionKey)
+}
+
+// oauth membaca OAUTH_PROVIDERS (comma separated) and the OAUTH_<NAME>_* variables of each provider.
+// Provider yang juga ada di file config memakai nilai file sebagai dasar.
+// The legacy GOOGLE_OAUTH_* variables still enable and configure the google provider.
+func (e *envLoader) oauth(out *OAuthConfig) {
+	e.str("OAUTH_STATE_STORE", &out.StateStore)
+
+	var names []string
+	if list := os.Getenv("OAUTH_PROVIDERS"); list != "" {
+		for _, n := range strings.Split(list, ",") {
+			if n = strings.ToLower(strings.TrimSpace(n)); n != "" {
+				names = append(names, n)
+			}
+		}
+	} else {
+		for _, p := range out.Providers {
+			names = append(names, p.Name)
+		}
+		if len(names) == 0 && os.Getenv("GOOGLE_OAUTH_CLIENT_ID") != "" {
+			names = []string{"google"}
+		}
+	}
+
+	providerKey := func(name, key string) string {
+		k := "OAUTH_" + strings.ToUpper(name) + "_" + key
+		if name == "google" && !isSet(k) && isSet("GOOGLE_OAUTH_"+key) {
+			return "GOOGLE_OAUTH_" + key
+		}
+		return k
+	}
+
+	providers := make([]OAuthProviderConfig, 0, len(names))
+	for _, name := range names {
+		p := OAuthProviderConfig{Name: name, PKCE: true}
+		for _, fp := range out.Providers {
+			if strings.EqualFold(fp.Name, name) {
+				p = fp
+				p.Name = name
+			}
+		}
+
+		e.str(providerKey(name, "CLIENT_ID"), &p.ClientID)
+		e.secret(providerKey(name, "CLIENT_SECRET"), &p.ClientSecret)
+		e.str(providerKey(name, "CALLBACK_URL"), &p.CallbackUrl)
+		e.str(providerKey(name, "ISSUER"), &p.Issuer)
+		e.str(providerKey(name, "AUTH_URL"), &p.AuthURL)
+		e.str(providerKey(name, "TOKEN_URL"), &p.TokenURL)
+		e.str(providerKey(name, "USERINFO_URL"), &p.UserInfoURL)
+		e.bool(providerKey(name, "PKCE"), &p.PKCE)
+		if scopes := os.Getenv(providerKey(name, "SCOPES")); scopes != "" {
+			p.Scopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+		}
+		providers = append(providers, p)
+	}
+	out.Providers = providers
+}
+
+func isSet(key string) bool {
+	return os.Getenv(key) != ""
+}
+
+func (e *envLoader) str(key string, dst *string) {
+	if v := os.Getenv(key); v != "" {
+		*dst = v
+	}
+}
+
+// secret seperti str, tapi juga menerima <key>_FILE berisi path ke file secret
+// (mis. Docker/Kubernetes secret). Newline di akhir file diabaikan.
+func (e *envLoader) secret(key string, dst *string) {
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		e.str(key, dst)
+		return
+	}
+	if isSet(key) {
+		e.problems = append(e.problems, fmt.Sprintf("%s and %s_FILE are both set, use only one", key, key))
+		return
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		e.problems = append(e.problems, fmt.Sprintf("%s_FILE: %v", key, err))
+		return
+	}
+	*dst = strings.TrimRight(string(b), "\r\n")
+}
+
+func (e *envLoader) int(key string, dst *int) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		e.problems = append(e.problems, fmt.Sprintf("%s must be an integer, got %q", key, v))
+		return
+	}
+	*dst = n
+}
+
+func (e *envLoader) float(key string, dst *float64) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		e.problems = append(e.problems, fmt.Sprintf("%s must be a number, got %q", key, v))
+		return
+	}
+	*dst = f
+}
+
+func (e *envLoader) bool(key string, dst *bool) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	b, err := strconv.ParseBool(strings.TrimSpace(v))
+	if err != nil {
+		e.problems = append(e.problems, fmt.Sprintf("%s must be true or false, got %q", key, v))
+		return
+	}
+	*dst = b
+}
+
+func (e *envLoader) duration(key string, dst *time.Duration) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(v))
+	if err != nil {
+		e.problems = append(e.problems, fmt.Sprintf("%s must be a duration like 30s or 5m, got %q", key, v))
+		return
+	}
+	*dst = d
+}
+
+// list membaca daftar comma separated.
+func (e *envLoader) list(key string, dst *[]string) {
+	v := os.Getenv(key)
+	if v == "" {
+		return
+	}
+	out := []string{}
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	*dst = out
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// writeFile menulis file sementara dan mengembalikan path-nya
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigEnvOverridesFile(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", `
+server:
+  port: "8000"
+  env: production
+cors:
+  allow_origins: ["https://shop.example.com"]
+db:
+  host: db.internal
+  database: topup
+  password: from-file
+jwt:
+  access_secret: from-file
+security:
+  otp_secret: `+testSecret+`
+  encryption_key: `+testSecret+`
+`))
+	t.Setenv("PORT", "9000")
+	t.Setenv("DB_DATABASE", "topup_env")
+	t.Setenv("DB_MAX_OPEN_CONNS", "50")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.1, 10.1.0.0/16,")
+	// Variable kosong (mis. "DB_HOST=" di .env) tidak menghapus nilai dari file
+	t.Setenv("DB_HOST", "")
+	t.Setenv("CORS_ALLOW_ORIGINS", "")
+	t.Setenv("ACCESS_SECRET", "")
+	t.Setenv("COOKIE_SECURE", "")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Server.Port != "9000" || cfg.Db.Database != "topup_env" || cfg.Db.MaxOpenConns != 50 {
+		t.Errorf("env not applied: port %q, database %q, max open %d", cfg.Server.Port, cfg.Db.Database, cfg.Db.MaxOpenConns)
+	}
+	if !slices.Equal(cfg.Server.TrustedProxies, []string{"10.0.0.1", "10.1.0.0/16"}) {
+		t.Errorf("trusted proxies %q", cfg.Server.TrustedProxies)
+	}
+	if cfg.Db.Host != "db.internal" || cfg.JWT.AccessSecret != "from-file" || !slices.Equal(cfg.Cors.AllowOrigins, []string{"https://shop.example.com"}) {
+		t.Errorf("empty env cleared file values: %+v", cfg)
+	}
+	// Nilai yang tidak ada di file maupun env memakai default
+	if cfg.Db.TimeZone != "Asia/Jakarta" || cfg.JWT.AccessTokenMinutes != 15 {
+		t.Errorf("defaults lost: timezone %q, access minutes %d", cfg.Db.TimeZone, cfg.JWT.AccessTokenMinutes)
+	}
+	// COOKIE_SECURE kosong berarti otomatis: true di luar development
+	if cfg.Cookie.Secure == nil || !*cfg.Cookie.Secure {
+		t.Errorf("cookie secure %v, want true in production", cfg.Cookie.Secure)
+	}
+}
+
+func TestLoadConfigRejectsUnknownFileKeys(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", "server:\n  prot: \"8000\"\n"))
+	if _, err := LoadConfig(); err == nil || !strings.Contains(err.Error(), "prot") {
+		t.Fatalf("err = %v, want the unknown key reported", err)
+	}
+}
+
+func TestEnvSecretFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		value   string
+		file    string // isi file secret; "-" berarti path tidak ada
+		want    string
+		problem string
+	}{
+		{name: "value", value: "plain", want: "plain"},
+		{name: "file", file: "s3cret\n", want: "s3cret"},
+		{name: "file with CRLF", file: "s3cret\r\n", want: "s3cret"},
+		{name: "file keeps inner whitespace", file: " s3 cret\n", want: " s3 cret"},
+		{name: "both set", value: "plain", file: "s3cret", want: "from-config", problem: "DB_PASSWORD and DB_PASSWORD_FILE are both set"},
+		{name: "missing file", file: "-", want: "from-config", problem: "DB_PASSWORD_FILE:"},
+		{name: "neither set", want: "from-config"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("DB_PASSWORD", tc.value)
+			switch tc.file {
+			case "":
+				t.Setenv("DB_PASSWORD_FILE", "")
+			case "-":
+				t.Setenv("DB_PASSWORD_FILE", filepath.Join(t.TempDir(), "missing"))
+			default:
+				t.Setenv("DB_PASSWORD_FILE", writeFile(t, "db_password", tc.file))
+			}
+
+			e := &envLoader{}
+			got := "from-config"
+			e.secret("DB_PASSWORD", &got)
+			if got != tc.want {
+				t.Errorf("secret = %q, want %q", got, tc.want)
+			}
+			if tc.problem == "" && len(e.problems) > 0 || tc.problem != "" && (len(e.problems) != 1 || !strings.HasPrefix(e.problems[0], tc.problem)) {
+				t.Errorf("problems %q, want %q", e.problems, tc.problem)
+			}
+		})
+	}
+}
+
+func TestEnvOAuthProviders(t *testing.T) {
+	fileProviders := func() []OAuthProviderConfig {
+		return []OAuthProviderConfig{
+			{Name: "google", ClientID: "file-google", CallbackUrl: "https://api.example.com/google", Scopes: []string{"openid"}, PKCE: true},
+			{Name: "github", ClientID: "file-github", PKCE: false},
+		}
+	}
+	byName := func(ps []OAuthProviderConfig) map[string]OAuthProviderConfig {
+		out := map[string]OAuthProviderConfig{}
+		for _, p := range ps {
+			out[p.Name] = p
+		}
+		return out
+	}
+
+	t.Run("env overrides fields of file providers", func(t *testing.T) {
+		t.Setenv("OAUTH_GITHUB_CLIENT_ID", "env-github")
+		t.Setenv("OAUTH_GITHUB_PKCE", "true")
+		t.Setenv("OAUTH_GOOGLE_SCOPES", "openid, email profile")
+		cfg := OAuthConfig{Providers: fileProviders()}
+		e := &envLoader{}
+		e.oauth(&cfg)
+
+		got := byName(cfg.Providers)
+		if len(got) != 2 || got["github"].ClientID != "env-github" || !got["github"].PKCE {
+			t.Fatalf("github %+v", got["github"])
+		}
+		google := got["google"]
+		if google.ClientID != "file-google" || google.CallbackUrl != "https://api.example.com/google" ||
+			!slices.Equal(google.Scopes, []string{"openid", "email", "profile"}) {
+			t.Fatalf("google %+v", google)
+		}
+	})
+
+	t.Run("OAUTH_PROVIDERS replaces the file list", func(t *testing.T) {
+		t.Setenv("OAUTH_PROVIDERS", " Google, discord ,")
+		t.Setenv("OAUTH_DISCORD_CLIENT_ID", "env-discord")
+		cfg := OAuthConfig{Providers: fileProviders()}
+		e := &envLoader{}
+		e.oauth(&cfg)
+
+		got := byName(cfg.Providers)
+		if len(got) != 2 || got["google"].ClientID != "file-google" {
+			t.Fatalf("providers %+v", cfg.Providers)
+		}
+		// Provider baru dari env memakai PKCE secara default
+		if d := got["discord"]; d.ClientID != "env-discord" || !d.PKCE {
+			t.Fatalf("discord %+v", d)
+		}
+	})
+
+	t.Run("legacy GOOGLE_OAUTH variables", func(t *testing.T) {
+		t.Setenv("GOOGLE_OAUTH_CLIENT_ID", "legacy-id")
+		t.Setenv("GOOGLE_OAUTH_CLIENT_SECRET", "legacy-secret")
+		t.Setenv("GOOGLE_OAUTH_CALLBACK_URL", "https://api.example.com/legacy")
+		var cfg OAuthConfig
+		e := &envLoader{}
+		e.oauth(&cfg)
+		if len(cfg.Providers) != 1 {
+			t.Fatalf("providers %+v, want google enabled by the legacy variables", cfg.Providers)
+		}
+		if g := cfg.Providers[0]; g.Name != "google" || g.ClientID != "legacy-id" || g.ClientSecret != "legacy-secret" || g.CallbackUrl != "https://api.example.com/legacy" {
+			t.Fatalf("google %+v", g)
+		}
+
+		// Variable baru menang atas yang lama
+		t.Setenv("OAUTH_GOOGLE_CLIENT_ID", "new-id")
+		cfg = OAuthConfig{}
+		e.oauth(&cfg)
+		if g := cfg.Providers[0]; g.ClientID != "new-id" || g.ClientSecret != "legacy-secret" {
+			t.Fatalf("google %+v", g)
+		}
+	})
+
+	t.Run("PKCE defaults to true in the config file", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", `
+oauth:
+  providers:
+    - name: google
+      client_id: a
+    - name: legacy
+      client_id: b
+      pkce: false
+`))
+		cfg := defaults()
+		if err := loadFile(cfg); err != nil {
+			t.Fatal(err)
+		}
+		got := byName(cfg.Oauth.Providers)
+		if !got["google"].PKCE || got["legacy"].PKCE {
+			t.Fatalf("providers %+v", cfg.Oauth.Providers)
+		}
+	})
+}
+
+func TestLoadConfigCollectsEnvProblems(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeFile(t, "config.yaml", ""))
+	t.Setenv("HTTP_REQUEST_TIME_OUT", "15s")
+	t.Setenv("METRICS_ENABLED", "maybe")
+	t.Setenv("HEALTH_CHECK_TIMEOUT", "2")
+	t.Setenv("TRACING_SAMPLE_RATIO", "half")
+
+	_, err := LoadConfig()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("err = %v, want a ValidationError", err)
+	}
+	want := []string{"HTTP_REQUEST_TIME_OUT must be an integer", "METRICS_ENABLED must be true or false", "TRACING_SAMPLE_RATIO must be a number", "HEALTH_CHECK_TIMEOUT must be a duration"}
+	if len(verr.Problems) != len(want) {
+		t.Fatalf("problems %q", verr.Problems)
+	}
+	for i, p := range want {
+		if !strings.HasPrefix(verr.Problems[i], p) {
+			t.Errorf("problem %d = %q, want %q", i, verr.Problems[i], p)
+		}
+	}
+}
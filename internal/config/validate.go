@@ -0,0 +1,214 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ValidationError berisi semua masalah konfigurasi, agar bisa diperbaiki sekaligus.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate memeriksa kombinasi nilai config. Nama field ditulis sebagai env var beserta key YAML-nya.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	oneOf := func(name, value string, allowed ...string) {
+		if !slices.Contains(allowed, value) {
+			add("%s must be one of %s, got %q", name, strings.Join(allowed, ", "), value)
+		}
+	}
+
+	// --- Server ---
+	if p, err := strconv.Atoi(c.Server.Port); err != nil || p < 1 || p > 65535 {
+		add("PORT (server.port) must be a TCP port, got %q", c.Server.Port)
+	}
+	if c.Server.RequestTimeOut <= 0 {
+		add("HTTP_REQUEST_TIME_OUT (server.request_timeout) must be greater than 0")
+	}
+	if c.Server.Env == "" {
+		add("ENV (server.env) is required")
+	}
+	if c.Server.UploadDir == "" {
+		add("UPLOAD_DIR (server.upload_dir) is required")
+	}
+	// Tanpa daftar proxy, header IP dari siapa pun akan dipercaya
+	if c.Server.ProxyHeader != "" && len(c.Server.TrustedProxies) == 0 {
+		add("TRUSTED_PROXIES (server.trusted_proxies) is required when PROXY_HEADER is set")
+	}
+	for _, p := range c.Server.TrustedProxies {
+		if net.ParseIP(p) == nil {
+			if _, _, err := net.ParseCIDR(p); err != nil {
+				add("TRUSTED_PROXIES (server.trusted_proxies) must contain IPs or CIDRs, got %q", p)
+			}
+		}
+	}
+
+	// --- CORS & cookie ---
+	if len(c.Cors.AllowOrigins) == 0 {
+		add("CORS_ALLOW_ORIGINS (cors.allow_origins) needs at least one origin")
+	}
+	for _, o := range c.Cors.AllowOrigins {
+		if o == "*" {
+			if c.Cors.AllowCredentials {
+				add("CORS_ALLOW_ORIGINS (cors.allow_origins) cannot be * when CORS_ALLOW_CREDENTIALS is true")
+			}
+			continue
+		}
+		if u, err := url.Parse(o); err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			add("CORS_ALLOW_ORIGINS (cors.allow_origins) entry %q must be an origin like https://example.com", o)
+		}
+	}
+	if c.Cors.MaxAge < 0 {
+		add("CORS_MAX_AGE (cors.max_age) cannot be negative")
+	}
+	oneOf("COOKIE_SAMESITE (cookie.same_site)", c.Cookie.SameSite, "Lax", "Strict", "None")
+	if c.Cookie.SameSite == "None" && c.Cookie.Secure != nil && !*c.Cookie.Secure {
+		add("COOKIE_SECURE (cookie.secure) must be true when COOKIE_SAMESITE is None")
+	}
+	if !strings.HasPrefix(c.Cookie.Path, "/") {
+		add("COOKIE_PATH (cookie.path) must start with /")
+	}
+
+	// --- Database ---
+	if c.Db.Host == "" {
+		add("DB_HOST (db.host) is required")
+	}
+	if c.Db.Database == "" {
+		add("DB_DATABASE (db.database) is required")
+	}
+	if c.Db.Username == "" {
+		add("DB_USERNAME (db.username) is required")
+	}
+	oneOf("DB_SSLMODE (db.sslmode)", c.Db.SSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	if c.Db.TimeZone == "" {
+		add("DB_TIMEZONE (db.timezone) is required")
+	}
+	if c.Db.MaxOpenConns < 0 || c.Db.MaxIdleConns < 0 {
+		add("DB_MAX_OPEN_CONNS and DB_MAX_IDLE_CONNS cannot be negative")
+	}
+	if c.Db.MaxOpenConns > 0 && c.Db.MaxIdleConns > c.Db.MaxOpenConns {
+		add("DB_MAX_IDLE_CONNS (db.max_idle_conns) cannot exceed DB_MAX_OPEN_CONNS (%d)", c.Db.MaxOpenConns)
+	}
+	if c.Db.ConnMaxLifetime < 0 || c.Db.ConnMaxIdleTime < 0 {
+		add("DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME cannot be negative")
+	}
+
+	// --- JWT ---
+	if c.JWT.KeysDir == "" && c.JWT.AccessSecret == "" {
+		add("either JWT_KEYS_DIR (jwt.keys_dir) or ACCESS_SECRET (jwt.access_secret) is required")
+	}
+	if c.JWT.KeysDir != "" && c.JWT.ActiveKeyID == "" {
+		add("JWT_ACTIVE_KID (jwt.active_kid) is required when JWT_KEYS_DIR is set")
+	}
+	if c.JWT.AccessTokenMinutes <= 0 {
+		add("ACCESS_TOKEN_MINUTES (jwt.access_token_minutes) must be greater than 0")
+	}
+	if c.JWT.RefreshTokenDays <= 0 {
+		add("REFRESH_TOKEN_DAYS (jwt.refresh_token_days) must be greater than 0")
+	}
+
+	// --- OAuth ---
+	oneOf("OAUTH_STATE_STORE (oauth.state_store)", c.Oauth.StateStore, "memory", "database")
+	for _, p := range c.Oauth.Providers {
+		if p.Name == "" {
+			add("oauth.providers entries need a name")
+			continue
+		}
+		if p.ClientID == "" || p.CallbackUrl == "" {
+			name := strings.ToUpper(p.Name)
+			add("OAUTH_%s_CLIENT_ID and OAUTH_%s_CALLBACK_URL are required", name, name)
+		}
+	}
+
+	// --- Mail & WhatsApp ---
+	oneOf("MAIL_DRIVER (mail.driver)", c.Mail.Driver, "memory", "smtp")
+	if c.Mail.Driver == "smtp" && c.Mail.Host == "" {
+		add("MAIL_HOST (mail.host) is required when MAIL_DRIVER=smtp")
+	}
+	oneOf("WHATSAPP_DRIVER (whatsapp.driver)", c.Whatsapp.Driver, "memory", "fonnte")
+	if c.Whatsapp.Driver == "fonnte" && c.Whatsapp.Token == "" {
+		add("WHATSAPP_TOKEN (whatsapp.token) is required when WHATSAPP_DRIVER=fonnte")
+	}
+
+	// --- Supplier ---
+	if u, err := url.Parse(c.Digiflazz.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		add("DIGIFLAZZ_BASE_URL (digiflazz.base_url) must be an absolute URL, got %q", c.Digiflazz.BaseURL)
+	}
+	if (c.Digiflazz.Username == "") != (c.Digiflazz.APIKey == "") {
+		add("DIGIFLAZZ_USERNAME and DIGIFLAZZ_API_KEY must be set together")
+	}
+
+	// --- Rate limit ---
+	oneOf("RATE_LIMIT_STORE (rate_limit.store)", c.RateLimit.Store, "memory", "redis", "database")
+	if c.RateLimit.Store == "redis" && c.RateLimit.RedisURL == "" {
+		add("REDIS_URL (rate_limit.redis_url) is required when RATE_LIMIT_STORE=redis")
+	}
+
+	// --- Metrics ---
+	if c.Metrics.Enabled {
+		host, port, err := net.SplitHostPort(c.Metrics.Addr)
+		switch {
+		case err != nil || port == "":
+			add("METRICS_ADDR (metrics.addr) must be host:port like 127.0.0.1:9090, got %q", c.Metrics.Addr)
+		case port == c.Server.Port:
+			add("METRICS_ADDR (metrics.addr) must not use the public API port %s", c.Server.Port)
+		case !isLoopback(host) && c.Metrics.Token == "":
+			// Metrics berisi route, status dan volume transaksi; jangan terbuka tanpa token di interface lain
+			add("METRICS_TOKEN (metrics.token) is required when METRICS_ADDR listens beyond loopback, got %q", c.Metrics.Addr)
+		}
+	}
+
+	// --- Tracing ---
+	oneOf("TRACING_EXPORTER (tracing.exporter)", c.Tracing.Exporter, "none", "stdout", "otlp")
+	if c.Tracing.Exporter == "otlp" && c.Tracing.Endpoint == "" {
+		add("TRACING_ENDPOINT (tracing.endpoint) is required when TRACING_EXPORTER=otlp")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		add("TRACING_SAMPLE_RATIO (tracing.sample_ratio) must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+	}
+	if c.Tracing.ServiceName == "" {
+		add("TRACING_SERVICE_NAME (tracing.service_name) is required")
+	}
+
+	// --- Health ---
+	if c.Health.Timeout <= 0 {
+		add("HEALTH_CHECK_TIMEOUT (health.timeout) must be greater than 0")
+	}
+	if c.Health.DrainDelay < 0 {
+		add("HEALTH_DRAIN_DELAY (health.drain_delay) cannot be negative")
+	}
+
+	// --- Security ---
+	if len(c.Security.OTPSecret) < 32 {
+		add("OTP_SECRET (security.otp_secret) must be at least 32 characters")
+	}
+	if len(c.Security.EncryptionKey) < 32 {
+		add("ENCRYPTION_KEY (security.encryption_key) must be at least 32 characters")
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// isLoopback: host kosong (semua interface) bukan loopback
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// validConfig adalah default ditambah nilai wajib yang tidak punya default
+func validConfig() *Config {
+	cfg := defaults()
+	cfg.Db.Database = "topup"
+	cfg.JWT.AccessSecret = "secret"
+	cfg.Security.OTPSecret = testSecret
+	cfg.Security.EncryptionKey = testSecret
+	return cfg
+}
+
+func TestValidateAcceptsDefaults(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateCollectsEveryProblem(t *testing.T) {
+	cfg := validConfig()
+	cfg.Server.Port = "http"
+	cfg.Server.ProxyHeader = "X-Real-IP"
+	cfg.Cors.AllowOrigins = []string{"*"}
+	cfg.Cookie.SameSite = "Loose"
+	cfg.Db.MaxIdleConns = 50
+	cfg.JWT.KeysDir = "/keys"
+	cfg.Mail.Driver = "smtp"
+	cfg.Security.OTPSecret = "short"
+
+	err := cfg.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("err = %v, want a ValidationError", err)
+	}
+	want := []string{
+		`PORT (server.port) must be a TCP port, got "http"`,
+		"TRUSTED_PROXIES (server.trusted_proxies) is required when PROXY_HEADER is set",
+		"CORS_ALLOW_ORIGINS (cors.allow_origins) cannot be * when CORS_ALLOW_CREDENTIALS is true",
+		`COOKIE_SAMESITE (cookie.same_site) must be one of Lax, Strict, None, got "Loose"`,
+		"DB_MAX_IDLE_CONNS (db.max_idle_conns) cannot exceed DB_MAX_OPEN_CONNS (25)",
+		"JWT_ACTIVE_KID (jwt.active_kid) is required when JWT_KEYS_DIR is set",
+		"MAIL_HOST (mail.host) is required when MAIL_DRIVER=smtp",
+		"OTP_SECRET (security.otp_secret) must be at least 32 characters",
+	}
+	if strings.Join(verr.Problems, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("problems:\n%s\nwant:\n%s", strings.Join(verr.Problems, "\n"), strings.Join(want, "\n"))
+	}
+	if msg := err.Error(); !strings.HasPrefix(msg, "invalid configuration:\n  - PORT") || strings.Count(msg, "\n  - ") != len(want) {
+		t.Fatalf("message %q", msg)
+	}
+}
+
+func TestValidateRules(t *testing.T) {
+	cases := []struct {
+		name    string
+		mutate  func(*Config)
+		problem string // kosong berarti valid
+	}{
+		{"trusted proxy CIDR", func(c *Config) { c.Server.TrustedProxies = []string{"10.0.0.0/8", "::1"} }, ""},
+		{"trusted proxy hostname", func(c *Config) { c.Server.TrustedProxies = []string{"lb.internal"} }, "TRUSTED_PROXIES (server.trusted_proxies) must contain IPs or CIDRs"},
+		{"origin with path", func(c *Config) { c.Cors.AllowOrigins = []string{"https://shop.example.com/app"} }, "CORS_ALLOW_ORIGINS (cors.allow_origins) entry"},
+		{"wildcard origin without credentials", func(c *Config) { c.Cors.AllowOrigins, c.Cors.AllowCredentials = []string{"*"}, false }, ""},
+		{"SameSite None over http", func(c *Config) {
+			insecure := false
+			c.Cookie.SameSite, c.Cookie.Secure = "None", &insecure
+		}, "COOKIE_SECURE (cookie.secure) must be true"},
+		{"keys dir with kid", func(c *Config) { c.JWT.AccessSecret, c.JWT.KeysDir, c.JWT.ActiveKeyID = "", "/keys", "2026-01" }, ""},
+		{"no signing key", func(c *Config) { c.JWT.AccessSecret = "" }, "either JWT_KEYS_DIR"},
+		{"oauth provider without callback", func(c *Config) { c.Oauth.Providers = []OAuthProviderConfig{{Name: "google", ClientID: "id"}} }, "OAUTH_GOOGLE_CLIENT_ID and OAUTH_GOOGLE_CALLBACK_URL are required"},
+		{"fonnte without token", func(c *Config) { c.Whatsapp.Driver = "fonnte" }, "WHATSAPP_TOKEN (whatsapp.token) is required"},
+		{"supplier key without username", func(c *Config) { c.Digiflazz.APIKey = "key" }, "DIGIFLAZZ_USERNAME and DIGIFLAZZ_API_KEY must be set together"},
+		{"redis without url", func(c *Config) { c.RateLimit.Store = "redis" }, "REDIS_URL (rate_limit.redis_url) is required"},
+		{"metrics on the API port", func(c *Config) { c.Metrics.Addr = ":8080" }, "METRICS_ADDR (metrics.addr) must not use the public API port 8080"},
+		{"metrics address without port", func(c *Config) { c.Metrics.Addr = "localhost" }, "METRICS_ADDR (metrics.addr) must be host:port"},
+		{"metrics on all interfaces without token", func(c *Config) { c.Metrics.Addr = ":9090" }, "METRICS_TOKEN (metrics.token) is required"},
+		{"metrics on a private IP without token", func(c *Config) { c.Metrics.Addr = "10.0.0.5:9090" }, "METRICS_TOKEN (metrics.token) is required"},
+		{"metrics on all interfaces with token", func(c *Config) { c.Metrics.Addr, c.Metrics.Token = ":9090", "scrape" }, ""},
+		{"metrics on localhost", func(c *Config) { c.Metrics.Addr = "localhost:9090" }, ""},
+		{"metrics on IPv6 loopback", func(c *Config) { c.Metrics.Addr = "[::1]:9090" }, ""},
+		{"metrics disabled ignores address", func(c *Config) { c.Metrics.Enabled, c.Metrics.Addr = false, "" }, ""},
+		{"otlp without endpoint", func(c *Config) { c.Tracing.Exporter = "otlp" }, "TRACING_ENDPOINT (tracing.endpoint) is required"},
+		{"sample ratio above 1", func(c *Config) { c.Tracing.SampleRatio = 1.5 }, "TRACING_SAMPLE_RATIO (tracing.sample_ratio) must be between 0 and 1"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validConfig()
+			tc.mutate(cfg)
+			err := cfg.Validate()
+			if tc.problem == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) || len(verr.Problems) != 1 || !strings.HasPrefix(verr.Problems[0], tc.problem) {
+				t.Fatalf("err = %v, want only %q", err, tc.problem)
+			}
+		})
+	}
+}
@@ -1,34 +1,86 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/wildanasyrof/backend-topup/internal/config"
-	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/db/migrations"
 	logger "github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/migrate"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+// Connect membuka koneksi database untuk API. Skema tidak dimigrasi di sini:
+// API menolak start jika masih ada migrasi pending, jalankan `go run ./cmd/migrate up` dulu.
 func Connect(cfg *config.Config, logger logger.Logger) *gorm.DB {
-	dsn := buildPostgresDSN(cfg) // Asumsikan cfg.Db adalah struct yang menampung field DB
+	db := Open(cfg, logger)
+
+	if err := db.Use(tracingPlugin{}); err != nil {
+		logger.Fatal("failed to register gorm tracing, " + err.Error())
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Fatal("failed to get database handle, " + err.Error())
+	}
+	m, err := migrate.New(sqlDB, migrations.FS)
+	if err != nil {
+		logger.Fatal("failed to load migrations, " + err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		logger.Fatal("failed to check schema version, " + err.Error())
+	}
+	if len(pending) > 0 {
+		logger.Fatal(fmt.Sprintf("database schema is behind: %d pending migration(s), first is %d_%s; run `go run ./cmd/migrate up`",
+			len(pending), pending[0].Version, pending[0].Name))
+	}
+	return db
+}
+
+// Open membuka koneksi database tanpa memeriksa versi skema (dipakai cmd/migrate).
+func Open(cfg *config.Config, logger logger.Logger) *gorm.DB {
+	dsn := buildPostgresDSN(cfg.Db)
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
 		logger.Fatal("failed to connect database error, " + err.Error())
 	}
-	if err := db.AutoMigrate(&entity.User{}, &entity.Menu{}, &entity.Settings{}, &entity.PaymentMethod{}, &entity.Banner{}, &entity.Deposit{}, &entity.Provider{}, &entity.Category{}, &entity.UserLevel{}, &entity.Product{}, &entity.Price{}, &entity.Order{}, &entity.UserSession{}); err != nil {
-		logger.Fatal("auto-migrate failed: " + err.Error())
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Fatal("failed to get database handle, " + err.Error())
 	}
+	sqlDB.SetMaxOpenConns(cfg.Db.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.Db.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.Db.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.Db.ConnMaxIdleTime)
 	return db
 }
 
-func buildPostgresDSN(cfg *config.Config) string {
+func buildPostgresDSN(c config.DatabaseConfig) string {
 	return fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta",
-		cfg.Db.Host,
-		cfg.Db.Username,
-		cfg.Db.Password,
-		cfg.Db.Database,
-		cfg.Db.Port,
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s",
+		dsnValue(c.Host),
+		dsnValue(c.Username),
+		dsnValue(c.Password),
+		dsnValue(c.Database),
+		dsnValue(c.Port),
+		dsnValue(c.SSLMode),
+		dsnValue(c.TimeZone),
 	)
 }
+
+// dsnValue meng-quote nilai DSN key=value agar password berisi spasi atau kutip tetap terbaca utuh.
+func dsnValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS "user_sessions";
+DROP TABLE IF EXISTS "orders";
+DROP TABLE IF EXISTS "prices";
+DROP TABLE IF EXISTS "products";
+DROP TABLE IF EXISTS "categories";
+DROP TABLE IF EXISTS "deposit";
+DROP TABLE IF EXISTS "banners";
+DROP TABLE IF EXISTS "payment_methods";
+DROP TABLE IF EXISTS "providers";
+DROP TABLE IF EXISTS "settings";
+DROP TABLE IF EXISTS "menus";
+DROP TABLE IF EXISTS "users";
+DROP TABLE IF EXISTS "user_levels";
@@ -0,0 +1,199 @@
+-- Baseline: skema yang dibuat AutoMigrate sebelum fitur-fitur berikutnya (13 tabel awal).
+-- Semua statement memakai IF NOT EXISTS agar database lama yang dibuat AutoMigrate
+-- bisa langsung ditandai sudah di versi ini tanpa error. Tabel dan kolom yang ditambahkan
+-- sesudahnya ada di migrasi berikutnya, yang juga idempotent karena database yang dibuat
+-- AutoMigrate versi lebih baru mungkin sudah memilikinya.
+
+CREATE TABLE IF NOT EXISTS "user_levels" (
+    "id" bigserial,
+    "name" varchar(100) NOT NULL,
+    "description" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" bigserial,
+    "name" text NOT NULL,
+    "email" text NOT NULL,
+    "role" varchar(10) DEFAULT 'user',
+    "balance" decimal NOT NULL DEFAULT 0,
+    "email_verified_at" timestamptz,
+    "password_hash" varchar(255),
+    "whatsapp" varchar(255),
+    "google_id" varchar(255),
+    "google_type" varchar(255),
+    "otp" bigint,
+    "is_verified" boolean NOT NULL DEFAULT false,
+    "remember_token" boolean NOT NULL DEFAULT false,
+    "user_level_id" bigint NOT NULL DEFAULT 1,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_users_user_level" FOREIGN KEY ("user_level_id") REFERENCES "user_levels"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "uni_users_email" UNIQUE ("email")
+);
+
+CREATE TABLE IF NOT EXISTS "menus" (
+    "id" bigserial,
+    "name" varchar(255) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "settings" (
+    "id" bigserial,
+    "name" varchar(100) NOT NULL,
+    "value" text NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "providers" (
+    "id" bigserial,
+    "name" varchar(255) NOT NULL,
+    "ref" varchar(255) NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "payment_methods" (
+    "id" bigserial,
+    "type" varchar(255) NOT NULL,
+    "name" varchar(255) NOT NULL,
+    "img_url" varchar(255) NOT NULL,
+    "provider_id" bigint NOT NULL,
+    "fee" decimal,
+    "percent" decimal,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_payment_methods_provider" FOREIGN KEY ("provider_id") REFERENCES "providers"("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "banners" (
+    "id" bigserial,
+    "img_url" text NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "deposit" (
+    "id" bigserial,
+    "topup_id" varchar(255) NOT NULL,
+    "user_id" bigint NOT NULL,
+    "payment_method_id" bigint NOT NULL,
+    "amount" decimal NOT NULL,
+    "status" text NOT NULL,
+    "fee" decimal NOT NULL,
+    "payment" text NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_deposit_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "fk_deposit_method" FOREIGN KEY ("payment_method_id") REFERENCES "payment_methods"("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "deposit_status_check" CHECK (status IN ('pending','processing','success','canceled'))
+);
+
+CREATE TABLE IF NOT EXISTS "categories" (
+    "id" bigserial,
+    "name" varchar(255) NOT NULL,
+    "type" text NOT NULL DEFAULT 'prabayar',
+    "menu_id" bigint NOT NULL,
+    "provider_id" bigint NOT NULL,
+    "slug" varchar(255) NOT NULL,
+    "status" text NOT NULL DEFAULT 'inactive',
+    "description" text,
+    "input_type" varchar(255) NOT NULL,
+    "img_url" text NOT NULL,
+    "is_login" boolean,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_categories_provider" FOREIGN KEY ("provider_id") REFERENCES "providers"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "fk_menus_categories" FOREIGN KEY ("menu_id") REFERENCES "menus"("id"),
+    CONSTRAINT "uni_categories_name" UNIQUE ("name"),
+    CONSTRAINT "uni_categories_slug" UNIQUE ("slug"),
+    CONSTRAINT "cat_status_check" CHECK (status IN ('inactive','active','problem')),
+    CONSTRAINT "cat_type_check" CHECK (type IN ('prabayar','pascabayar'))
+);
+
+CREATE TABLE IF NOT EXISTS "products" (
+    "id" bigserial,
+    "name" varchar(255) NOT NULL,
+    "sku_code" text NOT NULL,
+    "seller_name" text,
+    "category_id" bigint NOT NULL,
+    "provider_id" bigint NOT NULL,
+    "status" text NOT NULL DEFAULT 'inactive',
+    "stock" bigint NOT NULL,
+    "base_price" decimal NOT NULL,
+    "description" text,
+    "img_url" text NOT NULL,
+    "start_off" text,
+    "end_off" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_products_provider" FOREIGN KEY ("provider_id") REFERENCES "providers"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "fk_categories_products" FOREIGN KEY ("category_id") REFERENCES "categories"("id"),
+    CONSTRAINT "uni_products_name" UNIQUE ("name"),
+    CONSTRAINT "uni_products_sku_code" UNIQUE ("sku_code"),
+    CONSTRAINT "cat_status_check" CHECK (status IN ('inactive','active','problem'))
+);
+
+CREATE TABLE IF NOT EXISTS "prices" (
+    "id" bigserial,
+    "product_id" bigint NOT NULL,
+    "user_level_id" bigint NOT NULL DEFAULT 1,
+    "amount" decimal NOT NULL,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_products_prices" FOREIGN KEY ("product_id") REFERENCES "products"("id"),
+    CONSTRAINT "fk_prices_user_level" FOREIGN KEY ("user_level_id") REFERENCES "user_levels"("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS "orders" (
+    "id" bigserial,
+    "order_ref" varchar(50) NOT NULL,
+    "user_id" bigint NOT NULL,
+    "product_id" bigint NOT NULL,
+    "wa" varchar(20),
+    "email" varchar(100),
+    "customer_name" varchar(100),
+    "customer_id" varchar(100),
+    "payment_ref" varchar(100),
+    "payment_status" text NOT NULL DEFAULT 'processing',
+    "status" text NOT NULL DEFAULT 'processing',
+    "amount" decimal NOT NULL,
+    "fee" decimal NOT NULL DEFAULT 0,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_orders_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "fk_orders_product" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "order_status_check" CHECK (status IN ('success','canceled','pending','processing'))
+);
+CREATE INDEX IF NOT EXISTS "idx_orders_product_id" ON "orders" ("product_id");
+CREATE INDEX IF NOT EXISTS "idx_orders_user_id" ON "orders" ("user_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "ux_orders_order_ref" ON "orders" ("order_ref");
+
+CREATE TABLE IF NOT EXISTS "user_sessions" (
+    "id" uuid,
+    "user_id" bigint NOT NULL,
+    "is_revoked" boolean NOT NULL DEFAULT false,
+    "expires_at" timestamptz NOT NULL,
+    "user_agent" text,
+    "client_ip" varchar(50),
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_sessions_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_user_sessions_expires_at" ON "user_sessions" ("expires_at");
+CREATE INDEX IF NOT EXISTS "idx_user_sessions_user_id" ON "user_sessions" ("user_id");
@@ -0,0 +1,14 @@
+-- OTP email untuk verifikasi dan reset password
+CREATE TABLE IF NOT EXISTS "user_otps" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "purpose" varchar(50) NOT NULL,
+    "code_hash" varchar(64) NOT NULL,
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "expires_at" timestamptz NOT NULL,
+    "consumed_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_otps_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_user_otps_user_purpose" ON "user_otps" ("user_id","purpose");
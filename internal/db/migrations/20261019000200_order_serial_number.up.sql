@@ -0,0 +1,2 @@
+-- Serial number dari supplier, dikirim ke customer saat order sukses
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "serial_number" varchar(255);
@@ -0,0 +1,13 @@
+-- Akun OAuth/OIDC yang ditautkan ke user
+CREATE TABLE IF NOT EXISTS "user_identities" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "provider" varchar(50) NOT NULL,
+    "subject" varchar(255) NOT NULL,
+    "email" varchar(255),
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_identities_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_identities_provider_subject" ON "user_identities" ("provider","subject");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_user_identities_user_provider" ON "user_identities" ("user_id","provider");
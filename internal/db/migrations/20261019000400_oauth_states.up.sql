@@ -0,0 +1,11 @@
+-- State OAuth untuk store database (oauth.state_store: database)
+CREATE TABLE IF NOT EXISTS "oauth_states" (
+    "state_hash" varchar(64),
+    "provider" varchar(50) NOT NULL,
+    "verifier" varchar(255) NOT NULL,
+    "link_user_id" bigint NOT NULL DEFAULT 0,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("state_hash")
+);
+CREATE INDEX IF NOT EXISTS "idx_oauth_states_expires_at" ON "oauth_states" ("expires_at");
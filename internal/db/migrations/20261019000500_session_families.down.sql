@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "idx_user_sessions_family_id";
+ALTER TABLE "user_sessions" DROP COLUMN IF EXISTS "replaced_by";
+ALTER TABLE "user_sessions" DROP COLUMN IF EXISTS "rotated_at";
+ALTER TABLE "user_sessions" DROP COLUMN IF EXISTS "family_id";
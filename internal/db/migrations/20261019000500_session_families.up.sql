@@ -0,0 +1,7 @@
+-- Rotasi refresh token: sesi hasil rotasi berbagi family_id dengan sesi pertama login-nya.
+-- Sesi lama menjadi root family-nya sendiri.
+ALTER TABLE "user_sessions" ADD COLUMN IF NOT EXISTS "family_id" uuid;
+ALTER TABLE "user_sessions" ADD COLUMN IF NOT EXISTS "rotated_at" timestamptz;
+ALTER TABLE "user_sessions" ADD COLUMN IF NOT EXISTS "replaced_by" uuid;
+UPDATE "user_sessions" SET "family_id" = "id" WHERE "family_id" IS NULL;
+CREATE INDEX IF NOT EXISTS "idx_user_sessions_family_id" ON "user_sessions" ("family_id");
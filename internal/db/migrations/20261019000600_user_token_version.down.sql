@@ -0,0 +1,2 @@
+ALTER TABLE "users" DROP COLUMN IF EXISTS "suspended_at";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "token_version";
@@ -0,0 +1,3 @@
+-- token_version dinaikkan saat role berubah atau user di-suspend agar access token lama ditolak
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "token_version" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "suspended_at" timestamptz;
@@ -0,0 +1,15 @@
+-- TOTP 2FA; totp_secret diperlebar di 20261019140200 saat seed mulai disimpan terenkripsi
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_secret" varchar(64);
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_enabled" boolean NOT NULL DEFAULT false;
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "totp_last_step" bigint NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "user_recovery_codes" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "code_hash" varchar(64) NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_user_recovery_codes_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_user_recovery_codes_user_id" ON "user_recovery_codes" ("user_id");
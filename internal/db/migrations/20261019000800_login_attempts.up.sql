@@ -0,0 +1,8 @@
+-- Lockout login per akun dan per IP
+CREATE TABLE IF NOT EXISTS "login_attempts" (
+    "key" varchar(320),
+    "failures" bigint NOT NULL DEFAULT 0,
+    "last_failure_at" timestamptz NOT NULL,
+    "locked_until" timestamptz,
+    PRIMARY KEY ("key")
+);
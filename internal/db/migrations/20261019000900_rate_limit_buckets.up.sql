@@ -0,0 +1,9 @@
+-- Token bucket untuk rate limit store database
+CREATE TABLE IF NOT EXISTS "rate_limit_buckets" (
+    "key" varchar(255),
+    "tokens" decimal NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("key")
+);
+CREATE INDEX IF NOT EXISTS "idx_rate_limit_buckets_expires_at" ON "rate_limit_buckets" ("expires_at");
@@ -0,0 +1,22 @@
+-- API key H2H reseller. Kolom prefix/key_hash diganti key_id/signing_secret di 20261019140300;
+-- index key_hash hanya dibuat jika kolomnya masih ada (database AutoMigrate yang lebih baru tidak punya)
+CREATE TABLE IF NOT EXISTS "api_keys" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "prefix" varchar(16) NOT NULL,
+    "key_hash" varchar(64) NOT NULL,
+    "allowed_ips" text,
+    "last_used_at" timestamptz,
+    "revoked_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_api_keys_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_api_keys_user_id" ON "api_keys" ("user_id");
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM information_schema.columns WHERE table_name = 'api_keys' AND column_name = 'key_hash') THEN
+        CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_key_hash" ON "api_keys" ("key_hash");
+    END IF;
+END $$;
@@ -0,0 +1,35 @@
+-- Endpoint webhook reseller dan outbox/log pengirimannya
+CREATE TABLE IF NOT EXISTS "webhook_endpoints" (
+    "id" bigserial,
+    "user_id" bigint NOT NULL,
+    "url" varchar(500) NOT NULL,
+    "secret" varchar(100) NOT NULL,
+    "events" text NOT NULL DEFAULT '',
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_webhook_endpoints_user" FOREIGN KEY ("user_id") REFERENCES "users"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_endpoints_user_id" ON "webhook_endpoints" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "webhook_deliveries" (
+    "id" bigserial,
+    "endpoint_id" bigint NOT NULL,
+    "user_id" bigint NOT NULL,
+    "event_id" varchar(36) NOT NULL,
+    "event" varchar(50) NOT NULL,
+    "payload" jsonb NOT NULL,
+    "status" varchar(10) NOT NULL DEFAULT 'pending',
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL,
+    "last_attempt_at" timestamptz,
+    "response_status" bigint NOT NULL DEFAULT 0,
+    "last_error" text,
+    "delivered_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_webhook_deliveries_endpoint" FOREIGN KEY ("endpoint_id") REFERENCES "webhook_endpoints"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_due" ON "webhook_deliveries" ("status","next_attempt_at");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_user_id" ON "webhook_deliveries" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_endpoint_id" ON "webhook_deliveries" ("endpoint_id");
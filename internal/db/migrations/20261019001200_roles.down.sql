@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS "role_permissions";
+DROP TABLE IF EXISTS "roles";
+
+-- Role custom tidak ada lagi tanpa tabel roles, user-nya kembali menjadi user biasa
+UPDATE "users" SET "role" = 'user' WHERE "role" NOT IN ('admin', 'user');
+ALTER TABLE "users" ALTER COLUMN "role" TYPE varchar(10);
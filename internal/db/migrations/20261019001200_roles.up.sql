@@ -0,0 +1,25 @@
+-- Role staff dengan permission; users.role kini merujuk roles.name
+ALTER TABLE "users" ALTER COLUMN "role" TYPE varchar(50);
+
+CREATE TABLE IF NOT EXISTS "roles" (
+    "id" bigserial,
+    "name" varchar(50) NOT NULL,
+    "description" varchar(255),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_roles_name" ON "roles" ("name");
+
+CREATE TABLE IF NOT EXISTS "role_permissions" (
+    "role_id" bigint,
+    "permission" varchar(50),
+    PRIMARY KEY ("role_id","permission"),
+    CONSTRAINT "fk_roles_permissions" FOREIGN KEY ("role_id") REFERENCES "roles"("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- Role bawaan yang dipakai sebagai default di kode (entity.RoleAdmin, entity.RoleUser)
+INSERT INTO "roles" ("name", "description", "created_at", "updated_at") VALUES
+    ('admin', 'Full access', now(), now()),
+    ('user', 'Customer / reseller', now(), now())
+ON CONFLICT ("name") DO NOTHING;
@@ -0,0 +1,18 @@
+-- Audit log perubahan data oleh admin/staff
+CREATE TABLE IF NOT EXISTS "audit_logs" (
+    "id" bigserial,
+    "actor_id" bigint,
+    "actor_role" varchar(50),
+    "action" varchar(20) NOT NULL,
+    "entity_type" varchar(50) NOT NULL,
+    "entity_id" varchar(100) NOT NULL,
+    "changes" jsonb NOT NULL,
+    "ip" varchar(50),
+    "user_agent" text,
+    "request_id" varchar(100),
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_created_at" ON "audit_logs" ("created_at");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_entity" ON "audit_logs" ("entity_type","entity_id");
+CREATE INDEX IF NOT EXISTS "idx_audit_logs_actor_id" ON "audit_logs" ("actor_id");
@@ -0,0 +1,9 @@
+ALTER TABLE "payment_methods" ALTER COLUMN "percent" TYPE decimal;
+ALTER TABLE "payment_methods" ALTER COLUMN "fee" TYPE decimal;
+ALTER TABLE "orders" ALTER COLUMN "fee" TYPE decimal;
+ALTER TABLE "orders" ALTER COLUMN "amount" TYPE decimal;
+ALTER TABLE "prices" ALTER COLUMN "amount" TYPE decimal;
+ALTER TABLE "products" ALTER COLUMN "base_price" TYPE decimal;
+ALTER TABLE "deposit" ALTER COLUMN "fee" TYPE decimal;
+ALTER TABLE "deposit" ALTER COLUMN "amount" TYPE decimal;
+ALTER TABLE "users" ALTER COLUMN "balance" TYPE decimal;
@@ -0,0 +1,13 @@
+-- Nominal rupiah disimpan sebagai bigint (rupiah penuh, pkg/money.Money) menggantikan decimal.
+-- Nilai pecahan lama dibulatkan ke rupiah terdekat.
+ALTER TABLE "users" ALTER COLUMN "balance" TYPE bigint USING round("balance");
+ALTER TABLE "deposit" ALTER COLUMN "amount" TYPE bigint USING round("amount");
+ALTER TABLE "deposit" ALTER COLUMN "fee" TYPE bigint USING round("fee");
+ALTER TABLE "products" ALTER COLUMN "base_price" TYPE bigint USING round("base_price");
+ALTER TABLE "prices" ALTER COLUMN "amount" TYPE bigint USING round("amount");
+ALTER TABLE "orders" ALTER COLUMN "amount" TYPE bigint USING round("amount");
+ALTER TABLE "orders" ALTER COLUMN "fee" TYPE bigint USING round("fee");
+ALTER TABLE "payment_methods" ALTER COLUMN "fee" TYPE bigint USING round("fee");
+
+-- Persentase fee tetap persen desimal (pkg/money.Rate), presisi dibatasi 4 desimal
+ALTER TABLE "payment_methods" ALTER COLUMN "percent" TYPE numeric(9,4) USING round("percent", 4);
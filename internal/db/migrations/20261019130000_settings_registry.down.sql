@@ -0,0 +1,2 @@
+ALTER TABLE "orders" DROP COLUMN IF EXISTS "expires_at";
+DROP INDEX IF EXISTS "ux_settings_name";
@@ -0,0 +1,6 @@
+-- Setting dibaca per nama (registry + cache), jadi nama harus unik. Duplikat lama: simpan baris terbaru.
+DELETE FROM "settings" a USING "settings" b WHERE a."name" = b."name" AND a."id" < b."id";
+CREATE UNIQUE INDEX IF NOT EXISTS "ux_settings_name" ON "settings" ("name");
+
+-- Batas waktu pembayaran order, dihitung dari setting order_ttl saat order dibuat
+ALTER TABLE "orders" ADD COLUMN IF NOT EXISTS "expires_at" timestamptz;
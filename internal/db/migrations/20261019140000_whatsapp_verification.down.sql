@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "ux_users_whatsapp_verified";
+ALTER TABLE "users" DROP COLUMN IF EXISTS "whatsapp_verified_at";
@@ -0,0 +1,10 @@
+-- Samakan format nomor lama dengan utils.NormalizePhone (hanya digit, awalan 0/8 menjadi 62)
+UPDATE "users" SET "whatsapp" = regexp_replace(regexp_replace(regexp_replace("whatsapp", '[^0-9]', '', 'g'), '^0', '62'), '^8', '628')
+WHERE "whatsapp" IS NOT NULL AND "whatsapp" <> '';
+
+-- Nomor lama belum pernah dibuktikan kepemilikannya, jadi semuanya mulai sebagai belum terverifikasi
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "whatsapp_verified_at" timestamptz;
+
+-- Login WhatsApp mencari user dari nomor, jadi satu nomor terverifikasi hanya boleh dimiliki satu user.
+-- Nomor yang belum terverifikasi boleh sama (mis. dua akun mengetik nomor yang sama) sampai salah satu verifikasi.
+CREATE UNIQUE INDEX IF NOT EXISTS "ux_users_whatsapp_verified" ON "users" ("whatsapp") WHERE "whatsapp_verified_at" IS NOT NULL;
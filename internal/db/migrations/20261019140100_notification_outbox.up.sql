@@ -0,0 +1,17 @@
+-- Outbox notifikasi customer: order_service menulis baris di sini, NotificationDispatcher mengirimnya
+CREATE TABLE IF NOT EXISTS "notifications" (
+    "id" bigserial,
+    "channel" varchar(20) NOT NULL,
+    "recipient" varchar(255) NOT NULL,
+    "body" text NOT NULL,
+    "reference" varchar(100),
+    "status" varchar(10) NOT NULL DEFAULT 'pending',
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL,
+    "last_attempt_at" timestamptz,
+    "last_error" text,
+    "sent_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_notifications_due" ON "notifications" ("status","next_attempt_at");
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS "used_challenges";
+-- totp_secret tidak dipersempit lagi: seed terenkripsi tidak muat di varchar(64)
@@ -0,0 +1,12 @@
+-- jti challenge token 2FA yang sudah dipakai; baris kedaluwarsa dibersihkan oleh ChallengeRepository
+CREATE TABLE IF NOT EXISTS "used_challenges" (
+    "jti" varchar(36),
+    "user_id" bigint NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("jti")
+);
+CREATE INDEX IF NOT EXISTS "idx_used_challenges_expires_at" ON "used_challenges" ("expires_at");
+
+-- Seed TOTP kini disimpan terenkripsi (enc:v1:<base64>), lebih panjang dari seed base32 aslinya
+ALTER TABLE "users" ALTER COLUMN "totp_secret" TYPE varchar(255);
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS "api_request_signatures";
+
+-- Hash key asli tidak bisa dipulihkan: semua key di-revoke dan harus dibuat ulang
+ALTER TABLE "api_keys" ADD COLUMN IF NOT EXISTS "prefix" varchar(16);
+ALTER TABLE "api_keys" ADD COLUMN IF NOT EXISTS "key_hash" varchar(64);
+UPDATE "api_keys"
+SET "prefix" = left("key_id", 16),
+    "key_hash" = md5("key_id") || md5("id"::text),
+    "revoked_at" = COALESCE("revoked_at", now());
+ALTER TABLE "api_keys" ALTER COLUMN "prefix" SET NOT NULL;
+ALTER TABLE "api_keys" ALTER COLUMN "key_hash" SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_key_hash" ON "api_keys" ("key_hash");
+DROP INDEX IF EXISTS "idx_api_keys_key_id";
+ALTER TABLE "api_keys" DROP COLUMN IF EXISTS "signing_secret";
+ALTER TABLE "api_keys" DROP COLUMN IF EXISTS "key_id";
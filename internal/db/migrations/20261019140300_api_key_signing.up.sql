@@ -0,0 +1,25 @@
+-- API key H2H kini terdiri dari key ID publik (header X-API-Key) dan signing secret yang tidak pernah
+-- dikirim. Key lama hanya tersimpan sebagai hash sehingga tidak bisa dipakai untuk skema baru:
+-- key ID-nya diisi dari prefix lama agar tetap dikenali di daftar, lalu key tsb di-revoke.
+ALTER TABLE "api_keys" ADD COLUMN IF NOT EXISTS "key_id" varchar(32);
+ALTER TABLE "api_keys" ADD COLUMN IF NOT EXISTS "signing_secret" varchar(255);
+UPDATE "api_keys"
+SET "key_id" = "prefix" || '-' || "id",
+    "signing_secret" = '',
+    "revoked_at" = COALESCE("revoked_at", now())
+WHERE "key_id" IS NULL;
+ALTER TABLE "api_keys" ALTER COLUMN "key_id" SET NOT NULL;
+ALTER TABLE "api_keys" ALTER COLUMN "signing_secret" SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_key_id" ON "api_keys" ("key_id");
+DROP INDEX IF EXISTS "idx_api_keys_key_hash";
+ALTER TABLE "api_keys" DROP COLUMN IF EXISTS "key_hash";
+ALTER TABLE "api_keys" DROP COLUMN IF EXISTS "prefix";
+
+-- Signature request H2H yang sudah diterima, disimpan selama jendela timestamp untuk menolak replay
+CREATE TABLE IF NOT EXISTS "api_request_signatures" (
+    "api_key_id" bigint NOT NULL,
+    "signature" varchar(64) NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("api_key_id","signature")
+);
+CREATE INDEX IF NOT EXISTS "idx_api_request_signatures_expires_at" ON "api_request_signatures" ("expires_at");
@@ -0,0 +1,8 @@
+// Package migrations berisi file SQL migrasi skema yang di-embed ke binary.
+// Buat file baru dengan: go run ./cmd/migrate create <name>
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
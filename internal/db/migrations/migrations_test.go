@@ -0,0 +1,63 @@
+package migrations
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/wildanasyrof/backend-topup/pkg/migrate"
+)
+
+var (
+	createTable = regexp.MustCompile(`(?i)CREATE TABLE (IF NOT EXISTS )?"?(\w+)"?`)
+	// DDL yang gagal jika objeknya sudah ada (atau belum ada saat rollback)
+	ddl = regexp.MustCompile(`(?i)(CREATE (?:UNIQUE )?(?:TABLE|INDEX)|ADD COLUMN|DROP (?:TABLE|INDEX|COLUMN))\s+"?(\w+)`)
+)
+
+// Database yang sudah menjalankan baseline versi lama (yang memuat semua tabel) mencatat baseline
+// sebagai applied, lalu menjalankan migrasi lanjutan di atas skema itu. Karena itu migrasi lanjutan
+// harus idempotent, dan baseline tidak boleh kembali memuat tabel milik migrasi lanjutan.
+func TestEmbeddedMigrations(t *testing.T) {
+	all, err := migrate.Load(FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) < 2 || all[0].Name != "baseline" {
+		t.Fatalf("first migration %+v, want the baseline", all[0])
+	}
+
+	createdBy := map[string]string{}
+	for _, m := range all {
+		name := m.Name
+		if strings.TrimSpace(m.Down) == "" {
+			t.Errorf("%d_%s has no down migration", m.Version, name)
+		}
+		for _, match := range createTable.FindAllStringSubmatch(m.Up, -1) {
+			table := strings.ToLower(match[2])
+			if prev, ok := createdBy[table]; ok {
+				t.Errorf("table %s created by both %s and %s", table, prev, name)
+			}
+			createdBy[table] = name
+		}
+		if m.Version == all[0].Version {
+			continue
+		}
+		for _, sql := range []string{m.Up, m.Down} {
+			for _, match := range ddl.FindAllStringSubmatch(sql, -1) {
+				if !strings.EqualFold(match[2], "IF") {
+					t.Errorf("%d_%s: %q needs IF [NOT] EXISTS", m.Version, name, match[0])
+				}
+			}
+		}
+	}
+
+	// Tabel dari skema awal tetap di baseline; tabel yang datang belakangan tidak boleh ikut pindah ke sana
+	for table, want := range map[string]string{
+		"users": "baseline", "orders": "baseline", "user_sessions": "baseline",
+		"user_otps": "email_otp", "api_keys": "api_keys", "audit_logs": "audit_logs", "roles": "roles",
+	} {
+		if createdBy[table] != want {
+			t.Errorf("table %s created by %q, want %q", table, createdBy[table], want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/wildanasyrof/backend-topup/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const tracingSpanKey = "otel:span"
+
+type querySpan struct {
+	span      trace.Span
+	operation string
+}
+
+// tracingPlugin membuat span client untuk setiap query GORM yang dijalankan dengan WithContext(ctx),
+// sehingga query muncul sebagai child span dari request HTTP. SQL dicatat dengan placeholder saja;
+// nilai parameter tidak ikut karena bisa berisi data pribadi.
+type tracingPlugin struct{}
+
+func (tracingPlugin) Name() string { return "otel-tracing" }
+
+func (p tracingPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	hooks := []struct {
+		name   string
+		before func(string, func(*gorm.DB)) error
+		after  func(string, func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	}
+	for _, h := range hooks {
+		if err := h.before("otel:before_"+h.name, p.before(h.name)); err != nil {
+			return err
+		}
+		if err := h.after("otel:after_"+h.name, p.after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tracingPlugin) before(operation string) func(*gorm.DB) {
+	tracer := tracing.Tracer()
+	return func(db *gorm.DB) {
+		// Query tanpa context request (mis. saat startup) tidak perlu jadi root span sendiri
+		parent := db.Statement.Context
+		if parent == nil || !trace.SpanContextFromContext(parent).IsValid() {
+			return
+		}
+		ctx, span := tracer.Start(parent, "db "+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNamePostgreSQL,
+				semconv.DBOperationName(operation),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(tracingSpanKey, querySpan{span: span, operation: operation})
+	}
+}
+
+func (tracingPlugin) after(db *gorm.DB) {
+	v, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	qs := v.(querySpan)
+	span := qs.span
+	defer span.End()
+
+	// Nama tabel baru terisi setelah statement di-parse oleh callback GORM
+	if table := db.Statement.Table; table != "" {
+		span.SetName("db " + qs.operation + " " + table)
+		span.SetAttributes(semconv.DBCollectionName(table))
+	}
+	span.SetAttributes(
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	// Record not found adalah hasil normal, bukan kegagalan database
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
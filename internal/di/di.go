@@ -4,15 +4,26 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/redis/go-redis/v9"
 	"github.com/wildanasyrof/backend-topup/internal/config"
 	"github.com/wildanasyrof/backend-topup/internal/db"
+	"github.com/wildanasyrof/backend-topup/internal/db/migrations"
+	"github.com/wildanasyrof/backend-topup/internal/http/apidoc"
 	"github.com/wildanasyrof/backend-topup/internal/http/handler"
 	"github.com/wildanasyrof/backend-topup/internal/repository"
 	"github.com/wildanasyrof/backend-topup/internal/service"
 	"github.com/wildanasyrof/backend-topup/pkg/jwt"
 	logger "github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/mailer"
+	"github.com/wildanasyrof/backend-topup/pkg/messaging"
+	"github.com/wildanasyrof/backend-topup/pkg/metrics"
+	"github.com/wildanasyrof/backend-topup/pkg/migrate"
 	"github.com/wildanasyrof/backend-topup/pkg/oauth"
+	"github.com/wildanasyrof/backend-topup/pkg/ratelimit"
+	"github.com/wildanasyrof/backend-topup/pkg/safehttp"
+	"github.com/wildanasyrof/backend-topup/pkg/secretbox"
 	"github.com/wildanasyrof/backend-topup/pkg/storage"
+	"github.com/wildanasyrof/backend-topup/pkg/tracing"
 	"github.com/wildanasyrof/backend-topup/pkg/validator"
 	"gorm.io/gorm"
 )
@@ -23,10 +34,14 @@ type DI struct {
 	Jwt                   jwt.JWTService
 	Storage               storage.LocalStorage
 	HTTPClient            *http.Client
-	OauthPkg              *oauth.GoogleOauthPkg
+	OAuthProviders        *oauth.Registry
 	DevStore              *oauth.DevStore
+	Mailer                mailer.Mailer
+	Messenger             messaging.MessagingProvider
+	RateLimiter           ratelimit.Store
 	AuthHandler           *handler.AuthHandler
 	UserHandler           *handler.UserHandler
+	TwoFactorHandler      *handler.TwoFactorHandler
 	MenuHandler           *handler.MenuHandler
 	SettingsHandler       *handler.SettingsHandler
 	PaymentMethodsHandler *handler.PaymentMethodsHandler
@@ -38,73 +53,140 @@ type DI struct {
 	PriceHandler          *handler.PriceHandler
 	OrderHandler          *handler.OrderHandler
 	SessionHandler        *handler.SessionHandler // <--- TAMBAHKAN
+	SessionService        service.SessionService
+	AccessCache           service.AccessCache
+	APIKeyHandler         *handler.APIKeyHandler
+	APIKeyService         service.APIKeyService
+	WebhookHandler        *handler.WebhookHandler
+	WebhookDispatcher     service.WebhookDispatcher
+	NotifyDispatcher      service.NotificationDispatcher
+	RoleHandler           *handler.RoleHandler
+	AuditLogHandler       *handler.AuditLogHandler
+	RoleService           service.RoleService
+	SettingsService       service.SettingsService
+	Metrics               metrics.Metrics
+	MetricsCollector      service.MetricsCollector
+	HealthHandler         *handler.HealthHandler
+	HealthService         service.HealthService
+	DocsHandler           *handler.DocsHandler
 }
 
 func InitDI(cfg *config.Config) *DI {
 	logger := logger.NewZerologLogger(cfg.Server.Env)
 	DB := db.Connect(cfg, logger)
 	validator := validator.NewValidator()
-	jwt := jwt.NewJWTService(cfg)
+	jwt, err := jwt.NewJWTService(cfg)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
 	storage := storage.NewLocalStorage(cfg)
-	httpClient := &http.Client{Timeout: time.Duration(cfg.Server.RequestTimeOut) * time.Second}
-	devStore := oauth.NewDevStore(5 * time.Minute)
-	oauth := oauth.NewGoogleOauthPkg(cfg)
+	httpClient := &http.Client{
+		Timeout:   time.Duration(cfg.Server.RequestTimeOut) * time.Second,
+		Transport: tracing.NewTransport(nil), // span + X-Request-ID untuk panggilan ke supplier dan provider OAuth
+	}
+	devStore := newDevStore(cfg, DB, logger)
+	oauthProviders := oauth.NewRegistry(cfg, httpClient)
+	mail := newMailer(cfg, logger)
+	messenger := newMessenger(cfg, httpClient, logger)
+	rateLimiter := newRateLimiter(cfg, DB, logger)
+	metrics := newMetrics(DB, logger)
 
 	// --- REPO BARU ---
 	sessionRepo := repository.NewSessionRepository(DB) // <--- TAMBAHKAN
 
 	userRepo := repository.NewUserRepository(DB)
+	otpRepo := repository.NewOTPRepository(DB)
+	otpService := service.NewOTPService(otpRepo, []byte(cfg.Security.OTPSecret))
+	auditLogService := service.NewAuditLogService(repository.NewAuditLogRepository(DB), logger)
+	auditLogHandler := handler.NewAuditLogHandler(auditLogService)
+
+	settingsRepo := repository.NewSettingsRepository(DB)
+	settingsService := service.NewSettingsService(settingsRepo, auditLogService, logger)
+	settingsHandler := handler.NewSettingsHandler(settingsService, validator)
+
+	recoveryCodeRepo := repository.NewRecoveryCodeRepository(DB)
+	secrets, err := secretbox.New(cfg.Security.EncryptionKey)
+	if err != nil {
+		logger.Fatal("failed to init secret encryption, " + err.Error())
+	}
+	twoFactorService := service.NewTwoFactorService(userRepo, recoveryCodeRepo, settingsService, secrets)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService, validator)
 	// --- MODIFIKASI AUTH SERVICE ---
-	authService := service.NewAuthService(userRepo, sessionRepo, jwt) // <--- Inject sessionRepo
-	userService := service.NewUserService(userRepo)
-	userHandler := handler.NewUserHandler(userService, validator)
+	loginGuard := service.NewLoginGuard(repository.NewLoginAttemptRepository(DB), userRepo, mail, logger)
+	accessCache := service.NewAccessCache(repository.NewAccessInvalidationRepository(DB), logger)
+	authService := service.NewAuthService(userRepo, sessionRepo, accessCache, jwt, otpService, twoFactorService, repository.NewChallengeRepository(DB), loginGuard, mail, messenger, logger) // <--- Inject sessionRepo
+	roleRepo := repository.NewRoleRepository(DB)
+	roleService := service.NewRoleService(roleRepo, auditLogService)
+	roleHandler := handler.NewRoleHandler(roleService, validator)
+
+	userService := service.NewUserService(userRepo, sessionRepo, roleRepo, accessCache, auditLogService)
+	identityRepo := repository.NewIdentityRepository(DB)
+	identityService := service.NewIdentityService(userRepo, identityRepo)
+	userHandler := handler.NewUserHandler(userService, identityService, validator)
 	// --- MODIFIKASI AUTH HANDLER ---
-	authHandler := handler.NewAuthHandler(authService, userService, *oauth, devStore, validator, cfg) // <--- Inject cfg
+	authHandler := handler.NewAuthHandler(authService, identityService, oauthProviders, devStore, validator, cfg) // <--- Inject cfg
 
 	menuRepo := repository.NewMenuRepository(DB)
 	menuService := service.NewMenuService(menuRepo)
 	menuHandler := handler.NewMenuHandler(menuService, validator)
 
-	settingsRepo := repository.NewSettingsRepository(DB)
-	settingsService := service.NewSettingsService(settingsRepo)
-	settingsHandler := handler.NewSettingsHandler(settingsService, validator)
-
 	paymentMethodRepo := repository.NewPaymentMethodsRepository(DB)
-	paymentMethodService := service.NewPaymentMethodsService(paymentMethodRepo)
+	paymentMethodService := service.NewPaymentMethodsService(paymentMethodRepo, auditLogService)
 	paymentMethodsHandler := handler.NewPaymentMethodsHandler(paymentMethodService, validator, storage)
 
 	bannerRepo := repository.NewBannerRepository(DB)
-	bannerService := service.NewBannerService(bannerRepo)
+	bannerService := service.NewBannerService(bannerRepo, auditLogService)
 	bannerHandler := handler.NewBannerHandler(bannerService, storage)
 
+	webhookRepo := repository.NewWebhookRepository(DB)
+	webhookService := service.NewWebhookService(webhookRepo, auditLogService)
+	webhookHandler := handler.NewWebhookHandler(webhookService, validator)
+	// Client terpisah untuk URL milik user: tidak boleh menjangkau alamat internal kecuali saat development
+	webhookDispatcher := service.NewWebhookDispatcher(webhookRepo, safehttp.NewClient(30*time.Second, cfg.Server.Env == "development"), logger)
+
 	depositRepo := repository.NewDepositRepository(DB)
-	depositService := service.NewDepositService(depositRepo)
+	depositService := service.NewDepositService(depositRepo, paymentMethodRepo, webhookService, settingsService, auditLogService, metrics)
 	depositHandler := handler.NewDepositHandler(depositService, validator, logger)
 
 	providerRepo := repository.NewProviderRepository(DB)
-	providerService := service.NewProviderService(providerRepo)
+	providerService := service.NewProviderService(providerRepo, auditLogService)
 	providerHandler := handler.NewProviderHandler(providerService, validator)
 
 	categoryRepository := repository.NewCategoryRepository(DB)
-	categoryService := service.NewCategoryService(categoryRepository)
+	categoryService := service.NewCategoryService(categoryRepository, auditLogService)
 	categoryHandler := handler.NewCategoryHandler(categoryService, validator, storage)
 
 	productRepository := repository.NewProductRepository(DB)
-	extService := service.NewExternalService(httpClient, logger, productRepository)
-	productService := service.NewProductRepository(productRepository)
+	extService := service.NewExternalService(httpClient, logger, productRepository, cfg.Digiflazz, metrics)
+	productService := service.NewProductRepository(productRepository, auditLogService)
 	productHandler := handler.NewProductHandler(productService, validator, storage, extService)
 
 	priceRepository := repository.NewPriceRepository(DB)
-	priceService := service.NewPriceService(priceRepository)
+	priceService := service.NewPriceService(priceRepository, auditLogService)
 	priceHandler := handler.NewPriceHandler(priceService, validator)
 
 	orderRepository := repository.NewOrderRepository(DB)
-	orderService := service.NewOrderService(orderRepository, logger, userRepo, priceRepository)
+	notificationRepo := repository.NewNotificationRepository(DB)
+	notificationService := service.NewNotificationService()
+	notifyDispatcher := service.NewNotificationDispatcher(notificationRepo, messenger, logger)
+	orderService := service.NewOrderService(orderRepository, logger, userRepo, priceRepository, notificationService, webhookService, settingsService, auditLogService, metrics)
 	orderHandler := handler.NewOrderHandler(orderService, validator)
+	metricsCollector := service.NewMetricsCollector(orderRepository, extService, metrics, logger)
+
+	healthService := newHealthService(cfg, DB, extService, logger)
+	healthHandler := handler.NewHealthHandler(healthService)
+
+	docsHandler, err := handler.NewDocsHandler(apidoc.Build())
+	if err != nil {
+		logger.Fatal("failed to build openapi spec, " + err.Error())
+	}
 
 	// --- SERVICE & HANDLER BARU ---
-	sessionService := service.NewSessionService(sessionRepo)    // <--- TAMBAHKAN
-	sessionHandler := handler.NewSessionHandler(sessionService) // <--- TAMBAHKAN
+	sessionService := service.NewSessionService(sessionRepo, accessCache) // <--- TAMBAHKAN
+	sessionHandler := handler.NewSessionHandler(sessionService)           // <--- TAMBAHKAN
+
+	apiKeyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(DB), secrets, logger)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService, validator)
 
 	return &DI{
 		Logger:                logger,
@@ -112,10 +194,14 @@ func InitDI(cfg *config.Config) *DI {
 		Jwt:                   jwt,
 		Storage:               storage,
 		HTTPClient:            httpClient,
-		OauthPkg:              oauth,
+		OAuthProviders:        oauthProviders,
 		DevStore:              &devStore,
+		Mailer:                mail,
+		Messenger:             messenger,
+		RateLimiter:           rateLimiter,
 		AuthHandler:           authHandler,
 		UserHandler:           userHandler,
+		TwoFactorHandler:      twoFactorHandler,
 		MenuHandler:           menuHandler,
 		SettingsHandler:       settingsHandler,
 		PaymentMethodsHandler: paymentMethodsHandler,
@@ -127,7 +213,93 @@ func InitDI(cfg *config.Config) *DI {
 		PriceHandler:          priceHandler,
 		OrderHandler:          orderHandler,
 		SessionHandler:        sessionHandler, // <--- TAMBAHKAN
+		SessionService:        sessionService,
+		AccessCache:           accessCache,
+		APIKeyHandler:         apiKeyHandler,
+		APIKeyService:         apiKeyService,
+		WebhookHandler:        webhookHandler,
+		WebhookDispatcher:     webhookDispatcher,
+		NotifyDispatcher:      notifyDispatcher,
+		RoleHandler:           roleHandler,
+		AuditLogHandler:       auditLogHandler,
+		RoleService:           roleService,
+		SettingsService:       settingsService,
+		Metrics:               metrics,
+		MetricsCollector:      metricsCollector,
+		HealthHandler:         healthHandler,
+		HealthService:         healthService,
+		DocsHandler:           docsHandler,
+	}
+}
+
+func newHealthService(cfg *config.Config, db *gorm.DB, supplier service.ExternalService, logger logger.Logger) service.HealthService {
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Fatal("failed to get database handle, " + err.Error())
+	}
+	migrator, err := migrate.New(sqlDB, migrations.FS)
+	if err != nil {
+		logger.Fatal("failed to load migrations, " + err.Error())
+	}
+	if !cfg.Health.CheckSupplier {
+		supplier = nil
+	}
+	return service.NewHealthService(sqlDB, migrator, cfg.Server.UploadDir, supplier, cfg.Health.Timeout, logger)
+}
+
+// newMetrics membuat registry Prometheus; statistik pool koneksi database ikut diekspos.
+func newMetrics(db *gorm.DB, logger logger.Logger) metrics.Metrics {
+	m := metrics.New()
+	sqlDB, err := db.DB()
+	if err != nil {
+		logger.Fatal("failed to get database handle, " + err.Error())
+	}
+	m.RegisterDB("topup", sqlDB)
+	return m
+}
+
+func newDevStore(cfg *config.Config, db *gorm.DB, logger logger.Logger) oauth.DevStore {
+	const ttl = 5 * time.Minute
+	if cfg.Oauth.StateStore == "database" {
+		return repository.NewOAuthStateRepository(db, ttl)
+	}
+	if cfg.Server.Env != "development" {
+		logger.Warn("OAUTH_STATE_STORE is memory, oauth callbacks must reach the instance that started the login")
+	}
+	return oauth.NewDevStore(ttl)
+}
+
+func newRateLimiter(cfg *config.Config, db *gorm.DB, logger logger.Logger) ratelimit.Store {
+	switch cfg.RateLimit.Store {
+	case "redis":
+		opt, err := redis.ParseURL(cfg.RateLimit.RedisURL)
+		if err != nil {
+			logger.Fatal("invalid REDIS_URL: " + err.Error())
+		}
+		return ratelimit.NewRedisStore(redis.NewClient(opt), "topup:")
+	case "database":
+		return repository.NewRateLimitRepository(db)
+	}
+	if cfg.Server.Env != "development" {
+		logger.Warn("RATE_LIMIT_STORE is memory, limits are counted per instance")
+	}
+	return ratelimit.NewMemoryStore()
+}
+
+func newMailer(cfg *config.Config, logger logger.Logger) mailer.Mailer {
+	if cfg.Mail.Driver == "smtp" {
+		return mailer.NewSMTPMailer(cfg)
+	}
+	logger.Warn("MAIL_DRIVER is not smtp, emails are kept in memory and never delivered")
+	return mailer.NewMemoryMailer()
+}
+
+func newMessenger(cfg *config.Config, httpClient *http.Client, logger logger.Logger) messaging.MessagingProvider {
+	if cfg.Whatsapp.Driver == "fonnte" {
+		return messaging.NewFonnteProvider(cfg, httpClient)
 	}
+	logger.Warn("WHATSAPP_DRIVER is not fonnte, whatsapp messages are kept in memory and never delivered")
+	return messaging.NewMemoryProvider()
 }
 
 func (d *DI) GetDB() *gorm.DB {
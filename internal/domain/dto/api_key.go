@@ -0,0 +1,37 @@
+package dto
+
+import "time"
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=100"`
+	// AllowedIPs berisi IP atau CIDR; kosong berarti key bisa dipakai dari IP mana pun
+	AllowedIPs []string `json:"allowed_ips" validate:"omitempty,max=20,dive,ip|cidr"`
+}
+
+type APIKeyResponse struct {
+	ID         uint64     `json:"id"`
+	Name       string     `json:"name"`
+	KeyID      string     `json:"key_id"`
+	AllowedIPs []string   `json:"allowed_ips"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// APIKeyCreatedResponse: Secret hanya dikembalikan sekali, saat dibuat. Client mengirim KeyID di
+// header X-API-Key dan memakai Secret untuk menandatangani request, Secret sendiri tidak pernah dikirim.
+type APIKeyCreatedResponse struct {
+	APIKeyResponse
+	Secret string `json:"secret"`
+}
+
+// SignedAPIRequest adalah request H2H yang diperiksa APIKeyService, lihat signature.Sign
+type SignedAPIRequest struct {
+	KeyID     string
+	Timestamp int64
+	Signature string
+	Method    string
+	Path      string
+	Body      []byte
+	ClientIP  string
+}
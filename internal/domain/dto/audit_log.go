@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/pkg/pagination"
+)
+
+type AuditLogListQuery struct {
+	pagination.Query
+
+	ActorID    *uint64 `query:"actor_id"`
+	Action     string  `query:"action"`
+	EntityType string  `query:"entity_type"`
+	EntityID   string  `query:"entity_id"`
+	From       string  `query:"from"` // RFC3339 atau YYYY-MM-DD, inklusif
+	To         string  `query:"to"`   // eksklusif
+}
+
+// TimeRange mem-parse From/To; nilai kosong menghasilkan zero time.
+func (q AuditLogListQuery) TimeRange() (from, to time.Time, err error) {
+	if from, err = parseDateTime(q.From); err != nil {
+		return
+	}
+	to, err = parseDateTime(q.To)
+	return
+}
+
+func parseDateTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
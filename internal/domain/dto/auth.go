@@ -1,5 +1,7 @@
 package dto
 
+import "github.com/wildanasyrof/backend-topup/internal/domain/entity"
+
 type RegisterUserRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Name     string `json:"name" validate:"required,min=3,max=20"`
@@ -11,14 +13,69 @@ type LoginUserRequest struct {
 	Password string `json:"password" validate:"required,min=8,max=100"`
 }
 
+type EmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type VerifyEmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Code  string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type ResetPasswordRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Code     string `json:"code" validate:"required,len=6,numeric"`
+	Password string `json:"password" validate:"required,min=8,max=100"`
+}
+
+type WhatsappLoginRequest struct {
+	Whatsapp string `json:"whatsapp" validate:"required,min=10,max=15"`
+}
+
+type WhatsappVerifyRequest struct {
+	Whatsapp string `json:"whatsapp" validate:"required,min=10,max=15"`
+	Code     string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// WhatsappCodeRequest: kode verifikasi nomor WhatsApp di profil user
+type WhatsappCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
 type TokenResponse struct {
 	AccessToken string `json:"access_token"`
 }
 
-type GoogleLoginResponse struct {
-	Sub           string `json:"sub"`
-	Email         string `json:"email"`
-	EmailVerified bool   `json:"email_verified"`
-	Name          string `json:"name"`
-	Picture       string `json:"picture"`
+// LoginResult: jika TwoFactor terisi, login belum selesai dan client harus mengirim kode 2FA
+type LoginResult struct {
+	User          *entity.User
+	AccessToken   string
+	Session       *entity.UserSession
+	TwoFactor     *TwoFactorChallenge
+	RecoveryCodes []string
+}
+
+type TwoFactorChallenge struct {
+	ChallengeToken string `json:"challenge_token"`
+	// EnrollmentRequired: admin wajib 2FA tapi belum setup, lanjutkan ke /auth/2fa/enroll
+	EnrollmentRequired bool `json:"enrollment_required"`
+}
+
+type TwoFactorChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+}
+
+// TwoFactorVerifyRequest: Code berupa kode TOTP 6 digit atau recovery code
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,min=6,max=20"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,min=6,max=20"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
 }
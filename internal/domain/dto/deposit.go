@@ -1,7 +1,27 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/pkg/money"
+)
+
 type DepositRequest struct {
-	Amount          float64 `json:"amount" validate:"required,min=1000"`
-	PaymentMethodID uint64  `json:"payment_method_id" validate:"required"`
-	Fee             float64 `json:"fee,omitempty"`
+	Amount          money.Money `json:"amount" validate:"required,min=1000"`
+	PaymentMethodID uint64      `json:"payment_method_id" validate:"required"`
+}
+
+// UpdateDepositStatus dipakai admin untuk mengonfirmasi atau membatalkan deposit
+type UpdateDepositStatus struct {
+	Status string `json:"status" validate:"required,oneof=pending processing success canceled"`
+}
+
+type DepositResponse struct {
+	TopupID         string      `json:"topup_id"`
+	PaymentMethodID uint64      `json:"payment_method_id"`
+	Amount          money.Money `json:"amount"`
+	Fee             money.Money `json:"fee"`
+	Status          string      `json:"status"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
 }
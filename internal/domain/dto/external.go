@@ -27,3 +27,15 @@ type DFProductListRes struct {
 	EndCutOff           string `json:"end_cut_off"`
 	Desc                string `json:"desc"`
 }
+
+type DFBalanceReq struct {
+	Cmd      string `json:"cmd"`      // "deposit"
+	Username string `json:"username"` // from cfg
+	Sign     string `json:"sign"`     // md5(username+apiKey+"depo")
+}
+
+type DFBalanceRes struct {
+	Data struct {
+		Deposit int64 `json:"deposit"`
+	} `json:"data"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+// Status health check.
+const (
+	HealthOK       = "ok"
+	HealthFail     = "fail"
+	HealthDraining = "draining"
+)
+
+// HealthCheck adalah hasil satu pemeriksaan readiness. Check yang tidak Critical hanya informasi
+// dan tidak membuat instance dianggap tidak siap.
+type HealthCheck struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Critical   bool    `json:"critical"`
+	DurationMs float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// HealthReport adalah body GET /health/ready.
+type HealthReport struct {
+	Status     string        `json:"status"`
+	Checks     []HealthCheck `json:"checks"`
+	DurationMs float64       `json:"duration_ms"`
+}
@@ -1,5 +1,11 @@
 package dto
 
+import (
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/pkg/money"
+)
+
 type CreateOrder struct {
 	ProductID    int    `json:"product_id" validate:"required"`
 	WA           string `json:"wa,omitempty"`
@@ -16,15 +22,23 @@ type UpdateOrder struct {
 	CustomerID   *string `json:"customer_id,omitempty"`
 }
 
+// UpdateOrderStatus is used by admins (and later supplier callbacks) to move an order forward
+type UpdateOrderStatus struct {
+	Status       string `json:"status" validate:"required,oneof=success canceled pending processing"`
+	SerialNumber string `json:"serial_number,omitempty" validate:"max=255"`
+}
+
 type OrderResponse struct {
-	OrderRef      string  `json:"order_ref"`
-	ProductID     uint64  `json:"product_id"`
-	WA            string  `json:"wa"`
-	Email         string  `json:"email"`
-	CustomerName  string  `json:"customer_name"`
-	CustomerID    string  `json:"customer_id"`
-	PaymentRef    string  `json:"payment_ref"`
-	PaymentStatus string  `json:"payment_status"`
-	Status        string  `json:"status"`
-	Amount        float64 `json:"amount"`
+	OrderRef      string      `json:"order_ref"`
+	ProductID     uint64      `json:"product_id"`
+	WA            string      `json:"wa"`
+	Email         string      `json:"email"`
+	CustomerName  string      `json:"customer_name"`
+	CustomerID    string      `json:"customer_id"`
+	PaymentRef    string      `json:"payment_ref"`
+	PaymentStatus string      `json:"payment_status"`
+	Status        string      `json:"status"`
+	SerialNumber  string      `json:"serial_number"`
+	Amount        money.Money `json:"amount"`
+	ExpiresAt     *time.Time  `json:"expires_at,omitempty"`
 }
@@ -2,17 +2,18 @@ package dto
 
 import (
 	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/pkg/money"
 	"github.com/wildanasyrof/backend-topup/pkg/pagination"
 )
 
 // In your DTO, keep json tags for JSON requests, and ADD form tags for multipart.
 type CreatePaymentMethodRequest struct {
-	Type       string   `json:"type" form:"type" validate:"required,min=3,max=100"`
-	Name       string   `json:"name" form:"name" validate:"required,min=3,max=100"`
-	ImgUrl     string   `json:"img_url" form:"img_url" validate:"omitempty,url"`
-	ProviderID int64    `form:"provider_id" validate:"required"`
-	Fee        *float64 `json:"fee,omitempty" form:"fee"`
-	Percent    *float64 `json:"percent,omitempty" form:"percent"`
+	Type       string       `json:"type" form:"type" validate:"required,min=3,max=100"`
+	Name       string       `json:"name" form:"name" validate:"required,min=3,max=100"`
+	ImgUrl     string       `json:"img_url" form:"img_url" validate:"omitempty,url"`
+	ProviderID int64        `form:"provider_id" validate:"required"`
+	Fee        *money.Money `json:"fee,omitempty" form:"fee"`
+	Percent    *money.Rate  `json:"percent,omitempty" form:"percent"`
 }
 
 type PaymentMethodListQuery struct {
@@ -25,12 +26,12 @@ type PaymentMethodListQuery struct {
 }
 
 type UpdatePaymentMethodRequest struct {
-	Type       string   `json:"type" form:"type" validate:"omitempty,min=3,max=100"`
-	Name       string   `json:"name" form:"name" validate:"omitempty,min=3,max=100"`
-	ImgUrl     string   `json:"img_url" form:"img_url" validate:"omitempty,startswith=/uploads/"`
-	ProviderID *int64   `form:"provider_id"`
-	Fee        *float64 `json:"fee,omitempty" form:"fee"`
-	Percent    *float64 `json:"percent,omitempty" form:"percent"`
+	Type       string       `json:"type" form:"type" validate:"omitempty,min=3,max=100"`
+	Name       string       `json:"name" form:"name" validate:"omitempty,min=3,max=100"`
+	ImgUrl     string       `json:"img_url" form:"img_url" validate:"omitempty,startswith=/uploads/"`
+	ProviderID *int64       `form:"provider_id"`
+	Fee        *money.Money `json:"fee,omitempty" form:"fee"`
+	Percent    *money.Rate  `json:"percent,omitempty" form:"percent"`
 }
 
 // in dto/update_payment_method_request.go
@@ -2,19 +2,20 @@ package dto
 
 import (
 	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/pkg/money"
 	"github.com/wildanasyrof/backend-topup/pkg/pagination"
 )
 
 type CreatePrice struct {
-	ProductID   int     `json:"product_id" validate:"required,gte=1"`
-	UserLevelID int     `json:"user_level_id" validate:"required,gte=1"`
-	Price       float64 `json:"price" validate:"required,gt=0"`
+	ProductID   int         `json:"product_id" validate:"required,gte=1"`
+	UserLevelID int         `json:"user_level_id" validate:"required,gte=1"`
+	Price       money.Money `json:"price" validate:"required,gt=0"`
 }
 
 type UpdatePrice struct {
-	ProductID   *int     `json:"product_id" validate:"omitempty,gte=1"`
-	UserLevelID *int     `json:"user_level_id" validate:"omitempty,gte=1"`
-	Price       *float64 `json:"price" validate:"omitempty,gt=0"`
+	ProductID   *int         `json:"product_id" validate:"omitempty,gte=1"`
+	UserLevelID *int         `json:"user_level_id" validate:"omitempty,gte=1"`
+	Price       *money.Money `json:"price" validate:"omitempty,gt=0"`
 }
 
 type PriceListQuery struct {
@@ -0,0 +1,21 @@
+package dto
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=50,lowercase"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"dive,required"`
+}
+
+// UpdateRoleRequest: nama role tidak bisa diubah karena tersimpan di users.role
+type UpdateRoleRequest struct {
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"dive,required"`
+}
+
+type RoleResponse struct {
+	ID          uint64   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	BuiltIn     bool     `json:"built_in"`
+}
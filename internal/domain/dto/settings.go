@@ -18,3 +18,13 @@ type SettingsListQuery struct {
 	// Filter spesifik
 	Name *string `query:"name"`
 }
+
+// SettingDefinitionResponse adalah satu key dari registry setting beserta nilai efektifnya
+type SettingDefinitionResponse struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Default     string `json:"default"`
+	Public      bool   `json:"public"`
+	Description string `json:"description"`
+	Value       any    `json:"value"`
+}
@@ -4,3 +4,13 @@ type UpdateUserRequest struct {
 	Name     *string `json:"name" validate:"omitempty,min=2,max=100"`
 	Whatsapp *string `json:"whatsapp" validate:"omitempty,min=10,max=15"`
 }
+
+// SetPasswordRequest: CurrentPassword wajib jika user sudah punya password
+type SetPasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"omitempty,max=100"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=100"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required,max=50"`
+}
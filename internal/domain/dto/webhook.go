@@ -0,0 +1,42 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/pkg/pagination"
+)
+
+type CreateWebhookRequest struct {
+	URL string `json:"url" validate:"required,url,max=500"`
+	// Events kosong berarti berlangganan semua event
+	Events []string `json:"events" validate:"omitempty,dive,oneof=order.status_changed deposit.status_changed"`
+}
+
+type WebhookEndpointResponse struct {
+	ID        uint64    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookCreatedResponse: Secret hanya dikembalikan sekali, saat endpoint dibuat
+type WebhookCreatedResponse struct {
+	WebhookEndpointResponse
+	Secret string `json:"secret"`
+}
+
+type WebhookDeliveryListQuery struct {
+	pagination.Query
+
+	Status     string  `query:"status"`
+	EndpointID *uint64 `query:"endpoint_id"`
+	UserID     *uint64 `query:"user_id"`
+}
+
+// WebhookEvent adalah body JSON yang dikirim ke endpoint reseller
+type WebhookEvent struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
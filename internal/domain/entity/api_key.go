@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// APIKey adalah kredensial H2H milik seorang user (reseller).
+// Client mengirim KeyID di setiap request dan menandatanganinya dengan SigningSecret; secret hanya
+// ditampilkan sekali saat dibuat dan tidak pernah ikut terkirim di request.
+type APIKey struct {
+	ID     uint64 `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID uint64 `gorm:"not null;index" json:"user_id"`
+	Name   string `gorm:"type:varchar(100);not null" json:"name"`
+	// KeyID bukan rahasia, hanya untuk mencari key (header X-API-Key)
+	KeyID string `gorm:"type:varchar(32);not null;uniqueIndex" json:"key_id"`
+	// SigningSecret disimpan terenkripsi (pkg/secretbox): server butuh nilai aslinya untuk memverifikasi HMAC
+	SigningSecret string     `gorm:"type:varchar(255);not null" json:"-"`
+	AllowedIPs    string     `gorm:"type:text" json:"-"` // IP/CIDR dipisah koma; kosong berarti semua IP
+	LastUsedAt    *time.Time `json:"last_used_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relasi
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+func (APIKey) TableName() string { return "api_keys" }
+
+// APIRequestSignature mencatat signature request H2H yang sudah diterima selama masih di dalam
+// jendela timestamp, agar request yang sama tidak bisa dikirim ulang.
+type APIRequestSignature struct {
+	APIKeyID  uint64    `gorm:"primaryKey;autoIncrement:false" json:"api_key_id"`
+	Signature string    `gorm:"type:varchar(64);primaryKey" json:"-"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+func (APIRequestSignature) TableName() string { return "api_request_signatures" }
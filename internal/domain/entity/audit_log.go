@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+	AuditResend = "resend"
+)
+
+// AuditLog mencatat satu perubahan data oleh admin/staff.
+// Changes berisi field yang berubah: {"field": {"before": ..., "after": ...}}.
+type AuditLog struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	ActorID    *uint64   `gorm:"index" json:"actor_id"`
+	ActorRole  string    `gorm:"type:varchar(50)" json:"actor_role"`
+	Action     string    `gorm:"type:varchar(20);not null" json:"action"`
+	EntityType string    `gorm:"type:varchar(50);not null;index:idx_audit_logs_entity,priority:1" json:"entity_type"`
+	EntityID   string    `gorm:"type:varchar(100);not null;index:idx_audit_logs_entity,priority:2" json:"entity_id"`
+	Changes    string    `gorm:"type:jsonb;not null" json:"changes"`
+	IP         string    `gorm:"type:varchar(50)" json:"ip"`
+	UserAgent  string    `gorm:"type:text" json:"user_agent"`
+	RequestID  string    `gorm:"type:varchar(100)" json:"request_id"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+func (AuditLog) TableName() string { return "audit_logs" }
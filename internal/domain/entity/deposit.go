@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/pkg/money"
+)
 
 // DepositStatus is a custom type to represent the status of a deposit.
 type DepositStatus string
@@ -18,9 +22,9 @@ type Deposit struct {
 	TopupID         string        `gorm:"type:varchar(255);not null;column:topup_id"`
 	UserID          uint64        `gorm:"not null;column:user_id"`
 	PaymentMethodID uint64        `gorm:"not null;column:payment_method_id"`
-	Amount          float64       `gorm:"not null;column:amount"`
+	Amount          money.Money   `gorm:"not null;column:amount"`
 	Status          DepositStatus `gorm:"type:text;not null;default=pending;check:deposit_status_check,status IN ('pending','processing','success','canceled')"`
-	Fee             float64       `gorm:"not null;column:fee"`
+	Fee             money.Money   `gorm:"not null;column:fee"`
 	Payment         string        `gorm:"type:text;not null;column:payment"`
 	CreatedAt       time.Time     `gorm:"autoCreateTime;column:created_at"`
 	UpdatedAt       time.Time     `gorm:"autoUpdateTime;column:updated_at"`
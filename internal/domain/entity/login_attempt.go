@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// LoginAttempt menghitung kegagalan login per kunci ("ip:<addr>" atau "email:<addr>").
+// Disimpan di database agar batasannya berlaku di semua replica API.
+type LoginAttempt struct {
+	Key           string     `gorm:"type:varchar(320);primaryKey" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `gorm:"not null" json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
+
+func (LoginAttempt) TableName() string { return "login_attempts" }
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending"
+	NotificationSent    NotificationStatus = "sent"
+	NotificationFailed  NotificationStatus = "failed"
+)
+
+// NotificationChannelWhatsapp adalah satu-satunya channel saat ini; kolom channel disiapkan untuk email dsb.
+const NotificationChannelWhatsapp = "whatsapp"
+
+// Notification adalah outbox pesan ke customer (mis. notifikasi order via WhatsApp).
+// Baris ditulis saat event terjadi dan dikirim oleh NotificationDispatcher di background,
+// sehingga gateway yang lambat atau down tidak menahan request.
+type Notification struct {
+	ID            uint64             `gorm:"primaryKey;autoIncrement" json:"id"`
+	Channel       string             `gorm:"type:varchar(20);not null" json:"channel"`
+	Recipient     string             `gorm:"type:varchar(255);not null" json:"recipient"`
+	Body          string             `gorm:"type:text;not null" json:"body"`
+	Reference     string             `gorm:"type:varchar(100)" json:"reference"` // mis. order ref, untuk log
+	Status        NotificationStatus `gorm:"type:varchar(10);not null;default:pending;index:idx_notifications_due,priority:1" json:"status"`
+	Attempts      int                `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time          `gorm:"not null;index:idx_notifications_due,priority:2" json:"next_attempt_at"`
+	LastAttemptAt *time.Time         `json:"last_attempt_at"`
+	LastError     string             `gorm:"type:text" json:"last_error"`
+	SentAt        *time.Time         `json:"sent_at"`
+	CreatedAt     time.Time          `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (Notification) TableName() string { return "notifications" }
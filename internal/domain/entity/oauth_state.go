@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// OAuthState menyimpan state login OAuth yang sedang berjalan agar callback bisa diterima replica mana pun.
+// State disimpan sebagai hash; verifier PKCE hanya dipakai sekali lalu barisnya dihapus.
+type OAuthState struct {
+	StateHash  string    `gorm:"type:varchar(64);primaryKey" json:"-"`
+	Provider   string    `gorm:"type:varchar(50);not null" json:"provider"`
+	Verifier   string    `gorm:"size:255;not null" json:"-"`
+	LinkUserID uint64    `gorm:"not null;default:0" json:"link_user_id"`
+	ExpiresAt  time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (OAuthState) TableName() string { return "oauth_states" }
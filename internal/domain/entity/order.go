@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/pkg/money"
+)
 
 type OrderStatus string
 
@@ -32,8 +36,14 @@ type Order struct {
 	PaymentStatus OrderStatus `json:"payment_status" gorm:"type:text;not null;default:processing;check:order_status_check,status IN ('success','canceled','pending','processing')"`
 	Status        OrderStatus `json:"status" gorm:"type:text;not null;default:processing;check:order_status_check,status IN ('success','canceled','pending','processing')"`
 
-	Amount float64 `gorm:"not null" json:"amount"`
-	Fee    float64 `gorm:"not null;default:0" json:"fee"`
+	// SerialNumber (SN) dari supplier, dikirim ke customer saat order sukses
+	SerialNumber string `gorm:"size:255" json:"serial_number"`
+
+	Amount money.Money `gorm:"not null" json:"amount"`
+	Fee    money.Money `gorm:"not null;default:0" json:"fee"`
+
+	// ExpiresAt batas waktu pembayaran (setting order_ttl); nil untuk order lama
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
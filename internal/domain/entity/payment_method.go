@@ -1,21 +1,38 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/pkg/money"
+)
 
 type PaymentMethod struct {
-	ID         uint64    `json:"id" gorm:"primaryKey;autoIncrement"`
-	Type       string    `json:"type" gorm:"type:varchar(255);not null"`
-	Name       string    `json:"name" gorm:"type:varchar(255);not null"`
-	ImgUrl     string    `json:"img_url" gorm:"type:varchar(255);not null"`
-	ProviderID int64     `json:"provider_id" gorm:"not null"`
-	Fee        *float64  `json:"fee,omitempty"`
-	Percent    *float64  `json:"percent,omitempty"`
-	CreatedAt  time.Time `json:"created_at,omitempty" gorm:"autoCreateTime;"`
-	UpdatedAt  time.Time `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
+	ID         uint64       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Type       string       `json:"type" gorm:"type:varchar(255);not null"`
+	Name       string       `json:"name" gorm:"type:varchar(255);not null"`
+	ImgUrl     string       `json:"img_url" gorm:"type:varchar(255);not null"`
+	ProviderID int64        `json:"provider_id" gorm:"not null"`
+	Fee        *money.Money `json:"fee,omitempty"`
+	Percent    *money.Rate  `json:"percent,omitempty" gorm:"type:numeric(9,4)"` // persen, mis. 0.7
+	CreatedAt  time.Time    `json:"created_at,omitempty" gorm:"autoCreateTime;"`
+	UpdatedAt  time.Time    `json:"updated_at,omitempty" gorm:"autoUpdateTime;"`
 
 	// --- UBAH JSON TAG DARI "-" -> "provider,omitempty" ---
 	Provider Provider `json:"provider,omitempty" gorm:"foreignKey:ProviderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
 }
 
+// FeeFor menghitung biaya metode pembayaran (flat + persen) untuk nominal amount
+func (pm *PaymentMethod) FeeFor(amount money.Money) money.Money {
+	var flat money.Money
+	var rate money.Rate
+	if pm.Fee != nil {
+		flat = *pm.Fee
+	}
+	if pm.Percent != nil {
+		rate = *pm.Percent
+	}
+	return money.Fee(amount, flat, rate)
+}
+
 // TableName overrides the table name used by GORM
 func (PaymentMethod) TableName() string { return "payment_methods" }
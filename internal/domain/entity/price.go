@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/pkg/money"
+)
 
 type Price struct {
 	ID          int `json:"id" gorm:"primaryKey;autoIncrement"`
@@ -8,7 +12,7 @@ type Price struct {
 	UserLevelID int `json:"user_level_id" gorm:"not null;default:1"`
 
 	// --- UBAH JSON TAG DARI "Price" -> "price" ---
-	Price float64 `json:"price" gorm:"not null;column:amount"`
+	Price money.Money `json:"price" gorm:"not null;column:amount"`
 	// ---
 
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
@@ -1,23 +1,27 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/pkg/money"
+)
 
 type Product struct {
-	ID          int       `json:"id" gorm:"primaryKey;autoIncrement"`
-	Name        string    `json:"name" gorm:"type:varchar(255);not null;unique"`
-	SkuCode     string    `json:"sku_code" gorm:"not null;unique"`
-	SellerName  string    `json:"seller_name" gorm:"varchar(255)"`
-	CategoryID  int       `json:"category_id" gorm:"not null"`
-	ProviderID  int64     `json:"provider_id" gorm:"not null"`
-	Status      CatStatus `json:"status" gorm:"type:text;not null;default:inactive;check:cat_status_check,status IN ('inactive','active','problem')"`
-	Stock       int64     `json:"stock" gorm:"not null"`
-	BasePrice   float64   `json:"-" gorm:"not null;"` // <-- Bagus, BasePrice disembunyikan
-	Description string    `json:"description"`
-	ImgUrl      string    `json:"img_url" gorm:"not null"`
-	StartOff    string    `json:"start_off"`
-	EndOff      string    `json:"end_off"`
-	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID          int         `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string      `json:"name" gorm:"type:varchar(255);not null;unique"`
+	SkuCode     string      `json:"sku_code" gorm:"not null;unique"`
+	SellerName  string      `json:"seller_name" gorm:"varchar(255)"`
+	CategoryID  int         `json:"category_id" gorm:"not null"`
+	ProviderID  int64       `json:"provider_id" gorm:"not null"`
+	Status      CatStatus   `json:"status" gorm:"type:text;not null;default:inactive;check:cat_status_check,status IN ('inactive','active','problem')"`
+	Stock       int64       `json:"stock" gorm:"not null"`
+	BasePrice   money.Money `json:"-" gorm:"not null;"` // <-- Bagus, BasePrice disembunyikan
+	Description string      `json:"description"`
+	ImgUrl      string      `json:"img_url" gorm:"not null"`
+	StartOff    string      `json:"start_off"`
+	EndOff      string      `json:"end_off"`
+	CreatedAt   time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
 
 	// --- PERUBAHAN DI SINI ---
 	// UBAH DARI:
@@ -0,0 +1,13 @@
+package entity
+
+import "time"
+
+// RateLimitBucket adalah state token bucket per kunci untuk store rate limit "database".
+type RateLimitBucket struct {
+	Key       string    `gorm:"type:varchar(255);primaryKey" json:"key"`
+	Tokens    float64   `gorm:"not null" json:"tokens"`
+	UpdatedAt time.Time `gorm:"not null" json:"updated_at"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+}
+
+func (RateLimitBucket) TableName() string { return "rate_limit_buckets" }
@@ -0,0 +1,16 @@
+package entity
+
+import "time"
+
+// RecoveryCode adalah kode cadangan 2FA sekali pakai, disimpan dalam bentuk hash.
+type RecoveryCode struct {
+	ID        uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint64     `gorm:"not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"type:varchar(64);not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+func (RecoveryCode) TableName() string { return "user_recovery_codes" }
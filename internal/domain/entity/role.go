@@ -0,0 +1,50 @@
+package entity
+
+import "time"
+
+// Role bawaan. Nama role disimpan di users.role dan ikut di access token.
+const (
+	RoleAdmin = "admin" // superuser: selalu punya semua permission
+	RoleUser  = "user"  // customer/reseller, tanpa permission admin
+)
+
+// Permission yang dicek oleh middleware RequirePermission
+const (
+	PermOrdersRead      = "orders:read"
+	PermOrdersWrite     = "orders:write"
+	PermPricesWrite     = "prices:write"
+	PermProductsWrite   = "products:write"
+	PermCatalogWrite    = "catalog:write" // menu, kategori, banner, metode pembayaran
+	PermProvidersManage = "providers:manage"
+	PermSettingsManage  = "settings:manage"
+	PermUsersManage     = "users:manage"
+	PermBalanceAdjust   = "balance:adjust"
+	PermWebhooksManage  = "webhooks:manage"
+	PermRolesManage     = "roles:manage"
+	PermAuditRead       = "audit:read"
+)
+
+// Permissions adalah katalog lengkap permission yang bisa diberikan ke role
+var Permissions = []string{
+	PermOrdersRead, PermOrdersWrite, PermPricesWrite, PermProductsWrite, PermCatalogWrite,
+	PermProvidersManage, PermSettingsManage, PermUsersManage, PermBalanceAdjust,
+	PermWebhooksManage, PermRolesManage, PermAuditRead,
+}
+
+type Role struct {
+	ID          uint64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string           `gorm:"type:varchar(50);not null;uniqueIndex" json:"name"`
+	Description string           `gorm:"type:varchar(255)" json:"description"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	CreatedAt   time.Time        `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time        `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+func (Role) TableName() string { return "roles" }
+
+type RolePermission struct {
+	RoleID     uint64 `gorm:"primaryKey" json:"role_id"`
+	Permission string `gorm:"type:varchar(50);primaryKey" json:"permission"`
+}
+
+func (RolePermission) TableName() string { return "role_permissions" }
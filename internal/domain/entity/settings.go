@@ -2,9 +2,28 @@ package entity
 
 import "time"
 
+// Key setting yang dikenal aplikasi. Tipe, default dan validasinya dideklarasikan di service.SettingDefinitions.
+const (
+	SettingMaintenanceMode    = "maintenance_mode"
+	SettingMaintenanceMessage = "maintenance_message"
+	SettingSiteName           = "site_name"
+	SettingSiteLogo           = "site_logo"
+	SettingSupportEmail       = "support_email"
+	SettingContactWhatsapp    = "contact_whatsapp"
+	SettingMinDeposit         = "min_deposit"
+	SettingOrderTTL           = "order_ttl"
+
+	SettingFeatureRegistration  = "feature_registration"
+	SettingFeatureDeposit       = "feature_deposit"
+	SettingFeatureGuestCheckout = "feature_guest_checkout"
+
+	// SettingRequireAdmin2FA: jika true, admin dan staff (role selain user) wajib 2FA sebelum mendapat sesi
+	SettingRequireAdmin2FA = "require_admin_2fa"
+)
+
 type Settings struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Name      string    `gorm:"type:varchar(100);not null" json:"name"`
+	Name      string    `gorm:"type:varchar(100);not null;uniqueIndex:ux_settings_name" json:"name"`
 	Value     string    `gorm:"type:text;not null" json:"value"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// UsedChallenge mencatat jti challenge token 2FA yang sudah dipakai agar token tidak bisa dipakai ulang.
+// Baris boleh dihapus setelah ExpiresAt karena token-nya sendiri sudah tidak berlaku.
+type UsedChallenge struct {
+	JTI       string    `gorm:"type:varchar(36);primaryKey" json:"-"`
+	UserID    uint64    `gorm:"not null" json:"user_id"`
+	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+func (UsedChallenge) TableName() string { return "used_challenges" }
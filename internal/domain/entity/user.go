@@ -2,25 +2,41 @@ package entity
 
 import (
 	"time"
+
+	"github.com/wildanasyrof/backend-topup/pkg/money"
 )
 
 type User struct {
-	ID              uint64    `gorm:"primaryKey;autoIncrement"                json:"id"`
-	Name            string    `gorm:"not null"               json:"name"`
-	Email           string    `gorm:"unique;not null"              json:"email"`
-	Role            string    `gorm:"type:varchar(10);default:'user'"            json:"role"`
-	Balance         float64   `gorm:";not null;default:0"         json:"balance"`
-	EmailVerifiedAt time.Time `json:"verified_at"`
-	PasswordHash    string    `gorm:"size:255"       json:"-"`
-	Whatsapp        string    `gorm:"size:255"     json:"whatsapp"`
-	GoogleID        string    `gorm:"size:255"       json:"google_id"`
-	GoogleType      string    `gorm:"size:255"     json:"google_type"`
-	OTP             int       `         json:"-"`
-	IsVerified      bool      `gorm:"not null;default:false"      json:"-"`
-	RememberToken   bool      `gorm:"not null;default:false"  json:"-"`
-	UserLevelID     int       `json:"user_level_id" gorm:"not null;default:1"`
-	CreatedAt       time.Time `gorm:"autoCreateTime"         json:"created_at"`
-	UpdatedAt       time.Time `gorm:"autoUpdateTime"         json:"updated_at"`
+	ID              uint64      `gorm:"primaryKey;autoIncrement"                json:"id"`
+	Name            string      `gorm:"not null"               json:"name"`
+	Email           string      `gorm:"unique;not null"              json:"email"`
+	Role            string      `gorm:"type:varchar(50);default:'user'"            json:"role"`
+	Balance         money.Money `gorm:";not null;default:0"         json:"balance"`
+	EmailVerifiedAt time.Time   `json:"verified_at"`
+	PasswordHash    string      `gorm:"size:255"       json:"-"`
+	Whatsapp        string      `gorm:"size:255"     json:"whatsapp"`
+	GoogleID        string      `gorm:"size:255"       json:"google_id"`
+	GoogleType      string      `gorm:"size:255"     json:"google_type"`
+	OTP             int         `         json:"-"`
+	IsVerified      bool        `gorm:"not null;default:false"      json:"-"`
+	RememberToken   bool        `gorm:"not null;default:false"  json:"-"`
+	UserLevelID     int         `json:"user_level_id" gorm:"not null;default:1"`
+	CreatedAt       time.Time   `gorm:"autoCreateTime"         json:"created_at"`
+	UpdatedAt       time.Time   `gorm:"autoUpdateTime"         json:"updated_at"`
+
+	// TokenVersion dinaikkan saat role berubah atau user di-suspend agar access token lama langsung ditolak
+	TokenVersion int        `gorm:"not null;default:0" json:"-"`
+	SuspendedAt  *time.Time `json:"suspended_at,omitempty"`
+
+	// WhatsappVerifiedAt diisi setelah user membuktikan kepemilikan nomor lewat OTP. Hanya nomor
+	// terverifikasi yang bisa dipakai login WhatsApp, dan nomor terverifikasi unik antar user
+	WhatsappVerifiedAt *time.Time `json:"whatsapp_verified_at,omitempty"`
+
+	// TOTPSecret diisi saat setup, disimpan terenkripsi (pkg/secretbox); 2FA baru aktif setelah
+	// kode pertama diverifikasi (TOTPEnabled)
+	TOTPSecret   string `gorm:"size:255" json:"-"`
+	TOTPEnabled  bool   `gorm:"not null;default:false" json:"two_factor_enabled"`
+	TOTPLastStep int64  `gorm:"not null;default:0" json:"-"`
 
 	UserLevel UserLevel `json:"-" gorm:"foreignKey:UserLevelID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
 }
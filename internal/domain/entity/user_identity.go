@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// UserIdentity menautkan akun provider OAuth/OIDC (google, discord, ...) ke user.
+// Satu user maksimal punya satu identitas per provider.
+type UserIdentity struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint64    `gorm:"not null;uniqueIndex:idx_user_identities_user_provider" json:"user_id"`
+	Provider  string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_user_identities_user_provider;uniqueIndex:idx_user_identities_provider_subject" json:"provider"`
+	Subject   string    `gorm:"size:255;not null;uniqueIndex:idx_user_identities_provider_subject" json:"-"`
+	Email     string    `gorm:"size:255" json:"email"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+func (UserIdentity) TableName() string { return "user_identities" }
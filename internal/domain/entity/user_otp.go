@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// OTPPurpose membedakan kegunaan sebuah kode OTP.
+type OTPPurpose string
+
+const (
+	OTPEmailVerification OTPPurpose = "email_verification"
+	OTPPasswordReset     OTPPurpose = "password_reset"
+	OTPWhatsappLogin     OTPPurpose = "whatsapp_login"
+	OTPWhatsappVerify    OTPPurpose = "whatsapp_verification"
+)
+
+// UserOTP menyimpan kode OTP dalam bentuk hash. Kode plain hanya dikirim ke user.
+type UserOTP struct {
+	ID         uint64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     uint64     `gorm:"not null;index:idx_user_otps_user_purpose" json:"user_id"`
+	Purpose    OTPPurpose `gorm:"type:varchar(50);not null;index:idx_user_otps_user_purpose" json:"purpose"`
+	CodeHash   string     `gorm:"type:varchar(64);not null" json:"-"`
+	Attempts   int        `gorm:"not null;default:0" json:"attempts"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	ConsumedAt *time.Time `json:"consumed_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+func (UserOTP) TableName() string { return "user_otps" }
@@ -9,14 +9,19 @@ import (
 
 // UserSession merepresentasikan refresh token yang valid untuk seorang user.
 // ID-nya (UUID) adalah refresh token itu sendiri.
+// Setiap rotasi membuat sesi baru dengan FamilyID yang sama; sesi lama ditandai RotatedAt
+// sehingga pemakaian ulang token lama bisa dideteksi dan seluruh family di-revoke.
 type UserSession struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
-	UserID    uint64    `gorm:"not null;index" json:"user_id"`
-	IsRevoked bool      `gorm:"not null;default:false" json:"-"`
-	ExpiresAt time.Time `gorm:"not null;index" json:"expires_at"`
-	UserAgent string    `gorm:"type:text" json:"user_agent"`
-	ClientIP  string    `gorm:"type:varchar(50)" json:"client_ip"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	FamilyID   uuid.UUID  `gorm:"type:uuid;index" json:"family_id"`
+	UserID     uint64     `gorm:"not null;index" json:"user_id"`
+	IsRevoked  bool       `gorm:"not null;default:false" json:"-"`
+	RotatedAt  *time.Time `json:"-"`
+	ReplacedBy *uuid.UUID `gorm:"type:uuid" json:"-"`
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expires_at"`
+	UserAgent  string     `gorm:"type:text" json:"user_agent"`
+	ClientIP   string     `gorm:"type:varchar(50)" json:"client_ip"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
 
 	// Relasi
 	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user"`
@@ -32,5 +37,9 @@ func (s *UserSession) BeforeCreate(tx *gorm.DB) (err error) {
 	if s.ID == uuid.Nil {
 		s.ID = uuid.New()
 	}
+	// Sesi pertama dari sebuah login memulai family baru
+	if s.FamilyID == uuid.Nil {
+		s.FamilyID = s.ID
+	}
 	return
 }
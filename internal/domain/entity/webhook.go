@@ -0,0 +1,52 @@
+package entity
+
+import "time"
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookPending WebhookDeliveryStatus = "pending"
+	WebhookSuccess WebhookDeliveryStatus = "success"
+	WebhookFailed  WebhookDeliveryStatus = "failed"
+)
+
+// WebhookEndpoint adalah URL milik reseller yang menerima event.
+// Secret disimpan apa adanya karena dibutuhkan untuk menandatangani setiap pengiriman.
+type WebhookEndpoint struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint64    `gorm:"not null;index" json:"user_id"`
+	URL       string    `gorm:"type:varchar(500);not null" json:"url"`
+	Secret    string    `gorm:"type:varchar(100);not null" json:"-"`
+	Events    string    `gorm:"type:text;not null;default:''" json:"-"` // dipisah koma; kosong berarti semua event
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// Relasi
+	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+func (WebhookEndpoint) TableName() string { return "webhook_endpoints" }
+
+// WebhookDelivery adalah outbox sekaligus log pengiriman: satu baris per event per endpoint.
+// Dispatcher mengambil baris pending yang NextAttemptAt-nya sudah lewat.
+type WebhookDelivery struct {
+	ID             uint64                `gorm:"primaryKey;autoIncrement" json:"id"`
+	EndpointID     uint64                `gorm:"not null;index" json:"endpoint_id"`
+	UserID         uint64                `gorm:"not null;index" json:"user_id"`
+	EventID        string                `gorm:"type:varchar(36);not null" json:"event_id"`
+	Event          string                `gorm:"type:varchar(50);not null" json:"event"`
+	Payload        string                `gorm:"type:jsonb;not null" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(10);not null;default:pending;index:idx_webhook_deliveries_due,priority:1" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time             `gorm:"not null;index:idx_webhook_deliveries_due,priority:2" json:"next_attempt_at"`
+	LastAttemptAt  *time.Time            `json:"last_attempt_at"`
+	ResponseStatus int                   `gorm:"not null;default:0" json:"response_status"`
+	LastError      string                `gorm:"type:text" json:"last_error"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `gorm:"autoCreateTime" json:"created_at"`
+
+	// Relasi
+	Endpoint *WebhookEndpoint `gorm:"foreignKey:EndpointID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+func (WebhookDelivery) TableName() string { return "webhook_deliveries" }
@@ -0,0 +1,328 @@
+// Package apidoc menyusun spesifikasi OpenAPI dari katalog route (routes.go) dan DTO yang dipakai handler.
+// Setiap route baru di internal/http/router wajib ditambahkan ke katalog; Missing dipakai saat startup
+// dan oleh `go run ./cmd/openapi -check` untuk menemukan route yang belum terdokumentasi.
+package apidoc
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/pkg/money"
+	"github.com/wildanasyrof/backend-topup/pkg/openapi"
+	"github.com/wildanasyrof/backend-topup/pkg/pagination"
+	"github.com/wildanasyrof/backend-topup/pkg/response"
+)
+
+// Auth adalah cara autentikasi sebuah route.
+type Auth int
+
+const (
+	Public Auth = iota
+	Bearer
+	APIKey
+	RefreshCookie
+)
+
+// Route mendeskripsikan satu operasi. Path memakai format fiber (/menus/:id).
+type Route struct {
+	Method string
+	Path   string
+	// SpecPath dipakai di spesifikasi jika berbeda dari Path: OpenAPI melarang dua path yang hanya
+	// beda nama parameter (mis. /categories/:slug dan /categories/:id)
+	SpecPath string
+	Tag      string
+	Summary  string
+	Auth     Auth
+	// Permission diisi jika route dijaga RequirePermission
+	Permission string
+
+	Body  any // body JSON
+	Query any // struct dengan tag `query`
+	// Form adalah body multipart/form-data (tag `form`); File menambahkan field file pada form itu
+	Form         any
+	FormType     string // default multipart/form-data
+	File         string
+	FileRequired bool
+	// StringID: path param :id berupa string (mis. UUID), bukan angka
+	StringID bool
+
+	// Response adalah isi field data pada envelope, atau seluruh body jika Raw
+	Response  any
+	Paginated bool
+	Raw       bool
+	Status    int // default 200
+	Redirect  bool
+	// Extra menambahkan response sukses lain dengan bentuk body yang sama (mis. 503 readiness)
+	Extra map[int]string
+}
+
+const (
+	envelopeSchema = "Envelope"
+	errorSchema    = "ErrorResponse"
+)
+
+// Undocumented adalah prefix route yang sengaja tidak masuk spesifikasi (file statis dan Swagger UI).
+var Undocumented = []string{"/uploads", "/docs"}
+
+// Build menghasilkan dokumen OpenAPI untuk semua route di katalog.
+func Build() *openapi.Document {
+	g := openapi.NewGenerator()
+	g.Register(money.Money(0), &openapi.Schema{Type: "integer", Format: "int64", Description: "Amount in rupiah"})
+	g.Register(money.Rate(0), &openapi.Schema{Type: "number", Description: "Percentage, e.g. 0.7"})
+
+	g.Schema(response.Envelope{})
+	errResp := &openapi.Schema{AllOf: []*openapi.Schema{
+		openapi.Ref(envelopeSchema),
+		{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"success": {Type: "boolean", Enum: []any{false}},
+				"error":   g.Schema(response.ErrBody{}),
+			},
+			Required: []string{"error"},
+		},
+	}}
+	meta := g.Schema(pagination.Meta{})
+
+	doc := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Backend Topup API",
+			Description: "Semua response JSON memakai envelope `{success, data, meta, error, request_id}` kecuali disebutkan lain.",
+			Version:     "1.0.0",
+		},
+		Tags:  tags,
+		Paths: map[string]*openapi.PathItem{},
+		Components: openapi.Components{
+			SecuritySchemes: map[string]*openapi.SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT", Description: "Access token dari /auth/login"},
+				"apiKey": {Type: "apiKey", In: "header", Name: "X-API-Key",
+					Description: "Key ID reseller (tk_...). Request juga wajib membawa X-Timestamp (unix detik) dan X-Signature (HMAC-SHA256 dengan signing secret sk_..., secret tidak pernah dikirim). Signature yang sama hanya diterima sekali."},
+				"refreshCookie": {Type: "apiKey", In: "cookie", Name: "refresh_token", Description: "Refresh token HttpOnly dari login"},
+			},
+		},
+	}
+
+	for _, r := range Routes {
+		path := specPath(r.specPath())
+		item := doc.Paths[path]
+		if item == nil {
+			item = &openapi.PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(r.Method)] = operation(g, r, meta)
+	}
+
+	doc.Components.Schemas = g.Schemas()
+	doc.Components.Schemas[errorSchema] = errResp
+	return doc
+}
+
+func operation(g *openapi.Generator, r Route, meta *openapi.Schema) *openapi.Operation {
+	op := &openapi.Operation{
+		Tags:        []string{r.Tag},
+		Summary:     r.Summary,
+		OperationID: operationID(r.Method, r.Path),
+		Responses:   map[string]*openapi.Response{},
+	}
+	if r.Permission != "" {
+		op.Description = "Membutuhkan permission `" + r.Permission + "`."
+	}
+
+	switch r.Auth {
+	case Bearer:
+		op.Security = []openapi.SecurityRequirement{{"bearerAuth": {}}}
+	case APIKey:
+		op.Security = []openapi.SecurityRequirement{{"apiKey": {}}}
+		for _, h := range []string{"X-Timestamp", "X-Signature"} {
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: h, In: "header", Required: true, Schema: &openapi.Schema{Type: "string"}})
+		}
+	case RefreshCookie:
+		op.Security = []openapi.SecurityRequirement{{"refreshCookie": {}}}
+	}
+
+	params := pathParams(r)
+	op.Parameters = append(op.Parameters, params...)
+	if r.Query != nil {
+		op.Parameters = append(op.Parameters, g.Parameters(r.Query)...)
+	}
+
+	switch {
+	case r.Body != nil:
+		op.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{
+			fiber.MIMEApplicationJSON: {Schema: g.Schema(r.Body)},
+		}}
+	case r.Form != nil || r.File != "":
+		form := g.FormSchema(r.Form)
+		if r.File != "" {
+			form.Properties[r.File] = &openapi.Schema{Type: "string", Format: "binary"}
+			if r.FileRequired {
+				form.Required = append(form.Required, r.File)
+			}
+		}
+		ct := r.FormType
+		if ct == "" {
+			ct = fiber.MIMEMultipartForm
+		}
+		op.RequestBody = &openapi.RequestBody{Required: true, Content: map[string]openapi.MediaType{ct: {Schema: form}}}
+	}
+
+	status := r.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if r.Redirect {
+		op.Responses[strconv.Itoa(status)] = &openapi.Response{
+			Description: http.StatusText(status),
+			Headers:     map[string]*openapi.Header{"Location": {Schema: &openapi.Schema{Type: "string", Format: "uri"}}},
+		}
+	} else {
+		op.Responses[strconv.Itoa(status)] = success(g, r, meta, http.StatusText(status))
+		for code, desc := range r.Extra {
+			op.Responses[strconv.Itoa(code)] = success(g, r, meta, desc)
+		}
+	}
+
+	if r.Raw {
+		return op
+	}
+	errResp := func(desc string) *openapi.Response {
+		return &openapi.Response{Description: desc, Content: map[string]openapi.MediaType{
+			fiber.MIMEApplicationJSON: {Schema: openapi.Ref(errorSchema)},
+		}}
+	}
+	if op.RequestBody != nil || r.Query != nil || len(params) > 0 {
+		op.Responses["400"] = errResp("Invalid request or validation failed (error.fields berisi pesan per field)")
+	}
+	if r.Auth != Public {
+		op.Responses["401"] = errResp("Missing or invalid credentials")
+	}
+	if r.Permission != "" {
+		op.Responses["403"] = errResp("Missing permission")
+	}
+	if len(params) > 0 {
+		op.Responses["404"] = errResp("Resource not found")
+	}
+	op.Responses["default"] = errResp("Error")
+	return op
+}
+
+// success membungkus schema Response dengan envelope, kecuali route Raw.
+func success(g *openapi.Generator, r Route, meta *openapi.Schema, desc string) *openapi.Response {
+	data := g.Schema(r.Response)
+	if r.Raw {
+		if data == nil {
+			return &openapi.Response{Description: desc}
+		}
+		return &openapi.Response{Description: desc, Content: map[string]openapi.MediaType{
+			fiber.MIMEApplicationJSON: {Schema: data},
+		}}
+	}
+
+	body := &openapi.Schema{Type: "object", Properties: map[string]*openapi.Schema{}}
+	if data != nil {
+		body.Properties["data"] = data
+	}
+	if r.Paginated {
+		body.Properties["meta"] = meta
+		body.Required = []string{"data", "meta"}
+	}
+	return &openapi.Response{Description: desc, Content: map[string]openapi.MediaType{
+		fiber.MIMEApplicationJSON: {Schema: &openapi.Schema{AllOf: []*openapi.Schema{openapi.Ref(envelopeSchema), body}}},
+	}}
+}
+
+var paramPattern = regexp.MustCompile(`:(\w+)`)
+
+func (r Route) specPath() string {
+	if r.SpecPath != "" {
+		return r.SpecPath
+	}
+	return r.Path
+}
+
+// pathParams: tipe parameter mengikuti nama di Path (yang dibaca handler), nama mengikuti SpecPath.
+func pathParams(r Route) []openapi.Parameter {
+	var params []openapi.Parameter
+	names := paramPattern.FindAllStringSubmatch(r.specPath(), -1)
+	for i, m := range paramPattern.FindAllStringSubmatch(r.Path, -1) {
+		p := openapi.Parameter{Name: m[1], In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}
+		if m[1] == "id" && !r.StringID {
+			one := 1.0
+			p.Schema = &openapi.Schema{Type: "integer", Format: "int64", Minimum: &one}
+		}
+		if i < len(names) && names[i][1] != m[1] {
+			p.Name, p.Description = names[i][1], m[1]+", bukan "+names[i][1]
+		}
+		params = append(params, p)
+	}
+	return params
+}
+
+// specPath mengubah /menus/:id menjadi /menus/{id}.
+func specPath(path string) string {
+	return paramPattern.ReplaceAllString(normalizePath(path), "{$1}")
+}
+
+func normalizePath(path string) string {
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// operationID: GET /menus/:id menjadi getMenusById.
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, seg := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' }) {
+		if strings.HasPrefix(seg, ":") {
+			b.WriteString("By")
+			seg = seg[1:]
+		}
+		for _, part := range strings.FieldsFunc(seg, func(r rune) bool { return r == '-' || r == '.' || r == '_' }) {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// Missing mengembalikan route terdaftar ("METHOD /path") yang tidak ada di katalog Routes.
+func Missing(routes []fiber.Route) []string {
+	documented := map[string]bool{}
+	for _, r := range Routes {
+		documented[r.Method+" "+normalizePath(r.Path)] = true
+	}
+
+	seen := map[string]bool{}
+	var missing []string
+	for _, r := range routes {
+		// HEAD otomatis dibuat fiber untuk setiap GET
+		if r.Method == fiber.MethodHead || undocumented(r.Path) {
+			continue
+		}
+		key := r.Method + " " + normalizePath(r.Path)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if !documented[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func undocumented(path string) bool {
+	for _, p := range Undocumented {
+		if path == p || strings.HasPrefix(path, p+"/") || strings.HasPrefix(path, p+"*") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package apidoc
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/pkg/jwt"
+	"github.com/wildanasyrof/backend-topup/pkg/openapi"
+)
+
+// Response yang dibangun dengan fiber.Map di handler
+type (
+	message struct {
+		Message string `json:"message"`
+	}
+	// loginResponse: user + access_token saat login selesai, atau field two_factor_* saat butuh 2FA
+	loginResponse struct {
+		User               *entity.User `json:"user,omitempty"`
+		AccessToken        string       `json:"access_token,omitempty"`
+		RecoveryCodes      []string     `json:"recovery_codes,omitempty"`
+		TwoFactorRequired  bool         `json:"two_factor_required,omitempty"`
+		ChallengeToken     string       `json:"challenge_token,omitempty"`
+		EnrollmentRequired bool         `json:"enrollment_required,omitempty"`
+	}
+	recoveryCodes struct {
+		RecoveryCodes []string `json:"recovery_codes"`
+	}
+	oauthURL struct {
+		URL string `json:"url"`
+	}
+	liveness struct {
+		Status string `json:"status"`
+	}
+)
+
+// Parameter yang dibaca langsung lewat c.Query/c.FormValue, bukan DTO
+type (
+	depositQuery struct {
+		ID string `query:"id" validate:"required"`
+	}
+	oauthCallback struct {
+		Code  string `query:"code" form:"code" validate:"required"`
+		State string `query:"state" form:"state" validate:"required"`
+	}
+)
+
+var tags = []openapi.Tag{
+	{Name: "Health", Description: "Liveness dan readiness probe"},
+	{Name: "Auth", Description: "Registrasi, login, refresh token, 2FA dan social login"},
+	{Name: "Me", Description: "Profil, identitas, 2FA, API key dan webhook milik user yang sedang login"},
+	{Name: "Users", Description: "Manajemen user oleh staff"},
+	{Name: "Sessions", Description: "Daftar sesi login dan remote logout"},
+	{Name: "Settings", Description: "Pengaturan aplikasi"},
+	{Name: "Menus"},
+	{Name: "Categories"},
+	{Name: "Products"},
+	{Name: "Prices"},
+	{Name: "Providers"},
+	{Name: "Banners"},
+	{Name: "Payment Methods"},
+	{Name: "Deposits"},
+	{Name: "Orders"},
+	{Name: "H2H", Description: "Host-to-host untuk reseller, memakai API key + HMAC signature"},
+	{Name: "Admin", Description: "Role, permission dan audit log"},
+	{Name: "Webhooks", Description: "Log pengiriman webhook"},
+	{Name: "Docs"},
+}
+
+// Routes adalah katalog semua route di internal/http/router. Tambahkan entri di sini setiap kali
+// menambah route; route yang terlewat dilaporkan oleh Missing.
+var Routes = []Route{
+	// --- Health & infrastruktur ---
+	{Method: fiber.MethodGet, Path: "/health", Tag: "Health", Summary: "Liveness probe (alias /health/live)", Response: liveness{}, Raw: true},
+	{Method: fiber.MethodGet, Path: "/health/live", Tag: "Health", Summary: "Liveness probe", Response: liveness{}, Raw: true},
+	{Method: fiber.MethodGet, Path: "/health/ready", Tag: "Health", Summary: "Readiness probe dengan hasil tiap check", Response: dto.HealthReport{}, Raw: true,
+		Extra: map[int]string{http.StatusServiceUnavailable: "Not ready or draining"}},
+	{Method: fiber.MethodGet, Path: "/.well-known/jwks.json", Tag: "Auth", Summary: "Public key untuk verifikasi access token", Response: jwt.JWKSet{}, Raw: true},
+	{Method: fiber.MethodGet, Path: "/public-settings", Tag: "Settings", Summary: "Pengaturan publik untuk frontend", Response: map[string]any{}},
+	{Method: fiber.MethodGet, Path: "/openapi.json", Tag: "Docs", Summary: "Spesifikasi OpenAPI ini", Response: map[string]any{}, Raw: true},
+
+	// --- Auth ---
+	{Method: fiber.MethodPost, Path: "/auth/register", Tag: "Auth", Summary: "Registrasi user baru", Body: dto.RegisterUserRequest{}, Response: entity.User{}},
+	{Method: fiber.MethodPost, Path: "/auth/login", Tag: "Auth", Summary: "Login dengan email dan password", Body: dto.LoginUserRequest{}, Response: loginResponse{}},
+	{Method: fiber.MethodPost, Path: "/auth/refresh", Tag: "Auth", Summary: "Rotasi refresh token dan terbitkan access token baru", Auth: RefreshCookie, Response: dto.TokenResponse{}},
+	{Method: fiber.MethodPost, Path: "/auth/logout", Tag: "Auth", Summary: "Hapus sesi dan cookie refresh token", Auth: RefreshCookie, Response: message{}},
+	{Method: fiber.MethodPost, Path: "/auth/verify-email/request", Tag: "Auth", Summary: "Kirim kode verifikasi email", Body: dto.EmailRequest{}, Response: message{}},
+	{Method: fiber.MethodPost, Path: "/auth/verify-email", Tag: "Auth", Summary: "Verifikasi email dengan kode", Body: dto.VerifyEmailRequest{}, Response: message{}},
+	{Method: fiber.MethodPost, Path: "/auth/forgot-password", Tag: "Auth", Summary: "Kirim kode reset password", Body: dto.EmailRequest{}, Response: message{}},
+	{Method: fiber.MethodPost, Path: "/auth/reset-password", Tag: "Auth", Summary: "Reset password dengan kode", Body: dto.ResetPasswordRequest{}, Response: message{}},
+	{Method: fiber.MethodPost, Path: "/auth/whatsapp/request", Tag: "Auth", Summary: "Kirim kode login via WhatsApp (hanya nomor terverifikasi)", Body: dto.WhatsappLoginRequest{}, Response: message{}},
+	{Method: fiber.MethodPost, Path: "/auth/whatsapp/verify", Tag: "Auth", Summary: "Login dengan kode WhatsApp", Body: dto.WhatsappVerifyRequest{}, Response: loginResponse{}},
+	{Method: fiber.MethodPost, Path: "/auth/2fa/verify", Tag: "Auth", Summary: "Selesaikan login dengan kode 2FA", Body: dto.TwoFactorVerifyRequest{}, Response: loginResponse{}},
+	{Method: fiber.MethodPost, Path: "/auth/2fa/enroll", Tag: "Auth", Summary: "Mulai setup 2FA wajib saat login", Body: dto.TwoFactorChallengeRequest{}, Response: dto.TwoFactorSetupResponse{}},
+	{Method: fiber.MethodPost, Path: "/auth/2fa/enroll/confirm", Tag: "Auth", Summary: "Aktifkan 2FA lalu selesaikan login", Body: dto.TwoFactorVerifyRequest{}, Response: loginResponse{}},
+	{Method: fiber.MethodGet, Path: "/auth/:provider/login", Tag: "Auth", Summary: "Redirect ke halaman login provider OAuth", Status: http.StatusFound, Redirect: true},
+	{Method: fiber.MethodGet, Path: "/auth/:provider/callback", Tag: "Auth", Summary: "Callback OAuth", Query: oauthCallback{}, Response: loginResponse{}},
+	{Method: fiber.MethodPost, Path: "/auth/:provider/callback", Tag: "Auth", Summary: "Callback OAuth (response_mode=form_post)", Form: oauthCallback{},
+		FormType: fiber.MIMEApplicationForm, Response: loginResponse{}},
+
+	// --- Me ---
+	{Method: fiber.MethodGet, Path: "/me", Tag: "Me", Summary: "Profil user", Auth: Bearer, Response: entity.User{}},
+	{Method: fiber.MethodPut, Path: "/me", Tag: "Me", Summary: "Ubah profil", Auth: Bearer, Body: dto.UpdateUserRequest{}, Response: entity.User{}},
+	{Method: fiber.MethodPut, Path: "/me/password", Tag: "Me", Summary: "Ubah atau buat password (sesi lain di-logout)", Auth: Bearer, Body: dto.SetPasswordRequest{}, Response: message{}},
+	{Method: fiber.MethodPost, Path: "/me/whatsapp/verify/request", Tag: "Me", Summary: "Kirim kode verifikasi ke nomor WhatsApp profil", Auth: Bearer, Response: message{}},
+	{Method: fiber.MethodPost, Path: "/me/whatsapp/verify", Tag: "Me", Summary: "Verifikasi nomor WhatsApp (wajib sebelum login WhatsApp)", Auth: Bearer, Body: dto.WhatsappCodeRequest{}, Response: entity.User{}},
+	{Method: fiber.MethodGet, Path: "/me/identities", Tag: "Me", Summary: "Daftar akun OAuth yang tertaut", Auth: Bearer, Response: []entity.UserIdentity{}},
+	{Method: fiber.MethodPost, Path: "/me/identities/:provider", Tag: "Me", Summary: "Mulai menautkan akun OAuth", Auth: Bearer, Response: oauthURL{}},
+	{Method: fiber.MethodDelete, Path: "/me/identities/:provider", Tag: "Me", Summary: "Lepas akun OAuth", Auth: Bearer, Response: message{}},
+	{Method: fiber.MethodPost, Path: "/me/2fa/setup", Tag: "Me", Summary: "Buat secret TOTP", Auth: Bearer, Response: dto.TwoFactorSetupResponse{}},
+	{Method: fiber.MethodPost, Path: "/me/2fa/enable", Tag: "Me", Summary: "Aktifkan 2FA", Auth: Bearer, Body: dto.TwoFactorCodeRequest{}, Response: recoveryCodes{}},
+	{Method: fiber.MethodPost, Path: "/me/2fa/disable", Tag: "Me", Summary: "Matikan 2FA", Auth: Bearer, Body: dto.TwoFactorCodeRequest{}, Response: message{}},
+	{Method: fiber.MethodPost, Path: "/me/2fa/recovery-codes", Tag: "Me", Summary: "Buat ulang recovery code", Auth: Bearer, Body: dto.TwoFactorCodeRequest{}, Response: recoveryCodes{}},
+	{Method: fiber.MethodGet, Path: "/me/api-keys", Tag: "Me", Summary: "Daftar API key", Auth: Bearer, Response: []dto.APIKeyResponse{}},
+	{Method: fiber.MethodPost, Path: "/me/api-keys", Tag: "Me", Summary: "Buat API key (secret hanya ditampilkan sekali)", Auth: Bearer, Body: dto.CreateAPIKeyRequest{}, Response: dto.APIKeyCreatedResponse{}},
+	{Method: fiber.MethodDelete, Path: "/me/api-keys/:id", Tag: "Me", Summary: "Cabut API key", Auth: Bearer, Response: message{}},
+	{Method: fiber.MethodGet, Path: "/me/webhooks", Tag: "Me", Summary: "Daftar endpoint webhook", Auth: Bearer, Response: []dto.WebhookEndpointResponse{}},
+	{Method: fiber.MethodPost, Path: "/me/webhooks", Tag: "Me", Summary: "Daftarkan endpoint webhook (secret hanya ditampilkan sekali)", Auth: Bearer, Body: dto.CreateWebhookRequest{}, Response: dto.WebhookCreatedResponse{}},
+	{Method: fiber.MethodDelete, Path: "/me/webhooks/:id", Tag: "Me", Summary: "Hapus endpoint webhook", Auth: Bearer, Response: message{}},
+	{Method: fiber.MethodGet, Path: "/me/webhooks/deliveries", Tag: "Me", Summary: "Log pengiriman webhook milik user", Auth: Bearer, Query: dto.WebhookDeliveryListQuery{},
+		Response: []entity.WebhookDelivery{}, Paginated: true},
+
+	// --- Users ---
+	{Method: fiber.MethodPut, Path: "/users/:id/suspend", Tag: "Users", Summary: "Suspend user", Auth: Bearer, Permission: entity.PermUsersManage, Response: entity.User{}},
+	{Method: fiber.MethodDelete, Path: "/users/:id/suspend", Tag: "Users", Summary: "Cabut suspend user", Auth: Bearer, Permission: entity.PermUsersManage, Response: entity.User{}},
+	{Method: fiber.MethodPut, Path: "/users/:id/role", Tag: "Users", Summary: "Ubah role user", Auth: Bearer, Permission: entity.PermUsersManage, Body: dto.UpdateUserRoleRequest{}, Response: entity.User{}},
+
+	// --- Sessions ---
+	{Method: fiber.MethodGet, Path: "/sessions", Tag: "Sessions", Summary: "Daftar sesi aktif", Auth: Bearer, Response: []*entity.UserSession{}},
+	{Method: fiber.MethodDelete, Path: "/sessions/:id", Tag: "Sessions", Summary: "Remote logout satu sesi", Auth: Bearer, StringID: true, Response: message{}},
+
+	// --- Settings ---
+	{Method: fiber.MethodPost, Path: "/settings", Tag: "Settings", Summary: "Buat setting", Auth: Bearer, Permission: entity.PermSettingsManage, Body: dto.CreateSettingsRequest{},
+		Response: entity.Settings{}, Status: http.StatusCreated},
+	{Method: fiber.MethodGet, Path: "/settings", Tag: "Settings", Summary: "Daftar setting", Auth: Bearer, Permission: entity.PermSettingsManage, Query: dto.SettingsListQuery{},
+		Response: []*entity.Settings{}, Paginated: true},
+	{Method: fiber.MethodGet, Path: "/settings/definitions", Tag: "Settings", Summary: "Definisi setting yang dikenal aplikasi", Auth: Bearer, Permission: entity.PermSettingsManage,
+		Response: []dto.SettingDefinitionResponse{}},
+	{Method: fiber.MethodPut, Path: "/settings/:id", Tag: "Settings", Summary: "Ubah setting", Auth: Bearer, Permission: entity.PermSettingsManage, Body: dto.UpdateSettingsRequest{}, Response: entity.Settings{}},
+	{Method: fiber.MethodDelete, Path: "/settings/:id", Tag: "Settings", Summary: "Hapus setting", Auth: Bearer, Permission: entity.PermSettingsManage, Response: entity.Settings{}},
+
+	// --- Menus ---
+	{Method: fiber.MethodGet, Path: "/menus", Tag: "Menus", Summary: "Daftar menu", Query: dto.MenuListQuery{}, Response: []entity.Menu{}, Paginated: true},
+	{Method: fiber.MethodGet, Path: "/menu", Tag: "Menus", Summary: "Daftar menu (alias lama /menus)", Query: dto.MenuListQuery{}, Response: []entity.Menu{}, Paginated: true},
+	{Method: fiber.MethodGet, Path: "/menus/:id", Tag: "Menus", Summary: "Detail menu", Response: entity.Menu{}},
+	{Method: fiber.MethodPost, Path: "/menus", Tag: "Menus", Summary: "Buat menu", Auth: Bearer, Permission: entity.PermCatalogWrite, Body: dto.CreateMenuRequest{}, Response: entity.Menu{}},
+	{Method: fiber.MethodPut, Path: "/menus/:id", Tag: "Menus", Summary: "Ubah menu", Auth: Bearer, Permission: entity.PermCatalogWrite, Body: dto.CreateMenuRequest{}, Response: entity.Menu{}},
+	{Method: fiber.MethodDelete, Path: "/menus/:id", Tag: "Menus", Summary: "Hapus menu", Auth: Bearer, Permission: entity.PermCatalogWrite, Response: entity.Menu{}},
+
+	// --- Categories ---
+	{Method: fiber.MethodGet, Path: "/categories", Tag: "Categories", Summary: "Daftar kategori", Query: dto.CategoryListQuery{}, Response: []entity.Category{}, Paginated: true},
+	{Method: fiber.MethodGet, Path: "/categories/:slug", Tag: "Categories", Summary: "Detail kategori berdasarkan slug", Response: entity.Category{}},
+	{Method: fiber.MethodPost, Path: "/categories", Tag: "Categories", Summary: "Buat kategori", Auth: Bearer, Permission: entity.PermCatalogWrite,
+		Form: dto.CreateCategoryRequest{}, File: "image", FileRequired: true, Response: entity.Category{}},
+	{Method: fiber.MethodPut, Path: "/categories/:id", SpecPath: "/categories/:slug", Tag: "Categories", Summary: "Ubah kategori", Auth: Bearer, Permission: entity.PermCatalogWrite,
+		Form: dto.UpdateCategoryRequest{}, File: "image", Response: entity.Category{}},
+	{Method: fiber.MethodDelete, Path: "/categories/:id", SpecPath: "/categories/:slug", Tag: "Categories", Summary: "Hapus kategori", Auth: Bearer, Permission: entity.PermCatalogWrite, Response: entity.Category{}},
+
+	// --- Payment methods ---
+	{Method: fiber.MethodGet, Path: "/payment-methods", Tag: "Payment Methods", Summary: "Daftar metode pembayaran", Query: dto.PaymentMethodListQuery{},
+		Response: []entity.PaymentMethod{}, Paginated: true},
+	{Method: fiber.MethodGet, Path: "/payment-methods/:id", Tag: "Payment Methods", Summary: "Detail metode pembayaran", Response: entity.PaymentMethod{}},
+	{Method: fiber.MethodPost, Path: "/payment-methods", Tag: "Payment Methods", Summary: "Buat metode pembayaran", Auth: Bearer, Permission: entity.PermCatalogWrite,
+		Form: dto.CreatePaymentMethodRequest{}, File: "image", FileRequired: true, Response: entity.PaymentMethod{}, Status: http.StatusCreated},
+	{Method: fiber.MethodPut, Path: "/payment-methods/:id", Tag: "Payment Methods", Summary: "Ubah metode pembayaran", Auth: Bearer, Permission: entity.PermCatalogWrite,
+		Form: dto.UpdatePaymentMethodRequest{}, File: "image", Response: entity.PaymentMethod{}},
+	{Method: fiber.MethodDelete, Path: "/payment-methods/:id", Tag: "Payment Methods", Summary: "Hapus metode pembayaran", Auth: Bearer, Permission: entity.PermCatalogWrite,
+		Response: entity.PaymentMethod{}},
+
+	// --- Banners ---
+	{Method: fiber.MethodGet, Path: "/banners", Tag: "Banners", Summary: "Daftar banner", Response: []*entity.Banner{}},
+	{Method: fiber.MethodPost, Path: "/banners", Tag: "Banners", Summary: "Upload banner", Auth: Bearer, Permission: entity.PermCatalogWrite,
+		File: "image", FileRequired: true, Response: entity.Banner{}},
+	{Method: fiber.MethodPut, Path: "/banners/:id", Tag: "Banners", Summary: "Ganti gambar banner", Auth: Bearer, Permission: entity.PermCatalogWrite,
+		File: "image", FileRequired: true, Response: entity.Banner{}},
+	{Method: fiber.MethodDelete, Path: "/banners/:id", Tag: "Banners", Summary: "Hapus banner", Auth: Bearer, Permission: entity.PermCatalogWrite, Response: entity.Banner{}},
+
+	// --- Products ---
+	{Method: fiber.MethodGet, Path: "/products", Tag: "Products", Summary: "Daftar produk", Query: dto.ProductListQuery{}, Response: []entity.Product{}, Paginated: true},
+	{Method: fiber.MethodPost, Path: "/products", Tag: "Products", Summary: "Buat produk", Auth: Bearer, Permission: entity.PermProductsWrite,
+		Form: dto.ProductCreateRequest{}, File: "image", FileRequired: true, Response: entity.Product{}, Status: http.StatusCreated},
+	{Method: fiber.MethodPut, Path: "/products/:id", Tag: "Products", Summary: "Ubah produk", Auth: Bearer, Permission: entity.PermProductsWrite,
+		Form: dto.ProductUpdateRequest{}, File: "image", Response: entity.Product{}},
+	{Method: fiber.MethodDelete, Path: "/products/:id", Tag: "Products", Summary: "Hapus produk", Auth: Bearer, Permission: entity.PermProductsWrite, Response: entity.Product{}},
+	{Method: fiber.MethodGet, Path: "/products/df", Tag: "Products", Summary: "Sinkronisasi daftar produk dari Digiflazz", Auth: Bearer, Permission: entity.PermProductsWrite,
+		Response: []dto.DFProductListRes{}},
+
+	// --- Prices ---
+	{Method: fiber.MethodGet, Path: "/prices", Tag: "Prices", Summary: "Daftar harga per role", Query: dto.PriceListQuery{}, Response: []entity.Price{}, Paginated: true},
+	{Method: fiber.MethodPost, Path: "/prices", Tag: "Prices", Summary: "Buat harga", Auth: Bearer, Permission: entity.PermPricesWrite, Body: dto.CreatePrice{},
+		Response: entity.Price{}, Status: http.StatusCreated},
+	{Method: fiber.MethodPut, Path: "/prices/:id", Tag: "Prices", Summary: "Ubah harga", Auth: Bearer, Permission: entity.PermPricesWrite, Body: dto.UpdatePrice{}, Response: entity.Price{}},
+	{Method: fiber.MethodDelete, Path: "/prices/:id", Tag: "Prices", Summary: "Hapus harga", Auth: Bearer, Permission: entity.PermPricesWrite, Response: entity.Price{}},
+
+	// --- Providers ---
+	{Method: fiber.MethodGet, Path: "/providers", Tag: "Providers", Summary: "Daftar provider", Auth: Bearer, Permission: entity.PermProvidersManage, Query: dto.ProviderListQuery{},
+		Response: []entity.Provider{}, Paginated: true},
+	{Method: fiber.MethodPost, Path: "/providers", Tag: "Providers", Summary: "Buat provider", Auth: Bearer, Permission: entity.PermProvidersManage, Body: dto.ProviderRequest{},
+		Response: entity.Provider{}, Status: http.StatusCreated},
+	{Method: fiber.MethodPut, Path: "/providers/:id", Tag: "Providers", Summary: "Ubah provider", Auth: Bearer, Permission: entity.PermProvidersManage, Body: dto.ProviderUpdate{},
+		Response: entity.Provider{}},
+	{Method: fiber.MethodDelete, Path: "/providers/:id", Tag: "Providers", Summary: "Hapus provider", Auth: Bearer, Permission: entity.PermProvidersManage, Response: entity.Provider{}},
+
+	// --- Deposits ---
+	{Method: fiber.MethodPost, Path: "/deposits", Tag: "Deposits", Summary: "Buat deposit saldo", Auth: Bearer, Body: dto.DepositRequest{}, Response: entity.Deposit{}},
+	{Method: fiber.MethodGet, Path: "/deposits", Tag: "Deposits", Summary: "Detail deposit berdasarkan deposit id", Auth: Bearer, Query: depositQuery{}, Response: entity.Deposit{}},
+	{Method: fiber.MethodGet, Path: "/deposits/all", Tag: "Deposits", Summary: "Riwayat deposit user", Auth: Bearer, Response: []entity.Deposit{}},
+	{Method: fiber.MethodPut, Path: "/deposits/:id/status", Tag: "Deposits", Summary: "Ubah status deposit", Auth: Bearer, Permission: entity.PermBalanceAdjust, StringID: true,
+		Body: dto.UpdateDepositStatus{}, Response: entity.Deposit{}},
+
+	// --- Orders ---
+	{Method: fiber.MethodPost, Path: "/orders/guest", Tag: "Orders", Summary: "Buat order tanpa login", Body: dto.CreateOrder{}, Response: entity.Order{}},
+	{Method: fiber.MethodGet, Path: "/orders/:ref", Tag: "Orders", Summary: "Detail order berdasarkan ref", Response: entity.Order{}},
+	{Method: fiber.MethodPost, Path: "/orders", Tag: "Orders", Summary: "Buat order", Auth: Bearer, Body: dto.CreateOrder{}, Response: entity.Order{}},
+	{Method: fiber.MethodGet, Path: "/orders/all", Tag: "Orders", Summary: "Semua order", Auth: Bearer, Permission: entity.PermOrdersRead, Response: []*entity.Order{}},
+	{Method: fiber.MethodPut, Path: "/orders/:ref/status", Tag: "Orders", Summary: "Ubah status order", Auth: Bearer, Permission: entity.PermOrdersWrite,
+		Body: dto.UpdateOrderStatus{}, Response: entity.Order{}},
+
+	// --- H2H ---
+	{Method: fiber.MethodPost, Path: "/h2h/orders", Tag: "H2H", Summary: "Buat order", Auth: APIKey, Body: dto.CreateOrder{}, Response: entity.Order{}},
+	{Method: fiber.MethodGet, Path: "/h2h/orders/:ref", Tag: "H2H", Summary: "Status order", Auth: APIKey, Response: entity.Order{}},
+
+	// --- Admin ---
+	{Method: fiber.MethodGet, Path: "/admin/roles", Tag: "Admin", Summary: "Daftar role", Auth: Bearer, Permission: entity.PermRolesManage, Response: []dto.RoleResponse{}},
+	{Method: fiber.MethodPost, Path: "/admin/roles", Tag: "Admin", Summary: "Buat role", Auth: Bearer, Permission: entity.PermRolesManage, Body: dto.CreateRoleRequest{}, Response: dto.RoleResponse{}},
+	{Method: fiber.MethodPut, Path: "/admin/roles/:id", Tag: "Admin", Summary: "Ubah role", Auth: Bearer, Permission: entity.PermRolesManage, Body: dto.UpdateRoleRequest{}, Response: dto.RoleResponse{}},
+	{Method: fiber.MethodDelete, Path: "/admin/roles/:id", Tag: "Admin", Summary: "Hapus role", Auth: Bearer, Permission: entity.PermRolesManage, Response: message{}},
+	{Method: fiber.MethodGet, Path: "/admin/permissions", Tag: "Admin", Summary: "Katalog permission", Auth: Bearer, Permission: entity.PermRolesManage, Response: []string{}},
+	{Method: fiber.MethodGet, Path: "/admin/audit-logs", Tag: "Admin", Summary: "Audit log perubahan data", Auth: Bearer, Permission: entity.PermAuditRead, Query: dto.AuditLogListQuery{},
+		Response: []entity.AuditLog{}, Paginated: true},
+
+	// --- Webhooks ---
+	{Method: fiber.MethodGet, Path: "/webhooks/deliveries", Tag: "Webhooks", Summary: "Log pengiriman webhook", Auth: Bearer, Permission: entity.PermWebhooksManage,
+		Query: dto.WebhookDeliveryListQuery{}, Response: []entity.WebhookDelivery{}, Paginated: true},
+	{Method: fiber.MethodPost, Path: "/webhooks/deliveries/:id/resend", Tag: "Webhooks", Summary: "Kirim ulang webhook", Auth: Bearer, Permission: entity.PermWebhooksManage, Response: message{}},
+}
@@ -0,0 +1,182 @@
+package apidoc
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/pkg/openapi"
+)
+
+// field adalah satu field request DTO beserta schema yang dihasilkan untuknya
+type field struct {
+	name     string
+	typ      reflect.Type
+	rules    string
+	schema   *openapi.Schema
+	required bool
+}
+
+func resolve(doc *openapi.Document, s *openapi.Schema) *openapi.Schema {
+	if s != nil && s.Ref != "" {
+		return doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+	return s
+}
+
+// structFields meratakan field struct seperti encoding/json; name memakai tag lalu json
+func structFields(t reflect.Type, tag string) []reflect.StructField {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var out []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && tagName(f, tag) == "" {
+			out = append(out, structFields(f.Type, tag)...)
+			continue
+		}
+		if f.IsExported() && tagName(f, tag) != "-" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func tagName(f reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+	if name == "" {
+		name, _, _ = strings.Cut(f.Tag.Get("json"), ",")
+	}
+	return name
+}
+
+// requestFields mengumpulkan field body, form dan query semua route beserta schema di dokumen
+func requestFields(t *testing.T, doc *openapi.Document, r Route) []field {
+	t.Helper()
+	op := (*doc.Paths[specPath(r.specPath())])[strings.ToLower(r.Method)]
+	var out []field
+	object := func(v any, tag string, s *openapi.Schema) {
+		for _, f := range structFields(reflect.TypeOf(v), tag) {
+			name := tagName(f, tag)
+			if name == "" {
+				name = f.Name
+			}
+			out = append(out, field{name: name, typ: f.Type, rules: f.Tag.Get("validate"),
+				schema: s.Properties[name], required: slices.Contains(s.Required, name)})
+		}
+	}
+	switch {
+	case r.Body != nil:
+		object(r.Body, "json", resolve(doc, op.RequestBody.Content[fiber.MIMEApplicationJSON].Schema))
+	case r.Form != nil:
+		for _, mt := range op.RequestBody.Content {
+			object(r.Form, "form", mt.Schema)
+		}
+	}
+	if r.Query != nil {
+		for _, f := range structFields(reflect.TypeOf(r.Query), "query") {
+			name := tagName(f, "query")
+			for _, p := range op.Parameters {
+				if p.In == "query" && p.Name == name {
+					out = append(out, field{name: name, typ: f.Type, rules: f.Tag.Get("validate"), schema: p.Schema, required: p.Required})
+				}
+			}
+		}
+	}
+	return out
+}
+
+func intPtr(p *int) string {
+	if p == nil {
+		return "unset"
+	}
+	return strconv.Itoa(*p)
+}
+
+func floatPtr(p *float64) string {
+	if p == nil {
+		return "unset"
+	}
+	return strconv.FormatFloat(*p, 'f', -1, 64)
+}
+
+// checkBound memastikan min/max di tag validate muncul sebagai minLength/minItems/minimum sesuai tipe
+func checkBound(s *openapi.Schema, kind reflect.Kind, param string, lower bool) (got string, ok bool) {
+	switch kind {
+	case reflect.String:
+		if lower {
+			return intPtr(s.MinLength), intPtr(s.MinLength) == param
+		}
+		return intPtr(s.MaxLength), intPtr(s.MaxLength) == param
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if lower {
+			return intPtr(s.MinItems), intPtr(s.MinItems) == param
+		}
+		return intPtr(s.MaxItems), intPtr(s.MaxItems) == param
+	default:
+		want, _ := strconv.ParseFloat(param, 64)
+		if lower {
+			return floatPtr(s.Minimum), s.Minimum != nil && *s.Minimum == want
+		}
+		return floatPtr(s.Maximum), s.Maximum != nil && *s.Maximum == want
+	}
+}
+
+func TestValidateTagsMapToSchemaConstraints(t *testing.T) {
+	doc := Build()
+	checked := 0
+	for _, r := range Routes {
+		for _, f := range requestFields(t, doc, r) {
+			if f.rules == "" {
+				continue
+			}
+			where := r.Method + " " + r.Path + " " + f.name
+			if f.schema == nil {
+				t.Errorf("%s: field missing from the spec", where)
+				continue
+			}
+			typ := f.typ
+			for typ.Kind() == reflect.Pointer {
+				typ = typ.Elem()
+			}
+			kind := typ.Kind()
+			for _, rule := range strings.Split(f.rules, ",") {
+				name, param, _ := strings.Cut(rule, "=")
+				if name == "dive" {
+					break // rule sesudahnya berlaku untuk elemen slice
+				}
+				if f.schema.Ref != "" && name != "required" {
+					continue // constraint tidak bisa ditempel ke $ref
+				}
+				checked++
+				switch name {
+				case "required":
+					if !f.required {
+						t.Errorf("%s: validate required but not required in the spec", where)
+					}
+				case "min", "gte", "max", "lte":
+					if got, ok := checkBound(f.schema, kind, param, name == "min" || name == "gte"); !ok {
+						t.Errorf("%s: %s=%s documented as %s", where, name, param, got)
+					}
+				case "email":
+					if f.schema.Format != "email" {
+						t.Errorf("%s: email documented with format %q", where, f.schema.Format)
+					}
+				case "oneof":
+					if len(f.schema.Enum) != len(strings.Fields(param)) {
+						t.Errorf("%s: oneof=%s documented as enum %v", where, param, f.schema.Enum)
+					}
+				default:
+					checked--
+				}
+			}
+		}
+	}
+	if checked < 50 {
+		t.Fatalf("only %d validate rules checked, request DTOs not found in the spec", checked)
+	}
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/service"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/response"
+	"github.com/wildanasyrof/backend-topup/pkg/validator"
+)
+
+type APIKeyHandler struct {
+	service   service.APIKeyService
+	validator validator.Validator
+}
+
+func NewAPIKeyHandler(service service.APIKeyService, validator validator.Validator) *APIKeyHandler {
+	return &APIKeyHandler{service: service, validator: validator}
+}
+
+// Create membuat API key baru; key asli hanya ada di response ini
+func (h *APIKeyHandler) Create(c *fiber.Ctx) error {
+	var req dto.CreateAPIKeyRequest
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	key, err := h.service.Create(c.UserContext(), uid, &req)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, key)
+}
+
+func (h *APIKeyHandler) List(c *fiber.Ctx) error {
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	keys, err := h.service.List(c.UserContext(), uid)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, keys)
+}
+
+func (h *APIKeyHandler) Revoke(c *fiber.Ctx) error {
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.New(apperror.CodeBadRequest, "invalid api key id", err)
+	}
+
+	if err := h.service.Revoke(c.UserContext(), uid, id); err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"message": "api key revoked"})
+}
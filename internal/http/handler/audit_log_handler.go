@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/service"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/response"
+)
+
+type AuditLogHandler struct {
+	service service.AuditLogService
+}
+
+func NewAuditLogHandler(service service.AuditLogService) *AuditLogHandler {
+	return &AuditLogHandler{service: service}
+}
+
+// List: riwayat perubahan, bisa difilter per actor, entity, action dan rentang waktu
+func (h *AuditLogHandler) List(c *fiber.Ctx) error {
+	var req dto.AuditLogListQuery
+	if err := c.QueryParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "invalid query parameters", err)
+	}
+
+	items, meta, err := h.service.List(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, items, meta)
+}
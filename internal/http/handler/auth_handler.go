@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"encoding/json"
 	"errors"
 	"time"
 
@@ -14,36 +13,35 @@ import (
 	"github.com/wildanasyrof/backend-topup/pkg/oauth"
 	"github.com/wildanasyrof/backend-topup/pkg/response"
 	"github.com/wildanasyrof/backend-topup/pkg/validator"
-	"golang.org/x/oauth2"
 )
 
 const refreshTokenCookieName = "refresh_token"
 
 type AuthHandler struct {
-	authService service.AuthService
-	userService service.UserService
-	oauthPkg    oauth.GoogleOauthPkg
-	devStore    oauth.DevStore
-	validator   validator.Validator
-	cfg         *config.Config // <--- TAMBAHKAN
+	authService     service.AuthService
+	identityService service.IdentityService
+	providers       *oauth.Registry
+	devStore        oauth.DevStore
+	validator       validator.Validator
+	cfg             *config.Config // <--- TAMBAHKAN
 }
 
 // Modifikasi NewAuthHandler
 func NewAuthHandler(
 	authService service.AuthService,
-	userService service.UserService,
-	oauthPkg oauth.GoogleOauthPkg,
+	identityService service.IdentityService,
+	providers *oauth.Registry,
 	devStore oauth.DevStore,
 	validator validator.Validator,
 	cfg *config.Config, // <--- TAMBAHKAN
 ) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		userService: userService,
-		oauthPkg:    oauthPkg,
-		devStore:    devStore,
-		validator:   validator,
-		cfg:         cfg, // <--- TAMBAHKAN
+		authService:     authService,
+		identityService: identityService,
+		providers:       providers,
+		devStore:        devStore,
+		validator:       validator,
+		cfg:             cfg, // <--- TAMBAHKAN
 	}
 }
 
@@ -54,11 +52,11 @@ func (h *AuthHandler) setRefreshTokenCookie(c *fiber.Ctx, session *entity.UserSe
 		Value:    session.ID.String(),
 		Expires:  session.ExpiresAt,
 		HTTPOnly: true,
-		SameSite: "Lax", // Melindungi dari CSRF
+		SameSite: h.cfg.Cookie.SameSite, // Lax melindungi dari CSRF
 		// 'Secure: true' WAJIB di produksi (HTTPS)
-		Secure: h.cfg.Server.Env != "development",
-		Path:   "/auth", // Hanya kirim cookie ke endpoint /auth/*
-		Domain: h.cfg.Server.CookieDomain,
+		Secure: *h.cfg.Cookie.Secure,
+		Path:   h.cfg.Cookie.Path,
+		Domain: h.cfg.Cookie.Domain,
 	}
 	c.Cookie(cookie)
 }
@@ -70,10 +68,10 @@ func (h *AuthHandler) clearRefreshTokenCookie(c *fiber.Ctx) {
 		Value:    "",
 		Expires:  time.Now().Add(-time.Hour), // Set kedaluwarsa di masa lalu
 		HTTPOnly: true,
-		SameSite: "Lax",
-		Secure:   h.cfg.Server.Env != "development",
-		Path:     "/auth",
-		Domain:   h.cfg.Server.CookieDomain,
+		SameSite: h.cfg.Cookie.SameSite,
+		Secure:   *h.cfg.Cookie.Secure,
+		Path:     h.cfg.Cookie.Path,
+		Domain:   h.cfg.Cookie.Domain,
 	}
 	c.Cookie(cookie)
 }
@@ -98,6 +96,153 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	return response.OK(c, user)
 }
 
+// RequestEmailVerification mengirim (ulang) kode verifikasi ke email user
+func (h *AuthHandler) RequestEmailVerification(c *fiber.Ctx) error {
+	var req dto.EmailRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	if err := h.authService.RequestEmailVerification(c.UserContext(), req.Email); err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"message": "if the email is registered and unverified, a code has been sent"})
+}
+
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req dto.VerifyEmailRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	if err := h.authService.VerifyEmail(c.UserContext(), &req); err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"message": "email verified"})
+}
+
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req dto.EmailRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	if err := h.authService.ForgotPassword(c.UserContext(), req.Email); err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"message": "if the email is registered, a reset code has been sent"})
+}
+
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req dto.ResetPasswordRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	if err := h.authService.ResetPassword(c.UserContext(), &req); err != nil {
+		return err
+	}
+
+	// Semua sesi sudah di-revoke, termasuk yang mungkin ada di browser ini
+	h.clearRefreshTokenCookie(c)
+
+	return response.OK(c, fiber.Map{"message": "password has been reset"})
+}
+
+// WhatsappRequest mengirim kode login ke nomor WhatsApp user
+func (h *AuthHandler) WhatsappRequest(c *fiber.Ctx) error {
+	var req dto.WhatsappLoginRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	if err := h.authService.RequestWhatsappLogin(c.UserContext(), req.Whatsapp); err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"message": "if the number is registered, a login code has been sent"})
+}
+
+// WhatsappVerify menukar kode WhatsApp dengan sesi login
+func (h *AuthHandler) WhatsappVerify(c *fiber.Ctx) error {
+	var req dto.WhatsappVerifyRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	result, err := h.authService.LoginWithWhatsapp(c.UserContext(), &req, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		return err
+	}
+
+	return h.respondLogin(c, result)
+}
+
+// WhatsappVerificationRequest mengirim kode verifikasi ke nomor WhatsApp di profil user
+func (h *AuthHandler) WhatsappVerificationRequest(c *fiber.Ctx) error {
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	if err := h.authService.RequestWhatsappVerification(c.UserContext(), uid); err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"message": "verification code has been sent"})
+}
+
+// WhatsappVerificationConfirm menandai nomor WhatsApp di profil sebagai terverifikasi
+func (h *AuthHandler) WhatsappVerificationConfirm(c *fiber.Ctx) error {
+	var req dto.WhatsappCodeRequest
+
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	user, err := h.authService.VerifyWhatsapp(c.UserContext(), uid, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, user)
+}
+
 // Modifikasi Login
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req dto.LoginUserRequest
@@ -112,20 +257,94 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	userAgent := c.Get(fiber.HeaderUserAgent)
 	clientIP := c.IP()
 
-	// Service mengembalikan AT dan Sesi (RT)
-	user, accessToken, session, err := h.authService.Login(c.UserContext(), &req, userAgent, clientIP)
+	// Service mengembalikan AT dan Sesi (RT), atau challenge 2FA
+	result, err := h.authService.Login(c.UserContext(), &req, userAgent, clientIP)
 	if err != nil {
 		return err
 	}
 
+	return h.respondLogin(c, result)
+}
+
+// TwoFactorVerify menyelesaikan login dengan kode TOTP atau recovery code
+func (h *AuthHandler) TwoFactorVerify(c *fiber.Ctx) error {
+	var req dto.TwoFactorVerifyRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	result, err := h.authService.VerifyTwoFactor(c.UserContext(), &req, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		return err
+	}
+
+	return h.respondLogin(c, result)
+}
+
+// TwoFactorEnroll memulai setup 2FA untuk admin yang wajib 2FA saat login
+func (h *AuthHandler) TwoFactorEnroll(c *fiber.Ctx) error {
+	var req dto.TwoFactorChallengeRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	setup, err := h.authService.StartTwoFactorEnrollment(c.UserContext(), req.ChallengeToken)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, setup)
+}
+
+// TwoFactorEnrollConfirm mengaktifkan 2FA lalu menyelesaikan login
+func (h *AuthHandler) TwoFactorEnrollConfirm(c *fiber.Ctx) error {
+	var req dto.TwoFactorVerifyRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	result, err := h.authService.ConfirmTwoFactorEnrollment(c.UserContext(), &req, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		return err
+	}
+
+	return h.respondLogin(c, result)
+}
+
+// respondLogin mengirim challenge 2FA, atau set RT cookie dan mengirim AT + user
+func (h *AuthHandler) respondLogin(c *fiber.Ctx, result *dto.LoginResult) error {
+	if result.TwoFactor != nil {
+		return response.OK(c, fiber.Map{
+			"two_factor_required": true,
+			"challenge_token":     result.TwoFactor.ChallengeToken,
+			"enrollment_required": result.TwoFactor.EnrollmentRequired,
+		})
+	}
+
 	// 1. Set RT sebagai HttpOnly cookie
-	h.setRefreshTokenCookie(c, session)
+	h.setRefreshTokenCookie(c, result.Session)
 
 	// 2. Kirim AT dan data user di body JSON
-	return response.OK(c, fiber.Map{
-		"user":         user,
-		"access_token": accessToken,
-	})
+	body := fiber.Map{
+		"user":         result.User,
+		"access_token": result.AccessToken,
+	}
+	if result.RecoveryCodes != nil {
+		body["recovery_codes"] = result.RecoveryCodes
+	}
+	return response.OK(c, body)
 }
 
 // Handler Baru: Refresh
@@ -175,89 +394,107 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	return response.OK(c, fiber.Map{"message": "logged out"})
 }
 
-// Modifikasi GoogleCallback
-func (h *AuthHandler) GoogleCallback(c *fiber.Ctx) error {
-	code, state := c.Query("code"), c.Query("state")
-	if code == "" || state == "" {
-		return apperror.New(apperror.CodeBadRequest, "OAUTH_INVALID_REQUEST", errors.New("missing code/state"))
+// OAuthLogin mengarahkan browser ke halaman login provider
+func (h *AuthHandler) OAuthLogin(c *fiber.Ctx) error {
+	url, err := h.oauthAuthURL(c, 0)
+	if err != nil {
+		return err
 	}
-	codeVerifier, ok := h.devStore.Consume(state)
+	return c.Redirect(url)
+}
+
+// OAuthLinkStart memulai flow OAuth untuk menautkan provider ke user yang sedang login.
+// URL dikembalikan sebagai JSON karena request ini dikirim dengan Bearer token, bukan navigasi browser.
+func (h *AuthHandler) OAuthLinkStart(c *fiber.Ctx) error {
+	uid, ok := c.Locals("user_id").(uint64)
 	if !ok {
-		return apperror.New(apperror.CodeBadRequest, "OAUTH_STATE_MISMATCH", errors.New("invalid state"))
+		return apperror.ErrUnauthorized
 	}
 
-	tok, err := h.oauthPkg.Config.Exchange(c.UserContext(), code,
-		oauth2.SetAuthURLParam("code_verifier", codeVerifier),
-	)
+	url, err := h.oauthAuthURL(c, uid)
 	if err != nil {
-		return apperror.New(apperror.CodeUnavailable, "OAUTH_EXCHANGE_FAILED", err)
+		return err
 	}
+	return response.OK(c, fiber.Map{"url": url})
+}
 
-	client := h.oauthPkg.Config.Client(c.UserContext(), tok)
-	uinfoResp, err := client.Get("https://openidconnect.googleapis.com/v1/userinfo")
+// OAuthCallback menerima redirect dari provider (GET, atau POST untuk response_mode=form_post)
+func (h *AuthHandler) OAuthCallback(c *fiber.Ctx) error {
+	provider, err := h.provider(c)
 	if err != nil {
-		return apperror.New(apperror.CodeUnavailable, "OAUTH_USERINFO_FAILED", err)
-	}
-	defer uinfoResp.Body.Close()
-	if uinfoResp.StatusCode >= 400 {
-		return apperror.New(apperror.CodeUnavailable, "OAUTH_USERINFO_BAD_STATUS", err)
+		return err
 	}
 
-	var userInfo dto.GoogleLoginResponse
-	if err := json.NewDecoder(uinfoResp.Body).Decode(&userInfo); err != nil {
-		return apperror.New(apperror.CodeUnavailable, "OAUTH_USERINFO_PARSE_FAILED", err)
+	code, state := c.FormValue("code"), c.FormValue("state")
+	if code == "" || state == "" {
+		return apperror.New(apperror.CodeBadRequest, "OAUTH_INVALID_REQUEST", errors.New("missing code/state"))
+	}
+	pending, err := h.devStore.Consume(c.UserContext(), state)
+	switch {
+	case errors.Is(err, oauth.ErrStateNotFound):
+		return apperror.New(apperror.CodeBadRequest, "OAUTH_STATE_MISMATCH", err)
+	case err != nil:
+		// Gagal membaca store bukan kesalahan client; jangan dilaporkan sebagai state tidak valid
+		return apperror.New(apperror.CodeInternal, "OAUTH_STATE_LOOKUP_FAILED", err)
+	case pending.Provider != provider.Name():
+		return apperror.New(apperror.CodeBadRequest, "OAUTH_STATE_MISMATCH", errors.New("state issued for another provider"))
 	}
 
-	// Cari user berdasarkan Google ID
-	user, _ := h.userService.FindUserByGoogleID(c.UserContext(), userInfo.Sub)
-
-	// Jika tidak ada, cari berdasarkan Email
-	if user == nil && userInfo.Email != "" {
-		user, _ = h.userService.FindUserByEmail(c.UserContext(), userInfo.Email)
-		if user != nil {
-			// TODO: Tautkan akun (Update user dengan GoogleID) - (Opsional)
-		}
+	userInfo, err := provider.Exchange(c.UserContext(), code, pending.Verifier)
+	if err != nil {
+		return apperror.New(apperror.CodeUnavailable, "OAUTH_EXCHANGE_FAILED", err)
 	}
 
-	// Jika tetap tidak ada, buat user baru
-	if user == nil {
-		user, err = h.authService.RegisterByGoogle(c.UserContext(), &userInfo)
+	// Flow dimulai dari POST /me/identities/:provider: tautkan ke user yang sedang login
+	if pending.LinkUserID != 0 {
+		user, err := h.identityService.Link(c.UserContext(), pending.LinkUserID, userInfo)
 		if err != nil {
-			return apperror.New(apperror.CodeInternal, "USER_CREATE_FAILED", err)
+			return err
 		}
+		return response.OK(c, user)
+	}
+
+	user, err := h.identityService.Authenticate(c.UserContext(), userInfo)
+	if err != nil {
+		return err
 	}
 
-	// Buat Sesi (AT & RT)
+	// Buat Sesi (AT & RT), atau challenge 2FA
 	userAgent := c.Get(fiber.HeaderUserAgent)
 	clientIP := c.IP()
 
-	accessToken, session, err := h.authService.CreateSession(c.UserContext(), user, userAgent, clientIP)
+	result, err := h.authService.CompleteLogin(c.UserContext(), user, userAgent, clientIP)
 	if err != nil {
-		return apperror.New(apperror.CodeInternal, "JWT_CREATE_FAILED", err)
+		return err
 	}
 
-	// 1. Set RT sebagai HttpOnly cookie
-	h.setRefreshTokenCookie(c, session)
-
-	// 2. Kirim AT dan data user di body JSON
 	// PENTING: Untuk callback, seringkali lebih baik me-redirect kembali ke
 	// frontend dengan token di query param. Tapi untuk API, ini OK.
 	// Mari kita asumsikan client API-based.
-	return response.OK(c, fiber.Map{
-		"access_token": accessToken,
-		"user":         user,
-	})
+	return h.respondLogin(c, result)
+}
+
+func (h *AuthHandler) provider(c *fiber.Ctx) (oauth.Provider, error) {
+	provider, ok := h.providers.Get(c.Params("provider"))
+	if !ok {
+		return nil, apperror.New(apperror.CodeNotFound, "unknown oauth provider", nil)
+	}
+	return provider, nil
 }
 
-func (h *AuthHandler) GoogleLogin(c *fiber.Ctx) error {
-	state, verifier, challenge, err := h.devStore.NewStateAndPKCE()
+func (h *AuthHandler) oauthAuthURL(c *fiber.Ctx, linkUserID uint64) (string, error) {
+	provider, err := h.provider(c)
+	if err != nil {
+		return "", err
+	}
+
+	state, verifier, err := h.devStore.NewStateAndPKCE(c.UserContext(), provider.Name(), linkUserID)
 	if err != nil || verifier == "" {
-		return apperror.New(apperror.CodeInternal, "OAUTH_STATE_GEN_FAILED", err)
+		return "", apperror.New(apperror.CodeInternal, "OAUTH_STATE_GEN_FAILED", err)
 	}
-	url := h.oauthPkg.Config.AuthCodeURL(
-		state,
-		oauth2.SetAuthURLParam("code_challenge", challenge),
-		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
-	)
-	return c.Redirect(url)
+	url, err := provider.AuthCodeURL(c.UserContext(), state, verifier)
+	if err != nil {
+		return "", apperror.New(apperror.CodeUnavailable, "OAUTH_PROVIDER_UNAVAILABLE", err)
+	}
+	return url, nil
 }
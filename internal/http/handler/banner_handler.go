@@ -47,7 +47,7 @@ func (h *BannerHandler) Create(c *fiber.Ctx) error {
 }
 
 func (h *BannerHandler) GetAll(c *fiber.Ctx) error {
-	res, err := h.bannerSvc.FindAll(c.Context())
+	res, err := h.bannerSvc.FindAll(c.UserContext())
 	if err != nil {
 		return err
 	}
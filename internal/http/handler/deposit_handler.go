@@ -74,3 +74,23 @@ func (h *DepositHandler) GetByDepositID(c *fiber.Ctx) error {
 
 	return response.OK(c, deposit)
 }
+
+// UpdateStatus: admin mengonfirmasi/membatalkan deposit
+func (h *DepositHandler) UpdateStatus(c *fiber.Ctx) error {
+	var req dto.UpdateDepositStatus
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	deposit, err := h.DepositSvc.UpdateStatus(c.UserContext(), c.Params("id"), &req)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, deposit)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"encoding/json"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/pkg/openapi"
+)
+
+// DocsHandler melayani spesifikasi OpenAPI dan Swagger UI. Spesifikasi di-marshal sekali saat startup.
+type DocsHandler struct {
+	spec []byte
+}
+
+func NewDocsHandler(doc *openapi.Document) (*DocsHandler, error) {
+	spec, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &DocsHandler{spec: spec}, nil
+}
+
+func (h *DocsHandler) OpenAPI(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	return c.Send(h.spec)
+}
+
+// SwaggerUI memuat swagger-ui dari CDN; hanya dipasang di luar production.
+func (h *DocsHandler) SwaggerUI(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.SendString(swaggerUIPage)
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Backend Topup API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui", withCredentials: true });
+  </script>
+</body>
+</html>`
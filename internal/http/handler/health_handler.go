@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/service"
+)
+
+// HealthHandler melayani probe infrastruktur. Body dikembalikan apa adanya (bukan envelope)
+// agar mudah dibaca load balancer dan orchestrator.
+type HealthHandler struct {
+	service service.HealthService
+}
+
+func NewHealthHandler(service service.HealthService) *HealthHandler {
+	return &HealthHandler{service: service}
+}
+
+// Live hanya menandakan proses masih melayani request; tidak memeriksa dependency agar
+// gangguan database tidak membuat orchestrator me-restart semua instance.
+func (h *HealthHandler) Live(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(fiber.Map{"status": dto.HealthOK})
+}
+
+// Ready mengembalikan 503 jika ada check penting yang gagal atau instance sedang shutdown.
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	report := h.service.Ready(c.UserContext())
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if report.Status != dto.HealthOK {
+		c.Status(fiber.StatusServiceUnavailable)
+	}
+	return c.JSON(report)
+}
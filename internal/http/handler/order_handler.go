@@ -91,7 +91,7 @@ func (o *OrderHandler) GetByRef(c *fiber.Ctx) error {
 }
 
 func (o *OrderHandler) GetAll(c *fiber.Ctx) error {
-	orders, err := o.service.GetAll(c.Context())
+	orders, err := o.service.GetAll(c.UserContext())
 
 	if err != nil {
 		return err
@@ -99,3 +99,23 @@ func (o *OrderHandler) GetAll(c *fiber.Ctx) error {
 
 	return response.OK(c, orders)
 }
+
+func (o *OrderHandler) UpdateStatus(c *fiber.Ctx) error {
+	var req dto.UpdateOrderStatus
+	ref := c.Params("ref")
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+
+	if err := o.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	order, err := o.service.UpdateStatus(c.UserContext(), ref, &req)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, order)
+}
@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/service"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/response"
+	"github.com/wildanasyrof/backend-topup/pkg/validator"
+)
+
+type RoleHandler struct {
+	service   service.RoleService
+	validator validator.Validator
+}
+
+func NewRoleHandler(service service.RoleService, validator validator.Validator) *RoleHandler {
+	return &RoleHandler{service: service, validator: validator}
+}
+
+func (h *RoleHandler) List(c *fiber.Ctx) error {
+	roles, err := h.service.List(c.UserContext())
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, roles)
+}
+
+// Permissions mengembalikan katalog permission yang bisa dipilih saat membuat role
+func (h *RoleHandler) Permissions(c *fiber.Ctx) error {
+	return response.OK(c, entity.Permissions)
+}
+
+func (h *RoleHandler) Create(c *fiber.Ctx) error {
+	var req dto.CreateRoleRequest
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	actorRole, _ := c.Locals("role").(string)
+	role, err := h.service.Create(c.UserContext(), actorRole, &req)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, role)
+}
+
+func (h *RoleHandler) Update(c *fiber.Ctx) error {
+	var req dto.UpdateRoleRequest
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.New(apperror.CodeBadRequest, "invalid role id", err)
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	actorRole, _ := c.Locals("role").(string)
+	role, err := h.service.Update(c.UserContext(), actorRole, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, role)
+}
+
+func (h *RoleHandler) Delete(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.New(apperror.CodeBadRequest, "invalid role id", err)
+	}
+
+	if err := h.service.Delete(c.UserContext(), id); err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"message": "role deleted"})
+}
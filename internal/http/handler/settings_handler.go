@@ -102,3 +102,14 @@ func (h *SettingsHandler) Delete(c *fiber.Ctx) error {
 
 	return response.OK(c, settings)
 }
+
+// Definitions menampilkan registry setting beserta nilai efektifnya (untuk form admin)
+func (h *SettingsHandler) Definitions(c *fiber.Ctx) error {
+	return response.OK(c, h.settingsService.Definitions(c.UserContext()))
+}
+
+// Public menampilkan setting yang boleh dibaca storefront tanpa login
+func (h *SettingsHandler) Public(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=30")
+	return response.OK(c, h.settingsService.Public(c.UserContext()))
+}
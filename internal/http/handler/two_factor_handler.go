@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/service"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/response"
+	"github.com/wildanasyrof/backend-topup/pkg/validator"
+)
+
+// TwoFactorHandler: pengelolaan 2FA oleh user yang sedang login (/me/2fa)
+type TwoFactorHandler struct {
+	twoFactorService service.TwoFactorService
+	validator        validator.Validator
+}
+
+func NewTwoFactorHandler(twoFactorService service.TwoFactorService, validator validator.Validator) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactorService: twoFactorService, validator: validator}
+}
+
+// Setup membuat secret TOTP baru dan provisioning URI untuk QR code
+func (h *TwoFactorHandler) Setup(c *fiber.Ctx) error {
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	setup, err := h.twoFactorService.Setup(c.UserContext(), uid)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, setup)
+}
+
+func (h *TwoFactorHandler) Enable(c *fiber.Ctx) error {
+	uid, req, err := h.parseCode(c)
+	if err != nil {
+		return err
+	}
+
+	codes, err := h.twoFactorService.Enable(c.UserContext(), uid, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"recovery_codes": codes})
+}
+
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	uid, req, err := h.parseCode(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.twoFactorService.Disable(c.UserContext(), uid, req.Code); err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"message": "two-factor authentication disabled"})
+}
+
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	uid, req, err := h.parseCode(c)
+	if err != nil {
+		return err
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(c.UserContext(), uid, req.Code)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"recovery_codes": codes})
+}
+
+func (h *TwoFactorHandler) parseCode(c *fiber.Ctx) (uint64, *dto.TwoFactorCodeRequest, error) {
+	var req dto.TwoFactorCodeRequest
+
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return 0, nil, apperror.ErrUnauthorized
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return 0, nil, apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+
+	if err := h.validator.ValidateBody(req); err != nil {
+		return 0, nil, apperror.Validation(err)
+	}
+
+	return uid, &req, nil
+}
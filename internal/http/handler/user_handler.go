@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
 	"github.com/wildanasyrof/backend-topup/internal/service"
@@ -10,12 +12,13 @@ import (
 )
 
 type UserHandler struct {
-	userService service.UserService
-	validator   validator.Validator
+	userService     service.UserService
+	identityService service.IdentityService
+	validator       validator.Validator
 }
 
-func NewUserHandler(userService service.UserService, validator validator.Validator) *UserHandler {
-	return &UserHandler{userService: userService, validator: validator}
+func NewUserHandler(userService service.UserService, identityService service.IdentityService, validator validator.Validator) *UserHandler {
+	return &UserHandler{userService: userService, identityService: identityService, validator: validator}
 }
 
 func (h *UserHandler) GetProfile(c *fiber.Ctx) error {
@@ -56,3 +59,129 @@ func (h *UserHandler) Update(c *fiber.Ctx) error {
 	return response.OK(c, user)
 
 }
+
+// ListIdentities menampilkan provider login yang tertaut ke user
+func (h *UserHandler) ListIdentities(c *fiber.Ctx) error {
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	identities, err := h.identityService.List(c.UserContext(), uid)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, identities)
+}
+
+// UnlinkIdentity melepas akun provider dari user yang sedang login
+func (h *UserHandler) UnlinkIdentity(c *fiber.Ctx) error {
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	if err := h.identityService.Unlink(c.UserContext(), uid, c.Params("provider")); err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"message": c.Params("provider") + " account unlinked"})
+}
+
+func (h *UserHandler) SetPassword(c *fiber.Ctx) error {
+	var req dto.SetPasswordRequest
+
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	sid, _ := c.Locals("session_id").(string)
+	if err := h.userService.SetPassword(c.UserContext(), uid, sid, &req); err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"message": "password updated"})
+}
+
+// Suspend (admin) memblokir user dan mencabut semua sesinya
+func (h *UserHandler) Suspend(c *fiber.Ctx) error {
+	id, err := h.targetUserID(c)
+	if err != nil {
+		return err
+	}
+
+	actorID, _ := c.Locals("user_id").(uint64)
+	actorRole, _ := c.Locals("role").(string)
+	user, err := h.userService.Suspend(c.UserContext(), actorID, actorRole, id)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, user)
+}
+
+// Unsuspend (admin) mengizinkan user login kembali
+func (h *UserHandler) Unsuspend(c *fiber.Ctx) error {
+	id, err := h.targetUserID(c)
+	if err != nil {
+		return err
+	}
+
+	actorID, _ := c.Locals("user_id").(uint64)
+	actorRole, _ := c.Locals("role").(string)
+	user, err := h.userService.Unsuspend(c.UserContext(), actorID, actorRole, id)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, user)
+}
+
+// ChangeRole (staff dengan users:manage) mengganti role user
+func (h *UserHandler) ChangeRole(c *fiber.Ctx) error {
+	var req dto.UpdateUserRoleRequest
+
+	id, err := h.targetUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	actorID, _ := c.Locals("user_id").(uint64)
+	actorRole, _ := c.Locals("role").(string)
+	user, err := h.userService.ChangeRole(c.UserContext(), actorID, actorRole, id, &req)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, user)
+}
+
+// targetUserID membaca :id dan mencegah admin mengubah akunnya sendiri
+func (h *UserHandler) targetUserID(c *fiber.Ctx) (uint64, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return 0, apperror.New(apperror.CodeBadRequest, "invalid user id", err)
+	}
+	if uid, ok := c.Locals("user_id").(uint64); ok && uid == id {
+		return 0, apperror.New(apperror.CodeBadRequest, "you cannot change your own account", nil)
+	}
+	return id, nil
+}
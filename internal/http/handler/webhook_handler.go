@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/service"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/response"
+	"github.com/wildanasyrof/backend-topup/pkg/validator"
+)
+
+type WebhookHandler struct {
+	service   service.WebhookService
+	validator validator.Validator
+}
+
+func NewWebhookHandler(service service.WebhookService, validator validator.Validator) *WebhookHandler {
+	return &WebhookHandler{service: service, validator: validator}
+}
+
+// Create mendaftarkan endpoint baru; secret hanya ada di response ini
+func (h *WebhookHandler) Create(c *fiber.Ctx) error {
+	var req dto.CreateWebhookRequest
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	if err := c.BodyParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "Invalid JSON", err)
+	}
+
+	if err := h.validator.ValidateBody(req); err != nil {
+		return apperror.Validation(err)
+	}
+
+	endpoint, err := h.service.CreateEndpoint(c.UserContext(), uid, &req)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, endpoint)
+}
+
+func (h *WebhookHandler) List(c *fiber.Ctx) error {
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	endpoints, err := h.service.ListEndpoints(c.UserContext(), uid)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, endpoints)
+}
+
+func (h *WebhookHandler) Delete(c *fiber.Ctx) error {
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.New(apperror.CodeBadRequest, "invalid webhook id", err)
+	}
+
+	if err := h.service.DeleteEndpoint(c.UserContext(), uid, id); err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"message": "webhook deleted"})
+}
+
+// MyDeliveries: log pengiriman milik user yang login, bisa difilter ?endpoint_id= dan ?status=
+func (h *WebhookHandler) MyDeliveries(c *fiber.Ctx) error {
+	uid, ok := c.Locals("user_id").(uint64)
+	if !ok {
+		return apperror.ErrUnauthorized
+	}
+
+	var req dto.WebhookDeliveryListQuery
+	if err := c.QueryParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "invalid query parameters", err)
+	}
+	req.UserID = &uid
+
+	items, meta, err := h.service.ListDeliveries(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, items, meta)
+}
+
+// Deliveries: log pengiriman semua user untuk admin
+func (h *WebhookHandler) Deliveries(c *fiber.Ctx) error {
+	var req dto.WebhookDeliveryListQuery
+	if err := c.QueryParser(&req); err != nil {
+		return apperror.New(apperror.CodeBadRequest, "invalid query parameters", err)
+	}
+
+	items, meta, err := h.service.ListDeliveries(c.UserContext(), req)
+	if err != nil {
+		return err
+	}
+
+	return response.OK(c, items, meta)
+}
+
+// Resend menjadwalkan ulang sebuah delivery (admin)
+func (h *WebhookHandler) Resend(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return apperror.New(apperror.CodeBadRequest, "invalid delivery id", err)
+	}
+
+	if err := h.service.Resend(c.UserContext(), id); err != nil {
+		return err
+	}
+
+	return response.OK(c, fiber.Map{"message": "delivery scheduled"})
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/reqctx"
+)
+
+// APIKeyAuthenticator memeriksa signature request H2H dan memetakan key ke user pemiliknya
+// (cek replay, revoked, suspended, dan IP allowlist).
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, req *dto.SignedAPIRequest) (*entity.APIKey, error)
+}
+
+// APIKeyAuth mengautentikasi request H2H. Client mengirim:
+//
+//	X-API-Key:   tk_... (key ID, bukan rahasia)
+//	X-Timestamp: unix detik
+//	X-Signature: hex HMAC-SHA256 dengan signing secret (sk_...) sebagai kunci, lihat signature.Sign
+//
+// Signing secret tidak pernah ikut dikirim. Setiap signature hanya diterima sekali.
+// Setelah lolos, c.Locals("user_id") dan c.Locals("role") terisi sama seperti Auth,
+// sehingga handler yang sudah ada bisa dipakai ulang. c.Locals("api_key_id") dipakai ByAPIKey.
+func APIKeyAuth(authenticator APIKeyAuthenticator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		keyID := c.Get("X-API-Key")
+		sig := c.Get("X-Signature")
+		if keyID == "" || sig == "" {
+			return apperror.ErrUnauthorized
+		}
+
+		ts, err := strconv.ParseInt(c.Get("X-Timestamp"), 10, 64)
+		if err != nil {
+			return apperror.New(apperror.CodeUnauthorized, "request timestamp is missing or expired", nil)
+		}
+
+		apiKey, err := authenticator.AuthenticateAPIKey(c.UserContext(), &dto.SignedAPIRequest{
+			KeyID:     keyID,
+			Timestamp: ts,
+			Signature: sig,
+			Method:    c.Method(),
+			Path:      c.OriginalURL(),
+			Body:      c.Body(),
+			ClientIP:  c.IP(),
+		})
+		if err != nil {
+			return err
+		}
+
+		c.Locals("api_key_id", apiKey.ID)
+		c.Locals("user_id", apiKey.UserID)
+		c.Locals("role", apiKey.User.Role)
+		c.SetUserContext(reqctx.WithActor(c.UserContext(), apiKey.UserID, apiKey.User.Role))
+		return c.Next()
+	}
+}
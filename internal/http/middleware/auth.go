@@ -1,14 +1,21 @@
 package middleware
 
 import (
+	"context"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
 	"github.com/wildanasyrof/backend-topup/pkg/jwt"
+	"github.com/wildanasyrof/backend-topup/pkg/reqctx"
 )
 
-func Auth(jwtSvc jwt.JWTService, allowedRoles ...string) fiber.Handler {
+// AccessChecker memastikan sesi di balik access token belum di-revoke dan user tidak di-suspend.
+type AccessChecker interface {
+	CheckAccess(ctx context.Context, userID uint64, sessionID string, tokenVersion int) error
+}
+
+func Auth(jwtSvc jwt.JWTService, checker AccessChecker, allowedRoles ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		auth := c.Get("Authorization")
 		if auth == "" || !strings.HasPrefix(auth, "Bearer ") {
@@ -16,17 +23,24 @@ func Auth(jwtSvc jwt.JWTService, allowedRoles ...string) fiber.Handler {
 		}
 
 		tokenStr := strings.TrimPrefix(auth, "Bearer ")
-		id, role, err := jwtSvc.ValidateToken(tokenStr)
+		claims, err := jwtSvc.ValidateToken(tokenStr)
 		if err != nil {
 			return apperror.ErrUnauthorized
 		}
 
-		if role == "" {
+		if claims.Role == "" || claims.SessionID == "" {
 			return apperror.ErrUnauthorized
 		}
 
-		c.Locals("user_id", id)
-		c.Locals("role", role)
+		// Signature valid belum cukup: sesi bisa saja sudah di-revoke sebelum token expired
+		if err := checker.CheckAccess(c.UserContext(), claims.Id, claims.SessionID, claims.TokenVersion); err != nil {
+			return err
+		}
+
+		c.Locals("user_id", claims.Id)
+		c.Locals("role", claims.Role)
+		c.Locals("session_id", claims.SessionID)
+		c.SetUserContext(reqctx.WithActor(c.UserContext(), claims.Id, claims.Role))
 
 		// If no specific roles are required, allow all authenticated users
 		if len(allowedRoles) == 0 {
@@ -35,7 +49,7 @@ func Auth(jwtSvc jwt.JWTService, allowedRoles ...string) fiber.Handler {
 
 		// Check if user has an allowed role
 		for _, allowedRole := range allowedRoles {
-			if role == allowedRole {
+			if claims.Role == allowedRole {
 				return c.Next() // Proceed to the next handler
 			}
 		}
@@ -7,6 +7,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/utils"
 	"github.com/wildanasyrof/backend-topup/pkg/logger" // Import your logger package
+	"go.opentelemetry.io/otel/trace"
 )
 
 func LoggerMiddleware(log logger.Logger) fiber.Handler {
@@ -29,9 +30,15 @@ func LoggerMiddleware(log logger.Logger) fiber.Handler {
 		c.Set(fiber.HeaderXRequestID, reqID)
 
 		// ---- BEST PRACTICE: Create and inject request-scoped logger ----
-		reqLogger := log.With(logger.Fields{
+		fields := logger.Fields{
 			"request_id": reqID,
-		})
+		}
+		// Span dibuat oleh middleware Tracing; trace_id menghubungkan baris log ke trace-nya
+		if sc := trace.SpanContextFromContext(c.UserContext()); sc.IsValid() {
+			fields["trace_id"] = sc.TraceID().String()
+			fields["span_id"] = sc.SpanID().String()
+		}
+		reqLogger := log.With(fields)
 		c.Locals(logger.CtxKey, reqLogger) // Use the const from logger package
 
 		// ---- Next handler ----------------------------------------------------
@@ -67,7 +74,7 @@ func LoggerMiddleware(log logger.Logger) fiber.Handler {
 		uid, _ := c.Locals("user_id").(string) // set by your auth middleware
 
 		// ---- BEST PRACTICE: Log as structured fields, not one string ----
-		fields := logger.Fields{
+		fields = logger.Fields{
 			"method":     method,
 			"path":       path,
 			"route":      routePath,
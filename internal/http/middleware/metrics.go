@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/metrics"
+)
+
+// Metrics mencatat jumlah dan latency request per route template (mis. /orders/:ref), bukan URL mentah.
+// Dipasang sebelum middleware lain agar latency mencakup seluruh rantai handler.
+func Metrics(m metrics.Metrics) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		started := time.Now()
+		err := c.Next()
+
+		status, routed := finalStatus(c, err)
+		route := c.Route().Path
+		if !routed {
+			// Path asli tidak dipakai sebagai label agar scanner URL acak tidak menambah time series
+			route = "unmatched"
+		}
+		m.ObserveHTTP(c.Method(), route, status, time.Since(started))
+		return err
+	}
+}
+
+// finalStatus menentukan status response dari sisi middleware. ErrorHandler baru menulis response
+// setelah seluruh middleware selesai, jadi untuk request yang gagal status diambil dari mapping error.
+// routed false berarti tidak ada route yang cocok (404 dari router Fiber).
+func finalStatus(c *fiber.Ctx, err error) (status int, routed bool) {
+	var fe *fiber.Error
+	switch {
+	case err == nil:
+		return c.Response().StatusCode(), true
+	case errors.As(err, &fe):
+		return fe.Code, fe.Code != fiber.StatusNotFound
+	default:
+		mapping, _ := apperror.MapToHTTP(err)
+		return mapping.Status, true
+	}
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+)
+
+// PermissionChecker memetakan role ke permission (lihat entity.Permissions).
+type PermissionChecker interface {
+	HasPermission(ctx context.Context, role, permission string) (bool, error)
+}
+
+// RequirePermission mengizinkan request hanya jika role user punya semua permission yang diminta.
+// Harus dipasang setelah Auth.
+func RequirePermission(checker PermissionChecker, permissions ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		role, ok := c.Locals("role").(string)
+		if !ok || role == "" {
+			return apperror.ErrUnauthorized
+		}
+
+		for _, p := range permissions {
+			allowed, err := checker.HasPermission(c.UserContext(), role, p)
+			if err != nil {
+				return err
+			}
+			if !allowed {
+				return apperror.ErrForbidden
+			}
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/http/server"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/ratelimit"
+	"github.com/wildanasyrof/backend-topup/pkg/signature"
+)
+
+// keyRing menerima key ID "tk_<id>" yang ditandatangani dengan secret "sk_<id>"
+type keyRing struct{}
+
+func (keyRing) AuthenticateAPIKey(_ context.Context, req *dto.SignedAPIRequest) (*entity.APIKey, error) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(req.KeyID, "tk_"), 10, 64)
+	if err != nil || !signature.Verify("sk_"+strconv.FormatUint(id, 10), req.Timestamp, req.Method, req.Path, req.Body, req.Signature) {
+		return nil, apperror.ErrUnauthorized
+	}
+	return &entity.APIKey{ID: id, UserID: 100 + id, User: entity.User{Role: entity.RoleUser}}, nil
+}
+
+func testLogger() logger.Logger {
+	l := logger.NewZerologLogger("test")
+	l.SetLevel("panic")
+	return l
+}
+
+func h2hApp(perIP, perKey int) *fiber.App {
+	store := ratelimit.NewMemoryStore()
+	log := testLogger()
+	app := fiber.New(fiber.Config{ErrorHandler: server.ErrorHandler(log)})
+	h2h := app.Group("/h2h", RateLimit(store, log, RateLimitPolicy{Name: "h2h-ip", Limit: ratelimit.PerMinute(perIP), Key: ByIP}))
+	h2h.Use(APIKeyAuth(keyRing{}), RateLimit(store, log, RateLimitPolicy{Name: "h2h", Limit: ratelimit.PerMinute(perKey), Key: ByAPIKey}))
+	h2h.Get("/ping", func(c *fiber.Ctx) error { return c.SendString("pong") })
+	return app
+}
+
+func h2hRequest(t *testing.T, app *fiber.App, key string) int {
+	t.Helper()
+	ts := time.Now().Unix()
+	req := httptest.NewRequest(fiber.MethodGet, "/h2h/ping", nil)
+	req.Header.Set("X-API-Key", key)
+	req.Header.Set("X-Timestamp", strconv.FormatInt(ts, 10))
+	req.Header.Set("X-Signature", signature.Sign("sk_"+strings.TrimPrefix(key, "tk_"), ts, fiber.MethodGet, "/h2h/ping", nil))
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode
+}
+
+func TestH2HRateLimitIsPerAuthenticatedKey(t *testing.T) {
+	app := h2hApp(100, 2)
+
+	for range 2 {
+		if code := h2hRequest(t, app, "tk_1"); code != fiber.StatusOK {
+			t.Fatalf("status %d", code)
+		}
+	}
+	if code := h2hRequest(t, app, "tk_1"); code != fiber.StatusTooManyRequests {
+		t.Fatalf("third request with the same key got %d, want 429", code)
+	}
+	// Key lain punya bucket sendiri
+	if code := h2hRequest(t, app, "tk_2"); code != fiber.StatusOK {
+		t.Fatalf("other key got %d", code)
+	}
+}
+
+func TestH2HInvalidKeysShareTheIPLimit(t *testing.T) {
+	app := h2hApp(3, 100)
+
+	// Header X-API-Key acak tidak memberi bucket baru
+	for i := range 3 {
+		if code := h2hRequest(t, app, "bogus-"+strconv.Itoa(i)); code != fiber.StatusUnauthorized {
+			t.Fatalf("bogus key got %d, want 401", code)
+		}
+	}
+	if code := h2hRequest(t, app, "bogus-new"); code != fiber.StatusTooManyRequests {
+		t.Fatalf("fourth request from the same IP got %d, want 429", code)
+	}
+}
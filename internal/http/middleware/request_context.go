@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/pkg/reqctx"
+)
+
+// RequestContext menyimpan IP, user agent dan request ID ke c.UserContext() agar bisa dibaca
+// service (mis. audit log) lewat reqctx.From. Harus dipasang setelah LoggerMiddleware dan
+// sebelum TimeoutMiddleware.
+func RequestContext() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		reqID, _ := c.Locals("requestid").(string)
+		c.SetUserContext(reqctx.With(c.UserContext(), reqctx.Info{
+			IP:        c.IP(),
+			UserAgent: string(c.Request().Header.UserAgent()),
+			RequestID: reqID,
+		}))
+		return c.Next()
+	}
+}
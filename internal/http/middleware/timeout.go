@@ -2,30 +2,67 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
 )
 
-func TimeoutMiddleware(d time.Duration) fiber.Handler {
+// TimeoutRule memberi batas waktu berbeda untuk request yang path-nya diawali Prefix.
+// Methods kosong berarti berlaku untuk semua method.
+type TimeoutRule struct {
+	Prefix  string
+	Methods []string
+	Timeout time.Duration
+}
+
+func (r TimeoutRule) match(method, path string) bool {
+	if path != r.Prefix && !strings.HasPrefix(path, strings.TrimSuffix(r.Prefix, "/")+"/") {
+		return false
+	}
+	if len(r.Methods) == 0 {
+		return true
+	}
+	for _, m := range r.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// TimeoutMiddleware memasang deadline pada c.UserContext(). Handler tetap berjalan di goroutine
+// request (fiber.Ctx tidak aman dipakai dari goroutine lain dan di-pool setelah request selesai);
+// pembatalan terjadi lewat context, jadi query database dan panggilan HTTP keluar ikut berhenti.
+// Handler yang tidak memakai context tetap selesai sendiri, tapi error-nya dilaporkan sebagai timeout.
+//
+// Rule pertama yang cocok menggantikan d. Deadline child context tidak bisa melebihi parent, karena itu
+// override diputuskan di sini dan bukan dengan middleware kedua di route-nya.
+func TimeoutMiddleware(d time.Duration, rules ...TimeoutRule) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		ctx, cancel := context.WithTimeout(c.UserContext(), d)
+		timeout := d
+		for _, r := range rules {
+			if r.match(c.Method(), c.Path()) {
+				timeout = r.Timeout
+				break
+			}
+		}
+
+		parent := c.UserContext()
+		ctx, cancel := context.WithTimeout(parent, timeout)
 		defer cancel()
 
-		// replace Fiber's context with ours
 		c.SetUserContext(ctx)
+		err := c.Next()
+		// Middleware di luar tidak boleh menerima context yang sudah dibatalkan
+		c.SetUserContext(parent)
 
-		errCh := make(chan error, 1)
-		go func() {
-			errCh <- c.Next()
-		}()
-
-		select {
-		case <-ctx.Done():
-			return apperror.New(apperror.CodeTimeout, "REQUEST_TIME_OUT", nil)
-		case err := <-errCh:
-			return err
+		// Response yang sudah berhasil ditulis tetap dikirim walau deadline lewat di akhir
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return apperror.New(apperror.CodeTimeout, "REQUEST_TIME_OUT", err)
 		}
+		return err
 	}
 }
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/http/server"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+)
+
+const testTimeout = 50 * time.Millisecond
+
+func timeoutApp(t *testing.T, rules ...TimeoutRule) *fiber.App {
+	t.Helper()
+	app := fiber.New(fiber.Config{ErrorHandler: server.ErrorHandler(testLogger())})
+	// Middleware di luar timeout harus menerima kembali context request yang belum dibatalkan
+	app.Use(func(c *fiber.Ctx) error {
+		err := c.Next()
+		if c.UserContext().Err() != nil {
+			t.Error("outer middleware got the cancelled timeout context")
+		}
+		return err
+	})
+	app.Use(TimeoutMiddleware(testTimeout, rules...))
+	return app
+}
+
+type testResponse struct {
+	status int
+	code   string
+	body   string
+}
+
+func doRequest(t *testing.T, app *fiber.App, method, path string) testResponse {
+	t.Helper()
+	res, err := app.Test(httptest.NewRequest(method, path, nil), -1)
+	if err != nil {
+		t.Error(err)
+		return testResponse{}
+	}
+	body, _ := io.ReadAll(res.Body)
+	var env struct {
+		Error *struct{ Code string }
+	}
+	out := testResponse{status: res.StatusCode, body: string(body)}
+	if json.Unmarshal(body, &env) == nil && env.Error != nil {
+		out.code = env.Error.Code
+	}
+	return out
+}
+
+// parallel menjalankan fn beberapa kali bersamaan agar race detector melihat akses ctx yang tumpang tindih
+func parallel(n int, fn func()) {
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTimeoutCancelsContextAwareHandler(t *testing.T) {
+	app := timeoutApp(t)
+	app.Get("/slow", func(c *fiber.Ctx) error {
+		select {
+		case <-c.UserContext().Done():
+			return c.UserContext().Err()
+		case <-time.After(5 * time.Second):
+			return c.SendString("too late")
+		}
+	})
+
+	parallel(8, func() {
+		started := time.Now()
+		res := doRequest(t, app, fiber.MethodGet, "/slow")
+		if res.status != fiber.StatusGatewayTimeout || res.code != string(apperror.CodeTimeout) {
+			t.Errorf("got %d %q, want the timeout error", res.status, res.body)
+		}
+		if took := time.Since(started); took > time.Second {
+			t.Errorf("handler not cancelled, request took %s", took)
+		}
+	})
+}
+
+func TestTimeoutWithHandlerIgnoringContext(t *testing.T) {
+	app := timeoutApp(t)
+	// Handler tidak melihat context: tetap selesai di goroutine request, lalu hasilnya diputuskan
+	app.Get("/ignores/ok", func(c *fiber.Ctx) error {
+		time.Sleep(2 * testTimeout)
+		return c.SendString("done")
+	})
+	app.Get("/ignores/fail", func(c *fiber.Ctx) error {
+		time.Sleep(2 * testTimeout)
+		return errors.New("upstream failed")
+	})
+
+	parallel(8, func() {
+		// Response yang sudah ditulis tetap dikirim
+		if res := doRequest(t, app, fiber.MethodGet, "/ignores/ok"); res.status != fiber.StatusOK || res.body != "done" {
+			t.Errorf("got %d %q, want the handler response", res.status, res.body)
+		}
+		// Error setelah deadline dilaporkan sebagai timeout
+		if res := doRequest(t, app, fiber.MethodGet, "/ignores/fail"); res.status != fiber.StatusGatewayTimeout || res.code != string(apperror.CodeTimeout) {
+			t.Errorf("got %d %q, want the timeout error", res.status, res.body)
+		}
+	})
+}
+
+func TestTimeoutRuleOverrides(t *testing.T) {
+	app := timeoutApp(t,
+		TimeoutRule{Prefix: "/products/df", Methods: []string{fiber.MethodGet}, Timeout: 4 * time.Second},
+		TimeoutRule{Prefix: "/products", Methods: []string{fiber.MethodPost}, Timeout: 2 * time.Second},
+	)
+	// Handler mengembalikan sisa waktu sampai deadline dalam milidetik
+	remaining := func(c *fiber.Ctx) error {
+		deadline, ok := c.UserContext().Deadline()
+		if !ok {
+			return errors.New("no deadline")
+		}
+		return c.SendString(strconv.FormatInt(time.Until(deadline).Milliseconds(), 10))
+	}
+	app.All("/*", remaining)
+
+	cases := []struct {
+		method, path string
+		want         time.Duration
+	}{
+		{fiber.MethodGet, "/products/df", 4 * time.Second},
+		{fiber.MethodGet, "/products/df/sync", 4 * time.Second},
+		{fiber.MethodPost, "/products/df", 2 * time.Second},
+		{fiber.MethodPost, "/products", 2 * time.Second},
+		{fiber.MethodPost, "/products/12/image", 2 * time.Second},
+		{fiber.MethodGet, "/products", testTimeout},
+		{fiber.MethodPost, "/productsx", testTimeout},
+		{fiber.MethodGet, "/orders", testTimeout},
+	}
+	for _, tc := range cases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			res := doRequest(t, app, tc.method, tc.path)
+			ms, err := strconv.ParseInt(res.body, 10, 64)
+			if res.status != fiber.StatusOK || err != nil {
+				t.Fatalf("got %d %q", res.status, res.body)
+			}
+			if got := time.Duration(ms) * time.Millisecond; got > tc.want || got < tc.want-testTimeout {
+				t.Fatalf("deadline in %s, want about %s", got, tc.want)
+			}
+		})
+	}
+}
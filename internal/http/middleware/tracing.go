@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing membuat span server untuk setiap request dan menyimpannya di c.UserContext(), sehingga
+// query GORM dan panggilan http.Client yang memakai context tersebut menjadi child span.
+// Header traceparent dari client/gateway dihormati. Harus dipasang setelah requestid.
+func Tracing() fiber.Handler {
+	tracer := tracing.Tracer()
+	return func(c *fiber.Ctx) error {
+		headers := make(http.Header)
+		c.Request().Header.VisitAll(func(k, v []byte) {
+			headers.Add(string(k), string(v))
+		})
+		ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), propagation.HeaderCarrier(headers))
+
+		reqID, _ := c.Locals("requestid").(string)
+		ctx, span := tracer.Start(ctx, c.Method(),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Method()),
+				semconv.URLPath(c.Path()),
+				semconv.ClientAddress(c.IP()),
+				semconv.UserAgentOriginal(string(c.Request().Header.UserAgent())),
+				tracing.RequestIDKey.String(reqID),
+			),
+		)
+		defer span.End()
+		c.SetUserContext(ctx)
+
+		err := c.Next()
+
+		// Route template baru diketahui setelah routing; dipakai sebagai nama span agar jumlahnya terbatas
+		status, routed := finalStatus(c, err)
+		if routed {
+			route := c.Route().Path
+			span.SetName(c.Method() + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, http.StatusText(status))
+			if err != nil {
+				span.RecordError(err)
+			}
+		}
+		return err
+	}
+}
@@ -0,0 +1,138 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// fieldLogger menyimpan field dari semua With, cukup untuk memeriksa logger request
+type fieldLogger struct {
+	mu     *sync.Mutex
+	fields logger.Fields
+}
+
+func newFieldLogger() *fieldLogger {
+	return &fieldLogger{mu: &sync.Mutex{}, fields: logger.Fields{}}
+}
+
+func (l *fieldLogger) With(f logger.Fields) logger.Logger {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for k, v := range f {
+		l.fields[k] = v
+	}
+	return l
+}
+
+func (l *fieldLogger) field(k string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, _ := l.fields[k].(string)
+	return s
+}
+
+func (l *fieldLogger) Info(string)         {}
+func (l *fieldLogger) Error(error, string) {}
+func (l *fieldLogger) Warn(string)         {}
+func (l *fieldLogger) Debug(string)        {}
+func (l *fieldLogger) Fatal(string)        {}
+func (l *fieldLogger) SetLevel(string)     {}
+
+// stdoutSpans memasang tracer provider dengan exporter stdout (seperti tracing.Setup untuk
+// exporter "stdout") yang menulis ke buffer, dan mengembalikan provider global sesudah test
+func stdoutSpans(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	exp, err := stdouttrace.New(stdouttrace.WithWriter(&buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	prevTP, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		_ = tp.Shutdown(t.Context())
+		otel.SetTracerProvider(prevTP)
+		otel.SetTextMapPropagator(prevProp)
+	})
+	return &buf
+}
+
+func TestRequestLoggerCarriesTraceAndSpanID(t *testing.T) {
+	spans := stdoutSpans(t)
+	log := newFieldLogger()
+	app := fiber.New()
+	app.Use(requestid.New(), Tracing(), LoggerMiddleware(log))
+	app.Get("/ping/:id", func(c *fiber.Ctx) error {
+		// Handler memakai logger request yang sama dengan yang disiapkan middleware
+		if c.Locals(logger.CtxKey) == nil {
+			t.Error("request logger not injected")
+		}
+		return c.SendString("pong")
+	})
+
+	const parent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest(fiber.MethodGet, "/ping/1", nil)
+	req.Header.Set("traceparent", parent)
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.StatusCode != fiber.StatusOK {
+		t.Fatalf("status %d", res.StatusCode)
+	}
+
+	// trace_id mengikuti traceparent dari client, span_id adalah span server milik request ini
+	traceID, spanID := log.field("trace_id"), log.field("span_id")
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("trace_id %q does not continue the incoming trace", traceID)
+	}
+	if len(spanID) != 16 || spanID == "00f067aa0ba902b7" {
+		t.Fatalf("span_id %q is not the server span", spanID)
+	}
+	if log.field("request_id") == "" {
+		t.Fatal("request_id missing")
+	}
+
+	var exported struct {
+		Name        string
+		SpanContext struct{ TraceID, SpanID string }
+		Parent      struct{ SpanID string }
+	}
+	if err := json.Unmarshal(spans.Bytes(), &exported); err != nil {
+		t.Fatalf("decode exported span: %v\n%s", err, spans.String())
+	}
+	if exported.Name != "GET /ping/:id" {
+		t.Fatalf("span name %q", exported.Name)
+	}
+	if exported.SpanContext.TraceID != traceID || exported.SpanContext.SpanID != spanID || exported.Parent.SpanID != "00f067aa0ba902b7" {
+		t.Fatalf("exported span %+v does not match the logged ids %s/%s", exported, traceID, spanID)
+	}
+}
+
+func TestRequestLoggerStartsNewTraceWithoutParent(t *testing.T) {
+	stdoutSpans(t)
+	log := newFieldLogger()
+	app := fiber.New()
+	app.Use(Tracing(), LoggerMiddleware(log))
+	app.Get("/ping", func(c *fiber.Ctx) error { return c.SendString("pong") })
+
+	if _, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/ping", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if id := log.field("trace_id"); len(id) != 32 || id == "00000000000000000000000000000000" {
+		t.Fatalf("trace_id %q", id)
+	}
+}
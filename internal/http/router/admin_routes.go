@@ -0,0 +1,23 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/di"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/http/middleware"
+)
+
+// RoleRoutes: manajemen role & permission staff
+func RoleRoutes(r fiber.Router, di *di.DI) {
+	perm := middleware.RequirePermission(di.RoleService, entity.PermRolesManage)
+	r.Get("/roles", perm, di.RoleHandler.List)
+	r.Post("/roles", perm, di.RoleHandler.Create)
+	r.Put("/roles/:id", perm, di.RoleHandler.Update)
+	r.Delete("/roles/:id", perm, di.RoleHandler.Delete)
+	r.Get("/permissions", perm, di.RoleHandler.Permissions)
+}
+
+// AuditRoutes: riwayat perubahan data oleh staff
+func AuditRoutes(r fiber.Router, di *di.DI) {
+	r.Get("/audit-logs", middleware.RequirePermission(di.RoleService, entity.PermAuditRead), di.AuditLogHandler.List)
+}
@@ -10,6 +10,16 @@ func AuthRoutes(r fiber.Router, a *handler.AuthHandler) {
 	r.Post("/login", a.Login)
 	r.Post("/refresh", a.Refresh) // <--- TAMBAHKAN INI
 	r.Post("/logout", a.Logout)   // <--- TAMBAHKAN INI
-	r.Get("/google/login", a.GoogleLogin)
-	r.Get("/google/callback", a.GoogleCallback)
+	r.Post("/verify-email/request", a.RequestEmailVerification)
+	r.Post("/verify-email", a.VerifyEmail)
+	r.Post("/forgot-password", a.ForgotPassword)
+	r.Post("/reset-password", a.ResetPassword)
+	r.Post("/whatsapp/request", a.WhatsappRequest)
+	r.Post("/whatsapp/verify", a.WhatsappVerify)
+	r.Post("/2fa/verify", a.TwoFactorVerify)
+	r.Post("/2fa/enroll", a.TwoFactorEnroll)
+	r.Post("/2fa/enroll/confirm", a.TwoFactorEnrollConfirm)
+	r.Get("/:provider/login", a.OAuthLogin)
+	r.Get("/:provider/callback", a.OAuthCallback)
+	r.Post("/:provider/callback", a.OAuthCallback) // response_mode=form_post (Apple)
 }
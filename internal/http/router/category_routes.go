@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/wildanasyrof/backend-topup/internal/di"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
 	"github.com/wildanasyrof/backend-topup/internal/http/middleware"
 )
 
@@ -10,7 +11,7 @@ func CategoryRotues(r fiber.Router, di *di.DI) {
 	r.Get("/", di.CategoryHandler.GetAll)
 	r.Get("/:slug", di.CategoryHandler.GetBySlug)
 
-	r.Use(middleware.Auth(di.Jwt, "admin"))
+	r.Use(middleware.Auth(di.Jwt, di.SessionService), middleware.RequirePermission(di.RoleService, entity.PermCatalogWrite))
 	r.Post("/", di.CategoryHandler.Create)
 	r.Put("/:id", di.CategoryHandler.Update)
 	r.Delete("/:id", di.CategoryHandler.Delete)
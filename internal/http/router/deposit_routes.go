@@ -2,11 +2,16 @@ package router
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/di"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
 	"github.com/wildanasyrof/backend-topup/internal/http/handler"
+	"github.com/wildanasyrof/backend-topup/internal/http/middleware"
 )
 
-func DepositRoutes(r fiber.Router, h *handler.DepositHandler) {
+func DepositRoutes(r fiber.Router, h *handler.DepositHandler, di *di.DI) {
 	r.Post("/", h.Create)
 	r.Get("/", h.GetByDepositID)
 	r.Get("/all", h.GetByUserID)
+
+	r.Put("/:id/status", middleware.RequirePermission(di.RoleService, entity.PermBalanceAdjust), h.UpdateStatus)
 }
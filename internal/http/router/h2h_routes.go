@@ -0,0 +1,12 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/di"
+)
+
+// H2HRoutes: endpoint host-to-host untuk reseller, memakai handler yang sama dengan web
+func H2HRoutes(r fiber.Router, di *di.DI) {
+	r.Post("/orders", di.OrderHandler.Create)
+	r.Get("/orders/:ref", di.OrderHandler.GetByRef)
+}
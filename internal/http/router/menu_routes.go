@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/wildanasyrof/backend-topup/internal/di"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
 	"github.com/wildanasyrof/backend-topup/internal/http/handler"
 	"github.com/wildanasyrof/backend-topup/internal/http/middleware"
 )
@@ -13,7 +14,7 @@ func MenuRoutes(r fiber.Router, menuHandler *handler.MenuHandler, di *di.DI) {
 	r.Get("/:id", menuHandler.GetByID)
 
 	// Middleware auth hanya berlaku untuk rute DI BAWAH baris ini
-	r.Use(middleware.Auth(di.Jwt, "admin"))
+	r.Use(middleware.Auth(di.Jwt, di.SessionService), middleware.RequirePermission(di.RoleService, entity.PermCatalogWrite))
 	r.Post("/", menuHandler.Create)
 	r.Put("/:id", menuHandler.Update)
 	r.Delete("/:id", menuHandler.Delete)
@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/wildanasyrof/backend-topup/internal/di"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
 	"github.com/wildanasyrof/backend-topup/internal/http/middleware"
 )
 
@@ -12,9 +13,9 @@ func OrderRoutes(r fiber.Router, di *di.DI) {
 	r.Get("/:ref", di.OrderHandler.GetByRef)
 
 	// Route for LOGGED-IN users (requires authentication)
-	r.Use(middleware.Auth(di.Jwt))
+	r.Use(middleware.Auth(di.Jwt, di.SessionService))
 	r.Post("/", di.OrderHandler.Create)
 
-	r.Use(middleware.Auth(di.Jwt, "admin"))
-	r.Get("/all", di.OrderHandler.GetAll)
+	r.Get("/all", middleware.RequirePermission(di.RoleService, entity.PermOrdersRead), di.OrderHandler.GetAll)
+	r.Put("/:ref/status", middleware.RequirePermission(di.RoleService, entity.PermOrdersWrite), di.OrderHandler.UpdateStatus)
 }
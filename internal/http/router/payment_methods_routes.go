@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/wildanasyrof/backend-topup/internal/di"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
 	"github.com/wildanasyrof/backend-topup/internal/http/handler"
 	"github.com/wildanasyrof/backend-topup/internal/http/middleware"
 )
@@ -11,7 +12,7 @@ func PaymentMethodsRoutes(r fiber.Router, h *handler.PaymentMethodsHandler, di *
 	r.Get("/", h.GetAll)
 	r.Get("/:id", h.GetByID)
 
-	r.Use(middleware.Auth(di.Jwt, "admin"))
+	r.Use(middleware.Auth(di.Jwt, di.SessionService), middleware.RequirePermission(di.RoleService, entity.PermCatalogWrite))
 	r.Post("/", h.Create)
 	r.Put("/:id", h.Update)
 	r.Delete("/:id", h.Delete)
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -8,73 +9,138 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 	"github.com/wildanasyrof/backend-topup/internal/config"
 	"github.com/wildanasyrof/backend-topup/internal/di"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
 	"github.com/wildanasyrof/backend-topup/internal/http/middleware"
+	"github.com/wildanasyrof/backend-topup/pkg/ratelimit"
 )
 
 func SetupRouter(app *fiber.App, di *di.DI, cfg *config.Config) {
 	app.Use(cors.New(cors.Config{
-		AllowOrigins:     "http://localhost:5173", // <-- URL Frontend Anda
-		AllowCredentials: true,                    // <-- WAJIB untuk cookie
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
+		AllowOrigins:     strings.Join(cfg.Cors.AllowOrigins, ","),
+		AllowCredentials: cfg.Cors.AllowCredentials, // WAJIB true untuk cookie refresh token
+		AllowHeaders:     strings.Join(cfg.Cors.AllowHeaders, ","),
 		AllowMethods:     "GET, POST, PUT, DELETE, OPTIONS",
+		MaxAge:           cfg.Cors.MaxAge,
 	}))
 	app.Use(requestid.New(requestid.Config{
 		Header:     "X-Request-ID",
 		ContextKey: "requestid",
 	}))
+	app.Use(middleware.Tracing())
+	app.Use(middleware.Metrics(di.Metrics))
 	app.Use(middleware.LoggerMiddleware(di.Logger))
-	app.Use(middleware.TimeoutMiddleware(time.Duration(cfg.Server.RequestTimeOut) * time.Second))
-
-	app.Get("/health", func(c *fiber.Ctx) error { return c.JSON(fiber.Map{"status": "ok"}) })
+	app.Use(middleware.RequestContext())
+	// Sinkronisasi produk supplier dan upload gambar (multipart) butuh waktu lebih lama dari request biasa
+	uploadMethods := []string{fiber.MethodPost, fiber.MethodPut}
+	app.Use(middleware.TimeoutMiddleware(time.Duration(cfg.Server.RequestTimeOut)*time.Second,
+		middleware.TimeoutRule{Prefix: "/products/df", Methods: []string{fiber.MethodGet}, Timeout: 2 * time.Minute},
+		middleware.TimeoutRule{Prefix: "/products", Methods: uploadMethods, Timeout: time.Minute},
+		middleware.TimeoutRule{Prefix: "/categories", Methods: uploadMethods, Timeout: time.Minute},
+		middleware.TimeoutRule{Prefix: "/banners", Methods: uploadMethods, Timeout: time.Minute},
+		middleware.TimeoutRule{Prefix: "/payment-methods", Methods: uploadMethods, Timeout: time.Minute},
+	))
+	// Selama maintenance hanya login, area staff dan endpoint infrastruktur yang tetap dilayani
+	app.Use(middleware.Maintenance(di.SettingsService, di.Jwt,
+		"/health", "/.well-known", "/public-settings", "/auth", "/admin", "/settings", "/uploads", "/openapi.json", "/docs"))
+
+	// /health dipertahankan sebagai alias liveness untuk probe lama
+	app.Get("/health", di.HealthHandler.Live)
+	app.Get("/health/live", di.HealthHandler.Live)
+	app.Get("/health/ready", di.HealthHandler.Ready)
+	// JWKS dikembalikan apa adanya (bukan envelope) agar bisa dibaca library JWT standar
+	app.Get("/.well-known/jwks.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(di.Jwt.JWKS())
+	})
+
+	// Spesifikasi selalu tersedia untuk client generator; Swagger UI hanya di luar production
+	app.Get("/openapi.json", di.DocsHandler.OpenAPI)
+	if cfg.Server.Env != "production" {
+		app.Get("/docs", di.DocsHandler.SwaggerUI)
+	}
 
 	app.Static("/uploads", cfg.Server.UploadDir)
 
-	AuthRoutes(app.Group("/auth"), di.AuthHandler) // <-- Rute /auth
+	// Rate limit policy per route group. Policy ByUser dipasang setelah Auth agar user_id sudah tersedia
+	limit := func(name string, l ratelimit.Limit, key middleware.RateLimitKey) fiber.Handler {
+		return middleware.RateLimit(di.RateLimiter, di.Logger, middleware.RateLimitPolicy{Name: name, Limit: l, Key: key})
+	}
+	authLimit := limit("auth", ratelimit.PerMinute(20), middleware.ByIP)
+	publicLimit := limit("public", ratelimit.PerMinute(120), middleware.ByIP)
+	guestOrderLimit := limit("guest-order", ratelimit.PerMinute(10), middleware.ByIP)
+	userLimit := limit("user", ratelimit.PerMinute(300), middleware.ByUser)
+
+	app.Get("/public-settings", publicLimit, di.SettingsHandler.Public)
 
-	menu := app.Group("/menus")
+	app.Use("/auth/register", middleware.Feature(di.SettingsService, entity.SettingFeatureRegistration))
+	AuthRoutes(app.Group("/auth", authLimit), di.AuthHandler) // <-- Rute /auth
+
+	menu := app.Group("/menus", publicLimit)
 	MenuRoutes(menu, di.MenuHandler, di)
 
-	app.Get("/menu", di.MenuHandler.GetAll)
-	app.Get("/categories", di.CategoryHandler.GetAll)
-	app.Get("/categories/:slug", di.CategoryHandler.GetBySlug)
+	app.Get("/menu", publicLimit, di.MenuHandler.GetAll)
+	app.Get("/categories", publicLimit, di.CategoryHandler.GetAll)
+	app.Get("/categories/:slug", publicLimit, di.CategoryHandler.GetBySlug)
 	me := app.Group("/me")
-	me.Use(middleware.Auth(di.Jwt, "admin", "user"))
-	UserRoutes(me, di.UserHandler)
+	me.Use(middleware.Auth(di.Jwt, di.SessionService), userLimit)
+	UserRoutes(me, di)
+
+	users := app.Group("/users")
+	users.Use(middleware.Auth(di.Jwt, di.SessionService), middleware.RequirePermission(di.RoleService, entity.PermUsersManage), userLimit)
+	AdminUserRoutes(users, di)
 
 	// --- TAMBAHKAN INI ---
 	// Grup /sessions untuk manajemen sesi (remote logout)
 	sessions := app.Group("/sessions")
-	sessions.Use(middleware.Auth(di.Jwt, "admin", "user")) // Wajib login
+	sessions.Use(middleware.Auth(di.Jwt, di.SessionService), userLimit) // Wajib login
 	SessionRoutes(sessions, di)
 	// ---------------------
 
 	settings := app.Group("/settings")
-	settings.Use(middleware.Auth(di.Jwt, "admin"))
+	settings.Use(middleware.Auth(di.Jwt, di.SessionService), middleware.RequirePermission(di.RoleService, entity.PermSettingsManage), userLimit)
 	SettingsRoutes(settings, di.SettingsHandler)
 
-	paymentMethods := app.Group("/payment-methods")
+	paymentMethods := app.Group("/payment-methods", publicLimit)
 	PaymentMethodsRoutes(paymentMethods, di.PaymentMethodsHandler, di)
 
-	banner := app.Group("/banners")
+	banner := app.Group("/banners", publicLimit)
 	BannerRoutes(banner, di)
 
 	deposit := app.Group("/deposits")
-	deposit.Use(middleware.Auth(di.Jwt, "admin", "user"))
-	DepositRoutes(deposit, di.DepositHanlder)
+	deposit.Use(middleware.Auth(di.Jwt, di.SessionService), userLimit)
+	DepositRoutes(deposit, di.DepositHanlder, di)
+
+	// Area staff: permission dicek per route di RoleRoutes/AuditRoutes
+	admin := app.Group("/admin")
+	admin.Use(middleware.Auth(di.Jwt, di.SessionService), userLimit)
+	RoleRoutes(admin, di)
+	AuditRoutes(admin, di)
+
+	webhooks := app.Group("/webhooks")
+	webhooks.Use(middleware.Auth(di.Jwt, di.SessionService), middleware.RequirePermission(di.RoleService, entity.PermWebhooksManage), userLimit)
+	WebhookRoutes(webhooks, di)
 
 	provider := app.Group("/providers")
-	provider.Use(middleware.Auth(di.Jwt, "admin"))
+	provider.Use(middleware.Auth(di.Jwt, di.SessionService), middleware.RequirePermission(di.RoleService, entity.PermProvidersManage), userLimit)
 	ProviderRoutes(provider, di.ProviderHandler)
 
-	category := app.Group("/categories")
+	category := app.Group("/categories", publicLimit)
 	CategoryRotues(category, di)
 
-	product := app.Group("/products")
+	product := app.Group("/products", publicLimit)
 	ProductRouter(product, di)
 
-	price := app.Group("/prices")
+	price := app.Group("/prices", publicLimit)
 	PriceRoutes(price, di)
 
-	order := app.Group("/orders")
+	// Guest checkout tidak butuh login, jadi dibatasi lebih ketat per IP
+	app.Use("/orders/guest", guestOrderLimit, middleware.Feature(di.SettingsService, entity.SettingFeatureGuestCheckout))
+	order := app.Group("/orders", publicLimit)
 	OrderRoutes(order, di)
+
+	// Host-to-host reseller: API key + HMAC signature, bukan JWT.
+	// Batas per IP di depan menahan tebakan key/signature; batas per key dihitung setelah key terbukti valid
+	h2h := app.Group("/h2h", limit("h2h-ip", ratelimit.PerMinute(1200), middleware.ByIP))
+	h2h.Use(middleware.APIKeyAuth(di.APIKeyService), limit("h2h", ratelimit.PerMinute(600), middleware.ByAPIKey))
+	H2HRoutes(h2h, di)
 }
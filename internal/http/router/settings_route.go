@@ -8,6 +8,7 @@ import (
 func SettingsRoutes(r fiber.Router, h *handler.SettingsHandler) {
 	r.Post("/", h.Create)
 	r.Get("/", h.FindAll)
+	r.Get("/definitions", h.Definitions)
 	r.Put("/:id", h.Update)
 	r.Delete("/:id", h.Delete)
 }
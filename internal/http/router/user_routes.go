@@ -2,10 +2,38 @@ package router
 
 import (
 	"github.com/gofiber/fiber/v2"
-	"github.com/wildanasyrof/backend-topup/internal/http/handler"
+	"github.com/wildanasyrof/backend-topup/internal/di"
 )
 
-func UserRoutes(r fiber.Router, userHandler *handler.UserHandler) {
-	r.Get("/", userHandler.GetProfile)
-	r.Put("/", userHandler.Update)
+func UserRoutes(r fiber.Router, di *di.DI) {
+	r.Get("/", di.UserHandler.GetProfile)
+	r.Put("/", di.UserHandler.Update)
+	r.Put("/password", di.UserHandler.SetPassword)
+	r.Post("/whatsapp/verify/request", di.AuthHandler.WhatsappVerificationRequest)
+	r.Post("/whatsapp/verify", di.AuthHandler.WhatsappVerificationConfirm)
+
+	r.Get("/identities", di.UserHandler.ListIdentities)
+	r.Post("/identities/:provider", di.AuthHandler.OAuthLinkStart)
+	r.Delete("/identities/:provider", di.UserHandler.UnlinkIdentity)
+
+	r.Post("/2fa/setup", di.TwoFactorHandler.Setup)
+	r.Post("/2fa/enable", di.TwoFactorHandler.Enable)
+	r.Post("/2fa/disable", di.TwoFactorHandler.Disable)
+	r.Post("/2fa/recovery-codes", di.TwoFactorHandler.RegenerateRecoveryCodes)
+
+	r.Get("/api-keys", di.APIKeyHandler.List)
+	r.Post("/api-keys", di.APIKeyHandler.Create)
+	r.Delete("/api-keys/:id", di.APIKeyHandler.Revoke)
+
+	r.Get("/webhooks", di.WebhookHandler.List)
+	r.Post("/webhooks", di.WebhookHandler.Create)
+	r.Delete("/webhooks/:id", di.WebhookHandler.Delete)
+	r.Get("/webhooks/deliveries", di.WebhookHandler.MyDeliveries)
+}
+
+// AdminUserRoutes: manajemen user oleh admin
+func AdminUserRoutes(r fiber.Router, di *di.DI) {
+	r.Put("/:id/suspend", di.UserHandler.Suspend)
+	r.Delete("/:id/suspend", di.UserHandler.Unsuspend)
+	r.Put("/:id/role", di.UserHandler.ChangeRole)
 }
@@ -0,0 +1,12 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/wildanasyrof/backend-topup/internal/di"
+)
+
+// WebhookRoutes: log pengiriman webhook dan resend untuk admin
+func WebhookRoutes(r fiber.Router, di *di.DI) {
+	r.Get("/deliveries", di.WebhookHandler.Deliveries)
+	r.Post("/deliveries/:id/resend", di.WebhookHandler.Resend)
+}
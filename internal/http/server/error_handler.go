@@ -2,6 +2,9 @@
 package server
 
 import (
+	"math"
+	"strconv"
+
 	"github.com/gofiber/fiber/v2"
 	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
 	"github.com/wildanasyrof/backend-topup/pkg/logger"
@@ -13,7 +16,9 @@ func ErrorHandler(log logger.Logger) fiber.ErrorHandler {
 		mapping, ae := apperror.MapToHTTP(err)
 
 		// log: include request id, method, path, status, code
-		log = log.With(map[string]any{
+		// Logger per request disimpan di variabel lokal; menimpa `log` milik closure berarti race
+		// antar request dan field yang terus menumpuk
+		log := log.With(map[string]any{
 			"request_id": c.Get("X-Request-ID"),
 			"method":     c.Method(),
 			"path":       c.Path(),
@@ -33,6 +38,10 @@ func ErrorHandler(log logger.Logger) fiber.ErrorHandler {
 			log.Error(err, "request failed")
 		}
 
+		if ae != nil && ae.RetryAfter > 0 {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(ae.RetryAfter.Seconds()))))
+		}
+
 		env := response.Envelope{
 			Success:   false,
 			RequestID: c.Get("X-Request-ID"),
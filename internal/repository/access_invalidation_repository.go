@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/gorm"
+)
+
+// accessInvalidationChannel adalah channel LISTEN/NOTIFY Postgres yang dipakai bersama semua replica
+const accessInvalidationChannel = "access_invalidated"
+
+// AccessInvalidationRepository menyebarkan invalidasi AccessCache ke semua replica API.
+type AccessInvalidationRepository interface {
+	// Publish memberi tahu semua replica (termasuk yang ini) bahwa akses user berubah
+	Publish(ctx context.Context, userID uint64) error
+	// Listen memanggil onConnect setelah LISTEN aktif, lalu fn untuk setiap user yang di-invalidasi,
+	// sampai ctx selesai atau koneksi putus (error dikembalikan, caller yang menyambung ulang)
+	Listen(ctx context.Context, onConnect func(), fn func(userID uint64)) error
+}
+
+type accessInvalidationRepository struct {
+	db *gorm.DB
+}
+
+func NewAccessInvalidationRepository(db *gorm.DB) AccessInvalidationRepository {
+	return &accessInvalidationRepository{db: db}
+}
+
+// Publish implements AccessInvalidationRepository.
+func (r *accessInvalidationRepository) Publish(ctx context.Context, userID uint64) error {
+	return r.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", accessInvalidationChannel, strconv.FormatUint(userID, 10)).Error
+}
+
+// Listen implements AccessInvalidationRepository.
+// Satu koneksi dari pool dipegang selama listen, lalu dibuang (bukan dikembalikan) karena masih berstatus LISTEN.
+func (r *accessInvalidationRepository) Listen(ctx context.Context, onConnect func(), fn func(userID uint64)) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var listenErr error
+	err = conn.Raw(func(driverConn any) error {
+		pc, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			listenErr = fmt.Errorf("access invalidation: unexpected driver connection %T", driverConn)
+			return driver.ErrBadConn
+		}
+		if _, err := pc.Conn().Exec(ctx, "LISTEN "+accessInvalidationChannel); err != nil {
+			listenErr = err
+			return driver.ErrBadConn
+		}
+		onConnect()
+		for {
+			n, err := pc.Conn().WaitForNotification(ctx)
+			if err != nil {
+				listenErr = err
+				return driver.ErrBadConn
+			}
+			if userID, err := strconv.ParseUint(n.Payload, 10, 64); err == nil {
+				fn(userID)
+			}
+		}
+	})
+	if listenErr != nil {
+		return listenErr
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entity.APIKey) error
+	// FindByKeyID memuat key beserta User pemiliknya
+	FindByKeyID(ctx context.Context, keyID string) (*entity.APIKey, error)
+	FindByUser(ctx context.Context, userID uint64) ([]entity.APIKey, error)
+	CountActive(ctx context.Context, userID uint64) (int64, error)
+	Revoke(ctx context.Context, userID, id uint64) error
+	TouchLastUsed(ctx context.Context, id uint64, at time.Time) error
+	// RecordSignature mencatat signature request; false jika signature yang sama sudah pernah diterima
+	RecordSignature(ctx context.Context, id uint64, signature string, expiresAt time.Time) (bool, error)
+}
+
+// signatureSweepInterval: catatan signature kedaluwarsa dibersihkan paling sering sekali per selang ini
+const signatureSweepInterval = time.Minute
+
+type apiKeyRepository struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Create implements APIKeyRepository.
+func (r *apiKeyRepository) Create(ctx context.Context, key *entity.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+// FindByKeyID implements APIKeyRepository.
+func (r *apiKeyRepository) FindByKeyID(ctx context.Context, keyID string) (*entity.APIKey, error) {
+	var key entity.APIKey
+	err := r.db.WithContext(ctx).Joins("User").Where("api_keys.key_id = ?", keyID).First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.ErrNotFound
+	}
+	return &key, err
+}
+
+// FindByUser implements APIKeyRepository.
+func (r *apiKeyRepository) FindByUser(ctx context.Context, userID uint64) ([]entity.APIKey, error) {
+	var keys []entity.APIKey
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at desc").Find(&keys).Error
+	return keys, err
+}
+
+// CountActive implements APIKeyRepository.
+func (r *apiKeyRepository) CountActive(ctx context.Context, userID uint64) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&entity.APIKey{}).Where("user_id = ? AND revoked_at IS NULL", userID).Count(&n).Error
+	return n, err
+}
+
+// Revoke implements APIKeyRepository.
+func (r *apiKeyRepository) Revoke(ctx context.Context, userID, id uint64) error {
+	res := r.db.WithContext(ctx).Model(&entity.APIKey{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed implements APIKeyRepository.
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id uint64, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&entity.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+// RecordSignature implements APIKeyRepository.
+// Primary key (api_key_id, signature) menjamin hanya satu dari request kembar yang diterima.
+func (r *apiKeyRepository) RecordSignature(ctx context.Context, id uint64, signature string, expiresAt time.Time) (bool, error) {
+	// Bersihkan catatan kedaluwarsa sesekali, jadi tidak perlu janitor di setiap replica
+	r.mu.Lock()
+	sweep := time.Since(r.lastSweep) > signatureSweepInterval
+	if sweep {
+		r.lastSweep = time.Now()
+	}
+	r.mu.Unlock()
+	if sweep {
+		if err := r.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&entity.APIRequestSignature{}).Error; err != nil {
+			return false, err
+		}
+	}
+
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entity.APIRequestSignature{APIKeyID: id, Signature: signature, ExpiresAt: expiresAt})
+	return res.RowsAffected == 1, res.Error
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/pkg/pagination"
+	"gorm.io/gorm"
+)
+
+type AuditLogRepository interface {
+	Create(ctx context.Context, log *entity.AuditLog) error
+	FindAll(ctx context.Context, q dto.AuditLogListQuery) ([]entity.AuditLog, pagination.Meta, error)
+}
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+// Create implements AuditLogRepository.
+func (r *auditLogRepository) Create(ctx context.Context, log *entity.AuditLog) error {
+	return r.db.WithContext(ctx).Create(log).Error
+}
+
+// FindAll implements AuditLogRepository.
+func (r *auditLogRepository) FindAll(ctx context.Context, q dto.AuditLogListQuery) (items []entity.AuditLog, meta pagination.Meta, err error) {
+	q.Normalize()
+
+	filtered := r.db.WithContext(ctx).Model(&entity.AuditLog{}).Scopes(AuditLogFilters(q))
+
+	var total int64
+	if err = filtered.Count(&total).Error; err != nil {
+		return
+	}
+
+	allowedSort := map[string]struct{}{"created_at": {}, "id": {}}
+	if err = filtered.
+		Scopes(
+			func(db *gorm.DB) *gorm.DB { return pagination.ScopeSort(db, q.Sort, allowedSort) },
+			func(db *gorm.DB) *gorm.DB { return pagination.ScopePaginate(db, q.Page, q.Limit) },
+		).
+		Find(&items).Error; err != nil {
+		return
+	}
+
+	meta = pagination.CalcMeta(int(total), q.Page, q.Limit)
+	return
+}
+
+func AuditLogFilters(q dto.AuditLogListQuery) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if q.ActorID != nil {
+			db = db.Where("actor_id = ?", *q.ActorID)
+		}
+		if q.Action != "" {
+			db = db.Where("action = ?", q.Action)
+		}
+		if q.EntityType != "" {
+			db = db.Where("entity_type = ?", q.EntityType)
+		}
+		if q.EntityID != "" {
+			db = db.Where("entity_id = ?", q.EntityID)
+		}
+		// Format sudah divalidasi di service
+		from, to, _ := q.TimeRange()
+		if !from.IsZero() {
+			db = db.Where("created_at >= ?", from)
+		}
+		if !to.IsZero() {
+			db = db.Where("created_at < ?", to)
+		}
+		return db
+	}
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ChallengeRepository interface {
+	// Consume mencatat jti challenge token; false jika jti sudah pernah dipakai
+	Consume(ctx context.Context, jti string, userID uint64, expiresAt time.Time) (bool, error)
+}
+
+type challengeRepository struct {
+	db *gorm.DB
+}
+
+func NewChallengeRepository(db *gorm.DB) ChallengeRepository {
+	return &challengeRepository{db: db}
+}
+
+// Consume implements ChallengeRepository.
+// Primary key jti menjamin hanya satu request yang berhasil mencatat token yang sama.
+func (r *challengeRepository) Consume(ctx context.Context, jti string, userID uint64, expiresAt time.Time) (bool, error) {
+	// Bersihkan catatan kedaluwarsa sekalian, jadi tidak perlu janitor di setiap replica
+	if err := r.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&entity.UsedChallenge{}).Error; err != nil {
+		return false, err
+	}
+
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&entity.UsedChallenge{JTI: jti, UserID: userID, ExpiresAt: expiresAt})
+	return res.RowsAffected == 1, res.Error
+}
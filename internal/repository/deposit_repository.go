@@ -13,6 +13,10 @@ type DepositRepository interface {
 	Create(ctx context.Context, req *entity.Deposit) error
 	FindByTopupID(ctx context.Context, topupID string) (*entity.Deposit, error)
 	Update(ctx context.Context, deposit *entity.Deposit) error
+	// UpdateStatus memindahkan deposit dari status from ke deposit.Status, menambah saldo user
+	// jika status barunya success, dan menulis outbox; semuanya dalam satu transaksi.
+	// Mengembalikan CodeConflict jika status di database sudah bukan from.
+	UpdateStatus(ctx context.Context, deposit *entity.Deposit, from entity.DepositStatus, outbox Outbox) error
 	FindByUserID(ctx context.Context, userID uint64) ([]entity.Deposit, error)
 	FindAll(ctx context.Context) ([]entity.Deposit, error)
 }
@@ -66,3 +70,28 @@ func (d *depositRepository) FindByUserID(ctx context.Context, userID uint64) ([]
 func (d *depositRepository) Update(ctx context.Context, deposit *entity.Deposit) error {
 	return d.db.WithContext(ctx).Save(deposit).Error
 }
+
+// UpdateStatus implements DepositRepository.
+func (d *depositRepository) UpdateStatus(ctx context.Context, deposit *entity.Deposit, from entity.DepositStatus, outbox Outbox) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Kondisi status lama mencegah dua admin meng-credit deposit yang sama
+		res := tx.Model(&entity.Deposit{}).
+			Where("id = ? AND status = ?", deposit.ID, from).
+			Updates(map[string]any{"status": deposit.Status, "updated_at": deposit.UpdatedAt})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return apperror.New(apperror.CodeConflict, "deposit status has changed, please retry", nil)
+		}
+
+		if deposit.Status == entity.DepSuccess {
+			if err := tx.Model(&entity.User{}).
+				Where("id = ?", deposit.UserID).
+				Update("balance", gorm.Expr("balance + ?", deposit.Amount)).Error; err != nil {
+				return err
+			}
+		}
+		return outbox.write(tx)
+	})
+}
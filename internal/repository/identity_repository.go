@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"gorm.io/gorm"
+)
+
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *entity.UserIdentity) error
+	// CreateWithUser menyimpan user baru beserta identitasnya dalam satu transaksi,
+	// sehingga tidak ada user tanpa identitas jika salah satu insert gagal
+	CreateWithUser(ctx context.Context, user *entity.User, identity *entity.UserIdentity) error
+	FindBySubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error)
+	FindByUser(ctx context.Context, userID uint64) ([]entity.UserIdentity, error)
+	Delete(ctx context.Context, userID uint64, provider string) error
+}
+
+type identityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) IdentityRepository {
+	return &identityRepository{db: db}
+}
+
+// Create implements IdentityRepository.
+func (r *identityRepository) Create(ctx context.Context, identity *entity.UserIdentity) error {
+	err := r.db.WithContext(ctx).Create(identity).Error
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return apperror.New(apperror.CodeConflict, identity.Provider+" account is already linked", err)
+	}
+	return err
+}
+
+// CreateWithUser implements IdentityRepository.
+func (r *identityRepository) CreateWithUser(ctx context.Context, user *entity.User, identity *entity.UserIdentity) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var pgErr *pgconn.PgError
+		if err := tx.Create(user).Error; err != nil {
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return apperror.New(apperror.CodeConflict, "user already exist", err)
+			}
+			return err
+		}
+
+		identity.UserID = user.ID
+		if err := tx.Create(identity).Error; err != nil {
+			if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+				return apperror.New(apperror.CodeConflict, identity.Provider+" account is already linked", err)
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// FindBySubject implements IdentityRepository.
+func (r *identityRepository) FindBySubject(ctx context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	var identity entity.UserIdentity
+	err := r.db.WithContext(ctx).Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.ErrNotFound
+	}
+	return &identity, err
+}
+
+// FindByUser implements IdentityRepository.
+func (r *identityRepository) FindByUser(ctx context.Context, userID uint64) ([]entity.UserIdentity, error) {
+	var identities []entity.UserIdentity
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at asc").Find(&identities).Error
+	return identities, err
+}
+
+// Delete implements IdentityRepository.
+func (r *identityRepository) Delete(ctx context.Context, userID uint64, provider string) error {
+	res := r.db.WithContext(ctx).Where("user_id = ? AND provider = ?", userID, provider).Delete(&entity.UserIdentity{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apperror.New(apperror.CodeNotFound, provider+" account is not linked", nil)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoginAttemptRepository interface {
+	// Find mengembalikan nil jika kunci belum pernah gagal
+	Find(ctx context.Context, key string) (*entity.LoginAttempt, error)
+	// RecordFailure menaikkan counter secara atomik; counter dimulai ulang jika kegagalan terakhir lebih lama dari window
+	RecordFailure(ctx context.Context, key string, window time.Duration) (*entity.LoginAttempt, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+type loginAttemptRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+// Find implements LoginAttemptRepository.
+func (r *loginAttemptRepository) Find(ctx context.Context, key string) (*entity.LoginAttempt, error) {
+	var attempt entity.LoginAttempt
+	err := r.db.WithContext(ctx).Where("key = ?", key).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// RecordFailure implements LoginAttemptRepository.
+func (r *loginAttemptRepository) RecordFailure(ctx context.Context, key string, window time.Duration) (*entity.LoginAttempt, error) {
+	now := time.Now()
+	attempt := entity.LoginAttempt{Key: key, Failures: 1, LastFailureAt: now}
+
+	// INSERT ... ON CONFLICT DO UPDATE ... RETURNING: aman dipanggil bersamaan dari beberapa replica
+	err := r.db.WithContext(ctx).
+		Clauses(
+			clause.OnConflict{
+				Columns: []clause.Column{{Name: "key"}},
+				DoUpdates: clause.Assignments(map[string]any{
+					"failures":        gorm.Expr("CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END", now.Add(-window)),
+					"locked_until":    gorm.Expr("CASE WHEN login_attempts.last_failure_at < ? THEN NULL ELSE login_attempts.locked_until END", now.Add(-window)),
+					"last_failure_at": now,
+				}),
+			},
+			clause.Returning{},
+		).
+		Create(&attempt).Error
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+// Lock implements LoginAttemptRepository.
+func (r *loginAttemptRepository) Lock(ctx context.Context, key string, until time.Time) error {
+	return r.db.WithContext(ctx).Model(&entity.LoginAttempt{}).Where("key = ?", key).
+		Update("locked_until", until).Error
+}
+
+// Reset implements LoginAttemptRepository.
+func (r *loginAttemptRepository) Reset(ctx context.Context, key string) error {
+	return r.db.WithContext(ctx).Where("key = ?", key).Delete(&entity.LoginAttempt{}).Error
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, n *entity.Notification) error
+	// ClaimDue mengambil notifikasi yang jatuh tempo dan menundanya selama lease,
+	// sama seperti WebhookRepository.ClaimDue
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.Notification, error)
+	SaveAttempt(ctx context.Context, n *entity.Notification) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// Create implements NotificationRepository.
+func (r *notificationRepository) Create(ctx context.Context, n *entity.Notification) error {
+	return r.db.WithContext(ctx).Create(n).Error
+}
+
+// ClaimDue implements NotificationRepository.
+func (r *notificationRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.Notification, error) {
+	var items []entity.Notification
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entity.NotificationPending, now).
+			Order("next_attempt_at asc").
+			Limit(limit).
+			Find(&items).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+
+		ids := make([]uint64, 0, len(items))
+		for _, n := range items {
+			ids = append(ids, n.ID)
+		}
+		return tx.Model(&entity.Notification{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	return items, err
+}
+
+// SaveAttempt implements NotificationRepository.
+func (r *notificationRepository) SaveAttempt(ctx context.Context, n *entity.Notification) error {
+	return r.db.WithContext(ctx).Model(n).Select(
+		"status", "attempts", "next_attempt_at", "last_attempt_at", "last_error", "sent_at",
+	).Updates(n).Error
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/pkg/hash"
+	"github.com/wildanasyrof/backend-topup/pkg/oauth"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type oauthStateRepository struct {
+	db  *gorm.DB
+	ttl time.Duration
+}
+
+// NewOAuthStateRepository returns an oauth.DevStore shared by every API instance through Postgres.
+func NewOAuthStateRepository(db *gorm.DB, ttl time.Duration) oauth.DevStore {
+	return &oauthStateRepository{db: db, ttl: ttl}
+}
+
+// NewStateAndPKCE implements oauth.DevStore.
+func (r *oauthStateRepository) NewStateAndPKCE(ctx context.Context, provider string, linkUserID uint64) (string, string, error) {
+	state, verifier, err := oauth.GenerateStateAndVerifier()
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now()
+	// Bersihkan state kedaluwarsa sekalian, jadi tidak perlu janitor di setiap replica
+	if err := r.db.WithContext(ctx).Where("expires_at < ?", now).Delete(&entity.OAuthState{}).Error; err != nil {
+		return "", "", err
+	}
+
+	row := &entity.OAuthState{
+		StateHash:  hash.HashToken(state),
+		Provider:   provider,
+		Verifier:   verifier,
+		LinkUserID: linkUserID,
+		ExpiresAt:  now.Add(r.ttl),
+	}
+	if err := r.db.WithContext(ctx).Create(row).Error; err != nil {
+		return "", "", err
+	}
+	return state, verifier, nil
+}
+
+// Consume implements oauth.DevStore.
+// DELETE ... RETURNING guarantees only one concurrent callback gets the row.
+func (r *oauthStateRepository) Consume(ctx context.Context, state string) (*oauth.PendingAuth, error) {
+	var rows []entity.OAuthState
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Returning{}).
+		Where("state_hash = ?", hash.HashToken(state)).
+		Delete(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 || time.Now().After(rows[0].ExpiresAt) {
+		return nil, oauth.ErrStateNotFound
+	}
+
+	return &oauth.PendingAuth{
+		Provider:   rows[0].Provider,
+		Verifier:   rows[0].Verifier,
+		LinkUserID: rows[0].LinkUserID,
+	}, nil
+}
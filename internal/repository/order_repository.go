@@ -2,19 +2,25 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
 	"gorm.io/gorm"
 )
 
 type OrderRepository interface {
-	Create(ctx context.Context, req *entity.Order) error
+	// Create menyimpan order baru beserta outbox-nya dalam satu transaksi
+	Create(ctx context.Context, req *entity.Order, outbox Outbox) error
 	FindAll(ctx context.Context) ([]*entity.Order, error)
 	FindByID(ctx context.Context, id int) (*entity.Order, error)
 	FindByRef(ctx context.Context, ref string) (*entity.Order, error)
 	FindByUserID(ctx context.Context, userId int64) ([]*entity.Order, error)
 	Update(ctx context.Context, req *entity.Order) error
+	// UpdateStatus menyimpan perubahan status order beserta outbox-nya dalam satu transaksi
+	UpdateStatus(ctx context.Context, req *entity.Order, outbox Outbox) error
 	Delete(ctx context.Context, id int) error
+	// CountStuck menghitung order berstatus processing yang tidak berubah sejak before
+	CountStuck(ctx context.Context, before time.Time) (int64, error)
 }
 
 type orderRepository struct {
@@ -26,8 +32,22 @@ func NewOrderRepository(db *gorm.DB) OrderRepository {
 }
 
 // Create implements OrderRepository.
-func (o *orderRepository) Create(ctx context.Context, req *entity.Order) error {
-	return o.db.WithContext(ctx).Create(req).Error
+func (o *orderRepository) Create(ctx context.Context, req *entity.Order, outbox Outbox) error {
+	return o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(req).Error; err != nil {
+			return err
+		}
+		return outbox.write(tx)
+	})
+}
+
+// CountStuck implements OrderRepository.
+func (o *orderRepository) CountStuck(ctx context.Context, before time.Time) (int64, error) {
+	var n int64
+	err := o.db.WithContext(ctx).Model(&entity.Order{}).
+		Where("status = ? AND updated_at < ?", entity.StatusProcessing, before).
+		Count(&n).Error
+	return n, err
 }
 
 // Delete implements OrderRepository.
@@ -75,3 +95,13 @@ func (o *orderRepository) FindByUserID(ctx context.Context, userId int64) ([]*en
 func (o *orderRepository) Update(ctx context.Context, req *entity.Order) error {
 	return o.db.WithContext(ctx).Save(req).Error
 }
+
+// UpdateStatus implements OrderRepository.
+func (o *orderRepository) UpdateStatus(ctx context.Context, req *entity.Order, outbox Outbox) error {
+	return o.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(req).Error; err != nil {
+			return err
+		}
+		return outbox.write(tx)
+	})
+}
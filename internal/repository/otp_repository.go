@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"gorm.io/gorm"
+)
+
+type OTPRepository interface {
+	Create(ctx context.Context, otp *entity.UserOTP) error
+	FindLatestActive(ctx context.Context, userID uint64, purpose entity.OTPPurpose) (*entity.UserOTP, error)
+	CountSince(ctx context.Context, userID uint64, purpose entity.OTPPurpose, since time.Time) (int64, error)
+	// RecordAttempt menambah attempts secara atomik; false jika batas max sudah tercapai
+	RecordAttempt(ctx context.Context, id uint64, max int) (bool, error)
+	// Consume menandai kode sudah dipakai; false jika request lain sudah memakainya lebih dulu
+	Consume(ctx context.Context, id uint64) (bool, error)
+	InvalidateAll(ctx context.Context, userID uint64, purpose entity.OTPPurpose) error
+}
+
+type otpRepository struct {
+	db *gorm.DB
+}
+
+func NewOTPRepository(db *gorm.DB) OTPRepository {
+	return &otpRepository{db: db}
+}
+
+// Create implements OTPRepository.
+func (r *otpRepository) Create(ctx context.Context, otp *entity.UserOTP) error {
+	return r.db.WithContext(ctx).Create(otp).Error
+}
+
+// FindLatestActive implements OTPRepository.
+func (r *otpRepository) FindLatestActive(ctx context.Context, userID uint64, purpose entity.OTPPurpose) (*entity.UserOTP, error) {
+	var otp entity.UserOTP
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND purpose = ? AND consumed_at IS NULL AND expires_at > ?", userID, purpose, time.Now()).
+		Order("created_at desc").
+		First(&otp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.ErrNotFound
+	}
+	return &otp, err
+}
+
+// CountSince implements OTPRepository.
+func (r *otpRepository) CountSince(ctx context.Context, userID uint64, purpose entity.OTPPurpose, since time.Time) (int64, error) {
+	var total int64
+	err := r.db.WithContext(ctx).Model(&entity.UserOTP{}).
+		Where("user_id = ? AND purpose = ? AND created_at > ?", userID, purpose, since).
+		Count(&total).Error
+	return total, err
+}
+
+// RecordAttempt implements OTPRepository.
+func (r *otpRepository) RecordAttempt(ctx context.Context, id uint64, max int) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&entity.UserOTP{}).
+		Where("id = ? AND attempts < ?", id, max).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return res.RowsAffected == 1, res.Error
+}
+
+// Consume implements OTPRepository.
+func (r *otpRepository) Consume(ctx context.Context, id uint64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&entity.UserOTP{}).
+		Where("id = ? AND consumed_at IS NULL", id).
+		Update("consumed_at", time.Now())
+	return res.RowsAffected == 1, res.Error
+}
+
+// InvalidateAll implements OTPRepository.
+func (r *otpRepository) InvalidateAll(ctx context.Context, userID uint64, purpose entity.OTPPurpose) error {
+	return r.db.WithContext(ctx).Model(&entity.UserOTP{}).
+		Where("user_id = ? AND purpose = ? AND consumed_at IS NULL", userID, purpose).
+		Update("consumed_at", time.Now()).Error
+}
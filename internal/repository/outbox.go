@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"gorm.io/gorm"
+)
+
+// Outbox berisi pesan yang ditulis dalam transaksi yang sama dengan perubahan datanya,
+// jadi event tidak hilang kalau proses mati atau database error di antara keduanya.
+// Dispatcher webhook dan notifikasi yang mengirimnya di background.
+type Outbox struct {
+	Webhook       *WebhookEvent
+	Notifications []entity.Notification
+}
+
+// WebhookEvent di-fan-out menjadi satu delivery per endpoint UserID yang berlangganan Event
+type WebhookEvent struct {
+	UserID    uint64
+	ID        string
+	Event     string
+	Payload   string
+	CreatedAt time.Time
+}
+
+func (o Outbox) write(tx *gorm.DB) error {
+	if len(o.Notifications) > 0 {
+		if err := tx.Create(&o.Notifications).Error; err != nil {
+			return err
+		}
+	}
+	if o.Webhook == nil {
+		return nil
+	}
+
+	var endpoints []entity.WebhookEndpoint
+	if err := subscribers(tx, o.Webhook.UserID, o.Webhook.Event).Find(&endpoints).Error; err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	deliveries := make([]entity.WebhookDelivery, 0, len(endpoints))
+	for _, e := range endpoints {
+		deliveries = append(deliveries, entity.WebhookDelivery{
+			EndpointID:    e.ID,
+			UserID:        o.Webhook.UserID,
+			EventID:       o.Webhook.ID,
+			Event:         o.Webhook.Event,
+			Payload:       o.Webhook.Payload,
+			Status:        entity.WebhookPending,
+			NextAttemptAt: o.Webhook.CreatedAt,
+		})
+	}
+	return tx.Create(&deliveries).Error
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/pkg/ratelimit"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type rateLimitRepository struct {
+	db *gorm.DB
+}
+
+// NewRateLimitRepository returns a ratelimit.Store shared by every API instance through Postgres.
+func NewRateLimitRepository(db *gorm.DB) ratelimit.Store {
+	return &rateLimitRepository{db: db}
+}
+
+// Take implements ratelimit.Store.
+// Baris dikunci dengan SELECT ... FOR UPDATE supaya request paralel dari replica lain antre di key yang sama.
+func (r *rateLimitRepository) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	var res ratelimit.Result
+	created := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Bucket baru dibuat penuh; DO NOTHING jika replica lain sudah membuatnya
+		insert := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.RateLimitBucket{
+			Key:       key,
+			Tokens:    float64(limit.Burst),
+			UpdatedAt: now,
+			ExpiresAt: now,
+		})
+		if insert.Error != nil {
+			return insert.Error
+		}
+		created = insert.RowsAffected == 1
+
+		var row entity.RateLimitBucket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ?", key).First(&row).Error; err != nil {
+			return err
+		}
+
+		bucket := ratelimit.Bucket{Tokens: row.Tokens, UpdatedAt: row.UpdatedAt}
+		if now.After(row.ExpiresAt) {
+			// Sudah idle lebih lama dari TTL, artinya bucket pasti penuh
+			bucket = ratelimit.Bucket{}
+		}
+		res = ratelimit.Advance(&bucket, limit, now)
+
+		return tx.Model(&entity.RateLimitBucket{}).Where("key = ?", key).Updates(map[string]any{
+			"tokens":     bucket.Tokens,
+			"updated_at": bucket.UpdatedAt,
+			"expires_at": now.Add(ratelimit.TTL(limit)),
+		}).Error
+	})
+	if err != nil {
+		return res, err
+	}
+
+	// Bersihkan bucket kedaluwarsa hanya saat ada key baru, di luar transaksi agar tidak menahan lock.
+	// Best effort: gagal dibersihkan tidak membatalkan hasil Take
+	if created {
+		r.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&entity.RateLimitBucket{})
+	}
+	return res, nil
+}
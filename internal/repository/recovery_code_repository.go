@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository interface {
+	// Replace menghapus semua kode lama user dan menyimpan hash kode baru
+	Replace(ctx context.Context, userID uint64, hashes []string) error
+	// Use menandai kode terpakai; false jika kode tidak ada atau sudah dipakai
+	Use(ctx context.Context, userID uint64, hash string) (bool, error)
+	DeleteAll(ctx context.Context, userID uint64) error
+}
+
+type recoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) RecoveryCodeRepository {
+	return &recoveryCodeRepository{db: db}
+}
+
+// Replace implements RecoveryCodeRepository.
+func (r *recoveryCodeRepository) Replace(ctx context.Context, userID uint64, hashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]entity.RecoveryCode, 0, len(hashes))
+		for _, h := range hashes {
+			codes = append(codes, entity.RecoveryCode{UserID: userID, CodeHash: h})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Use implements RecoveryCodeRepository.
+func (r *recoveryCodeRepository) Use(ctx context.Context, userID uint64, hash string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&entity.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	return res.RowsAffected == 1, res.Error
+}
+
+// DeleteAll implements RecoveryCodeRepository.
+func (r *recoveryCodeRepository) DeleteAll(ctx context.Context, userID uint64) error {
+	return r.db.WithContext(ctx).Where("user_id = ?", userID).Delete(&entity.RecoveryCode{}).Error
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	FindAll(ctx context.Context) ([]entity.Role, error)
+	FindByID(ctx context.Context, id uint64) (*entity.Role, error)
+	FindByName(ctx context.Context, name string) (*entity.Role, error)
+	Create(ctx context.Context, role *entity.Role) error
+	// Update menyimpan deskripsi dan mengganti seluruh permission role
+	Update(ctx context.Context, role *entity.Role) error
+	Delete(ctx context.Context, id uint64) error
+	CountUsers(ctx context.Context, name string) (int64, error)
+	// PermissionMap mengembalikan permission setiap role, dipakai sebagai cache oleh RoleService
+	PermissionMap(ctx context.Context) (map[string][]string, error)
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// FindAll implements RoleRepository.
+func (r *roleRepository) FindAll(ctx context.Context) ([]entity.Role, error) {
+	var roles []entity.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Order("id asc").Find(&roles).Error
+	return roles, err
+}
+
+// FindByID implements RoleRepository.
+func (r *roleRepository) FindByID(ctx context.Context, id uint64) (*entity.Role, error) {
+	var role entity.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Where("id = ?", id).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.ErrNotFound
+	}
+	return &role, err
+}
+
+// FindByName implements RoleRepository.
+func (r *roleRepository) FindByName(ctx context.Context, name string) (*entity.Role, error) {
+	var role entity.Role
+	err := r.db.WithContext(ctx).Preload("Permissions").Where("name = ?", name).First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.ErrNotFound
+	}
+	return &role, err
+}
+
+// Create implements RoleRepository.
+func (r *roleRepository) Create(ctx context.Context, role *entity.Role) error {
+	err := r.db.WithContext(ctx).Create(role).Error
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return apperror.New(apperror.CodeConflict, "role "+role.Name+" already exists", err)
+	}
+	return err
+}
+
+// Update implements RoleRepository.
+func (r *roleRepository) Update(ctx context.Context, role *entity.Role) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(role).Updates(map[string]any{
+			"description": role.Description,
+			"updated_at":  time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", role.ID).Delete(&entity.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(role.Permissions) == 0 {
+			return nil
+		}
+		return tx.Create(&role.Permissions).Error
+	})
+}
+
+// Delete implements RoleRepository.
+func (r *roleRepository) Delete(ctx context.Context, id uint64) error {
+	res := r.db.WithContext(ctx).Delete(&entity.Role{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+// CountUsers implements RoleRepository.
+func (r *roleRepository) CountUsers(ctx context.Context, name string) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&entity.User{}).Where("role = ?", name).Count(&n).Error
+	return n, err
+}
+
+// PermissionMap implements RoleRepository.
+func (r *roleRepository) PermissionMap(ctx context.Context) (map[string][]string, error) {
+	var rows []struct {
+		Name       string
+		Permission string
+	}
+	err := r.db.WithContext(ctx).Table("role_permissions").
+		Select("roles.name, role_permissions.permission").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string][]string)
+	for _, row := range rows {
+		out[row.Name] = append(out[row.Name], row.Permission)
+	}
+	return out, nil
+}
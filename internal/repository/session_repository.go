@@ -11,12 +11,29 @@ import (
 	"gorm.io/gorm"
 )
 
+// SessionAccess adalah data minimal untuk memeriksa access token di setiap request.
+type SessionAccess struct {
+	UserID       uint64
+	IsRevoked    bool
+	ExpiresAt    time.Time
+	TokenVersion int
+	IsSuspended  bool
+}
+
 type SessionRepository interface {
 	Create(ctx context.Context, session *entity.UserSession) error
 	FindByID(ctx context.Context, id uuid.UUID) (*entity.UserSession, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	FindByUserID(ctx context.Context, userID uint64) ([]*entity.UserSession, error)
 	Revoke(ctx context.Context, id uuid.UUID) error
+	RevokeAllByUserID(ctx context.Context, userID uint64) error
+	// MarkRotated menandai sesi sudah dipakai; false jika sesi sudah dirotasi/di-revoke lebih dulu
+	MarkRotated(ctx context.Context, id, replacedBy uuid.UUID) (bool, error)
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	// RevokeOtherFamilies me-revoke semua sesi user kecuali family keepFamilyID
+	RevokeOtherFamilies(ctx context.Context, userID uint64, keepFamilyID uuid.UUID) error
+	DeleteExpiredByUserID(ctx context.Context, userID uint64) error
+	FindAccess(ctx context.Context, id uuid.UUID) (*SessionAccess, error)
 }
 
 type sessionRepository struct {
@@ -50,9 +67,9 @@ func (r *sessionRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 func (r *sessionRepository) FindByUserID(ctx context.Context, userID uint64) ([]*entity.UserSession, error) {
 	var sessions []*entity.UserSession
-	// Hanya ambil sesi yang belum di-revoke dan belum kedaluwarsa
+	// Hanya ambil sesi aktif: belum di-revoke, belum dirotasi, dan belum kedaluwarsa
 	err := r.db.WithContext(ctx).
-		Where("user_id = ? AND is_revoked = ? AND expires_at > ?", userID, false, time.Now()).
+		Where("user_id = ? AND is_revoked = ? AND rotated_at IS NULL AND expires_at > ?", userID, false, time.Now()).
 		Order("created_at desc").
 		Find(&sessions).Error
 	return sessions, err
@@ -62,3 +79,51 @@ func (r *sessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
 	return r.db.WithContext(ctx).Model(&entity.UserSession{}).Where("id = ?", id).
 		Update("is_revoked", true).Error
 }
+
+func (r *sessionRepository) RevokeAllByUserID(ctx context.Context, userID uint64) error {
+	return r.db.WithContext(ctx).Model(&entity.UserSession{}).Where("user_id = ?", userID).
+		Update("is_revoked", true).Error
+}
+
+func (r *sessionRepository) MarkRotated(ctx context.Context, id, replacedBy uuid.UUID) (bool, error) {
+	// Update bersyarat agar dua refresh bersamaan dengan token yang sama tidak sama-sama lolos
+	res := r.db.WithContext(ctx).Model(&entity.UserSession{}).
+		Where("id = ? AND rotated_at IS NULL AND is_revoked = ?", id, false).
+		Updates(map[string]any{"rotated_at": time.Now(), "replaced_by": replacedBy})
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *sessionRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	// id = familyID ikut mencakup sesi lama yang dibuat sebelum kolom family_id ada
+	return r.db.WithContext(ctx).Model(&entity.UserSession{}).Where("family_id = ? OR id = ?", familyID, familyID).
+		Update("is_revoked", true).Error
+}
+
+func (r *sessionRepository) RevokeOtherFamilies(ctx context.Context, userID uint64, keepFamilyID uuid.UUID) error {
+	// id <> keepFamilyID menjaga sesi lama yang family_id-nya belum terisi, sama seperti RevokeFamily
+	return r.db.WithContext(ctx).Model(&entity.UserSession{}).
+		Where("user_id = ? AND family_id IS DISTINCT FROM ? AND id <> ?", userID, keepFamilyID, keepFamilyID).
+		Update("is_revoked", true).Error
+}
+
+func (r *sessionRepository) DeleteExpiredByUserID(ctx context.Context, userID uint64) error {
+	return r.db.WithContext(ctx).Where("user_id = ? AND expires_at < ?", userID, time.Now()).
+		Delete(&entity.UserSession{}).Error
+}
+
+func (r *sessionRepository) FindAccess(ctx context.Context, id uuid.UUID) (*SessionAccess, error) {
+	var access SessionAccess
+	// Satu query lewat primary key, dipanggil oleh middleware Auth saat AccessCache tidak punya entry segar
+	err := r.db.WithContext(ctx).Table("user_sessions AS s").
+		Select("s.user_id, s.is_revoked, s.expires_at, u.token_version, u.suspended_at IS NOT NULL AS is_suspended").
+		Joins("JOIN users u ON u.id = s.user_id").
+		Where("s.id = ?", id).
+		Take(&access).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.ErrUnauthorized
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &access, nil
+}
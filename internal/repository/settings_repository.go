@@ -16,6 +16,8 @@ type SettingsRepository interface {
 	Delete(ctx context.Context, settings *entity.Settings) error
 	FindAll(ctx context.Context, q dto.SettingsListQuery) (items []*entity.Settings, meta pagination.Meta, err error)
 	FindByID(ctx context.Context, id int) (*entity.Settings, error)
+	// List mengembalikan semua baris tanpa paginasi, dipakai untuk mengisi cache setting
+	List(ctx context.Context) ([]entity.Settings, error)
 }
 
 type settingsRepository struct {
@@ -96,6 +98,13 @@ func (s *settingsRepository) FindByName(ctx context.Context, name string) (*enti
 	return &settings, nil
 }
 
+// List implements SettingsRepository.
+func (s *settingsRepository) List(ctx context.Context) ([]entity.Settings, error) {
+	var items []entity.Settings
+	err := s.db.WithContext(ctx).Find(&items).Error
+	return items, err
+}
+
 // Update implements SettingsRepository.
 func (s *settingsRepository) Update(ctx context.Context, settings *entity.Settings) error {
 	return s.db.WithContext(ctx).Save(settings).Error
@@ -15,7 +15,12 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
 	GetByID(ctx context.Context, id uint64) (*entity.User, error)
 	FindByGoogleID(ctx context.Context, id string) (*entity.User, error)
+	// FindByWhatsapp hanya mencocokkan nomor yang sudah terverifikasi; phone harus sudah dinormalisasi
+	FindByWhatsapp(ctx context.Context, phone string) (*entity.User, error)
+	// Update menyimpan semua kolom kecuali totp_last_step, yang hanya diubah lewat AdvanceTOTPStep
 	Update(ctx context.Context, user *entity.User) error
+	// AdvanceTOTPStep menyimpan step TOTP terakhir yang dipakai; false jika step tsb (atau yang lebih baru) sudah dipakai
+	AdvanceTOTPStep(ctx context.Context, userID uint64, step int64) (bool, error)
 	Destroy(ctx context.Context, id uint64) error
 }
 
@@ -72,7 +77,28 @@ func (u *userRepository) Store(ctx context.Context, user *entity.User) error {
 
 // Update implements UserRepository.
 func (u *userRepository) Update(ctx context.Context, user *entity.User) error {
-	return u.db.WithContext(ctx).Save(user).Error
+	// totp_last_step dan balance tidak ikut disimpan: keduanya diubah dengan update atomik
+	// (AdvanceTOTPStep, kredit deposit), dan user yang dibaca sebelumnya akan menimpanya dengan nilai lama
+	err := u.db.WithContext(ctx).Omit("totp_last_step", "balance").Save(user).Error
+
+	// Nomor WhatsApp terverifikasi dijaga unique index parsial ux_users_whatsapp_verified
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		if pgErr.ConstraintName == "ux_users_whatsapp_verified" {
+			return apperror.New(apperror.CodeConflict, "whatsapp number is already used by another account", err)
+		}
+		return apperror.New(apperror.CodeConflict, "user already exist", err)
+	}
+
+	return err
+}
+
+// AdvanceTOTPStep implements UserRepository.
+func (u *userRepository) AdvanceTOTPStep(ctx context.Context, userID uint64, step int64) (bool, error) {
+	res := u.db.WithContext(ctx).Model(&entity.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	return res.RowsAffected == 1, res.Error
 }
 
 // FindByGoogleID implements UserRepository.
@@ -82,3 +108,13 @@ func (u *userRepository) FindByGoogleID(ctx context.Context, id string) (*entity
 
 	return &user, err
 }
+
+// FindByWhatsapp implements UserRepository.
+func (u *userRepository) FindByWhatsapp(ctx context.Context, phone string) (*entity.User, error) {
+	var user entity.User
+	err := u.db.WithContext(ctx).Where("whatsapp = ? AND whatsapp_verified_at IS NOT NULL", phone).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, apperror.ErrNotFound
+	}
+	return &user, err
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/pagination"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *entity.WebhookEndpoint) error
+	FindEndpointsByUser(ctx context.Context, userID uint64) ([]entity.WebhookEndpoint, error)
+	CountEndpoints(ctx context.Context, userID uint64) (int64, error)
+	DeleteEndpoint(ctx context.Context, userID, id uint64) error
+
+	// Delivery baru ditulis lewat Outbox oleh repository yang mengubah status order/deposit.
+	// ClaimDue mengambil delivery yang jatuh tempo dan menundanya selama lease,
+	// sehingga dispatcher di replica lain tidak mengirim baris yang sama
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error)
+	SaveAttempt(ctx context.Context, delivery *entity.WebhookDelivery) error
+	FindDeliveries(ctx context.Context, q dto.WebhookDeliveryListQuery) ([]entity.WebhookDelivery, pagination.Meta, error)
+	// Resend menjadwalkan ulang delivery untuk segera dikirim dengan jatah retry baru
+	Resend(ctx context.Context, id uint64) error
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// CreateEndpoint implements WebhookRepository.
+func (r *webhookRepository) CreateEndpoint(ctx context.Context, endpoint *entity.WebhookEndpoint) error {
+	return r.db.WithContext(ctx).Create(endpoint).Error
+}
+
+// FindEndpointsByUser implements WebhookRepository.
+func (r *webhookRepository) FindEndpointsByUser(ctx context.Context, userID uint64) ([]entity.WebhookEndpoint, error) {
+	var endpoints []entity.WebhookEndpoint
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at asc").Find(&endpoints).Error
+	return endpoints, err
+}
+
+// CountEndpoints implements WebhookRepository.
+func (r *webhookRepository) CountEndpoints(ctx context.Context, userID uint64) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&entity.WebhookEndpoint{}).Where("user_id = ?", userID).Count(&n).Error
+	return n, err
+}
+
+// DeleteEndpoint implements WebhookRepository.
+func (r *webhookRepository) DeleteEndpoint(ctx context.Context, userID, id uint64) error {
+	res := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&entity.WebhookEndpoint{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return apperror.ErrNotFound
+	}
+	return nil
+}
+
+// subscribers memfilter endpoint milik user yang berlangganan event (Events kosong berarti semua event)
+func subscribers(db *gorm.DB, userID uint64, event string) *gorm.DB {
+	return db.Where("user_id = ?", userID).
+		Where("events = '' OR ',' || events || ',' LIKE ?", "%,"+event+",%")
+}
+
+// ClaimDue implements WebhookRepository.
+// FOR UPDATE SKIP LOCKED membuat beberapa dispatcher bisa berjalan bersamaan tanpa saling menunggu.
+func (r *webhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	var ids []uint64
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&entity.WebhookDelivery{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entity.WebhookPending, now).
+			Order("next_attempt_at asc").
+			Limit(limit).
+			Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&entity.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	var deliveries []entity.WebhookDelivery
+	err = r.db.WithContext(ctx).Preload("Endpoint").Where("id IN ?", ids).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// SaveAttempt implements WebhookRepository.
+func (r *webhookRepository) SaveAttempt(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	return r.db.WithContext(ctx).Model(delivery).Select(
+		"status", "attempts", "next_attempt_at", "last_attempt_at", "response_status", "last_error", "delivered_at",
+	).Updates(delivery).Error
+}
+
+// FindDeliveries implements WebhookRepository.
+func (r *webhookRepository) FindDeliveries(ctx context.Context, q dto.WebhookDeliveryListQuery) (items []entity.WebhookDelivery, meta pagination.Meta, err error) {
+	q.Normalize()
+
+	filtered := r.db.WithContext(ctx).Model(&entity.WebhookDelivery{})
+	if q.Status != "" {
+		filtered = filtered.Where("status = ?", q.Status)
+	}
+	if q.EndpointID != nil {
+		filtered = filtered.Where("endpoint_id = ?", *q.EndpointID)
+	}
+	if q.UserID != nil {
+		filtered = filtered.Where("user_id = ?", *q.UserID)
+	}
+
+	var total int64
+	if err = filtered.Count(&total).Error; err != nil {
+		return
+	}
+
+	allowedSort := map[string]struct{}{"created_at": {}, "next_attempt_at": {}, "id": {}}
+	if err = filtered.
+		Scopes(
+			func(db *gorm.DB) *gorm.DB { return pagination.ScopeSort(db, q.Sort, allowedSort) },
+			func(db *gorm.DB) *gorm.DB { return pagination.ScopePaginate(db, q.Page, q.Limit) },
+		).
+		Find(&items).Error; err != nil {
+		return
+	}
+
+	meta = pagination.CalcMeta(int(total), q.Page, q.Limit)
+	return
+}
+
+// Resend implements WebhookRepository.
+func (r *webhookRepository) Resend(ctx context.Context, id uint64) error {
+	var delivery entity.WebhookDelivery
+	if err := r.db.WithContext(ctx).Select("id").Where("id = ?", id).First(&delivery).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apperror.ErrNotFound
+		}
+		return err
+	}
+
+	return r.db.WithContext(ctx).Model(&delivery).Updates(map[string]any{
+		"status":          entity.WebhookPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}).Error
+}
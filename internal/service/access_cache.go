@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+)
+
+const (
+	// sessionAccessTTL hanya batas atas untuk perubahan yang tidak lewat InvalidateUser (mis. edit langsung
+	// di database); revoke/suspend/ganti role disebarkan ke semua replica lewat AccessInvalidationRepository
+	sessionAccessTTL = 15 * time.Second
+	// accessPublishTimeout membatasi NOTIFY agar request yang sudah selesai tidak tertahan
+	accessPublishTimeout = 5 * time.Second
+	accessListenRetryMin = time.Second
+	accessListenRetryMax = 30 * time.Second
+)
+
+// AccessCache menyimpan hasil lookup sesi untuk CheckAccess sebentar agar middleware Auth
+// tidak query database di setiap request.
+//
+// Dengan AccessInvalidationRepository, cache hanya dipakai selama Run terhubung ke channel invalidasi:
+// sebelum terhubung dan selama koneksi putus setiap request membaca database, sehingga revoke di
+// replica lain tidak pernah terlewat diam-diam. Tanpa repository (nil) invalidasi hanya berlaku di
+// proses ini, cukup untuk satu instance dan test.
+type AccessCache interface {
+	// Access mengembalikan entry yang masih segar, atau memanggil load lalu menyimpan hasilnya.
+	// Error dari load tidak di-cache.
+	Access(sessionID uuid.UUID, load func() (*repository.SessionAccess, error)) (*repository.SessionAccess, error)
+	// InvalidateUser membuang semua entry milik user di semua replica; wajib dipanggil setelah sesi
+	// di-revoke, user di-suspend/unsuspend atau role-nya berubah
+	InvalidateUser(ctx context.Context, userID uint64)
+	// Run menerima invalidasi dari replica lain sampai ctx dibatalkan, menyambung ulang jika koneksi putus
+	Run(ctx context.Context)
+}
+
+type accessEntry struct {
+	access    repository.SessionAccess
+	expiresAt time.Time
+}
+
+type accessCache struct {
+	ttl    time.Duration
+	shared repository.AccessInvalidationRepository
+	logger logger.Logger
+
+	mu      sync.RWMutex
+	entries map[uuid.UUID]accessEntry
+	// generation naik di setiap invalidasi; hasil load yang dimulai sebelumnya tidak disimpan
+	// agar data lama tidak masuk lagi setelah revoke
+	generation uint64
+	lastSweep  time.Time
+	// listening true selama LISTEN aktif; selalu true jika shared nil
+	listening bool
+}
+
+func NewAccessCache(shared repository.AccessInvalidationRepository, logger logger.Logger) AccessCache {
+	c := newAccessCache(sessionAccessTTL)
+	c.shared, c.logger = shared, logger
+	c.listening = shared == nil
+	return c
+}
+
+func newAccessCache(ttl time.Duration) *accessCache {
+	return &accessCache{ttl: ttl, entries: map[uuid.UUID]accessEntry{}, lastSweep: time.Now(), listening: true}
+}
+
+func (c *accessCache) Access(sessionID uuid.UUID, load func() (*repository.SessionAccess, error)) (*repository.SessionAccess, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[sessionID]
+	generation, listening := c.generation, c.listening
+	c.mu.RUnlock()
+	if ok && listening && time.Now().Before(entry.expiresAt) {
+		access := entry.access
+		return &access, nil
+	}
+
+	access, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation != generation || !c.listening {
+		return access, nil
+	}
+	// Entry kedaluwarsa dibuang sesekali agar map tidak tumbuh terus oleh sesi yang sudah tidak aktif
+	if now.Sub(c.lastSweep) > c.ttl {
+		for id, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, id)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[sessionID] = accessEntry{access: *access, expiresAt: now.Add(c.ttl)}
+	return access, nil
+}
+
+func (c *accessCache) InvalidateUser(ctx context.Context, userID uint64) {
+	c.drop(userID)
+	if c.shared == nil {
+		return
+	}
+
+	publishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), accessPublishTimeout)
+	defer cancel()
+	if err := c.shared.Publish(publishCtx, userID); err != nil {
+		// Replica lain tetap memakai entry lamanya sampai kedaluwarsa (paling lama sessionAccessTTL)
+		c.logger.Error(err, "failed to publish access invalidation")
+	}
+}
+
+// drop membuang entry milik user di proses ini
+func (c *accessCache) drop(userID uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for id, entry := range c.entries {
+		if entry.access.UserID == userID {
+			delete(c.entries, id)
+		}
+	}
+}
+
+// setListening mengosongkan cache di setiap perubahan status: invalidasi yang terkirim selama
+// koneksi putus tidak akan pernah diterima
+func (c *accessCache) setListening(listening bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries = map[uuid.UUID]accessEntry{}
+	c.listening = listening
+}
+
+func (c *accessCache) Run(ctx context.Context) {
+	if c.shared == nil {
+		return
+	}
+
+	retry := accessListenRetryMin
+	for {
+		connected := false
+		err := c.shared.Listen(ctx, func() {
+			connected = true
+			c.setListening(true)
+		}, c.drop)
+		c.setListening(false)
+		if ctx.Err() != nil {
+			return
+		}
+		c.logger.Error(err, "access invalidation listener disconnected, session checks bypass the cache until it reconnects")
+
+		if connected {
+			retry = accessListenRetryMin
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		retry = min(retry*2, accessListenRetryMax)
+	}
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/secretbox"
+	"github.com/wildanasyrof/backend-topup/pkg/signature"
+)
+
+const (
+	apiKeyPrefix        = "tk_"
+	apiSecretPrefix     = "sk_"
+	apiKeyMaxActive     = 10
+	apiKeyTouchInterval = time.Minute // last_used_at cukup diperbarui sekali per menit
+	// apiKeyMaxSkew adalah selisih maksimal X-Timestamp dengan jam server; signature dicatat
+	// selama jendela ini sehingga request yang sama tidak bisa dikirim ulang
+	apiKeyMaxSkew = 5 * time.Minute
+)
+
+var errInvalidAPIKey = apperror.New(apperror.CodeUnauthorized, "invalid api key", nil)
+
+// APIKeyService mengelola API key H2H milik user.
+type APIKeyService interface {
+	Create(ctx context.Context, userID uint64, req *dto.CreateAPIKeyRequest) (*dto.APIKeyCreatedResponse, error)
+	List(ctx context.Context, userID uint64) ([]dto.APIKeyResponse, error)
+	Revoke(ctx context.Context, userID, id uint64) error
+	// AuthenticateAPIKey dipakai middleware APIKeyAuth: memeriksa timestamp, signature, replay,
+	// status key dan IP allowlist, lalu mengembalikan key beserta User pemiliknya
+	AuthenticateAPIKey(ctx context.Context, req *dto.SignedAPIRequest) (*entity.APIKey, error)
+}
+
+type apiKeyService struct {
+	repo    repository.APIKeyRepository
+	secrets secretbox.Box
+	logger  logger.Logger
+}
+
+// NewAPIKeyService: secrets mengenkripsi signing secret sebelum disimpan (config security.encryption_key).
+func NewAPIKeyService(repo repository.APIKeyRepository, secrets secretbox.Box, logger logger.Logger) APIKeyService {
+	return &apiKeyService{repo: repo, secrets: secrets, logger: logger}
+}
+
+// Create implements APIKeyService.
+func (s *apiKeyService) Create(ctx context.Context, userID uint64, req *dto.CreateAPIKeyRequest) (*dto.APIKeyCreatedResponse, error) {
+	active, err := s.repo.CountActive(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if active >= apiKeyMaxActive {
+		return nil, apperror.New(apperror.CodeConflict, "maximum number of active api keys reached", nil)
+	}
+
+	keyID, err := randomToken(apiKeyPrefix, 12)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternal, "failed to generate api key", err)
+	}
+	secret, err := randomToken(apiSecretPrefix, 32)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternal, "failed to generate api key", err)
+	}
+	sealed, err := s.secrets.Seal(secret)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternal, "failed to store api key", err)
+	}
+
+	key := &entity.APIKey{
+		UserID:        userID,
+		Name:          req.Name,
+		KeyID:         keyID,
+		SigningSecret: sealed,
+		AllowedIPs:    strings.Join(req.AllowedIPs, ","),
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	return &dto.APIKeyCreatedResponse{APIKeyResponse: toAPIKeyResponse(key), Secret: secret}, nil
+}
+
+// List implements APIKeyService.
+func (s *apiKeyService) List(ctx context.Context, userID uint64) ([]dto.APIKeyResponse, error) {
+	keys, err := s.repo.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		out = append(out, toAPIKeyResponse(&keys[i]))
+	}
+	return out, nil
+}
+
+// Revoke implements APIKeyService.
+func (s *apiKeyService) Revoke(ctx context.Context, userID, id uint64) error {
+	return s.repo.Revoke(ctx, userID, id)
+}
+
+// AuthenticateAPIKey implements APIKeyService.
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, req *dto.SignedAPIRequest) (*entity.APIKey, error) {
+	if !strings.HasPrefix(req.KeyID, apiKeyPrefix) || req.Signature == "" {
+		return nil, errInvalidAPIKey
+	}
+	if !signature.Fresh(req.Timestamp, time.Now(), apiKeyMaxSkew) {
+		return nil, apperror.New(apperror.CodeUnauthorized, "request timestamp is missing or expired", nil)
+	}
+
+	key, err := s.repo.FindByKeyID(ctx, req.KeyID)
+	if err != nil {
+		if apperror.Is(err, apperror.CodeNotFound) {
+			return nil, errInvalidAPIKey
+		}
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, errInvalidAPIKey
+	}
+
+	secret, err := s.secrets.Open(key.SigningSecret)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternal, "failed to read api key", err)
+	}
+	if !signature.Verify(secret, req.Timestamp, req.Method, req.Path, req.Body, req.Signature) {
+		return nil, apperror.New(apperror.CodeUnauthorized, "invalid request signature", nil)
+	}
+
+	// Dicatat setelah signature valid, jadi hanya pemegang secret yang bisa mengisi tabel ini
+	fresh, err := s.repo.RecordSignature(ctx, key.ID, req.Signature, time.Unix(req.Timestamp, 0).Add(apiKeyMaxSkew))
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		return nil, apperror.New(apperror.CodeUnauthorized, "request has already been processed", nil)
+	}
+
+	if key.User.SuspendedAt != nil {
+		return nil, apperror.New(apperror.CodeForbidden, "account is suspended", nil)
+	}
+	if !ipAllowed(key.AllowedIPs, req.ClientIP) {
+		return nil, apperror.New(apperror.CodeForbidden, "ip address is not allowed for this api key", nil)
+	}
+
+	if now := time.Now(); key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.repo.TouchLastUsed(ctx, key.ID, now); err != nil {
+			s.logger.Error(err, "failed to update api key last_used_at")
+		}
+	}
+
+	return key, nil
+}
+
+// randomToken menghasilkan prefix + n byte acak dalam base64url.
+func randomToken(prefix string, n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ipAllowed mencocokkan IP client dengan daftar IP/CIDR; daftar kosong mengizinkan semua.
+func ipAllowed(allowed, clientIP string) bool {
+	if allowed == "" {
+		return true
+	}
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+	for _, entry := range strings.Split(allowed, ",") {
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if cidr.Contains(ip) {
+				return true
+			}
+		} else if allowedIP := net.ParseIP(entry); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func toAPIKeyResponse(key *entity.APIKey) dto.APIKeyResponse {
+	ips := []string{}
+	if key.AllowedIPs != "" {
+		ips = strings.Split(key.AllowedIPs, ",")
+	}
+	return dto.APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		KeyID:      key.KeyID,
+		AllowedIPs: ips,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/signature"
+)
+
+type fakeAPIKeyRepo struct {
+	mu         sync.Mutex
+	keys       []*entity.APIKey
+	signatures map[string]bool
+}
+
+func newFakeAPIKeyRepo() *fakeAPIKeyRepo {
+	return &fakeAPIKeyRepo{signatures: map[string]bool{}}
+}
+
+func (r *fakeAPIKeyRepo) Create(_ context.Context, key *entity.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key.ID = uint64(len(r.keys) + 1)
+	cp := *key
+	r.keys = append(r.keys, &cp)
+	return nil
+}
+
+func (r *fakeAPIKeyRepo) FindByKeyID(_ context.Context, keyID string) (*entity.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, k := range r.keys {
+		if k.KeyID == keyID {
+			cp := *k
+			return &cp, nil
+		}
+	}
+	return nil, apperror.ErrNotFound
+}
+
+func (r *fakeAPIKeyRepo) FindByUser(context.Context, uint64) ([]entity.APIKey, error) {
+	return nil, nil
+}
+
+func (r *fakeAPIKeyRepo) CountActive(context.Context, uint64) (int64, error) { return 0, nil }
+
+func (r *fakeAPIKeyRepo) Revoke(_ context.Context, userID, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.keys[id-1].RevokedAt = &now
+	return nil
+}
+
+func (r *fakeAPIKeyRepo) TouchLastUsed(context.Context, uint64, time.Time) error { return nil }
+
+func (r *fakeAPIKeyRepo) RecordSignature(_ context.Context, id uint64, sig string, _ time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := strconv.FormatUint(id, 10) + ":" + sig
+	if r.signatures[k] {
+		return false, nil
+	}
+	r.signatures[k] = true
+	return true, nil
+}
+
+func signedRequest(keyID, secret, body string, ts int64) *dto.SignedAPIRequest {
+	return &dto.SignedAPIRequest{
+		KeyID:     keyID,
+		Timestamp: ts,
+		Signature: signature.Sign(secret, ts, "POST", "/h2h/orders", []byte(body)),
+		Method:    "POST",
+		Path:      "/h2h/orders",
+		Body:      []byte(body),
+		ClientIP:  "203.0.113.7",
+	}
+}
+
+func TestAPIKeySecretIsSeparateAndEncrypted(t *testing.T) {
+	repo := newFakeAPIKeyRepo()
+	box := testSecretBox(t)
+	svc := NewAPIKeyService(repo, box, testLogger())
+
+	created, err := svc.Create(context.Background(), 1, &dto.CreateAPIKeyRequest{Name: "shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(created.KeyID, "tk_") || !strings.HasPrefix(created.Secret, "sk_") {
+		t.Fatalf("unexpected credentials %q / %q", created.KeyID, created.Secret)
+	}
+	if strings.Contains(created.Secret, created.KeyID) || strings.Contains(created.KeyID, created.Secret) {
+		t.Fatal("key id derived from the secret")
+	}
+	stored := repo.keys[0]
+	if stored.SigningSecret == created.Secret || !box.IsSealed(stored.SigningSecret) {
+		t.Fatalf("signing secret stored in plaintext: %q", stored.SigningSecret)
+	}
+}
+
+func TestAuthenticateAPIKey(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeAPIKeyRepo()
+	svc := NewAPIKeyService(repo, testSecretBox(t), testLogger())
+	created, err := svc.Create(ctx, 1, &dto.CreateAPIKeyRequest{Name: "shop", AllowedIPs: []string{"203.0.113.0/24"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+
+	key, err := svc.AuthenticateAPIKey(ctx, signedRequest(created.KeyID, created.Secret, `{"a":1}`, now))
+	if err != nil {
+		t.Fatalf("valid request rejected: %v", err)
+	}
+	if key.ID != created.ID || key.UserID != 1 {
+		t.Fatalf("unexpected key %+v", key)
+	}
+
+	cases := []struct {
+		name string
+		req  func() *dto.SignedAPIRequest
+		want apperror.Code
+	}{
+		{"replayed request", func() *dto.SignedAPIRequest {
+			return signedRequest(created.KeyID, created.Secret, `{"a":1}`, now)
+		}, apperror.CodeUnauthorized},
+		{"signed with the key id", func() *dto.SignedAPIRequest {
+			return signedRequest(created.KeyID, created.KeyID, `{"a":2}`, now)
+		}, apperror.CodeUnauthorized},
+		{"tampered body", func() *dto.SignedAPIRequest {
+			req := signedRequest(created.KeyID, created.Secret, `{"a":3}`, now)
+			req.Body = []byte(`{"a":4}`)
+			return req
+		}, apperror.CodeUnauthorized},
+		{"stale timestamp", func() *dto.SignedAPIRequest {
+			return signedRequest(created.KeyID, created.Secret, `{"a":5}`, now-int64(apiKeyMaxSkew.Seconds())-10)
+		}, apperror.CodeUnauthorized},
+		{"unknown key", func() *dto.SignedAPIRequest {
+			return signedRequest("tk_unknown", created.Secret, `{"a":6}`, now)
+		}, apperror.CodeUnauthorized},
+		{"ip not allowed", func() *dto.SignedAPIRequest {
+			req := signedRequest(created.KeyID, created.Secret, `{"a":7}`, now)
+			req.ClientIP = "198.51.100.1"
+			return req
+		}, apperror.CodeForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := svc.AuthenticateAPIKey(ctx, tc.req()); !apperror.Is(err, tc.want) {
+				t.Fatalf("got %v, want %s", err, tc.want)
+			}
+		})
+	}
+
+	if err := svc.Revoke(ctx, 1, created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.AuthenticateAPIKey(ctx, signedRequest(created.KeyID, created.Secret, `{"a":8}`, now)); !apperror.Is(err, apperror.CodeUnauthorized) {
+		t.Fatalf("revoked key got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/pagination"
+	"github.com/wildanasyrof/backend-topup/pkg/reqctx"
+)
+
+// Field yang selalu berubah tiap update dan tidak informatif di diff
+var auditIgnoredFields = map[string]struct{}{"created_at": {}, "updated_at": {}}
+
+// AuditLogService mencatat perubahan data oleh staff beserta pelakunya.
+type AuditLogService interface {
+	// Record menyimpan diff before/after (entity atau nil) dengan actor, IP dan request ID dari ctx.
+	// Best-effort: kegagalan hanya di-log karena perubahan datanya sendiri sudah tersimpan
+	Record(ctx context.Context, action, entityType string, entityID any, before, after any)
+	List(ctx context.Context, q dto.AuditLogListQuery) ([]entity.AuditLog, pagination.Meta, error)
+}
+
+type auditLogService struct {
+	repo   repository.AuditLogRepository
+	logger logger.Logger
+}
+
+func NewAuditLogService(repo repository.AuditLogRepository, logger logger.Logger) AuditLogService {
+	return &auditLogService{repo: repo, logger: logger}
+}
+
+// Record implements AuditLogService.
+func (s *auditLogService) Record(ctx context.Context, action, entityType string, entityID any, before, after any) {
+	log := s.logger.With(logger.Fields{"event": "audit", "action": action, "entity_type": entityType, "entity_id": entityID})
+
+	changes, err := auditDiff(before, after)
+	if err != nil {
+		log.Error(err, "failed to diff audit snapshot")
+		return
+	}
+	if len(changes) == 0 && action == entity.AuditUpdate {
+		return // update tanpa perubahan nyata
+	}
+
+	body, err := json.Marshal(changes)
+	if err != nil {
+		log.Error(err, "failed to encode audit changes")
+		return
+	}
+
+	info := reqctx.From(ctx)
+	entry := &entity.AuditLog{
+		ActorRole:  info.Role,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   fmt.Sprint(entityID),
+		Changes:    string(body),
+		IP:         info.IP,
+		UserAgent:  info.UserAgent,
+		RequestID:  info.RequestID,
+	}
+	if info.UserID != 0 {
+		entry.ActorID = &info.UserID
+	}
+
+	// Tetap dicatat walau request sudah timeout/dibatalkan setelah perubahan tersimpan
+	if err := s.repo.Create(context.WithoutCancel(ctx), entry); err != nil {
+		log.Error(err, "failed to write audit log")
+	}
+}
+
+// List implements AuditLogService.
+func (s *auditLogService) List(ctx context.Context, q dto.AuditLogListQuery) ([]entity.AuditLog, pagination.Meta, error) {
+	if _, _, err := q.TimeRange(); err != nil {
+		return nil, pagination.Meta{}, apperror.New(apperror.CodeBadRequest, "from/to must be RFC3339 or YYYY-MM-DD", err)
+	}
+	return s.repo.FindAll(ctx, q)
+}
+
+type auditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// auditDiff membandingkan representasi JSON dua snapshot per field top-level,
+// sehingga field ber-tag json:"-" (secret, hash) tidak pernah masuk audit log.
+func auditDiff(before, after any) (map[string]auditChange, error) {
+	b, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]auditChange{}
+	for k, bv := range b {
+		if _, skip := auditIgnoredFields[k]; skip {
+			continue
+		}
+		if av, ok := a[k]; !ok || !reflect.DeepEqual(av, bv) {
+			changes[k] = auditChange{Before: bv, After: a[k]}
+		}
+	}
+	for k, av := range a {
+		if _, skip := auditIgnoredFields[k]; skip {
+			continue
+		}
+		if _, ok := b[k]; !ok {
+			changes[k] = auditChange{After: av}
+		}
+	}
+	return changes, nil
+}
+
+func auditFields(v any) (map[string]any, error) {
+	fields := map[string]any{}
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return fields, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return fields, json.Unmarshal(raw, &fields)
+}
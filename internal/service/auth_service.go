@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid" // <-- Import
@@ -11,76 +12,121 @@ import (
 	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
 	"github.com/wildanasyrof/backend-topup/pkg/hash"
 	"github.com/wildanasyrof/backend-topup/pkg/jwt"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/mailer"
+	"github.com/wildanasyrof/backend-topup/pkg/messaging"
+	"github.com/wildanasyrof/backend-topup/pkg/utils"
 )
 
 type AuthService interface {
 	Register(ctx context.Context, req *dto.RegisterUserRequest) (*entity.User, error)
 	// Login sekarang mengembalikan AccessToken dan Session
-	Login(ctx context.Context, req *dto.LoginUserRequest, userAgent, clientIP string) (*entity.User, string, *entity.UserSession, error)
+	Login(ctx context.Context, req *dto.LoginUserRequest, userAgent, clientIP string) (*dto.LoginResult, error)
 	// Refresh mengambil RT string (dari cookie)
 	Refresh(ctx context.Context, oldRefreshToken string, userAgent, clientIP string) (string, *entity.UserSession, error)
 	// Logout mengambil RT string (dari cookie)
 	Logout(ctx context.Context, refreshToken string) error
 
-	RegisterByGoogle(ctx context.Context, userInfo *dto.GoogleLoginResponse) (*entity.User, error)
 	// CreateSession adalah helper baru, menggantikan GenerateToken
 	CreateSession(ctx context.Context, user *entity.User, userAgent, clientIP string) (string, *entity.UserSession, error)
+	// CompleteLogin membuat sesi, atau challenge 2FA jika user memakai/wajib 2FA
+	CompleteLogin(ctx context.Context, user *entity.User, userAgent, clientIP string) (*dto.LoginResult, error)
+
+	// VerifyTwoFactor menyelesaikan login dengan kode TOTP atau recovery code
+	VerifyTwoFactor(ctx context.Context, req *dto.TwoFactorVerifyRequest, userAgent, clientIP string) (*dto.LoginResult, error)
+	// StartTwoFactorEnrollment dipakai admin yang wajib 2FA tetapi belum setup
+	StartTwoFactorEnrollment(ctx context.Context, challengeToken string) (*dto.TwoFactorSetupResponse, error)
+	ConfirmTwoFactorEnrollment(ctx context.Context, req *dto.TwoFactorVerifyRequest, userAgent, clientIP string) (*dto.LoginResult, error)
+
+	// RequestEmailVerification mengirim ulang kode verifikasi email
+	RequestEmailVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) error
+	// ForgotPassword mengirim kode reset password ke email user
+	ForgotPassword(ctx context.Context, email string) error
+	// ResetPassword mengganti password dan me-revoke semua sesi user
+	ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error
+
+	// RequestWhatsappLogin mengirim kode login sekali pakai ke nomor WhatsApp user
+	RequestWhatsappLogin(ctx context.Context, phone string) error
+	// LoginWithWhatsapp menukar kode WhatsApp dengan AccessToken dan Session
+	LoginWithWhatsapp(ctx context.Context, req *dto.WhatsappVerifyRequest, userAgent, clientIP string) (*dto.LoginResult, error)
+	// RequestWhatsappVerification mengirim kode ke nomor WhatsApp di profil user.
+	// Login WhatsApp baru bisa dipakai setelah nomor diverifikasi lewat VerifyWhatsapp
+	RequestWhatsappVerification(ctx context.Context, userID uint64) error
+	VerifyWhatsapp(ctx context.Context, userID uint64, code string) (*entity.User, error)
 }
 
+// Purpose challenge token untuk langkah kedua login
+const (
+	challengeLogin  = "2fa_login"
+	challengeEnroll = "2fa_enroll"
+)
+
 type authService struct {
 	userRepository repository.UserRepository
 	sessionRepo    repository.SessionRepository // <--- TAMBAHKAN
+	accessCache    AccessCache
 	jwtService     jwt.JWTService
+	otpService     OTPService
+	twoFactor      TwoFactorService
+	challengeRepo  repository.ChallengeRepository
+	loginGuard     LoginGuard
+	mailer         mailer.Mailer
+	messenger      messaging.MessagingProvider
+	logger         logger.Logger
 }
 
 // Modifikasi NewAuthService
 func NewAuthService(
 	userRepository repository.UserRepository,
 	sessionRepo repository.SessionRepository, // <--- TAMBAHKAN
+	accessCache AccessCache,
 	jwtService jwt.JWTService,
+	otpService OTPService,
+	twoFactor TwoFactorService,
+	challengeRepo repository.ChallengeRepository,
+	loginGuard LoginGuard,
+	mailer mailer.Mailer,
+	messenger messaging.MessagingProvider,
+	logger logger.Logger,
 ) AuthService {
 	return &authService{
 		userRepository: userRepository,
 		sessionRepo:    sessionRepo, // <--- TAMBAHKAN
+		accessCache:    accessCache,
 		jwtService:     jwtService,
+		otpService:     otpService,
+		twoFactor:      twoFactor,
+		challengeRepo:  challengeRepo,
+		loginGuard:     loginGuard,
+		mailer:         mailer,
+		messenger:      messenger,
+		logger:         logger,
 	}
 }
 
-// RegisterByGoogle implements AuthService.
-func (a *authService) RegisterByGoogle(ctx context.Context, userInfo *dto.GoogleLoginResponse) (*entity.User, error) {
-	user := &entity.User{
-		GoogleID:   userInfo.Sub,
-		Name:       userInfo.Name,
-		IsVerified: userInfo.EmailVerified,
-		Email:      userInfo.Email, // <-- Pastikan email disimpan
-		Role:       "user",         // <-- Set role default
-	}
-
-	if err := a.userRepository.Store(ctx, user); err != nil {
+// Modifikasi Login
+func (a *authService) Login(ctx context.Context, req *dto.LoginUserRequest, userAgent, clientIP string) (*dto.LoginResult, error) {
+	if err := a.loginGuard.Check(ctx, req.Email, clientIP); err != nil {
 		return nil, err
 	}
 
-	return user, nil
-}
-
-// Modifikasi Login
-func (a *authService) Login(ctx context.Context, req *dto.LoginUserRequest, userAgent, clientIP string) (*entity.User, string, *entity.UserSession, error) {
 	user, err := a.userRepository.GetByEmail(ctx, req.Email)
 	if err != nil {
-		return nil, "", nil, err
+		// Email tidak terdaftar juga dihitung agar tidak bisa dipakai untuk enumerasi
+		if apperror.Is(err, apperror.CodeUnauthorized) {
+			a.loginGuard.Failure(ctx, req.Email, clientIP)
+		}
+		return nil, err
 	}
 
 	if err := hash.ComparePassword(user.PasswordHash, req.Password); err != nil {
-		return nil, "", nil, apperror.New(apperror.CodeUnauthorized, "invalid credentials", err)
+		a.loginGuard.Failure(ctx, req.Email, clientIP)
+		return nil, apperror.New(apperror.CodeUnauthorized, "invalid credentials", err)
 	}
 
-	// Gunakan CreateSession
-	accessToken, session, err := a.CreateSession(ctx, user, userAgent, clientIP)
-	if err != nil {
-		return nil, "", nil, apperror.New(apperror.CodeInternal, "issue token failed", err)
-	}
-
-	return user, accessToken, session, nil
+	a.loginGuard.Success(ctx, req.Email)
+	return a.CompleteLogin(ctx, user, userAgent, clientIP)
 }
 
 // Register implements AuthService.
@@ -98,23 +144,368 @@ func (a *authService) Register(ctx context.Context, req *dto.RegisterUserRequest
 		return nil, err
 	}
 
+	// Kegagalan kirim email tidak menggagalkan registrasi; user bisa minta kirim ulang.
+	if err := a.sendOTP(ctx, user, entity.OTPEmailVerification); err != nil {
+		a.logger.Error(err, fmt.Sprintf("failed to send verification email to user %d", user.ID))
+	}
+
 	return user, nil
 }
 
+// RequestEmailVerification implements AuthService.
+func (a *authService) RequestEmailVerification(ctx context.Context, email string) error {
+	user, err := a.findByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	// Jangan bocorkan apakah email terdaftar / sudah terverifikasi
+	if user == nil || user.IsVerified {
+		return nil
+	}
+
+	return a.sendOTP(ctx, user, entity.OTPEmailVerification)
+}
+
+// VerifyEmail implements AuthService.
+func (a *authService) VerifyEmail(ctx context.Context, req *dto.VerifyEmailRequest) error {
+	user, err := a.findByEmail(ctx, req.Email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errInvalidOTP
+	}
+	if user.IsVerified {
+		return nil
+	}
+
+	if err := a.otpService.Verify(ctx, user.ID, entity.OTPEmailVerification, req.Code); err != nil {
+		return err
+	}
+
+	user.IsVerified = true
+	user.EmailVerifiedAt = time.Now()
+
+	return a.userRepository.Update(ctx, user)
+}
+
+// ForgotPassword implements AuthService.
+func (a *authService) ForgotPassword(ctx context.Context, email string) error {
+	user, err := a.findByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return nil
+	}
+
+	return a.sendOTP(ctx, user, entity.OTPPasswordReset)
+}
+
+// ResetPassword implements AuthService.
+func (a *authService) ResetPassword(ctx context.Context, req *dto.ResetPasswordRequest) error {
+	user, err := a.findByEmail(ctx, req.Email)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errInvalidOTP
+	}
+
+	if err := a.otpService.Verify(ctx, user.ID, entity.OTPPasswordReset, req.Code); err != nil {
+		return err
+	}
+
+	user.PasswordHash = hash.HashPassword(req.Password)
+	// Kode sampai ke inbox user, jadi email-nya terbukti valid
+	if !user.IsVerified {
+		user.IsVerified = true
+		user.EmailVerifiedAt = time.Now()
+	}
+
+	if err := a.userRepository.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// Password berubah: semua refresh token lama harus mati
+	if err := a.sessionRepo.RevokeAllByUserID(ctx, user.ID); err != nil {
+		return err
+	}
+	a.accessCache.InvalidateUser(ctx, user.ID)
+	return nil
+}
+
+// RequestWhatsappLogin implements AuthService.
+func (a *authService) RequestWhatsappLogin(ctx context.Context, phone string) error {
+	phone = utils.NormalizePhone(phone)
+
+	user, err := a.userRepository.FindByWhatsapp(ctx, phone)
+	if err != nil {
+		// Jangan bocorkan apakah nomor terdaftar
+		if apperror.Is(err, apperror.CodeNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	code, err := a.otpService.Issue(ctx, user.ID, entity.OTPWhatsappLogin)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Your login code is %s. It expires in %d minutes. Never share this code with anyone.", code, int(otpTTL.Minutes()))
+	if err := a.messenger.SendText(ctx, phone, msg); err != nil {
+		return apperror.New(apperror.CodeUnavailable, "failed to send whatsapp message", err)
+	}
+	return nil
+}
+
+// LoginWithWhatsapp implements AuthService.
+func (a *authService) LoginWithWhatsapp(ctx context.Context, req *dto.WhatsappVerifyRequest, userAgent, clientIP string) (*dto.LoginResult, error) {
+	user, err := a.userRepository.FindByWhatsapp(ctx, utils.NormalizePhone(req.Whatsapp))
+	if err != nil {
+		if apperror.Is(err, apperror.CodeNotFound) {
+			return nil, errInvalidOTP
+		}
+		return nil, err
+	}
+
+	if err := a.otpService.Verify(ctx, user.ID, entity.OTPWhatsappLogin, req.Code); err != nil {
+		return nil, err
+	}
+
+	return a.CompleteLogin(ctx, user, userAgent, clientIP)
+}
+
+// RequestWhatsappVerification implements AuthService.
+func (a *authService) RequestWhatsappVerification(ctx context.Context, userID uint64) error {
+	user, err := a.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if user.Whatsapp == "" {
+		return apperror.New(apperror.CodeBadRequest, "set a whatsapp number on your profile first", nil)
+	}
+	if user.WhatsappVerifiedAt != nil {
+		return apperror.New(apperror.CodeConflict, "whatsapp number is already verified", nil)
+	}
+
+	code, err := a.otpService.Issue(ctx, user.ID, entity.OTPWhatsappVerify)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Your verification code is %s. It expires in %d minutes. Never share this code with anyone.", code, int(otpTTL.Minutes()))
+	if err := a.messenger.SendText(ctx, user.Whatsapp, msg); err != nil {
+		return apperror.New(apperror.CodeUnavailable, "failed to send whatsapp message", err)
+	}
+	return nil
+}
+
+// VerifyWhatsapp implements AuthService.
+func (a *authService) VerifyWhatsapp(ctx context.Context, userID uint64, code string) (*entity.User, error) {
+	user, err := a.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Whatsapp == "" {
+		return nil, errInvalidOTP
+	}
+	if user.WhatsappVerifiedAt != nil {
+		return user, nil
+	}
+
+	if err := a.otpService.Verify(ctx, user.ID, entity.OTPWhatsappVerify, code); err != nil {
+		return nil, err
+	}
+
+	// Nomor yang sama sudah diverifikasi akun lain: ditolak oleh unique index (CodeConflict dari repository)
+	now := time.Now()
+	user.WhatsappVerifiedAt = &now
+	if err := a.userRepository.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CompleteLogin implements AuthService.
+func (a *authService) CompleteLogin(ctx context.Context, user *entity.User, userAgent, clientIP string) (*dto.LoginResult, error) {
+	if user.SuspendedAt != nil {
+		return nil, apperror.New(apperror.CodeForbidden, "account is suspended", nil)
+	}
+
+	// Faktor pertama sudah lolos, sesi baru dibuat setelah langkah kedua
+	if user.TOTPEnabled || a.twoFactor.Required(ctx, user) {
+		purpose := challengeLogin
+		if !user.TOTPEnabled {
+			purpose = challengeEnroll
+		}
+		token, err := a.jwtService.GenerateChallengeToken(user.ID, purpose)
+		if err != nil {
+			return nil, apperror.New(apperror.CodeInternal, "failed to issue challenge", err)
+		}
+		return &dto.LoginResult{TwoFactor: &dto.TwoFactorChallenge{
+			ChallengeToken:     token,
+			EnrollmentRequired: purpose == challengeEnroll,
+		}}, nil
+	}
+
+	accessToken, session, err := a.CreateSession(ctx, user, userAgent, clientIP)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResult{User: user, AccessToken: accessToken, Session: session}, nil
+}
+
+// VerifyTwoFactor implements AuthService.
+func (a *authService) VerifyTwoFactor(ctx context.Context, req *dto.TwoFactorVerifyRequest, userAgent, clientIP string) (*dto.LoginResult, error) {
+	user, challenge, err := a.challengeUser(ctx, req.ChallengeToken, challengeLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	// Kode 2FA ikut dibatasi, challenge token berlaku beberapa menit dan bisa dicoba berulang sampai berhasil
+	if err := a.loginGuard.Check(ctx, user.Email, clientIP); err != nil {
+		return nil, err
+	}
+	if err := a.twoFactor.Verify(ctx, user, req.Code); err != nil {
+		if apperror.Is(err, apperror.CodeUnauthorized) {
+			a.loginGuard.Failure(ctx, user.Email, clientIP)
+		}
+		return nil, err
+	}
+	if err := a.consumeChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	accessToken, session, err := a.CreateSession(ctx, user, userAgent, clientIP)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResult{User: user, AccessToken: accessToken, Session: session}, nil
+}
+
+// StartTwoFactorEnrollment implements AuthService.
+func (a *authService) StartTwoFactorEnrollment(ctx context.Context, challengeToken string) (*dto.TwoFactorSetupResponse, error) {
+	user, _, err := a.challengeUser(ctx, challengeToken, challengeEnroll)
+	if err != nil {
+		return nil, err
+	}
+	return a.twoFactor.Setup(ctx, user.ID)
+}
+
+// ConfirmTwoFactorEnrollment implements AuthService.
+func (a *authService) ConfirmTwoFactorEnrollment(ctx context.Context, req *dto.TwoFactorVerifyRequest, userAgent, clientIP string) (*dto.LoginResult, error) {
+	user, challenge, err := a.challengeUser(ctx, req.ChallengeToken, challengeEnroll)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := a.twoFactor.Enable(ctx, user.ID, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.consumeChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	accessToken, session, err := a.CreateSession(ctx, user, userAgent, clientIP)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.LoginResult{User: user, AccessToken: accessToken, Session: session, RecoveryCodes: codes}, nil
+}
+
+// challengeUser memvalidasi challenge token dan memuat user-nya.
+func (a *authService) challengeUser(ctx context.Context, token, purpose string) (*entity.User, *jwt.ChallengeClaims, error) {
+	claims, err := a.jwtService.ValidateChallengeToken(token, purpose)
+	if err != nil {
+		return nil, nil, apperror.New(apperror.CodeUnauthorized, "invalid or expired challenge", err)
+	}
+	user, err := a.userRepository.GetByID(ctx, claims.Id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, claims, nil
+}
+
+// consumeChallenge mencatat jti challenge token setelah langkah kedua berhasil, agar token yang sama
+// tidak bisa dipakai lagi untuk membuat sesi lain (mis. dengan recovery code berikutnya).
+func (a *authService) consumeChallenge(ctx context.Context, claims *jwt.ChallengeClaims) error {
+	ok, err := a.challengeRepo.Consume(ctx, claims.ID, claims.Id, claims.ExpiresAt.Time)
+	if err != nil {
+		return apperror.New(apperror.CodeInternal, "could not verify challenge", err)
+	}
+	if !ok {
+		return apperror.New(apperror.CodeUnauthorized, "challenge has already been used", nil)
+	}
+	return nil
+}
+
+// findByEmail mengembalikan (nil, nil) jika user tidak ditemukan.
+func (a *authService) findByEmail(ctx context.Context, email string) (*entity.User, error) {
+	user, err := a.userRepository.GetByEmail(ctx, email)
+	if err != nil {
+		if apperror.Is(err, apperror.CodeUnauthorized) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (a *authService) sendOTP(ctx context.Context, user *entity.User, purpose entity.OTPPurpose) error {
+	code, err := a.otpService.Issue(ctx, user.ID, purpose)
+	if err != nil {
+		return err
+	}
+
+	msg := mailer.Message{To: user.Email}
+	switch purpose {
+	case entity.OTPPasswordReset:
+		msg.Subject = "Reset your password"
+		msg.Body = fmt.Sprintf("Hi %s,\n\nYour password reset code is %s. It expires in %d minutes.\n\nIf you did not request this, you can ignore this email.", user.Name, code, int(otpTTL.Minutes()))
+	default:
+		msg.Subject = "Verify your email"
+		msg.Body = fmt.Sprintf("Hi %s,\n\nYour verification code is %s. It expires in %d minutes.", user.Name, code, int(otpTTL.Minutes()))
+	}
+
+	if err := a.mailer.Send(ctx, msg); err != nil {
+		return apperror.New(apperror.CodeUnavailable, "failed to send email", err)
+	}
+	return nil
+}
+
 // Helper Baru: CreateSession
 func (a *authService) CreateSession(ctx context.Context, user *entity.User, userAgent, clientIP string) (string, *entity.UserSession, error) {
-	// 1. Buat Access Token
-	accessToken, err := a.jwtService.GenerateAccessToken(user.ID, user.Role)
+	// Login baru: sekalian bersihkan sesi user yang sudah kedaluwarsa
+	if err := a.sessionRepo.DeleteExpiredByUserID(ctx, user.ID); err != nil {
+		a.logger.Error(err, "failed to delete expired sessions")
+	}
+
+	return a.issueSession(ctx, user, &entity.UserSession{UserAgent: userAgent, ClientIP: clientIP})
+}
+
+// issueSession membuat Access Token dan menyimpan sesi (Refresh Token).
+// session boleh sudah berisi ID/FamilyID saat dipakai untuk rotasi.
+func (a *authService) issueSession(ctx context.Context, user *entity.User, session *entity.UserSession) (string, *entity.UserSession, error) {
+	if user.SuspendedAt != nil {
+		return "", nil, apperror.New(apperror.CodeForbidden, "account is suspended", nil)
+	}
+
+	// 1. Buat Access Token, terikat ke sesi lewat claim sid
+	if session.ID == uuid.Nil {
+		session.ID = uuid.New()
+	}
+	accessToken, err := a.jwtService.GenerateAccessToken(user.ID, user.Role, session.ID.String(), user.TokenVersion)
 	if err != nil {
 		return "", nil, apperror.New(apperror.CodeInternal, "failed to generate access token", err)
 	}
 
 	// 2. Buat Sesi (Refresh Token) di DB
-	session := &entity.UserSession{
-		UserID:    user.ID,
-		ExpiresAt: time.Now().Add(a.jwtService.GetRefreshTokenDuration()),
-		UserAgent: userAgent,
-		ClientIP:  clientIP,
+	session.UserID = user.ID
+	if session.ExpiresAt.IsZero() {
+		session.ExpiresAt = time.Now().Add(a.jwtService.GetRefreshTokenDuration())
 	}
 
 	if err := a.sessionRepo.Create(ctx, session); err != nil {
@@ -139,26 +530,48 @@ func (a *authService) Refresh(ctx context.Context, oldRefreshToken string, userA
 		return "", nil, err
 	}
 
+	familyID := oldSession.FamilyID
+	if familyID == uuid.Nil {
+		// Sesi lama dibuat sebelum ada family
+		familyID = oldSession.ID
+	}
+
 	// 2. Validasi sesi
+	if oldSession.RotatedAt != nil {
+		return "", nil, a.revokeReusedFamily(ctx, oldSession, familyID, userAgent, clientIP)
+	}
 	if oldSession.IsRevoked {
-		_ = a.sessionRepo.Delete(ctx, oldSession.ID)
 		return "", nil, apperror.New(apperror.CodeUnauthorized, "session has been revoked", nil)
 	}
+	if oldSession.User.SuspendedAt != nil {
+		return "", nil, apperror.New(apperror.CodeForbidden, "account is suspended", nil)
+	}
 	if time.Now().After(oldSession.ExpiresAt) {
 		// Hapus token kedaluwarsa dari DB
 		_ = a.sessionRepo.Delete(ctx, oldSession.ID)
 		return "", nil, apperror.New(apperror.CodeUnauthorized, "refresh token expired", nil)
 	}
 
-	// 3. Lakukan Rotasi: Hapus token lama
-	// Kita gunakan Delete, bukan Revoke, karena kita akan ganti dengan yang baru
-	if err := a.sessionRepo.Delete(ctx, oldSession.ID); err != nil {
+	// 3. Lakukan Rotasi: tandai token lama sudah dipakai (bukan dihapus) agar reuse bisa dideteksi
+	newSessionID := uuid.New()
+	rotated, err := a.sessionRepo.MarkRotated(ctx, oldSession.ID, newSessionID)
+	if err != nil {
 		return "", nil, apperror.New(apperror.CodeInternal, "could not rotate token", err)
 	}
+	if !rotated {
+		// Request lain sudah merotasi token yang sama lebih dulu
+		return "", nil, a.revokeReusedFamily(ctx, oldSession, familyID, userAgent, clientIP)
+	}
 
-	// 4. Buat token & sesi baru
+	// 4. Buat token & sesi baru dalam family yang sama, masa berlaku family tidak diperpanjang
 	// Kita gunakan data User dari oldSession yg sudah di-Preload
-	newAccessToken, newSession, err := a.CreateSession(ctx, &oldSession.User, userAgent, clientIP)
+	newAccessToken, newSession, err := a.issueSession(ctx, &oldSession.User, &entity.UserSession{
+		ID:        newSessionID,
+		FamilyID:  familyID,
+		ExpiresAt: oldSession.ExpiresAt,
+		UserAgent: userAgent,
+		ClientIP:  clientIP,
+	})
 	if err != nil {
 		return "", nil, apperror.New(apperror.CodeInternal, "could not issue new token", err)
 	}
@@ -166,6 +579,25 @@ func (a *authService) Refresh(ctx context.Context, oldRefreshToken string, userA
 	return newAccessToken, newSession, nil
 }
 
+// revokeReusedFamily menangani refresh token yang dipakai ulang setelah dirotasi:
+// salah satu pemegang token (asli atau pencuri) tidak sah, jadi seluruh family di-revoke.
+func (a *authService) revokeReusedFamily(ctx context.Context, session *entity.UserSession, familyID uuid.UUID, userAgent, clientIP string) error {
+	a.logger.With(logger.Fields{
+		"event":      "refresh_token_reuse",
+		"user_id":    session.UserID,
+		"session_id": session.ID.String(),
+		"family_id":  familyID.String(),
+		"client_ip":  clientIP,
+		"user_agent": userAgent,
+	}).Warn("refresh token reuse detected, revoking session family")
+
+	if err := a.sessionRepo.RevokeFamily(ctx, familyID); err != nil {
+		return apperror.New(apperror.CodeInternal, "could not revoke session", err)
+	}
+	a.accessCache.InvalidateUser(ctx, session.UserID)
+	return apperror.New(apperror.CodeUnauthorized, "refresh token has already been used", nil)
+}
+
 // Fungsi Baru: Logout
 func (a *authService) Logout(ctx context.Context, refreshToken string) error {
 	if refreshToken == "" {
@@ -179,8 +611,23 @@ func (a *authService) Logout(ctx context.Context, refreshToken string) error {
 		return nil
 	}
 
-	// Hapus sesi dari database
-	// Kita bisa gunakan Revoke() jika ingin menyimpan history,
-	// tapi Delete() lebih bersih untuk logout normal.
-	return a.sessionRepo.Delete(ctx, sessionID)
+	session, err := a.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		// Sesi sudah tidak ada, anggap sudah logout
+		if apperror.Is(err, apperror.CodeUnauthorized) {
+			return nil
+		}
+		return err
+	}
+
+	// Revoke seluruh family, termasuk token lama hasil rotasi
+	familyID := session.FamilyID
+	if familyID == uuid.Nil {
+		familyID = session.ID
+	}
+	if err := a.sessionRepo.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	a.accessCache.InvalidateUser(ctx, session.UserID)
+	return nil
 }
@@ -0,0 +1,433 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/hash"
+	"github.com/wildanasyrof/backend-topup/pkg/jwt"
+	"github.com/wildanasyrof/backend-topup/pkg/mailer"
+	"github.com/wildanasyrof/backend-topup/pkg/messaging"
+)
+
+type authHarness struct {
+	svc        AuthService
+	users      *fakeUserRepo
+	sessions   *fakeSessionRepo
+	otps       *fakeOTPRepo
+	challenges *fakeChallengeRepo
+	jwt        jwt.JWTService
+	mail       *mailer.MemoryMailer
+	whatsapp   *messaging.MemoryProvider
+	userAgent  string
+	clientIP   string
+}
+
+func newAuthHarness(t *testing.T) *authHarness {
+	h := &authHarness{
+		users:      newFakeUserRepo(),
+		sessions:   newFakeSessionRepo(),
+		otps:       newFakeOTPRepo(),
+		challenges: newFakeChallengeRepo(),
+		mail:       mailer.NewMemoryMailer(),
+		whatsapp:   messaging.NewMemoryProvider(),
+		userAgent:  "test",
+		clientIP:   "127.0.0.1",
+	}
+	h.jwt = testJWT(t)
+	h.withTwoFactor(noTwoFactor{})
+	return h
+}
+
+// withTwoFactor membangun ulang service dengan TwoFactorService lain
+func (h *authHarness) withTwoFactor(twoFactor TwoFactorService) {
+	h.svc = NewAuthService(h.users, h.sessions, NewAccessCache(nil, testLogger()), h.jwt, NewOTPService(h.otps, testOTPSecret),
+		twoFactor, h.challenges, allowLogins{}, h.mail, h.whatsapp, testLogger())
+}
+
+// mailedCode mengambil kode dari email terakhir ke alamat tsb
+func (h *authHarness) mailedCode(t *testing.T, to string) string {
+	t.Helper()
+	msg, ok := h.mail.Last(to)
+	if !ok {
+		t.Fatalf("no email sent to %s", to)
+	}
+	return codeIn(t, msg.Body)
+}
+
+func (h *authHarness) register(t *testing.T, email string) *entity.User {
+	t.Helper()
+	user, err := h.svc.Register(context.Background(), &dto.RegisterUserRequest{Email: email, Name: "Tester", Password: "password123"})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	return user
+}
+
+func TestRegisterSendsVerificationCode(t *testing.T) {
+	ctx := context.Background()
+	h := newAuthHarness(t)
+	user := h.register(t, "a@example.com")
+
+	msg, ok := h.mail.Last("a@example.com")
+	if !ok || msg.Subject != "Verify your email" {
+		t.Fatalf("verification email not sent: %+v", h.mail.Sent())
+	}
+
+	if err := h.svc.VerifyEmail(ctx, &dto.VerifyEmailRequest{Email: "a@example.com", Code: "000000"}); err == nil {
+		t.Fatal("wrong code accepted")
+	}
+	if h.users.get(t, user.ID).IsVerified {
+		t.Fatal("verified with wrong code")
+	}
+
+	code := codeIn(t, msg.Body)
+	if err := h.svc.VerifyEmail(ctx, &dto.VerifyEmailRequest{Email: "a@example.com", Code: code}); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if got := h.users.get(t, user.ID); !got.IsVerified || got.EmailVerifiedAt.IsZero() {
+		t.Fatal("user not marked verified")
+	}
+}
+
+func TestRequestEmailVerificationUnknownEmailIsSilent(t *testing.T) {
+	h := newAuthHarness(t)
+	if err := h.svc.RequestEmailVerification(context.Background(), "nobody@example.com"); err != nil {
+		t.Fatalf("unknown email should not error: %v", err)
+	}
+	if len(h.mail.Sent()) != 0 {
+		t.Fatal("email sent to unknown address")
+	}
+}
+
+func TestPasswordResetRevokesSessions(t *testing.T) {
+	ctx := context.Background()
+	h := newAuthHarness(t)
+	user := h.register(t, "b@example.com")
+	for range 2 {
+		if _, _, err := h.svc.CreateSession(ctx, h.users.get(t, user.ID), h.userAgent, h.clientIP); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := h.svc.ForgotPassword(ctx, "b@example.com"); err != nil {
+		t.Fatalf("forgot password: %v", err)
+	}
+	msg, _ := h.mail.Last("b@example.com")
+	if msg.Subject != "Reset your password" {
+		t.Fatalf("reset email not sent, last subject %q", msg.Subject)
+	}
+	code := h.mailedCode(t, "b@example.com")
+
+	req := &dto.ResetPasswordRequest{Email: "b@example.com", Code: code, Password: "new-password-1"}
+	if err := h.svc.ResetPassword(ctx, req); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+
+	got := h.users.get(t, user.ID)
+	if err := hash.ComparePassword(got.PasswordHash, "new-password-1"); err != nil {
+		t.Fatal("password not changed")
+	}
+	if !got.IsVerified {
+		t.Fatal("reset via emailed code should verify the email")
+	}
+	if n := h.sessions.active(user.ID); n != 0 {
+		t.Fatalf("%d sessions still active after reset", n)
+	}
+
+	// Kode yang sama tidak bisa dipakai lagi
+	req.Password = "another-password"
+	if err := h.svc.ResetPassword(ctx, req); err == nil {
+		t.Fatal("reset code accepted twice")
+	}
+}
+
+func TestResetCodeNotValidForEmailVerification(t *testing.T) {
+	ctx := context.Background()
+	h := newAuthHarness(t)
+	user := h.register(t, "c@example.com")
+
+	if err := h.svc.ForgotPassword(ctx, "c@example.com"); err != nil {
+		t.Fatal(err)
+	}
+	code := h.mailedCode(t, "c@example.com")
+
+	_ = h.svc.VerifyEmail(ctx, &dto.VerifyEmailRequest{Email: "c@example.com", Code: code})
+	if h.users.get(t, user.ID).IsVerified {
+		t.Fatal("password reset code verified the email")
+	}
+}
+
+// whatsappUser mendaftarkan user dengan nomor di profil (belum terverifikasi)
+func (h *authHarness) whatsappUser(t *testing.T, email, phone string) *entity.User {
+	t.Helper()
+	user := h.register(t, email)
+	user = h.users.get(t, user.ID)
+	user.Whatsapp = phone
+	if err := h.users.Update(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// whatsappCode mengambil kode dari pesan WhatsApp terakhir ke nomor tsb
+func (h *authHarness) whatsappCode(t *testing.T, to string) string {
+	t.Helper()
+	msg, ok := h.whatsapp.Last(to)
+	if !ok {
+		t.Fatalf("no whatsapp message sent to %s", to)
+	}
+	return codeIn(t, msg.Message)
+}
+
+func (h *authHarness) verifyWhatsapp(t *testing.T, user *entity.User) {
+	t.Helper()
+	ctx := context.Background()
+	if err := h.svc.RequestWhatsappVerification(ctx, user.ID); err != nil {
+		t.Fatalf("request verification: %v", err)
+	}
+	if _, err := h.svc.VerifyWhatsapp(ctx, user.ID, h.whatsappCode(t, user.Whatsapp)); err != nil {
+		t.Fatalf("verify whatsapp: %v", err)
+	}
+}
+
+func TestWhatsappLoginRequiresVerifiedNumber(t *testing.T) {
+	ctx := context.Background()
+	h := newAuthHarness(t)
+	user := h.whatsappUser(t, "wa@example.com", "6281234567890")
+
+	// Nomor belum terverifikasi: respons sama seperti nomor tidak terdaftar, tanpa pesan terkirim
+	if err := h.svc.RequestWhatsappLogin(ctx, "081234567890"); err != nil {
+		t.Fatal(err)
+	}
+	if len(h.whatsapp.Sent()) != 0 {
+		t.Fatal("login code sent to unverified number")
+	}
+
+	h.verifyWhatsapp(t, user)
+	if h.users.get(t, user.ID).WhatsappVerifiedAt == nil {
+		t.Fatal("number not marked verified")
+	}
+
+	// Input lokal (08...) dinormalisasi ke format yang tersimpan
+	if err := h.svc.RequestWhatsappLogin(ctx, "0812-3456-7890"); err != nil {
+		t.Fatalf("request login: %v", err)
+	}
+	code := h.whatsappCode(t, "6281234567890")
+
+	res, err := h.svc.LoginWithWhatsapp(ctx, &dto.WhatsappVerifyRequest{Whatsapp: "081234567890", Code: code}, h.userAgent, h.clientIP)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	if res.AccessToken == "" || res.Session == nil || res.Session.UserID != user.ID {
+		t.Fatalf("unexpected login result: %+v", res)
+	}
+
+	if _, err := h.svc.LoginWithWhatsapp(ctx, &dto.WhatsappVerifyRequest{Whatsapp: "081234567890", Code: code}, h.userAgent, h.clientIP); err == nil {
+		t.Fatal("login code accepted twice")
+	}
+}
+
+func TestVerificationCodeCannotLogIn(t *testing.T) {
+	ctx := context.Background()
+	h := newAuthHarness(t)
+	user := h.whatsappUser(t, "wa2@example.com", "6281111111111")
+	if err := h.svc.RequestWhatsappVerification(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	code := h.whatsappCode(t, user.Whatsapp)
+
+	// Nomor sudah terverifikasi (mis. lewat kode lain); kode verifikasi tetap tidak berlaku untuk login
+	now := time.Now()
+	user.WhatsappVerifiedAt = &now
+	if err := h.users.Update(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.svc.LoginWithWhatsapp(ctx, &dto.WhatsappVerifyRequest{Whatsapp: user.Whatsapp, Code: code}, h.userAgent, h.clientIP); err == nil {
+		t.Fatal("verification code accepted for login")
+	}
+}
+
+func TestVerifiedWhatsappIsUnique(t *testing.T) {
+	ctx := context.Background()
+	h := newAuthHarness(t)
+	first := h.whatsappUser(t, "one@example.com", "6282222222222")
+	second := h.whatsappUser(t, "two@example.com", "6282222222222")
+	h.verifyWhatsapp(t, first)
+
+	if err := h.svc.RequestWhatsappVerification(ctx, second.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, err := h.svc.VerifyWhatsapp(ctx, second.ID, h.whatsappCode(t, second.Whatsapp))
+	if !apperror.Is(err, apperror.CodeConflict) {
+		t.Fatalf("second verification of the same number got %v, want conflict", err)
+	}
+}
+
+func TestChangingWhatsappResetsVerification(t *testing.T) {
+	ctx := context.Background()
+	h := newAuthHarness(t)
+	user := h.whatsappUser(t, "change@example.com", "6283333333333")
+	h.verifyWhatsapp(t, user)
+
+	users := NewUserService(h.users, h.sessions, nil, NewAccessCache(nil, testLogger()), &fakeAudit{})
+	phone := "083344445555"
+	updated, err := users.Update(ctx, user.ID, &dto.UpdateUserRequest{Whatsapp: &phone})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Whatsapp != "6283344445555" || updated.WhatsappVerifiedAt != nil {
+		t.Fatalf("new number should be normalized and unverified, got %q verified=%v", updated.Whatsapp, updated.WhatsappVerifiedAt)
+	}
+	if _, err := h.users.FindByWhatsapp(ctx, "6283333333333"); err == nil {
+		t.Fatal("old number still resolves to the user")
+	}
+}
+
+// login membuat sesi baru untuk user dan mengembalikan refresh token-nya
+func (h *authHarness) login(t *testing.T, userID uint64) *entity.UserSession {
+	t.Helper()
+	h.sessions.users = h.users
+	_, session, err := h.svc.CreateSession(context.Background(), h.users.get(t, userID), h.userAgent, h.clientIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+func (h *authHarness) session(t *testing.T, id uuid.UUID) *entity.UserSession {
+	t.Helper()
+	s, err := h.sessions.FindByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestLoginSessionRecordsDevice(t *testing.T) {
+	h := newAuthHarness(t)
+	user := h.register(t, "device@example.com")
+
+	s := h.session(t, h.login(t, user.ID).ID)
+	if s.UserAgent != h.userAgent || s.ClientIP != h.clientIP {
+		t.Fatalf("session stored user agent %q and ip %q", s.UserAgent, s.ClientIP)
+	}
+}
+
+func TestRefreshRotatesWithinFamily(t *testing.T) {
+	ctx := context.Background()
+	h := newAuthHarness(t)
+	user := h.register(t, "rotate@example.com")
+	first := h.login(t, user.ID)
+
+	_, second, err := h.svc.Refresh(ctx, first.ID.String(), "new-agent", "10.0.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID == first.ID || second.FamilyID != first.FamilyID || !second.ExpiresAt.Equal(first.ExpiresAt) {
+		t.Fatalf("rotated session %+v does not continue family %s", second, first.FamilyID)
+	}
+	if second.UserAgent != "new-agent" || second.ClientIP != "10.0.0.2" {
+		t.Fatalf("rotated session stored user agent %q and ip %q", second.UserAgent, second.ClientIP)
+	}
+	old := h.session(t, first.ID)
+	if old.RotatedAt == nil || old.ReplacedBy == nil || *old.ReplacedBy != second.ID || old.IsRevoked {
+		t.Fatalf("old session not marked rotated: %+v", old)
+	}
+	if _, _, err := h.svc.Refresh(ctx, second.ID.String(), h.userAgent, h.clientIP); err != nil {
+		t.Fatalf("refresh with the new token: %v", err)
+	}
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	ctx := context.Background()
+	h := newAuthHarness(t)
+	user := h.register(t, "reuse@example.com")
+	stolen := h.login(t, user.ID)
+	otherDevice := h.login(t, user.ID)
+
+	_, current, err := h.svc.Refresh(ctx, stolen.ID.String(), h.userAgent, h.clientIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Token yang sudah dirotasi dipakai lagi: seluruh family, termasuk token terbarunya, di-revoke
+	if _, _, err := h.svc.Refresh(ctx, stolen.ID.String(), "attacker", "198.51.100.9"); !apperror.Is(err, apperror.CodeUnauthorized) {
+		t.Fatalf("reused token got %v, want unauthorized", err)
+	}
+	if !h.session(t, current.ID).IsRevoked {
+		t.Fatal("latest session in the family still active")
+	}
+	if _, _, err := h.svc.Refresh(ctx, current.ID.String(), h.userAgent, h.clientIP); err == nil {
+		t.Fatal("revoked family can still refresh")
+	}
+
+	// Family lain milik user yang sama tidak terpengaruh
+	if h.session(t, otherDevice.ID).IsRevoked {
+		t.Fatal("other family revoked")
+	}
+	if _, _, err := h.svc.Refresh(ctx, otherDevice.ID.String(), h.userAgent, h.clientIP); err != nil {
+		t.Fatalf("other family refresh: %v", err)
+	}
+}
+
+func TestConcurrentRefreshOnlyOneSucceeds(t *testing.T) {
+	ctx := context.Background()
+	h := newAuthHarness(t)
+	user := h.register(t, "race@example.com")
+	session := h.login(t, user.ID)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var issued []*entity.UserSession
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, s, err := h.svc.Refresh(ctx, session.ID.String(), h.userAgent, h.clientIP); err == nil {
+				mu.Lock()
+				issued = append(issued, s)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(issued) != 1 {
+		t.Fatalf("%d refreshes succeeded with the same token, want 1", len(issued))
+	}
+	// Request yang kalah terlihat seperti reuse, jadi family-nya di-revoke
+	if n := h.sessions.active(user.ID); n != 0 {
+		t.Fatalf("%d sessions still active after a racing refresh", n)
+	}
+}
+
+func TestRevokeOtherFamiliesKeepsCurrentFamily(t *testing.T) {
+	ctx := context.Background()
+	h := newAuthHarness(t)
+	user := h.register(t, "others@example.com")
+	current := h.login(t, user.ID)
+	other := h.login(t, user.ID)
+	_, rotated, err := h.svc.Refresh(ctx, current.ID.String(), h.userAgent, h.clientIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users := NewUserService(h.users, h.sessions, nil, NewAccessCache(nil, testLogger()), &fakeAudit{})
+	if err := users.SetPassword(ctx, user.ID, rotated.ID.String(), &dto.SetPasswordRequest{CurrentPassword: "password123", NewPassword: "new-password"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := h.svc.Refresh(ctx, other.ID.String(), h.userAgent, h.clientIP); !apperror.Is(err, apperror.CodeUnauthorized) {
+		t.Fatalf("other family refresh got %v, want unauthorized", err)
+	}
+	if _, _, err := h.svc.Refresh(ctx, rotated.ID.String(), h.userAgent, h.clientIP); err != nil {
+		t.Fatalf("current family refresh: %v", err)
+	}
+}
@@ -16,11 +16,13 @@ type BannerService interface {
 
 type bannerService struct {
 	bannerRepo repository.BannerRepository
+	audit      AuditLogService
 }
 
-func NewBannerService(bannerRepo repository.BannerRepository) BannerService {
+func NewBannerService(bannerRepo repository.BannerRepository, audit AuditLogService) BannerService {
 	return &bannerService{
 		bannerRepo: bannerRepo,
+		audit:      audit,
 	}
 }
 
@@ -34,6 +36,8 @@ func (b *bannerService) Create(ctx context.Context, imgUrl string) (*entity.Bann
 		return nil, err
 	}
 
+	b.audit.Record(ctx, entity.AuditCreate, "banner", banner.ID, nil, banner)
+
 	return banner, nil
 }
 
@@ -48,6 +52,8 @@ func (b *bannerService) Delete(ctx context.Context, id int) (*entity.Banner, err
 		return nil, err
 	}
 
+	b.audit.Record(ctx, entity.AuditDelete, "banner", banner.ID, banner, nil)
+
 	return banner, nil
 }
 
@@ -68,11 +74,14 @@ func (b *bannerService) Update(ctx context.Context, id int, imgUrl string) (*ent
 		return nil, err
 	}
 
+	before := *banner
 	banner.ImgUrl = imgUrl
 
 	if err := b.bannerRepo.Update(ctx, banner); err != nil {
 		return nil, err
 	}
 
+	b.audit.Record(ctx, entity.AuditUpdate, "banner", banner.ID, before, banner)
+
 	return banner, nil
 }
@@ -18,11 +18,12 @@ type CategoryService interface {
 }
 
 type categoryService struct {
-	repo repository.CategoryRepository
+	repo  repository.CategoryRepository
+	audit AuditLogService
 }
 
-func NewCategoryService(repo repository.CategoryRepository) CategoryService {
-	return &categoryService{repo: repo}
+func NewCategoryService(repo repository.CategoryRepository, audit AuditLogService) CategoryService {
+	return &categoryService{repo: repo, audit: audit}
 }
 
 // Create implements CategoryService.
@@ -44,6 +45,8 @@ func (c *categoryService) Create(ctx context.Context, req *dto.CreateCategoryReq
 		return nil, err
 	}
 
+	c.audit.Record(ctx, entity.AuditCreate, "category", category.ID, nil, category)
+
 	return category, nil
 }
 
@@ -58,6 +61,8 @@ func (c *categoryService) Delete(ctx context.Context, id int64) (*entity.Categor
 		return nil, err
 	}
 
+	c.audit.Record(ctx, entity.AuditDelete, "category", category.ID, category, nil)
+
 	return category, nil
 }
 
@@ -82,11 +87,14 @@ func (c *categoryService) Update(ctx context.Context, id int64, req *dto.UpdateC
 		return nil, err
 	}
 
+	before := *category
 	req.UpdateEntity(category)
 
 	if err := c.repo.Update(ctx, category); err != nil {
 		return nil, err
 	}
 
+	c.audit.Record(ctx, entity.AuditUpdate, "category", category.ID, before, category)
+
 	return category, nil
 }
@@ -2,10 +2,13 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
 	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
 	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/metrics"
 	"github.com/wildanasyrof/backend-topup/pkg/utils"
 )
 
@@ -13,30 +16,53 @@ type DepositService interface {
 	Create(ctx context.Context, userID uint64, req *dto.DepositRequest) (*entity.Deposit, error)
 	GetByUserID(ctx context.Context, userID uint64) ([]entity.Deposit, error)
 	GetByDepositID(ctx context.Context, depositID string) (*entity.Deposit, error)
+	UpdateStatus(ctx context.Context, depositID string, req *dto.UpdateDepositStatus) (*entity.Deposit, error)
 }
 
 type depositService struct {
-	repo repository.DepositRepository
+	repo           repository.DepositRepository
+	paymentMethods repository.PaymentMethodsRepository
+	webhooks       WebhookService
+	settings       SettingsReader
+	audit          AuditLogService
+	metrics        metrics.Metrics
 }
 
-func NewDepositService(repo repository.DepositRepository) DepositService {
-	return &depositService{repo: repo}
+func NewDepositService(repo repository.DepositRepository, paymentMethods repository.PaymentMethodsRepository, webhooks WebhookService, settings SettingsReader, audit AuditLogService, metrics metrics.Metrics) DepositService {
+	return &depositService{repo: repo, paymentMethods: paymentMethods, webhooks: webhooks, settings: settings, audit: audit, metrics: metrics}
 }
 
 // Create implements DepositService.
 func (d *depositService) Create(ctx context.Context, userID uint64, req *dto.DepositRequest) (*entity.Deposit, error) {
+	if !d.settings.Bool(ctx, entity.SettingFeatureDeposit) {
+		return nil, apperror.New(apperror.CodeForbidden, "deposits are currently disabled", nil)
+	}
+	if min := d.settings.Money(ctx, entity.SettingMinDeposit); req.Amount < min {
+		return nil, apperror.Validation(map[string]string{"amount": "minimum deposit is " + min.Format()})
+	}
+
+	// Fee dihitung dari metode pembayaran, bukan dari input client
+	method, err := d.paymentMethods.FindByID(ctx, req.PaymentMethodID)
+	if err != nil {
+		if apperror.Is(err, apperror.CodeNotFound) {
+			return nil, apperror.New(apperror.CodeBadRequest, "payment method not found", err)
+		}
+		return nil, err
+	}
+
 	deposit := &entity.Deposit{
 		UserID:          userID,
 		PaymentMethodID: req.PaymentMethodID,
 		Amount:          req.Amount,
 		Status:          entity.DepProcessing,
 		TopupID:         utils.GenerateTopupID(),
-		Fee:             req.Fee,
+		Fee:             method.FeeFor(req.Amount),
 	}
 
 	if err := d.repo.Create(ctx, deposit); err != nil {
 		return nil, err
 	}
+	d.metrics.DepositStatus(string(deposit.Status))
 
 	return deposit, nil
 }
@@ -50,3 +76,49 @@ func (d *depositService) GetByDepositID(ctx context.Context, depositID string) (
 func (d *depositService) GetByUserID(ctx context.Context, userID uint64) ([]entity.Deposit, error) {
 	return d.repo.FindByUserID(ctx, userID)
 }
+
+// UpdateStatus implements DepositService.
+// Status success menambah saldo user di transaksi yang sama, jadi deposit yang sudah success
+// tidak boleh dipindah ke status lain.
+func (d *depositService) UpdateStatus(ctx context.Context, depositID string, req *dto.UpdateDepositStatus) (*entity.Deposit, error) {
+	deposit, err := d.repo.FindByTopupID(ctx, depositID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := entity.DepositStatus(req.Status)
+	if deposit.Status == status {
+		return deposit, nil
+	}
+	if deposit.Status == entity.DepSuccess {
+		return nil, apperror.New(apperror.CodeConflict, "deposit has already been credited", nil)
+	}
+
+	before := toDepositResponse(deposit)
+	deposit.Status = status
+	deposit.UpdatedAt = time.Now()
+	after := toDepositResponse(deposit)
+	event, err := d.webhooks.NewEvent(deposit.UserID, WebhookDepositStatusChanged, after)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.repo.UpdateStatus(ctx, deposit, entity.DepositStatus(before.Status), repository.Outbox{Webhook: event}); err != nil {
+		return nil, err
+	}
+	d.metrics.DepositStatus(string(deposit.Status))
+	d.audit.Record(ctx, entity.AuditUpdate, "deposit", deposit.ID, before, after)
+
+	return deposit, nil
+}
+
+func toDepositResponse(deposit *entity.Deposit) dto.DepositResponse {
+	return dto.DepositResponse{
+		TopupID:         deposit.TopupID,
+		PaymentMethodID: deposit.PaymentMethodID,
+		Amount:          deposit.Amount,
+		Fee:             deposit.Fee,
+		Status:          string(deposit.Status),
+		CreatedAt:       deposit.CreatedAt,
+		UpdatedAt:       deposit.UpdatedAt,
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/metrics"
+)
+
+type fakeDepositRepo struct {
+	repository.DepositRepository
+	deposit *entity.Deposit
+	from    entity.DepositStatus
+	outbox  *repository.Outbox
+}
+
+func (r *fakeDepositRepo) FindByTopupID(context.Context, string) (*entity.Deposit, error) {
+	cp := *r.deposit
+	return &cp, nil
+}
+
+func (r *fakeDepositRepo) UpdateStatus(_ context.Context, deposit *entity.Deposit, from entity.DepositStatus, outbox repository.Outbox) error {
+	r.deposit, r.from, r.outbox = deposit, from, &outbox
+	return nil
+}
+
+func TestDepositStatusChangeWritesWebhookWithTheUpdate(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeDepositRepo{deposit: &entity.Deposit{ID: 1, TopupID: "DEP-1", UserID: 7, Amount: 50000, Status: entity.DepProcessing}}
+	audit := &fakeAudit{}
+	svc := NewDepositService(repo, nil, NewWebhookService(&fakeWebhookRepo{}, audit), noSettings{}, audit, metrics.New())
+
+	if _, err := svc.UpdateStatus(ctx, "DEP-1", &dto.UpdateDepositStatus{Status: string(entity.DepSuccess)}); err != nil {
+		t.Fatal(err)
+	}
+	if repo.outbox == nil || repo.outbox.Webhook == nil {
+		t.Fatal("status change saved without a webhook event in the same transaction")
+	}
+	if repo.from != entity.DepProcessing || repo.deposit.Status != entity.DepSuccess {
+		t.Fatalf("moved %s -> %s, want processing -> success", repo.from, repo.deposit.Status)
+	}
+	ev := repo.outbox.Webhook
+	var body dto.WebhookEvent
+	if err := json.Unmarshal([]byte(ev.Payload), &body); err != nil {
+		t.Fatal(err)
+	}
+	if ev.UserID != 7 || ev.Event != WebhookDepositStatusChanged || body.ID != ev.ID {
+		t.Fatalf("unexpected webhook event %+v", ev)
+	}
+	if got := audit.recorded(); !slices.Equal(got, []string{"update deposit:1"}) {
+		t.Fatalf("audit entries %v", got)
+	}
+
+	// Deposit yang sudah di-credit tidak boleh dibatalkan lewat endpoint status
+	repo.outbox = nil
+	_, err := svc.UpdateStatus(ctx, "DEP-1", &dto.UpdateDepositStatus{Status: string(entity.DepCanceled)})
+	if !apperror.Is(err, apperror.CodeConflict) || repo.outbox != nil {
+		t.Fatalf("credited deposit changed again: %v", err)
+	}
+}
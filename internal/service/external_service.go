@@ -10,27 +10,40 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/wildanasyrof/backend-topup/internal/config"
 	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
 	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
 	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
 	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/metrics"
+	"github.com/wildanasyrof/backend-topup/pkg/money"
+	"github.com/wildanasyrof/backend-topup/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
-// Define constants for external service configuration
+// Define constants for external service configuration.
+// Base URL dan kredensial supplier diambil dari config (DIGIFLAZZ_*).
 const (
-	DFBaseURL  = "http://localhost:3000" // Should ideally be configured externally (e.g., environment variable)
-	DFEndpoint = "/v1/price-list?brand=Mobile%20Legends"
-	DFUsername = "demo_buyer"
-	DFAPIKey   = "secret_apikey_demo" // Should *never* be hardcoded in production code! Use a secret manager.
-	DFCommand  = "prepaid"
+	DFEndpoint        = "/v1/price-list?brand=Mobile%20Legends"
+	DFBalanceEndpoint = "/v1/cek-saldo"
+	DFCommand         = "prepaid"
 )
 
 // ExternalService defines the interface for external API interactions.
 type ExternalService interface {
 	DFGetProductList(ctx context.Context) ([]dto.DFProductListRes, error)
 	DFSaveProductList(ctx context.Context) ([]dto.DFProductListRes, error)
+	// DFGetBalance mengambil sisa saldo deposit di supplier
+	DFGetBalance(ctx context.Context) (money.Money, error)
+	// DFPing memeriksa apakah API supplier bisa dijangkau; status HTTP apa pun dianggap berhasil
+	DFPing(ctx context.Context) error
 }
 
 // externalService holds the dependencies for interacting with the external API.
@@ -38,18 +51,20 @@ type externalService struct {
 	httpClient  *http.Client
 	logger      logger.Logger
 	productRepo repository.ProductRepository
-	// Consider adding base URL/credentials here if they vary or need to be configured at startup.
+	supplier    config.DigiflazzConfig
+	metrics     metrics.Metrics
 }
 
 // NewExternalService is the constructor for externalService.
-func NewExternalService(httpClient *http.Client, logger logger.Logger, productRepo repository.ProductRepository) ExternalService {
-	return &externalService{httpClient: httpClient, logger: logger, productRepo: productRepo}
+func NewExternalService(httpClient *http.Client, logger logger.Logger, productRepo repository.ProductRepository, supplier config.DigiflazzConfig, metrics metrics.Metrics) ExternalService {
+	return &externalService{httpClient: httpClient, logger: logger, productRepo: productRepo, supplier: supplier, metrics: metrics}
 }
 
 // makeSign generates the MD5 hash for API authentication.
-func makeSign(username, apiKey string) string {
+// suffix bergantung pada perintah: "pricelist" untuk daftar harga, "depo" untuk cek saldo.
+func makeSign(username, apiKey, suffix string) string {
 	// Concatenate the required components for the hash
-	dataToHash := username + apiKey + "pricelist"
+	dataToHash := username + apiKey + suffix
 	hash := md5.Sum([]byte(dataToHash))
 	return hex.EncodeToString(hash[:])
 }
@@ -59,20 +74,75 @@ func (e *externalService) DFGetProductList(ctx context.Context) ([]dto.DFProduct
 	// 1. Prepare Request Body
 	reqBody := dto.DFProductListReq{
 		Cmd:      DFCommand,
-		Username: DFUsername,
-		Sign:     makeSign(DFUsername, DFAPIKey),
+		Username: e.supplier.Username,
+		Sign:     makeSign(e.supplier.Username, e.supplier.APIKey, "pricelist"),
 	}
 
+	var payload dto.DFBaseRes
+	if err := e.call(ctx, "price_list", DFEndpoint, reqBody, &payload); err != nil {
+		return nil, err
+	}
+	return payload.Data, nil
+}
+
+// DFGetBalance implements ExternalService.
+func (e *externalService) DFGetBalance(ctx context.Context) (money.Money, error) {
+	reqBody := dto.DFBalanceReq{
+		Cmd:      "deposit",
+		Username: e.supplier.Username,
+		Sign:     makeSign(e.supplier.Username, e.supplier.APIKey, "depo"),
+	}
+
+	var payload dto.DFBalanceRes
+	if err := e.call(ctx, "balance", DFBalanceEndpoint, reqBody, &payload); err != nil {
+		return 0, err
+	}
+	return money.Money(payload.Data.Deposit), nil
+}
+
+// DFPing implements ExternalService.
+func (e *externalService) DFPing(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, e.supplier.BaseURL, nil)
+	if err != nil {
+		return fmt.Errorf("create request error: %w", err)
+	}
+	res, err := e.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("supplier unreachable: %w", err)
+	}
+	return res.Body.Close()
+}
+
+// call mengirim request JSON ke supplier dan mencatat latency serta error-nya ke metrics.
+func (e *externalService) call(ctx context.Context, operation, endpoint string, reqBody, out any) (err error) {
+	if e.supplier.Username == "" || e.supplier.APIKey == "" {
+		return apperror.New(apperror.CodeUnavailable, "supplier credentials are not configured", nil)
+	}
+
+	ctx, span := tracing.Tracer().Start(ctx, "digiflazz "+operation, trace.WithAttributes(
+		attribute.String("supplier.name", "digiflazz"),
+		attribute.String("supplier.operation", operation),
+	))
+	started := time.Now()
+	defer func() {
+		e.metrics.ObserveSupplier("digiflazz", operation, time.Since(started), err)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "supplier call failed")
+		}
+		span.End()
+	}()
+
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("marshal request error: %w", err)
+		return fmt.Errorf("marshal request error: %w", err)
 	}
 
 	// 2. Create HTTP Request
-	url := DFBaseURL + DFEndpoint
+	url := strings.TrimRight(e.supplier.BaseURL, "/") + endpoint
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bodyBytes))
 	if err != nil {
-		return nil, fmt.Errorf("create request error: %w", err)
+		return fmt.Errorf("create request error: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -80,7 +150,7 @@ func (e *externalService) DFGetProductList(ctx context.Context) ([]dto.DFProduct
 	// 3. Execute HTTP Request
 	res, err := e.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("http execution error: %w", err)
+		return fmt.Errorf("http execution error: %w", err)
 	}
 	defer func() {
 		// Ensure the response body is closed to prevent resource leaks
@@ -92,22 +162,20 @@ func (e *externalService) DFGetProductList(ctx context.Context) ([]dto.DFProduct
 	// 4. Read Response Body
 	rawBody, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, fmt.Errorf("read response body error: %w", err)
+		return fmt.Errorf("read response body error: %w", err)
 	}
 
 	// 5. Handle Non-2xx Status Codes
 	if res.StatusCode < 200 || res.StatusCode >= 300 {
 		// Return a specific error indicating a bad response
-		return nil, fmt.Errorf("external service bad response: status %d, body: %s", res.StatusCode, rawBody)
+		return fmt.Errorf("external service bad response: status %d, body: %s", res.StatusCode, rawBody)
 	}
 
 	// 6. Unmarshal Response
-	var payload dto.DFBaseRes
-	if err := json.Unmarshal(rawBody, &payload); err != nil {
-		return nil, fmt.Errorf("unmarshal response error: %w", err)
+	if err := json.Unmarshal(rawBody, out); err != nil {
+		return fmt.Errorf("unmarshal response error: %w", err)
 	}
-
-	return payload.Data, nil
+	return nil
 }
 
 // DFGetProductList implements ExternalService.
@@ -131,7 +199,7 @@ func (e *externalService) DFSaveProductList(ctx context.Context) ([]dto.DFProduc
 				ProviderID:  5,
 				Status:      StatusMapper(it.BuyerProductStatus),
 				Stock:       StockMapper(it.Stock, it.UnlimitedStock),
-				BasePrice:   float64(it.Price),
+				BasePrice:   money.Money(it.Price),
 				Description: it.Desc,
 				ImgUrl:      "",
 				StartOff:    it.StartCutOff,
@@ -143,7 +211,7 @@ func (e *externalService) DFSaveProductList(ctx context.Context) ([]dto.DFProduc
 			}
 		}
 
-		product.BasePrice = float64(it.Price)
+		product.BasePrice = money.Money(it.Price)
 		product.Stock = StockMapper(it.Stock, it.UnlimitedStock)
 		product.Status = StatusMapper(it.BuyerProductStatus)
 		product.StartOff = it.StartCutOff
@@ -0,0 +1,749 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wildanasyrof/backend-topup/internal/config"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/jwt"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// Repository in-memory untuk test service. Interface di-embed agar method yang tidak dipakai
+// test cukup panic, bukan diimplementasikan semua.
+
+type fakeOTPRepo struct {
+	mu     sync.Mutex
+	nextID uint64
+	otps   map[uint64]*entity.UserOTP
+}
+
+func newFakeOTPRepo() *fakeOTPRepo {
+	return &fakeOTPRepo{otps: map[uint64]*entity.UserOTP{}}
+}
+
+func (r *fakeOTPRepo) Create(_ context.Context, otp *entity.UserOTP) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	otp.ID = r.nextID
+	otp.CreatedAt = time.Now()
+	cp := *otp
+	r.otps[otp.ID] = &cp
+	return nil
+}
+
+func (r *fakeOTPRepo) FindLatestActive(_ context.Context, userID uint64, purpose entity.OTPPurpose) (*entity.UserOTP, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var latest *entity.UserOTP
+	for _, o := range r.otps {
+		if o.UserID == userID && o.Purpose == purpose && o.ConsumedAt == nil && o.ExpiresAt.After(time.Now()) {
+			if latest == nil || o.ID > latest.ID {
+				latest = o
+			}
+		}
+	}
+	if latest == nil {
+		return nil, apperror.ErrNotFound
+	}
+	cp := *latest
+	return &cp, nil
+}
+
+func (r *fakeOTPRepo) CountSince(_ context.Context, userID uint64, purpose entity.OTPPurpose, since time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var n int64
+	for _, o := range r.otps {
+		if o.UserID == userID && o.Purpose == purpose && o.CreatedAt.After(since) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (r *fakeOTPRepo) RecordAttempt(_ context.Context, id uint64, max int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o := r.otps[id]
+	if o == nil || o.Attempts >= max {
+		return false, nil
+	}
+	o.Attempts++
+	return true, nil
+}
+
+func (r *fakeOTPRepo) Consume(_ context.Context, id uint64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o := r.otps[id]
+	if o == nil || o.ConsumedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	o.ConsumedAt = &now
+	return true, nil
+}
+
+func (r *fakeOTPRepo) InvalidateAll(_ context.Context, userID uint64, purpose entity.OTPPurpose) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, o := range r.otps {
+		if o.UserID == userID && o.Purpose == purpose && o.ConsumedAt == nil {
+			o.ConsumedAt = &now
+		}
+	}
+	return nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	mu     sync.Mutex
+	nextID uint64
+	users  map[uint64]*entity.User
+}
+
+func newFakeUserRepo() *fakeUserRepo {
+	return &fakeUserRepo{users: map[uint64]*entity.User{}}
+}
+
+func (r *fakeUserRepo) Store(_ context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Email == user.Email {
+			return apperror.New(apperror.CodeConflict, "user already exist", nil)
+		}
+	}
+	r.nextID++
+	user.ID = r.nextID
+	cp := *user
+	r.users[user.ID] = &cp
+	return nil
+}
+
+func (r *fakeUserRepo) GetByEmail(_ context.Context, email string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Email == email {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return nil, apperror.New(apperror.CodeUnauthorized, "invalid credentials", nil)
+}
+
+func (r *fakeUserRepo) GetByID(_ context.Context, id uint64) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[id]
+	if !ok {
+		return nil, apperror.ErrNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (r *fakeUserRepo) Update(_ context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Seperti repository, totp_last_step dan balance tidak ikut disimpan
+	lastStep, balance := int64(0), user.Balance
+	if stored, ok := r.users[user.ID]; ok {
+		lastStep, balance = stored.TOTPLastStep, stored.Balance
+	}
+	// Meniru unique index ux_users_whatsapp_verified
+	if user.WhatsappVerifiedAt != nil {
+		for _, u := range r.users {
+			if u.ID != user.ID && u.WhatsappVerifiedAt != nil && u.Whatsapp == user.Whatsapp {
+				return apperror.New(apperror.CodeConflict, "whatsapp number is already used by another account", nil)
+			}
+		}
+	}
+	cp := *user
+	cp.TOTPLastStep, cp.Balance = lastStep, balance
+	r.users[user.ID] = &cp
+	return nil
+}
+
+func (r *fakeUserRepo) AdvanceTOTPStep(_ context.Context, userID uint64, step int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u, ok := r.users[userID]
+	if !ok || u.TOTPLastStep >= step {
+		return false, nil
+	}
+	u.TOTPLastStep = step
+	return true, nil
+}
+
+// FindByWhatsapp meniru repository: hanya nomor terverifikasi yang cocok
+func (r *fakeUserRepo) FindByWhatsapp(_ context.Context, phone string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.Whatsapp == phone && u.WhatsappVerifiedAt != nil {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return nil, apperror.ErrNotFound
+}
+
+func (r *fakeUserRepo) FindByGoogleID(_ context.Context, id string) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.GoogleID != "" && u.GoogleID == id {
+			cp := *u
+			return &cp, nil
+		}
+	}
+	return &entity.User{}, gorm.ErrRecordNotFound
+}
+
+// get membaca state tersimpan tanpa lewat service
+func (r *fakeUserRepo) get(t *testing.T, id uint64) *entity.User {
+	t.Helper()
+	u, err := r.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("user %d: %v", id, err)
+	}
+	return u
+}
+
+type fakeSessionRepo struct {
+	repository.SessionRepository
+	mu       sync.Mutex
+	sessions map[uuid.UUID]*entity.UserSession
+	// users dipakai FindAccess untuk token version dan status suspend
+	users *fakeUserRepo
+	// accessLookups menghitung pemanggilan FindAccess
+	accessLookups int
+}
+
+func newFakeSessionRepo() *fakeSessionRepo {
+	return &fakeSessionRepo{sessions: map[uuid.UUID]*entity.UserSession{}}
+}
+
+func (r *fakeSessionRepo) Create(_ context.Context, s *entity.UserSession) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := s.BeforeCreate(nil); err != nil {
+		return err
+	}
+	cp := *s
+	r.sessions[s.ID] = &cp
+	return nil
+}
+
+func (r *fakeSessionRepo) FindByID(_ context.Context, id uuid.UUID) (*entity.UserSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *session
+	// Seperti Preload("User") di repository
+	if r.users != nil {
+		r.users.mu.Lock()
+		if u, ok := r.users.users[cp.UserID]; ok {
+			cp.User = *u
+		}
+		r.users.mu.Unlock()
+	}
+	return &cp, nil
+}
+
+func (r *fakeSessionRepo) MarkRotated(_ context.Context, id, replacedBy uuid.UUID) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.sessions[id]
+	if !ok || s.RotatedAt != nil || s.IsRevoked {
+		return false, nil
+	}
+	now := time.Now()
+	s.RotatedAt, s.ReplacedBy = &now, &replacedBy
+	return true, nil
+}
+
+func (r *fakeSessionRepo) RevokeOtherFamilies(_ context.Context, userID uint64, keepFamilyID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.sessions {
+		if s.UserID == userID && s.FamilyID != keepFamilyID && s.ID != keepFamilyID {
+			s.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) RevokeFamily(_ context.Context, familyID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.sessions {
+		if s.FamilyID == familyID || s.ID == familyID {
+			s.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+func (r *fakeSessionRepo) FindAccess(ctx context.Context, id uuid.UUID) (*repository.SessionAccess, error) {
+	if r.users == nil {
+		panic("fakeSessionRepo.users is not set")
+	}
+	r.mu.Lock()
+	r.accessLookups++
+	session, ok := r.sessions[id]
+	var access repository.SessionAccess
+	if ok {
+		access = repository.SessionAccess{UserID: session.UserID, IsRevoked: session.IsRevoked, ExpiresAt: session.ExpiresAt}
+	}
+	r.mu.Unlock()
+	if !ok {
+		return nil, apperror.ErrUnauthorized
+	}
+
+	user, err := r.users.GetByID(ctx, access.UserID)
+	if err != nil {
+		return nil, err
+	}
+	access.TokenVersion = user.TokenVersion
+	access.IsSuspended = user.SuspendedAt != nil
+	return &access, nil
+}
+
+func (r *fakeSessionRepo) lookups() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.accessLookups
+}
+
+func (r *fakeSessionRepo) DeleteExpiredByUserID(context.Context, uint64) error { return nil }
+
+func (r *fakeSessionRepo) RevokeAllByUserID(_ context.Context, userID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.sessions {
+		if s.UserID == userID {
+			s.IsRevoked = true
+		}
+	}
+	return nil
+}
+
+// active menghitung sesi user yang belum di-revoke
+func (r *fakeSessionRepo) active(userID uint64) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, s := range r.sessions {
+		if s.UserID == userID && !s.IsRevoked {
+			n++
+		}
+	}
+	return n
+}
+
+// fakeRoleRepo menyimpan role sebagai nama -> permission, dengan ID sesuai urutan di ids
+type fakeRoleRepo struct {
+	repository.RoleRepository
+	roles map[string][]string
+	ids   []string
+}
+
+func newFakeRoleRepo(roles map[string][]string) *fakeRoleRepo {
+	r := &fakeRoleRepo{roles: roles}
+	for name := range roles {
+		r.ids = append(r.ids, name)
+	}
+	slices.Sort(r.ids)
+	return r
+}
+
+func (r *fakeRoleRepo) role(id int, name string) *entity.Role {
+	role := &entity.Role{ID: uint64(id + 1), Name: name}
+	for _, p := range r.roles[name] {
+		role.Permissions = append(role.Permissions, entity.RolePermission{RoleID: role.ID, Permission: p})
+	}
+	return role
+}
+
+func (r *fakeRoleRepo) FindByName(_ context.Context, name string) (*entity.Role, error) {
+	i := slices.Index(r.ids, name)
+	if i < 0 {
+		return nil, apperror.ErrNotFound
+	}
+	return r.role(i, name), nil
+}
+
+func (r *fakeRoleRepo) FindByID(_ context.Context, id uint64) (*entity.Role, error) {
+	if id == 0 || int(id) > len(r.ids) {
+		return nil, apperror.ErrNotFound
+	}
+	return r.role(int(id-1), r.ids[id-1]), nil
+}
+
+func (r *fakeRoleRepo) Create(_ context.Context, role *entity.Role) error {
+	r.ids = append(r.ids, role.Name)
+	role.ID = uint64(len(r.ids))
+	return r.Update(context.Background(), role)
+}
+
+func (r *fakeRoleRepo) Update(_ context.Context, role *entity.Role) error {
+	perms := []string{}
+	for _, p := range role.Permissions {
+		perms = append(perms, p.Permission)
+	}
+	r.roles[role.Name] = perms
+	return nil
+}
+
+func (r *fakeRoleRepo) CountUsers(context.Context, string) (int64, error) { return 0, nil }
+
+func (r *fakeRoleRepo) Delete(_ context.Context, id uint64) error {
+	delete(r.roles, r.ids[id-1])
+	r.ids[id-1] = ""
+	return nil
+}
+
+type fakeIdentityRepo struct {
+	mu         sync.Mutex
+	users      *fakeUserRepo
+	identities []entity.UserIdentity
+}
+
+func (r *fakeIdentityRepo) Create(_ context.Context, identity *entity.UserIdentity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, i := range r.identities {
+		if i.Provider == identity.Provider && (i.Subject == identity.Subject || i.UserID == identity.UserID) {
+			return apperror.New(apperror.CodeConflict, identity.Provider+" account is already linked", nil)
+		}
+	}
+	r.identities = append(r.identities, *identity)
+	return nil
+}
+
+// CreateWithUser memakai users agar user dan identitas tersimpan bersamaan seperti transaksi repository
+func (r *fakeIdentityRepo) CreateWithUser(ctx context.Context, user *entity.User, identity *entity.UserIdentity) error {
+	if r.users == nil {
+		panic("fakeIdentityRepo.users is not set")
+	}
+	if _, err := r.FindBySubject(ctx, identity.Provider, identity.Subject); err == nil {
+		return apperror.New(apperror.CodeConflict, identity.Provider+" account is already linked", nil)
+	}
+	if err := r.users.Store(ctx, user); err != nil {
+		return err
+	}
+	identity.UserID = user.ID
+	return r.Create(ctx, identity)
+}
+
+func (r *fakeIdentityRepo) FindBySubject(_ context.Context, provider, subject string) (*entity.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, i := range r.identities {
+		if i.Provider == provider && i.Subject == subject {
+			cp := i
+			return &cp, nil
+		}
+	}
+	return nil, apperror.ErrNotFound
+}
+
+func (r *fakeIdentityRepo) FindByUser(_ context.Context, userID uint64) ([]entity.UserIdentity, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []entity.UserIdentity
+	for _, i := range r.identities {
+		if i.UserID == userID {
+			out = append(out, i)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeIdentityRepo) Delete(_ context.Context, userID uint64, provider string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for n, i := range r.identities {
+		if i.UserID == userID && i.Provider == provider {
+			r.identities = append(r.identities[:n], r.identities[n+1:]...)
+			return nil
+		}
+	}
+	return apperror.ErrNotFound
+}
+
+type fakeRecoveryRepo struct {
+	mu    sync.Mutex
+	codes map[uint64]map[string]bool // user -> hash -> sudah dipakai
+}
+
+func newFakeRecoveryRepo() *fakeRecoveryRepo {
+	return &fakeRecoveryRepo{codes: map[uint64]map[string]bool{}}
+}
+
+func (r *fakeRecoveryRepo) Replace(_ context.Context, userID uint64, hashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codes[userID] = map[string]bool{}
+	for _, h := range hashes {
+		r.codes[userID][h] = false
+	}
+	return nil
+}
+
+func (r *fakeRecoveryRepo) Use(_ context.Context, userID uint64, hash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	used, ok := r.codes[userID][hash]
+	if !ok || used {
+		return false, nil
+	}
+	r.codes[userID][hash] = true
+	return true, nil
+}
+
+func (r *fakeRecoveryRepo) DeleteAll(_ context.Context, userID uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.codes, userID)
+	return nil
+}
+
+type fakeChallengeRepo struct {
+	mu   sync.Mutex
+	used map[string]bool
+}
+
+func newFakeChallengeRepo() *fakeChallengeRepo {
+	return &fakeChallengeRepo{used: map[string]bool{}}
+}
+
+func (r *fakeChallengeRepo) Consume(_ context.Context, jti string, _ uint64, _ time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.used[jti] {
+		return false, nil
+	}
+	r.used[jti] = true
+	return true, nil
+}
+
+// fakeClock: waktu yang hanya bergerak lewat advance
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// fakeLoginAttemptRepo meniru upsert RecordFailure: counter dan kunci dimulai ulang setelah window
+type fakeLoginAttemptRepo struct {
+	mu       sync.Mutex
+	clock    *fakeClock
+	attempts map[string]*entity.LoginAttempt
+}
+
+func newFakeLoginAttemptRepo(clock *fakeClock) *fakeLoginAttemptRepo {
+	return &fakeLoginAttemptRepo{clock: clock, attempts: map[string]*entity.LoginAttempt{}}
+}
+
+func (r *fakeLoginAttemptRepo) Find(_ context.Context, key string) (*entity.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	cp := *a
+	return &cp, nil
+}
+
+func (r *fakeLoginAttemptRepo) RecordFailure(_ context.Context, key string, window time.Duration) (*entity.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.clock.Now()
+	a, ok := r.attempts[key]
+	switch {
+	case !ok:
+		a = &entity.LoginAttempt{Key: key}
+		r.attempts[key] = a
+	case a.LastFailureAt.Before(now.Add(-window)):
+		a.Failures, a.LockedUntil = 0, nil
+	}
+	a.Failures++
+	a.LastFailureAt = now
+	cp := *a
+	return &cp, nil
+}
+
+func (r *fakeLoginAttemptRepo) Lock(_ context.Context, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a, ok := r.attempts[key]; ok {
+		a.LockedUntil = &until
+	}
+	return nil
+}
+
+func (r *fakeLoginAttemptRepo) Reset(_ context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.attempts, key)
+	return nil
+}
+
+// fakeInvalidationBus meniru LISTEN/NOTIFY: Publish langsung dikirim ke semua listener yang terhubung
+type fakeInvalidationBus struct {
+	mu        sync.Mutex
+	listeners map[int]func(uint64)
+	nextID    int
+	dropped   chan struct{} // ditutup oleh disconnect untuk memutus semua listener
+}
+
+func newFakeInvalidationBus() *fakeInvalidationBus {
+	return &fakeInvalidationBus{listeners: map[int]func(uint64){}, dropped: make(chan struct{})}
+}
+
+func (b *fakeInvalidationBus) Publish(_ context.Context, userID uint64) error {
+	b.mu.Lock()
+	listeners := make([]func(uint64), 0, len(b.listeners))
+	for _, fn := range b.listeners {
+		listeners = append(listeners, fn)
+	}
+	b.mu.Unlock()
+	for _, fn := range listeners {
+		fn(userID)
+	}
+	return nil
+}
+
+func (b *fakeInvalidationBus) Listen(ctx context.Context, onConnect func(), fn func(uint64)) error {
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	dropped := b.dropped
+	b.mu.Unlock()
+	// Seperti LISTEN: listener terdaftar dulu, baru onConnect, agar tidak ada notifikasi yang terlewat di antaranya
+	b.mu.Lock()
+	b.listeners[id] = fn
+	b.mu.Unlock()
+	onConnect()
+	defer func() {
+		b.mu.Lock()
+		delete(b.listeners, id)
+		b.mu.Unlock()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-dropped:
+		return errors.New("connection reset")
+	}
+}
+
+func (b *fakeInvalidationBus) disconnect() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	close(b.dropped)
+	b.dropped = make(chan struct{})
+}
+
+// noSettings: semua setting bernilai default nol (mis. 2FA admin tidak diwajibkan)
+type noSettings struct{ SettingsReader }
+
+func (noSettings) Bool(context.Context, string) bool { return false }
+
+// fakeAudit mencatat setiap Record sebagai "action entity_type:id"
+type fakeAudit struct {
+	AuditLogService
+	mu      sync.Mutex
+	entries []string
+}
+
+func (a *fakeAudit) Record(_ context.Context, action, entityType string, entityID any, _, _ any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.entries = append(a.entries, fmt.Sprintf("%s %s:%v", action, entityType, entityID))
+}
+
+func (a *fakeAudit) recorded() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.entries)
+}
+
+// noTwoFactor: user tanpa 2FA dan tanpa kewajiban 2FA
+type noTwoFactor struct{ TwoFactorService }
+
+func (noTwoFactor) Required(context.Context, *entity.User) bool { return false }
+
+// allowLogins: LoginGuard yang tidak pernah mengunci
+type allowLogins struct{}
+
+func (allowLogins) Check(context.Context, string, string) error { return nil }
+func (allowLogins) Failure(context.Context, string, string)     {}
+func (allowLogins) Success(context.Context, string)             {}
+
+func testLogger() logger.Logger {
+	l := logger.NewZerologLogger("test")
+	l.SetLevel("panic")
+	return l
+}
+
+func testJWT(t *testing.T) jwt.JWTService {
+	t.Helper()
+	svc, err := jwt.NewJWTService(&config.Config{JWT: config.JwtConfig{
+		AccessSecret: "test-secret", AccessTokenMinutes: 15, RefreshTokenDays: 30,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+var codePattern = regexp.MustCompile(`\b\d{6}\b`)
+
+// codeIn mengambil kode OTP 6 digit dari isi pesan
+func codeIn(t *testing.T, body string) string {
+	t.Helper()
+	code := codePattern.FindString(body)
+	if code == "" {
+		t.Fatalf("no code in message %q", body)
+	}
+	return code
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/migrate"
+)
+
+// HealthService menjawab liveness/readiness untuk load balancer dan orchestrator.
+type HealthService interface {
+	// Ready menjalankan semua check secara paralel. Instance siap jika semua check Critical lolos
+	// dan belum masuk fase shutdown.
+	Ready(ctx context.Context) dto.HealthReport
+	// Drain menandai instance sedang shutdown sehingga readiness langsung gagal
+	Drain()
+}
+
+type healthCheck struct {
+	name     string
+	critical bool
+	run      func(ctx context.Context) error
+}
+
+type healthService struct {
+	checks   []healthCheck
+	timeout  time.Duration
+	logger   logger.Logger
+	draining atomic.Bool
+}
+
+// NewHealthService menyusun check readiness. supplier boleh nil jika pengecekan supplier dimatikan.
+func NewHealthService(db *sql.DB, migrator migrate.Migrator, uploadDir string, supplier ExternalService, timeout time.Duration, logger logger.Logger) HealthService {
+	checks := []healthCheck{
+		{name: "database", critical: true, run: db.PingContext},
+		{name: "migrations", critical: true, run: func(ctx context.Context) error {
+			pending, err := migrator.Pending(ctx)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("%d pending migration(s)", len(pending))
+			}
+			return nil
+		}},
+		{name: "upload_dir", critical: true, run: func(context.Context) error {
+			return checkWritable(uploadDir)
+		}},
+	}
+	if supplier != nil {
+		// Supplier down dialami semua instance sekaligus; mengeluarkan instance dari rotasi tidak membantu
+		checks = append(checks, healthCheck{name: "supplier", critical: false, run: supplier.DFPing})
+	}
+	return &healthService{checks: checks, timeout: timeout, logger: logger}
+}
+
+// Ready implements HealthService.
+func (s *healthService) Ready(ctx context.Context) dto.HealthReport {
+	started := time.Now()
+	if s.draining.Load() {
+		return dto.HealthReport{Status: dto.HealthDraining, Checks: []dto.HealthCheck{}}
+	}
+
+	results := make([]dto.HealthCheck, len(s.checks))
+	var wg sync.WaitGroup
+	for i, c := range s.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = s.run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := dto.HealthReport{Status: dto.HealthOK, Checks: results}
+	for _, r := range results {
+		if r.Critical && r.Status != dto.HealthOK {
+			report.Status = dto.HealthFail
+		}
+	}
+	report.DurationMs = millis(time.Since(started))
+	return report
+}
+
+// Drain implements HealthService.
+func (s *healthService) Drain() {
+	s.draining.Store(true)
+}
+
+func (s *healthService) run(ctx context.Context, c healthCheck) dto.HealthCheck {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	started := time.Now()
+	err := c.run(ctx)
+	result := dto.HealthCheck{Name: c.name, Status: dto.HealthOK, Critical: c.critical, DurationMs: millis(time.Since(started))}
+	if err != nil {
+		// Endpoint ini publik: detail error (host, path) hanya masuk log
+		s.logger.With(logger.Fields{"check": c.name}).Error(err, "readiness check failed")
+		result.Status = dto.HealthFail
+		result.Error = "check failed"
+		if ctx.Err() != nil {
+			result.Error = "timed out"
+		}
+	}
+	return result
+}
+
+// checkWritable memastikan file upload benar-benar bisa ditulis, bukan sekadar direktorinya ada.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".health-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	if err := f.Close(); err != nil {
+		os.Remove(name)
+		return err
+	}
+	return os.Remove(name)
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/oauth"
+	"gorm.io/gorm"
+)
+
+// IdentityService mengelola login dan penautan akun lewat provider OAuth/OIDC.
+type IdentityService interface {
+	// Authenticate mencari user pemilik identitas, menautkan via email terverifikasi, atau mendaftarkan user baru
+	Authenticate(ctx context.Context, info *oauth.UserInfo) (*entity.User, error)
+	// Link menautkan identitas provider ke user yang sedang login
+	Link(ctx context.Context, userID uint64, info *oauth.UserInfo) (*entity.User, error)
+	// Unlink melepas identitas; user harus tetap punya cara lain untuk login
+	Unlink(ctx context.Context, userID uint64, provider string) error
+	List(ctx context.Context, userID uint64) ([]entity.UserIdentity, error)
+}
+
+type identityService struct {
+	userRepository     repository.UserRepository
+	identityRepository repository.IdentityRepository
+}
+
+func NewIdentityService(userRepository repository.UserRepository, identityRepository repository.IdentityRepository) IdentityService {
+	return &identityService{userRepository: userRepository, identityRepository: identityRepository}
+}
+
+// Authenticate implements IdentityService.
+func (s *identityService) Authenticate(ctx context.Context, info *oauth.UserInfo) (*entity.User, error) {
+	user, err := s.findOwner(ctx, info)
+	if err != nil || user != nil {
+		return user, err
+	}
+
+	// Belum ada identitas, coba cocokkan dengan email akun yang sudah ada
+	if info.Email == "" {
+		return nil, apperror.New(apperror.CodeBadRequest, info.Provider+" did not share an email address", nil)
+	}
+	existing, err := s.userRepository.GetByEmail(ctx, info.Email)
+	if err != nil && !apperror.Is(err, apperror.CodeUnauthorized) {
+		return nil, err
+	}
+	if err == nil {
+		// Tautkan otomatis hanya jika provider menjamin email tersebut milik pemilik akun, dan akun lokal
+		// juga sudah membuktikan email-nya. Akun lokal yang belum terverifikasi bisa saja didaftarkan orang
+		// lain dengan email korban; menautkannya akan memberi pendaftar itu akses ke login korban.
+		if !info.EmailVerified || !existing.IsVerified {
+			return nil, apperror.New(apperror.CodeConflict, "an account with this email already exists, log in and link "+info.Provider+" from your profile", nil)
+		}
+		return s.Link(ctx, existing.ID, info)
+	}
+
+	name := info.Name
+	if name == "" {
+		name = strings.Split(info.Email, "@")[0]
+	}
+	user = &entity.User{
+		Name:       name,
+		Email:      info.Email,
+		IsVerified: info.EmailVerified,
+		Role:       "user",
+	}
+	if info.EmailVerified {
+		user.EmailVerifiedAt = time.Now()
+	}
+	// Satu transaksi: jika identitas gagal disimpan, user tidak tertinggal tanpa cara login
+	if err := s.identityRepository.CreateWithUser(ctx, user, newIdentity(0, info)); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Link implements IdentityService.
+func (s *identityService) Link(ctx context.Context, userID uint64, info *oauth.UserInfo) (*entity.User, error) {
+	owner, err := s.findOwner(ctx, info)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil {
+		if owner.ID != userID {
+			return nil, apperror.New(apperror.CodeConflict, info.Provider+" account is already linked to another user", nil)
+		}
+		return owner, nil
+	}
+
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.adoptLegacyGoogle(ctx, user); err != nil {
+		return nil, err
+	}
+
+	identities, err := s.identityRepository.FindByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, identity := range identities {
+		if identity.Provider == info.Provider {
+			return nil, apperror.New(apperror.CodeConflict, "another "+info.Provider+" account is already linked, unlink it first", nil)
+		}
+	}
+
+	if err := s.identityRepository.Create(ctx, newIdentity(userID, info)); err != nil {
+		return nil, err
+	}
+
+	// Provider sudah memverifikasi email yang sama, anggap email kita juga terverifikasi
+	if info.EmailVerified && info.Email == user.Email && !user.IsVerified {
+		user.IsVerified = true
+		user.EmailVerifiedAt = time.Now()
+		if err := s.userRepository.Update(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// Unlink implements IdentityService.
+func (s *identityService) Unlink(ctx context.Context, userID uint64, provider string) error {
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.adoptLegacyGoogle(ctx, user); err != nil {
+		return err
+	}
+
+	identities, err := s.identityRepository.FindByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	linked := false
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			linked = true
+		}
+	}
+	if !linked {
+		return apperror.New(apperror.CodeNotFound, provider+" account is not linked", nil)
+	}
+	if user.PasswordHash == "" && len(identities) == 1 {
+		return apperror.New(apperror.CodeConflict, "set a password before unlinking "+provider, nil)
+	}
+
+	return s.identityRepository.Delete(ctx, userID, provider)
+}
+
+// List implements IdentityService.
+func (s *identityService) List(ctx context.Context, userID uint64) ([]entity.UserIdentity, error) {
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.adoptLegacyGoogle(ctx, user); err != nil {
+		return nil, err
+	}
+	return s.identityRepository.FindByUser(ctx, userID)
+}
+
+// findOwner mengembalikan user pemilik identitas, atau nil jika belum tertaut.
+func (s *identityService) findOwner(ctx context.Context, info *oauth.UserInfo) (*entity.User, error) {
+	identity, err := s.identityRepository.FindBySubject(ctx, info.Provider, info.Subject)
+	if err == nil {
+		return s.userRepository.GetByID(ctx, identity.UserID)
+	}
+	if !apperror.Is(err, apperror.CodeNotFound) {
+		return nil, err
+	}
+
+	if info.Provider != "google" {
+		return nil, nil
+	}
+	user, err := s.userRepository.FindByGoogleID(ctx, info.Subject)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.adoptLegacyGoogle(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// adoptLegacyGoogle memindahkan users.google_id (sebelum ada user_identities) ke tabel identitas.
+func (s *identityService) adoptLegacyGoogle(ctx context.Context, user *entity.User) error {
+	if user.GoogleID == "" {
+		return nil
+	}
+
+	identity := &entity.UserIdentity{UserID: user.ID, Provider: "google", Subject: user.GoogleID, Email: user.Email}
+	if err := s.identityRepository.Create(ctx, identity); err != nil && !apperror.Is(err, apperror.CodeConflict) {
+		return err
+	}
+
+	user.GoogleID = ""
+	user.GoogleType = ""
+	return s.userRepository.Update(ctx, user)
+}
+
+func newIdentity(userID uint64, info *oauth.UserInfo) *entity.UserIdentity {
+	return &entity.UserIdentity{
+		UserID:   userID,
+		Provider: info.Provider,
+		Subject:  info.Subject,
+		Email:    info.Email,
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/oauth"
+)
+
+func githubInfo(email string, verified bool) *oauth.UserInfo {
+	return &oauth.UserInfo{Provider: "github", Subject: "gh-1", Email: email, EmailVerified: verified, Name: "Octo"}
+}
+
+func TestAuthenticateLinksOnlyVerifiedLocalAccounts(t *testing.T) {
+	cases := []struct {
+		name          string
+		localVerified bool
+		idpVerified   bool
+		wantLinked    bool
+	}{
+		{"both verified", true, true, true},
+		{"local account unverified", false, true, false},
+		{"provider email unverified", true, false, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := newFakeUserRepo()
+			identities := &fakeIdentityRepo{users: users}
+			svc := NewIdentityService(users, identities)
+
+			local := &entity.User{Email: "victim@example.com", Name: "Local", PasswordHash: "x", IsVerified: tc.localVerified}
+			if err := users.Store(ctx, local); err != nil {
+				t.Fatal(err)
+			}
+
+			user, err := svc.Authenticate(ctx, githubInfo("victim@example.com", tc.idpVerified))
+			if tc.wantLinked {
+				if err != nil || user.ID != local.ID {
+					t.Fatalf("want link to user %d, got %v, %v", local.ID, user, err)
+				}
+				if linked, _ := identities.FindByUser(ctx, local.ID); len(linked) != 1 {
+					t.Fatal("identity not stored")
+				}
+				return
+			}
+			if !apperror.Is(err, apperror.CodeConflict) {
+				t.Fatalf("want conflict, got %v, %v", user, err)
+			}
+			if linked, _ := identities.FindByUser(ctx, local.ID); len(linked) != 0 {
+				t.Fatal("identity linked to unverified account")
+			}
+		})
+	}
+}
+
+func TestAuthenticateCreatesUserForNewEmail(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	identities := &fakeIdentityRepo{users: users}
+	svc := NewIdentityService(users, identities)
+
+	user, err := svc.Authenticate(ctx, githubInfo("new@example.com", true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !user.IsVerified || user.Role != "user" {
+		t.Fatalf("unexpected user: %+v", user)
+	}
+
+	// Login berikutnya menemukan user lewat identitas, bukan email
+	again, err := svc.Authenticate(ctx, githubInfo("changed@example.com", true))
+	if err != nil || again.ID != user.ID {
+		t.Fatalf("second login got %v, %v", again, err)
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/mailer"
+)
+
+const (
+	loginAttemptWindow    = 24 * time.Hour // counter dimulai ulang setelah sehari tanpa kegagalan
+	loginEmailFreeFailure = 5              // kegagalan per email sebelum dikunci
+	loginIPFreeFailure    = 20             // kegagalan per IP sebelum dikunci (satu IP bisa dipakai banyak user)
+	loginBaseLockout      = time.Minute
+	loginMaxLockout       = time.Hour
+	loginNotifyTimeout    = 30 * time.Second
+)
+
+// LoginGuard membatasi percobaan login per IP dan per email dengan exponential backoff.
+type LoginGuard interface {
+	// Check menolak percobaan dengan CodeRateLimited jika email atau IP sedang terkunci
+	Check(ctx context.Context, email, ip string) error
+	// Failure mencatat kegagalan; error penyimpanan hanya di-log agar respons login tetap sama
+	Failure(ctx context.Context, email, ip string)
+	// Success menghapus counter email setelah login berhasil
+	Success(ctx context.Context, email string)
+}
+
+type loginGuard struct {
+	attemptRepo    repository.LoginAttemptRepository
+	userRepository repository.UserRepository
+	mailer         mailer.Mailer
+	logger         logger.Logger
+	now            func() time.Time
+	// notifications menunggu email notifikasi yang dikirim di background (dipakai test)
+	notifications sync.WaitGroup
+}
+
+func NewLoginGuard(attemptRepo repository.LoginAttemptRepository, userRepository repository.UserRepository, mailer mailer.Mailer, logger logger.Logger) LoginGuard {
+	return &loginGuard{attemptRepo: attemptRepo, userRepository: userRepository, mailer: mailer, logger: logger, now: time.Now}
+}
+
+// Check implements LoginGuard.
+func (g *loginGuard) Check(ctx context.Context, email, ip string) error {
+	var wait time.Duration
+	for _, key := range []string{emailKey(email), ipKey(ip)} {
+		attempt, err := g.attemptRepo.Find(ctx, key)
+		if err != nil {
+			return err
+		}
+		if attempt != nil && attempt.LockedUntil != nil {
+			if d := attempt.LockedUntil.Sub(g.now()); d > wait {
+				wait = d
+			}
+		}
+	}
+
+	if wait > 0 {
+		return apperror.RateLimited(wait)
+	}
+	return nil
+}
+
+// Failure implements LoginGuard.
+func (g *loginGuard) Failure(ctx context.Context, email, ip string) {
+	if locked := g.record(ctx, emailKey(email), loginEmailFreeFailure); locked {
+		// Email yang tidak terdaftar dikunci dengan cara yang sama. Lookup dan pengiriman email
+		// berjalan di background agar waktu respons tidak membedakan akun yang ada dan yang tidak.
+		g.notifications.Add(1)
+		go func() {
+			defer g.notifications.Done()
+			notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loginNotifyTimeout)
+			defer cancel()
+			g.notifyLocked(notifyCtx, email)
+		}()
+	}
+	g.record(ctx, ipKey(ip), loginIPFreeFailure)
+}
+
+// Success implements LoginGuard.
+func (g *loginGuard) Success(ctx context.Context, email string) {
+	// Counter IP sengaja tidak di-reset: login ke akun sendiri tidak boleh membuka kunci brute-force dari IP yang sama
+	if err := g.attemptRepo.Reset(ctx, emailKey(email)); err != nil {
+		g.logger.Error(err, "failed to reset login attempts")
+	}
+}
+
+// record mencatat kegagalan dan mengunci kunci yang melewati batas.
+// Mengembalikan true jika kunci baru saja dikunci untuk pertama kali dalam window ini,
+// sehingga notifikasi hanya dikirim sekali per window meskipun percobaan terus berlanjut.
+func (g *loginGuard) record(ctx context.Context, key string, free int) bool {
+	attempt, err := g.attemptRepo.RecordFailure(ctx, key, loginAttemptWindow)
+	if err != nil {
+		g.logger.Error(err, "failed to record login attempt")
+		return false
+	}
+	if attempt.Failures < free {
+		return false
+	}
+
+	// 1m, 2m, 4m, ... maksimal 1 jam
+	lockout := loginMaxLockout
+	if shift := attempt.Failures - free; shift < 6 {
+		lockout = min(loginBaseLockout<<shift, loginMaxLockout)
+	}
+	if err := g.attemptRepo.Lock(ctx, key, g.now().Add(lockout)); err != nil {
+		g.logger.Error(err, "failed to lock login key")
+		return false
+	}
+
+	g.logger.With(logger.Fields{
+		"event":    "login_locked",
+		"key":      key,
+		"failures": attempt.Failures,
+		"lockout":  lockout.String(),
+	}).Warn("login temporarily locked after repeated failures")
+
+	return attempt.Failures == free
+}
+
+func (g *loginGuard) notifyLocked(ctx context.Context, email string) {
+	user, err := g.userRepository.GetByEmail(ctx, email)
+	if err != nil {
+		// Email tidak terdaftar: tidak ada yang perlu diberi tahu
+		if !apperror.Is(err, apperror.CodeUnauthorized) {
+			g.logger.Error(err, "failed to look up locked account")
+		}
+		return
+	}
+
+	msg := mailer.Message{
+		To:      user.Email,
+		Subject: "Your account has been temporarily locked",
+		Body: fmt.Sprintf("Hi %s,\n\nWe blocked sign-in to your account after %d failed login attempts. You can try again in a few minutes.\n\n"+
+			"If this wasn't you, reset your password using the forgot password page.", user.Name, loginEmailFreeFailure),
+	}
+	if err := g.mailer.Send(ctx, msg); err != nil {
+		g.logger.Error(err, "failed to send account lock notification")
+	}
+}
+
+func emailKey(email string) string {
+	return "email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
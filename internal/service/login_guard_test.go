@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/mailer"
+)
+
+type guardHarness struct {
+	clock *fakeClock
+	users *fakeUserRepo
+	mail  *mailer.MemoryMailer
+	guard *loginGuard
+}
+
+func newGuardHarness(t *testing.T) *guardHarness {
+	t.Helper()
+	clock := newFakeClock()
+	h := &guardHarness{clock: clock, users: newFakeUserRepo(), mail: mailer.NewMemoryMailer()}
+	h.guard = NewLoginGuard(newFakeLoginAttemptRepo(clock), h.users, h.mail, testLogger()).(*loginGuard)
+	h.guard.now = clock.Now
+	return h
+}
+
+// fail mencatat n kegagalan untuk email, masing-masing dari IP berbeda agar counter IP tidak ikut mengunci
+func (h *guardHarness) fail(email string, n int) {
+	for i := range n {
+		h.guard.Failure(context.Background(), email, fmt.Sprintf("10.0.%d.%d", i/250, i%250))
+	}
+	h.guard.notifications.Wait()
+}
+
+// wait mengembalikan RetryAfter dari Check; 0 jika percobaan diizinkan
+func (h *guardHarness) wait(t *testing.T, email, ip string) time.Duration {
+	t.Helper()
+	err := h.guard.Check(context.Background(), email, ip)
+	if err == nil {
+		return 0
+	}
+	var ae *apperror.AppError
+	if !errors.As(err, &ae) || ae.Code != apperror.CodeRateLimited {
+		t.Fatalf("Check = %v, want a rate limit error", err)
+	}
+	return ae.RetryAfter
+}
+
+func TestLoginGuardLockoutBackoff(t *testing.T) {
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{1, 0},
+		{4, 0},
+		{5, time.Minute},
+		{6, 2 * time.Minute},
+		{7, 4 * time.Minute},
+		{8, 8 * time.Minute},
+		{10, 32 * time.Minute},
+		{11, time.Hour}, // 64m dibatasi 1 jam
+		{12, time.Hour},
+		{40, time.Hour},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%d failures", tc.failures), func(t *testing.T) {
+			h := newGuardHarness(t)
+			h.fail("victim@example.com", tc.failures)
+
+			if got := h.wait(t, "victim@example.com", "192.0.2.1"); got != tc.want {
+				t.Fatalf("Retry-After %s, want %s", got, tc.want)
+			}
+			if tc.want == 0 {
+				return
+			}
+			// Email ditulis berbeda tetap kunci yang sama; email lain dari IP yang sama tidak terpengaruh
+			if got := h.wait(t, " Victim@Example.com", "192.0.2.1"); got != tc.want {
+				t.Fatalf("normalised email Retry-After %s, want %s", got, tc.want)
+			}
+			if got := h.wait(t, "other@example.com", "192.0.2.1"); got != 0 {
+				t.Fatalf("other email locked for %s", got)
+			}
+
+			// Retry-After berkurang seiring waktu dan kunci lepas tepat saat habis
+			h.clock.advance(tc.want - 20*time.Second)
+			if got := h.wait(t, "victim@example.com", "192.0.2.1"); got != 20*time.Second {
+				t.Fatalf("Retry-After %s after waiting, want 20s", got)
+			}
+			h.clock.advance(20 * time.Second)
+			if got := h.wait(t, "victim@example.com", "192.0.2.1"); got != 0 {
+				t.Fatalf("still locked for %s after the lockout", got)
+			}
+		})
+	}
+}
+
+func TestLoginGuardIPThreshold(t *testing.T) {
+	h := newGuardHarness(t)
+	ctx := context.Background()
+	for i := range loginIPFreeFailure - 1 {
+		h.guard.Failure(ctx, fmt.Sprintf("user%d@example.com", i), "198.51.100.7")
+	}
+	if got := h.wait(t, "new@example.com", "198.51.100.7"); got != 0 {
+		t.Fatalf("IP locked for %s before the threshold", got)
+	}
+
+	h.guard.Failure(ctx, "last@example.com", "198.51.100.7")
+	if got := h.wait(t, "new@example.com", "198.51.100.7"); got != time.Minute {
+		t.Fatalf("Retry-After %s, want 1m for every email from the IP", got)
+	}
+	if got := h.wait(t, "new@example.com", "198.51.100.8"); got != 0 {
+		t.Fatalf("another IP locked for %s", got)
+	}
+}
+
+func TestLoginGuardReset(t *testing.T) {
+	t.Run("success resets the email counter", func(t *testing.T) {
+		h := newGuardHarness(t)
+		h.fail("a@example.com", loginEmailFreeFailure-1)
+		h.guard.Success(context.Background(), "A@example.com")
+		h.fail("a@example.com", loginEmailFreeFailure-1)
+		if got := h.wait(t, "a@example.com", "192.0.2.1"); got != 0 {
+			t.Fatalf("locked for %s after a successful login reset the counter", got)
+		}
+	})
+
+	t.Run("success keeps the IP counter", func(t *testing.T) {
+		h := newGuardHarness(t)
+		ctx := context.Background()
+		for i := range loginIPFreeFailure - 1 {
+			h.guard.Failure(ctx, fmt.Sprintf("user%d@example.com", i), "198.51.100.7")
+		}
+		h.guard.Success(ctx, "mine@example.com")
+		h.guard.Failure(ctx, "user0@example.com", "198.51.100.7")
+		if got := h.wait(t, "mine@example.com", "198.51.100.7"); got != time.Minute {
+			t.Fatalf("Retry-After %s, want the IP lockout to survive a successful login", got)
+		}
+	})
+
+	t.Run("window restarts the counter", func(t *testing.T) {
+		h := newGuardHarness(t)
+		h.fail("a@example.com", loginEmailFreeFailure-1)
+		h.clock.advance(loginAttemptWindow + time.Second)
+		h.fail("a@example.com", 1)
+		if got := h.wait(t, "a@example.com", "192.0.2.1"); got != 0 {
+			t.Fatalf("locked for %s by failures from an earlier window", got)
+		}
+	})
+}
+
+func TestLoginGuardNotifiesOncePerLock(t *testing.T) {
+	h := newGuardHarness(t)
+	user := &entity.User{Name: "Budi", Email: "budi@example.com"}
+	if err := h.users.Store(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	h.fail(user.Email, loginEmailFreeFailure-1)
+	if sent := h.mail.Sent(); len(sent) != 0 {
+		t.Fatalf("notified before the lock: %v", sent)
+	}
+
+	// Percobaan setelah terkunci (dan backoff yang bertambah) tidak mengirim email lagi
+	h.fail(user.Email, 10)
+	sent := h.mail.Sent()
+	if len(sent) != 1 || sent[0].To != user.Email || sent[0].Subject != "Your account has been temporarily locked" {
+		t.Fatalf("sent %+v, want one lock notification", sent)
+	}
+
+	// Window baru: kunci pertama berikutnya diberitahukan lagi
+	h.clock.advance(loginAttemptWindow + time.Second)
+	h.fail(user.Email, loginEmailFreeFailure)
+	if sent := h.mail.Sent(); len(sent) != 2 {
+		t.Fatalf("sent %d notifications, want a second one in the new window", len(sent))
+	}
+}
+
+func TestLoginGuardUnknownEmailIsIndistinguishable(t *testing.T) {
+	h := newGuardHarness(t)
+	if err := h.users.Store(context.Background(), &entity.User{Email: "known@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, email := range []string{"known@example.com", "unknown@example.com"} {
+		h.fail(email, loginEmailFreeFailure+1)
+		if got := h.wait(t, email, "192.0.2.1"); got != 2*time.Minute {
+			t.Fatalf("%s: Retry-After %s, want the same lockout for every email", email, got)
+		}
+	}
+	for _, msg := range h.mail.Sent() {
+		if msg.To != "known@example.com" {
+			t.Fatalf("notification sent to %s", msg.To)
+		}
+	}
+}
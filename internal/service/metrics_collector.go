@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/metrics"
+)
+
+const (
+	stuckOrderPollInterval = 30 * time.Second
+	// stuckOrderAfter: order processing yang tidak berubah selama ini dianggap macet di supplier
+	stuckOrderAfter         = 15 * time.Minute
+	supplierBalanceInterval = 5 * time.Minute
+)
+
+// MetricsCollector memperbarui gauge yang nilainya harus diambil dari database atau supplier.
+type MetricsCollector interface {
+	// Run memperbarui gauge secara berkala sampai ctx dibatalkan
+	Run(ctx context.Context)
+}
+
+type metricsCollector struct {
+	orders   repository.OrderRepository
+	supplier ExternalService
+	metrics  metrics.Metrics
+	logger   logger.Logger
+}
+
+func NewMetricsCollector(orders repository.OrderRepository, supplier ExternalService, metrics metrics.Metrics, logger logger.Logger) MetricsCollector {
+	return &metricsCollector{orders: orders, supplier: supplier, metrics: metrics, logger: logger}
+}
+
+// Run implements MetricsCollector.
+func (m *metricsCollector) Run(ctx context.Context) {
+	stuck := time.NewTicker(stuckOrderPollInterval)
+	defer stuck.Stop()
+	balance := time.NewTicker(supplierBalanceInterval)
+	defer balance.Stop()
+
+	m.collectStuckOrders(ctx)
+	m.collectSupplierBalance(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stuck.C:
+			m.collectStuckOrders(ctx)
+		case <-balance.C:
+			m.collectSupplierBalance(ctx)
+		}
+	}
+}
+
+func (m *metricsCollector) collectStuckOrders(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	n, err := m.orders.CountStuck(ctx, time.Now().Add(-stuckOrderAfter))
+	if err != nil {
+		if ctx.Err() == nil {
+			m.logger.Error(err, "failed to count stuck orders")
+		}
+		return
+	}
+	m.metrics.SetStuckOrders(n)
+}
+
+func (m *metricsCollector) collectSupplierBalance(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	balance, err := m.supplier.DFGetBalance(ctx)
+	if err != nil {
+		// Tanpa kredensial supplier (mis. development) gauge memang tidak diisi
+		if !apperror.Is(err, apperror.CodeUnavailable) && ctx.Err() == nil {
+			m.logger.Error(err, "failed to fetch supplier balance")
+		}
+		return
+	}
+	m.metrics.SetSupplierBalance("digiflazz", float64(balance.Int64()))
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/messaging"
+)
+
+const (
+	notificationPollInterval = 5 * time.Second
+	notificationBatchSize    = 20
+	// notificationLease harus lebih lama dari timeout HTTP client gateway WhatsApp
+	notificationLease       = time.Minute
+	notificationMaxAttempts = 5
+	notificationBaseBackoff = 30 * time.Second // 30s, 1m, 2m, 4m; notifikasi order tidak berguna jika terlambat berjam-jam
+)
+
+// NotificationDispatcher mengirim isi outbox notifikasi di background.
+type NotificationDispatcher interface {
+	// Run memproses outbox sampai ctx dibatalkan
+	Run(ctx context.Context)
+}
+
+type notificationDispatcher struct {
+	repo      repository.NotificationRepository
+	messenger messaging.MessagingProvider
+	logger    logger.Logger
+}
+
+func NewNotificationDispatcher(repo repository.NotificationRepository, messenger messaging.MessagingProvider, logger logger.Logger) NotificationDispatcher {
+	return &notificationDispatcher{repo: repo, messenger: messenger, logger: logger}
+}
+
+// Run implements NotificationDispatcher.
+func (d *notificationDispatcher) Run(ctx context.Context) {
+	t := time.NewTicker(notificationPollInterval)
+	defer t.Stop()
+	for {
+		for d.dispatchBatch(ctx) == notificationBatchSize {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// dispatchBatch mengirim berurutan: gateway WhatsApp membatasi laju kirim per device
+func (d *notificationDispatcher) dispatchBatch(ctx context.Context) int {
+	items, err := d.repo.ClaimDue(ctx, notificationBatchSize, notificationLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.Error(err, "failed to claim notifications")
+		}
+		return 0
+	}
+
+	for i := range items {
+		d.deliver(ctx, &items[i])
+	}
+	return len(items)
+}
+
+func (d *notificationDispatcher) deliver(ctx context.Context, n *entity.Notification) {
+	now := time.Now()
+	n.Attempts++
+	n.LastAttemptAt = &now
+
+	err := d.messenger.SendText(ctx, n.Recipient, n.Body)
+	switch {
+	case err == nil:
+		n.Status = entity.NotificationSent
+		n.SentAt = &now
+		n.LastError = ""
+	case n.Attempts >= notificationMaxAttempts:
+		n.Status = entity.NotificationFailed
+		n.LastError = truncate(err.Error(), webhookMaxErrorLen)
+		d.logger.With(logger.Fields{
+			"event":           "notification_failed",
+			"notification_id": n.ID,
+			"reference":       n.Reference,
+		}).Warn("notification gave up after max attempts")
+	default:
+		n.LastError = truncate(err.Error(), webhookMaxErrorLen)
+		n.NextAttemptAt = now.Add(notificationBaseBackoff << (n.Attempts - 1))
+	}
+
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := d.repo.SaveAttempt(saveCtx, n); err != nil {
+		d.logger.Error(err, fmt.Sprintf("failed to save notification %d", n.ID))
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/pkg/utils"
+)
+
+// NotificationService menyusun notifikasi order ke nomor WhatsApp customer.
+// Hasilnya masuk ke repository.Outbox dan ditulis bersama perubahan order-nya;
+// NotificationDispatcher yang mengirimnya di background.
+type NotificationService interface {
+	// OrderCreated dan OrderStatusChanged mengembalikan slice kosong jika tidak ada yang perlu dikirim
+	OrderCreated(order *entity.Order) []entity.Notification
+	OrderStatusChanged(order *entity.Order) []entity.Notification
+}
+
+type notificationService struct{}
+
+func NewNotificationService() NotificationService {
+	return &notificationService{}
+}
+
+// OrderCreated implements NotificationService.
+func (n *notificationService) OrderCreated(order *entity.Order) []entity.Notification {
+	msg := fmt.Sprintf(
+		"Hi %s, your order %s has been received.\nCustomer ID: %s\nTotal: %s\n\nWe will let you know once it is processed.",
+		order.CustomerName, order.OrderRef, order.CustomerID, order.Amount.Add(order.Fee).Format(),
+	)
+	return n.whatsapp(order, msg)
+}
+
+// OrderStatusChanged implements NotificationService.
+func (n *notificationService) OrderStatusChanged(order *entity.Order) []entity.Notification {
+	var msg string
+	switch order.Status {
+	case entity.StatusSuccess:
+		msg = fmt.Sprintf("Your order %s is complete.\nCustomer ID: %s", order.OrderRef, order.CustomerID)
+		if order.SerialNumber != "" {
+			msg += fmt.Sprintf("\nSN: %s", order.SerialNumber)
+		}
+	case entity.StatusCanceled:
+		msg = fmt.Sprintf("Your order %s has been canceled. Please contact support if you need help.", order.OrderRef)
+	default:
+		// pending/processing tidak perlu dikirim ulang ke customer
+		return nil
+	}
+	return n.whatsapp(order, msg)
+}
+
+func (n *notificationService) whatsapp(order *entity.Order, msg string) []entity.Notification {
+	if order.WA == "" {
+		return nil
+	}
+	return []entity.Notification{{
+		Channel:       entity.NotificationChannelWhatsapp,
+		Recipient:     utils.NormalizePhone(order.WA),
+		Body:          msg,
+		Reference:     order.OrderRef,
+		Status:        entity.NotificationPending,
+		NextAttemptAt: time.Now(),
+	}}
+}
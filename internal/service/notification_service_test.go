@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/pkg/messaging"
+)
+
+type fakeNotificationRepo struct {
+	mu     sync.Mutex
+	nextID uint64
+	items  map[uint64]*entity.Notification
+}
+
+func newFakeNotificationRepo() *fakeNotificationRepo {
+	return &fakeNotificationRepo{items: map[uint64]*entity.Notification{}}
+}
+
+func (r *fakeNotificationRepo) Create(_ context.Context, n *entity.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	n.ID = r.nextID
+	cp := *n
+	r.items[n.ID] = &cp
+	return nil
+}
+
+func (r *fakeNotificationRepo) ClaimDue(_ context.Context, limit int, lease time.Duration) ([]entity.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var out []entity.Notification
+	for id := uint64(1); id <= r.nextID && len(out) < limit; id++ {
+		n := r.items[id]
+		if n.Status == entity.NotificationPending && !n.NextAttemptAt.After(now) {
+			out = append(out, *n)
+			n.NextAttemptAt = now.Add(lease)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeNotificationRepo) SaveAttempt(_ context.Context, n *entity.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *n
+	r.items[n.ID] = &cp
+	return nil
+}
+
+// due memajukan jadwal semua notifikasi pending agar bisa langsung diklaim lagi
+func (r *fakeNotificationRepo) due() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range r.items {
+		n.NextAttemptAt = time.Now().Add(-time.Second)
+	}
+}
+
+func (r *fakeNotificationRepo) get(id uint64) entity.Notification {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.items[id]
+}
+
+// failingMessenger gagal sebanyak fails kali lalu meneruskan ke MemoryProvider
+type failingMessenger struct {
+	*messaging.MemoryProvider
+	mu    sync.Mutex
+	fails int
+}
+
+func (m *failingMessenger) SendText(ctx context.Context, to, message string) error {
+	m.mu.Lock()
+	if m.fails > 0 {
+		m.fails--
+		m.mu.Unlock()
+		return errors.New("gateway down")
+	}
+	m.mu.Unlock()
+	return m.MemoryProvider.SendText(ctx, to, message)
+}
+
+// blockingMessenger meniru gateway yang menggantung sampai ctx dibatalkan
+type blockingMessenger struct{}
+
+func (blockingMessenger) SendText(ctx context.Context, _, _ string) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestOrderNotificationsAreBuiltForOutbox(t *testing.T) {
+	svc := NewNotificationService()
+	order := &entity.Order{OrderRef: "ORD-1", WA: "0812 3456 7890", CustomerName: "Budi", CustomerID: "123", Status: entity.StatusSuccess, SerialNumber: "SN-9"}
+
+	if got := svc.OrderCreated(order); len(got) != 1 {
+		t.Fatalf("built %d notifications on create, want 1", len(got))
+	}
+	got := svc.OrderStatusChanged(order)
+	if len(got) != 1 {
+		t.Fatalf("built %d notifications on status change, want 1", len(got))
+	}
+	n := got[0]
+	if n.Recipient != "6281234567890" || n.Status != entity.NotificationPending || !strings.Contains(n.Body, "SN-9") {
+		t.Fatalf("unexpected notification: %+v", n)
+	}
+
+	// Status tanpa pesan dan order tanpa nomor tidak membuat baris
+	order.Status = entity.StatusProcessing
+	if got := svc.OrderStatusChanged(order); len(got) != 0 {
+		t.Fatalf("built %d notifications for processing, want 0", len(got))
+	}
+	if got := svc.OrderCreated(&entity.Order{OrderRef: "ORD-2"}); len(got) != 0 {
+		t.Fatalf("built %d notifications without a number, want 0", len(got))
+	}
+}
+
+func TestNotificationDispatcherDeliversAndRetries(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeNotificationRepo()
+	messenger := &failingMessenger{MemoryProvider: messaging.NewMemoryProvider(), fails: 1}
+	d := NewNotificationDispatcher(repo, messenger, testLogger()).(*notificationDispatcher)
+
+	_ = repo.Create(ctx, &entity.Notification{Channel: entity.NotificationChannelWhatsapp, Recipient: "628111", Body: "hello", Status: entity.NotificationPending, NextAttemptAt: time.Now()})
+
+	d.dispatchBatch(ctx)
+	n := repo.get(1)
+	if n.Status != entity.NotificationPending || n.Attempts != 1 || n.LastError == "" {
+		t.Fatalf("after failed send: %+v", n)
+	}
+	if wait := time.Until(n.NextAttemptAt); wait < notificationBaseBackoff-time.Second {
+		t.Fatalf("retry scheduled in %s, want about %s", wait, notificationBaseBackoff)
+	}
+	if d.dispatchBatch(ctx) != 0 {
+		t.Fatal("notification retried before its backoff elapsed")
+	}
+
+	repo.due()
+	d.dispatchBatch(ctx)
+	n = repo.get(1)
+	if n.Status != entity.NotificationSent || n.SentAt == nil || n.LastError != "" {
+		t.Fatalf("after successful send: %+v", n)
+	}
+	if msg, ok := messenger.Last("628111"); !ok || msg.Message != "hello" {
+		t.Fatalf("message not delivered: %+v", messenger.Sent())
+	}
+}
+
+func TestNotificationDispatcherGivesUp(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeNotificationRepo()
+	messenger := &failingMessenger{MemoryProvider: messaging.NewMemoryProvider(), fails: notificationMaxAttempts}
+	d := NewNotificationDispatcher(repo, messenger, testLogger()).(*notificationDispatcher)
+
+	_ = repo.Create(ctx, &entity.Notification{Channel: entity.NotificationChannelWhatsapp, Recipient: "628111", Body: "hello", Status: entity.NotificationPending, NextAttemptAt: time.Now()})
+	for range notificationMaxAttempts {
+		repo.due()
+		d.dispatchBatch(ctx)
+	}
+
+	if n := repo.get(1); n.Status != entity.NotificationFailed || n.Attempts != notificationMaxAttempts {
+		t.Fatalf("after max attempts: %+v", n)
+	}
+	repo.due()
+	if d.dispatchBatch(ctx) != 0 {
+		t.Fatal("failed notification claimed again")
+	}
+}
+
+func TestNotificationDispatcherStopsOnCancel(t *testing.T) {
+	repo := newFakeNotificationRepo()
+	_ = repo.Create(context.Background(), &entity.Notification{Recipient: "628111", Body: "hello", Status: entity.NotificationPending, NextAttemptAt: time.Now()})
+	d := NewNotificationDispatcher(repo, blockingMessenger{}, testLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("dispatcher did not stop after cancel")
+	}
+	// Hasil kirim yang terputus tetap tercatat
+	if n := repo.get(1); n.Attempts != 1 {
+		t.Fatalf("interrupted attempt not saved: %+v", n)
+	}
+}
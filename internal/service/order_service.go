@@ -2,12 +2,17 @@ package service
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
 	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
 	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
 	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/metrics"
 	"github.com/wildanasyrof/backend-topup/pkg/utils"
+	"gorm.io/gorm"
 )
 
 type OrderService interface {
@@ -16,17 +21,23 @@ type OrderService interface {
 	GetByRef(ctx context.Context, ref string) (*entity.Order, error)
 	Update(ctx context.Context, ref string, req *dto.UpdateOrder) (*entity.Order, error)
 	GetByUserID(ctx context.Context, userId uint64) ([]*entity.Order, error)
+	UpdateStatus(ctx context.Context, ref string, req *dto.UpdateOrderStatus) (*entity.Order, error)
 }
 
 type orderService struct {
 	orderRepo repository.OrderRepository
 	userRepo  repository.UserRepository
 	priceRepo repository.PriceRepository
+	notifier  NotificationService
+	webhooks  WebhookService
+	settings  SettingsReader
+	audit     AuditLogService
+	metrics   metrics.Metrics
 	logger    logger.Logger
 }
 
-func NewOrderService(orderRepo repository.OrderRepository, logger logger.Logger, userRepo repository.UserRepository, priceRepo repository.PriceRepository) OrderService {
-	return &orderService{orderRepo: orderRepo, logger: logger, userRepo: userRepo, priceRepo: priceRepo}
+func NewOrderService(orderRepo repository.OrderRepository, logger logger.Logger, userRepo repository.UserRepository, priceRepo repository.PriceRepository, notifier NotificationService, webhooks WebhookService, settings SettingsReader, audit AuditLogService, metrics metrics.Metrics) OrderService {
+	return &orderService{orderRepo: orderRepo, logger: logger, userRepo: userRepo, priceRepo: priceRepo, notifier: notifier, webhooks: webhooks, settings: settings, audit: audit, metrics: metrics}
 }
 
 // Create implements OrderService.
@@ -41,6 +52,7 @@ func (o *orderService) Create(ctx context.Context, userId uint64, req *dto.Creat
 		return nil, err
 	}
 
+	expiresAt := time.Now().Add(o.settings.Duration(ctx, entity.SettingOrderTTL))
 	order := &entity.Order{
 		OrderRef:     utils.GenerateTopupID(),
 		UserID:       userId,
@@ -52,11 +64,13 @@ func (o *orderService) Create(ctx context.Context, userId uint64, req *dto.Creat
 		PaymentRef:   "link referensi",
 		Amount:       price.Price,
 		Fee:          500,
+		ExpiresAt:    &expiresAt,
 	}
 
-	if err := o.orderRepo.Create(ctx, order); err != nil {
+	if err := o.orderRepo.Create(ctx, order, repository.Outbox{Notifications: o.notifier.OrderCreated(order)}); err != nil {
 		return nil, err
 	}
+	o.metrics.OrderStatus(string(order.Status))
 
 	return order, nil
 }
@@ -76,7 +90,59 @@ func (o *orderService) GetByRef(ctx context.Context, ref string) (*entity.Order,
 	return o.orderRepo.FindByRef(ctx, ref)
 }
 
+// UpdateStatus implements OrderService.
+func (o *orderService) UpdateStatus(ctx context.Context, ref string, req *dto.UpdateOrderStatus) (*entity.Order, error) {
+	order, err := o.orderRepo.FindByRef(ctx, ref)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperror.ErrNotFound
+		}
+		return nil, err
+	}
+
+	status := entity.OrderStatus(req.Status)
+	if order.Status == status && (req.SerialNumber == "" || req.SerialNumber == order.SerialNumber) {
+		return order, nil
+	}
+
+	before := *order
+	order.Status = status
+	if req.SerialNumber != "" {
+		order.SerialNumber = req.SerialNumber
+	}
+
+	event, err := o.webhooks.NewEvent(order.UserID, WebhookOrderStatusChanged, toOrderResponse(order))
+	if err != nil {
+		return nil, err
+	}
+	outbox := repository.Outbox{Webhook: event, Notifications: o.notifier.OrderStatusChanged(order)}
+	if err := o.orderRepo.UpdateStatus(ctx, order, outbox); err != nil {
+		return nil, err
+	}
+	o.metrics.OrderStatus(string(order.Status))
+	o.audit.Record(ctx, entity.AuditUpdate, "order", order.ID, before, order)
+
+	return order, nil
+}
+
 // Update implements OrderService.
 func (o *orderService) Update(ctx context.Context, ref string, req *dto.UpdateOrder) (*entity.Order, error) {
 	panic("unimplemented")
 }
+
+func toOrderResponse(order *entity.Order) dto.OrderResponse {
+	return dto.OrderResponse{
+		OrderRef:      order.OrderRef,
+		ProductID:     order.ProductID,
+		WA:            order.WA,
+		Email:         order.Email,
+		CustomerName:  order.CustomerName,
+		CustomerID:    order.CustomerID,
+		PaymentRef:    order.PaymentRef,
+		PaymentStatus: string(order.PaymentStatus),
+		Status:        string(order.Status),
+		SerialNumber:  order.SerialNumber,
+		Amount:        order.Amount,
+		ExpiresAt:     order.ExpiresAt,
+	}
+}
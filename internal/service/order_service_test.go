@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	"github.com/wildanasyrof/backend-topup/pkg/metrics"
+)
+
+type fakeOrderRepo struct {
+	repository.OrderRepository
+	order  *entity.Order
+	outbox *repository.Outbox
+}
+
+func (r *fakeOrderRepo) FindByRef(context.Context, string) (*entity.Order, error) {
+	cp := *r.order
+	return &cp, nil
+}
+
+func (r *fakeOrderRepo) UpdateStatus(_ context.Context, order *entity.Order, outbox repository.Outbox) error {
+	r.order, r.outbox = order, &outbox
+	return nil
+}
+
+func TestOrderStatusChangeWritesOutboxWithTheUpdate(t *testing.T) {
+	repo := &fakeOrderRepo{order: &entity.Order{ID: 3, OrderRef: "ORD-1", UserID: 7, WA: "08123", Status: entity.StatusProcessing}}
+	audit := &fakeAudit{}
+	svc := NewOrderService(repo, testLogger(), nil, nil, NewNotificationService(), NewWebhookService(&fakeWebhookRepo{}, audit), noSettings{}, audit, metrics.New())
+
+	order, err := svc.UpdateStatus(context.Background(), "ORD-1", &dto.UpdateOrderStatus{Status: string(entity.StatusSuccess), SerialNumber: "SN-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Status != entity.StatusSuccess || order.SerialNumber != "SN-1" {
+		t.Fatalf("unexpected order %+v", order)
+	}
+	if repo.outbox == nil {
+		t.Fatal("status change saved without an outbox")
+	}
+	if ev := repo.outbox.Webhook; ev == nil || ev.Event != WebhookOrderStatusChanged || ev.UserID != 7 {
+		t.Fatalf("unexpected webhook event %+v", repo.outbox.Webhook)
+	}
+	if len(repo.outbox.Notifications) != 1 {
+		t.Fatalf("%d notifications in outbox, want 1", len(repo.outbox.Notifications))
+	}
+	if got := audit.recorded(); !slices.Equal(got, []string{"update order:3"}) {
+		t.Fatalf("audit entries %v", got)
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/hash"
+	"github.com/wildanasyrof/backend-topup/pkg/utils"
+)
+
+const (
+	otpLength      = 6
+	otpTTL         = 10 * time.Minute
+	otpMaxAttempts = 5
+	otpResendDelay = time.Minute // minimum jeda antar pengiriman kode
+	otpWindow      = time.Hour   // jendela rate-limit penerbitan kode
+	otpMaxPerWin   = 5           // maksimal kode per jendela
+)
+
+var errInvalidOTP = apperror.New(apperror.CodeBadRequest, "invalid or expired code", nil)
+
+// OTPService menerbitkan dan memverifikasi kode OTP sekali pakai.
+type OTPService interface {
+	// Issue membuat kode baru (kode lama untuk purpose yang sama jadi tidak berlaku).
+	Issue(ctx context.Context, userID uint64, purpose entity.OTPPurpose) (string, error)
+	// Verify mencocokkan kode dan menandainya sudah dipakai.
+	Verify(ctx context.Context, userID uint64, purpose entity.OTPPurpose, code string) error
+}
+
+type otpService struct {
+	otpRepo repository.OTPRepository
+	secret  []byte
+}
+
+// NewOTPService: secret adalah kunci HMAC untuk kode yang disimpan (config security.otp_secret).
+func NewOTPService(otpRepo repository.OTPRepository, secret []byte) OTPService {
+	return &otpService{otpRepo: otpRepo, secret: secret}
+}
+
+// Issue implements OTPService.
+func (s *otpService) Issue(ctx context.Context, userID uint64, purpose entity.OTPPurpose) (string, error) {
+	now := time.Now()
+
+	recent, err := s.otpRepo.CountSince(ctx, userID, purpose, now.Add(-otpResendDelay))
+	if err != nil {
+		return "", err
+	}
+	if recent > 0 {
+		return "", apperror.New(apperror.CodeRateLimited, "please wait before requesting a new code", nil)
+	}
+
+	total, err := s.otpRepo.CountSince(ctx, userID, purpose, now.Add(-otpWindow))
+	if err != nil {
+		return "", err
+	}
+	if total >= otpMaxPerWin {
+		return "", apperror.New(apperror.CodeRateLimited, "too many codes requested, try again later", nil)
+	}
+
+	code, err := utils.GenerateNumericCode(otpLength)
+	if err != nil {
+		return "", apperror.New(apperror.CodeInternal, "failed to generate code", err)
+	}
+
+	if err := s.otpRepo.InvalidateAll(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	otp := &entity.UserOTP{
+		UserID:    userID,
+		Purpose:   purpose,
+		CodeHash:  hash.HMACToken(s.secret, code),
+		ExpiresAt: now.Add(otpTTL),
+	}
+	if err := s.otpRepo.Create(ctx, otp); err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// Verify implements OTPService.
+func (s *otpService) Verify(ctx context.Context, userID uint64, purpose entity.OTPPurpose, code string) error {
+	otp, err := s.otpRepo.FindLatestActive(ctx, userID, purpose)
+	if err != nil {
+		if apperror.Is(err, apperror.CodeNotFound) {
+			return errInvalidOTP
+		}
+		return err
+	}
+
+	// Attempt dicatat sebelum kode dicocokkan, dengan kondisi di UPDATE yang sama,
+	// agar tebakan paralel tidak bisa melewati batas
+	allowed, err := s.otpRepo.RecordAttempt(ctx, otp.ID, otpMaxAttempts)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return apperror.New(apperror.CodeRateLimited, "too many attempts, request a new code", nil)
+	}
+
+	if !hash.CompareHMACToken(s.secret, otp.CodeHash, code) {
+		return errInvalidOTP
+	}
+
+	consumed, err := s.otpRepo.Consume(ctx, otp.ID)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		// Kode yang sama sudah ditukar oleh request lain
+		return errInvalidOTP
+	}
+	return nil
+}
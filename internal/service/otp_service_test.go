@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/hash"
+)
+
+var testOTPSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestOTPStoredAsHMAC(t *testing.T) {
+	repo := newFakeOTPRepo()
+	svc := NewOTPService(repo, testOTPSecret)
+
+	code, err := svc.Issue(context.Background(), 1, entity.OTPPasswordReset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := repo.otps[1].CodeHash
+	if stored == hash.HashToken(code) {
+		t.Fatal("code stored as plain SHA-256")
+	}
+	if stored != hash.HMACToken(testOTPSecret, code) {
+		t.Fatal("code not stored as HMAC with the server secret")
+	}
+}
+
+func TestOTPVerifyIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	svc := NewOTPService(newFakeOTPRepo(), testOTPSecret)
+
+	code, err := svc.Issue(ctx, 1, entity.OTPWhatsappLogin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Verify(ctx, 1, entity.OTPWhatsappLogin, code); err != nil {
+		t.Fatalf("first verify: %v", err)
+	}
+	if err := svc.Verify(ctx, 1, entity.OTPWhatsappLogin, code); err == nil {
+		t.Fatal("code accepted twice")
+	}
+}
+
+func TestOTPConcurrentRedeemSucceedsOnce(t *testing.T) {
+	ctx := context.Background()
+	svc := NewOTPService(newFakeOTPRepo(), testOTPSecret)
+	code, err := svc.Issue(ctx, 1, entity.OTPPasswordReset)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ok int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range otpMaxAttempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if svc.Verify(ctx, 1, entity.OTPPasswordReset, code) == nil {
+				mu.Lock()
+				ok++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if ok != 1 {
+		t.Fatalf("code redeemed %d times, want 1", ok)
+	}
+}
+
+func TestOTPConcurrentGuessesAreCapped(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeOTPRepo()
+	svc := NewOTPService(repo, testOTPSecret)
+	if _, err := svc.Issue(ctx, 1, entity.OTPWhatsappLogin); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = svc.Verify(ctx, 1, entity.OTPWhatsappLogin, "not-the-code")
+		}()
+	}
+	wg.Wait()
+
+	if got := repo.otps[1].Attempts; got != otpMaxAttempts {
+		t.Fatalf("attempts = %d, want %d", got, otpMaxAttempts)
+	}
+	err := svc.Verify(ctx, 1, entity.OTPWhatsappLogin, "not-the-code")
+	if !apperror.Is(err, apperror.CodeRateLimited) {
+		t.Fatalf("after max attempts got %v, want rate limited", err)
+	}
+}
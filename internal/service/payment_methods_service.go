@@ -18,11 +18,12 @@ type PaymentMethodsService interface {
 }
 
 type paymentMethodsService struct {
-	repo repository.PaymentMethodsRepository
+	repo  repository.PaymentMethodsRepository
+	audit AuditLogService
 }
 
-func NewPaymentMethodsService(repo repository.PaymentMethodsRepository) PaymentMethodsService {
-	return &paymentMethodsService{repo: repo}
+func NewPaymentMethodsService(repo repository.PaymentMethodsRepository, audit AuditLogService) PaymentMethodsService {
+	return &paymentMethodsService{repo: repo, audit: audit}
 }
 
 // Create implements PaymentMethodsService.
@@ -40,6 +41,8 @@ func (p *paymentMethodsService) Create(ctx context.Context, req *dto.CreatePayme
 		return nil, err
 	}
 
+	p.audit.Record(ctx, entity.AuditCreate, "payment_method", data.ID, nil, data)
+
 	return data, nil
 }
 
@@ -54,6 +57,8 @@ func (p *paymentMethodsService) Delete(ctx context.Context, id uint64) (*entity.
 		return nil, err
 	}
 
+	p.audit.Record(ctx, entity.AuditDelete, "payment_method", data.ID, data, nil)
+
 	return data, nil
 }
 
@@ -74,11 +79,14 @@ func (p *paymentMethodsService) Update(ctx context.Context, id uint64, req *dto.
 		return nil, err
 	}
 
+	before := *data
 	req.ApplyTo(data)
 
 	if err := p.repo.Update(ctx, data); err != nil {
 		return nil, err
 	}
 
+	p.audit.Record(ctx, entity.AuditUpdate, "payment_method", data.ID, before, data)
+
 	return data, nil
 }
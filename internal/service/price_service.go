@@ -17,11 +17,12 @@ type PriceService interface {
 }
 
 type priceService struct {
-	repo repository.PriceRepository
+	repo  repository.PriceRepository
+	audit AuditLogService
 }
 
-func NewPriceService(repo repository.PriceRepository) PriceService {
-	return &priceService{repo: repo}
+func NewPriceService(repo repository.PriceRepository, audit AuditLogService) PriceService {
+	return &priceService{repo: repo, audit: audit}
 }
 
 // Create implements PriceService.
@@ -36,6 +37,8 @@ func (p *priceService) Create(ctx context.Context, req *dto.CreatePrice) (*entit
 		return nil, err
 	}
 
+	p.audit.Record(ctx, entity.AuditCreate, "price", price.ID, nil, price)
+
 	return price, nil
 }
 
@@ -50,6 +53,8 @@ func (p *priceService) Delete(ctx context.Context, id int) (*entity.Price, error
 		return nil, err
 	}
 
+	p.audit.Record(ctx, entity.AuditDelete, "price", price.ID, price, nil)
+
 	return price, nil
 }
 
@@ -65,11 +70,14 @@ func (p *priceService) Update(ctx context.Context, id int, req *dto.UpdatePrice)
 		return nil, err
 	}
 
+	before := *price
 	req.ToEntity(price)
 
 	if err := p.repo.Update(ctx, price); err != nil {
 		return nil, err
 	}
 
+	p.audit.Record(ctx, entity.AuditUpdate, "price", price.ID, before, price)
+
 	return price, nil
 }
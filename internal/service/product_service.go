@@ -17,11 +17,12 @@ type ProductService interface {
 }
 
 type productService struct {
-	repo repository.ProductRepository
+	repo  repository.ProductRepository
+	audit AuditLogService
 }
 
-func NewProductRepository(repo repository.ProductRepository) ProductService {
-	return &productService{repo: repo}
+func NewProductRepository(repo repository.ProductRepository, audit AuditLogService) ProductService {
+	return &productService{repo: repo, audit: audit}
 }
 
 // Create implements ProductService.
@@ -45,6 +46,8 @@ func (p *productService) Create(ctx context.Context, req *dto.ProductCreateReque
 		return nil, err
 	}
 
+	p.audit.Record(ctx, entity.AuditCreate, "product", product.ID, nil, product)
+
 	return product, nil
 }
 
@@ -59,6 +62,8 @@ func (p *productService) Delete(ctx context.Context, id int) (*entity.Product, e
 		return nil, err
 	}
 
+	p.audit.Record(ctx, entity.AuditDelete, "product", product.ID, product, nil)
+
 	return product, nil
 }
 
@@ -74,11 +79,14 @@ func (p *productService) Update(ctx context.Context, id int, req *dto.ProductUpd
 		return nil, err
 	}
 
+	before := *product
 	req.ToEntity(product)
 
 	if err := p.repo.Update(ctx, product); err != nil {
 		return nil, err
 	}
 
+	p.audit.Record(ctx, entity.AuditUpdate, "product", product.ID, before, product)
+
 	return product, nil
 }
@@ -19,10 +19,11 @@ type ProviderService interface {
 
 type providerService struct {
 	providerRepo repository.ProviderRepository
+	audit        AuditLogService
 }
 
-func NewProviderService(p repository.ProviderRepository) ProviderService {
-	return &providerService{providerRepo: p}
+func NewProviderService(p repository.ProviderRepository, audit AuditLogService) ProviderService {
+	return &providerService{providerRepo: p, audit: audit}
 }
 
 // Create implements ProviderService.
@@ -37,6 +38,8 @@ func (p *providerService) Create(ctx context.Context, req *dto.ProviderRequest)
 		return nil, err
 	}
 
+	p.audit.Record(ctx, entity.AuditCreate, "provider", provider.ID, nil, provider)
+
 	return provider, nil
 }
 
@@ -53,6 +56,8 @@ func (p *providerService) Delete(ctx context.Context, id int64) (*entity.Provide
 		return nil, err
 	}
 
+	p.audit.Record(ctx, entity.AuditDelete, "provider", provider.ID, provider, nil)
+
 	return provider, nil
 }
 
@@ -69,6 +74,8 @@ func (p *providerService) Update(ctx context.Context, id int64, req *dto.Provide
 		return nil, err
 	}
 
+	before := *provider
+
 	if req.Name != "" {
 		provider.Name = req.Name
 	}
@@ -82,5 +89,7 @@ func (p *providerService) Update(ctx context.Context, id int64, req *dto.Provide
 		return nil, err
 	}
 
+	p.audit.Record(ctx, entity.AuditUpdate, "provider", provider.ID, before, provider)
+
 	return provider, nil
 }
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+)
+
+// rolePermissionTTL: perubahan role di replica lain paling lambat terlihat setelah selang ini
+const rolePermissionTTL = 30 * time.Second
+
+// RoleService mengelola role dan permission staff.
+type RoleService interface {
+	List(ctx context.Context) ([]dto.RoleResponse, error)
+	// Create dan Update hanya boleh memberi permission yang dipegang actorRole sendiri
+	Create(ctx context.Context, actorRole string, req *dto.CreateRoleRequest) (*dto.RoleResponse, error)
+	Update(ctx context.Context, actorRole string, id uint64, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error)
+	Delete(ctx context.Context, id uint64) error
+	// HasPermission dipakai middleware RequirePermission
+	HasPermission(ctx context.Context, role, permission string) (bool, error)
+}
+
+type roleService struct {
+	repo  repository.RoleRepository
+	audit AuditLogService
+
+	mu       sync.RWMutex
+	cache    map[string][]string
+	loadedAt time.Time
+}
+
+func NewRoleService(repo repository.RoleRepository, audit AuditLogService) RoleService {
+	return &roleService{repo: repo, audit: audit}
+}
+
+// List implements RoleService.
+func (s *roleService) List(ctx context.Context) ([]dto.RoleResponse, error) {
+	roles, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.RoleResponse, 0, len(roles))
+	for i := range roles {
+		out = append(out, toRoleResponse(&roles[i]))
+	}
+	return out, nil
+}
+
+// Create implements RoleService.
+func (s *roleService) Create(ctx context.Context, actorRole string, req *dto.CreateRoleRequest) (*dto.RoleResponse, error) {
+	perms, err := validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureHolds(ctx, s.repo, actorRole, perms); err != nil {
+		return nil, err
+	}
+
+	role := &entity.Role{Name: req.Name, Description: req.Description}
+	for _, p := range perms {
+		role.Permissions = append(role.Permissions, entity.RolePermission{Permission: p})
+	}
+	if err := s.repo.Create(ctx, role); err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	res := toRoleResponse(role)
+	s.audit.Record(ctx, entity.AuditCreate, "role", role.ID, nil, res)
+	return &res, nil
+}
+
+// Update implements RoleService.
+func (s *roleService) Update(ctx context.Context, actorRole string, id uint64, req *dto.UpdateRoleRequest) (*dto.RoleResponse, error) {
+	role, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if isBuiltInRole(role.Name) {
+		return nil, apperror.New(apperror.CodeForbidden, "built-in roles cannot be changed", nil)
+	}
+	if role.Name == actorRole {
+		return nil, apperror.New(apperror.CodeForbidden, "you cannot change your own role", nil)
+	}
+
+	perms, err := validatePermissions(req.Permissions)
+	if err != nil {
+		return nil, err
+	}
+	// Role yang memegang permission di luar milik actor juga tidak boleh diubah, walau hanya mengurangi
+	current := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		current = append(current, p.Permission)
+	}
+	if err := ensureHolds(ctx, s.repo, actorRole, append(current, perms...)); err != nil {
+		return nil, err
+	}
+
+	// Permission tidak ikut di JSON entity, jadi snapshot audit memakai response yang memuatnya
+	before := toRoleResponse(role)
+	role.Description = req.Description
+	role.Permissions = role.Permissions[:0]
+	for _, p := range perms {
+		role.Permissions = append(role.Permissions, entity.RolePermission{RoleID: role.ID, Permission: p})
+	}
+	if err := s.repo.Update(ctx, role); err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	res := toRoleResponse(role)
+	s.audit.Record(ctx, entity.AuditUpdate, "role", role.ID, before, res)
+	return &res, nil
+}
+
+// Delete implements RoleService.
+func (s *roleService) Delete(ctx context.Context, id uint64) error {
+	role, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if isBuiltInRole(role.Name) {
+		return apperror.New(apperror.CodeForbidden, "built-in roles cannot be deleted", nil)
+	}
+
+	users, err := s.repo.CountUsers(ctx, role.Name)
+	if err != nil {
+		return err
+	}
+	if users > 0 {
+		return apperror.New(apperror.CodeConflict, "role is still assigned to users", nil)
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate()
+	s.audit.Record(ctx, entity.AuditDelete, "role", role.ID, toRoleResponse(role), nil)
+	return nil
+}
+
+// HasPermission implements RoleService.
+func (s *roleService) HasPermission(ctx context.Context, role, permission string) (bool, error) {
+	if role == entity.RoleAdmin {
+		return true, nil
+	}
+
+	s.mu.RLock()
+	cache, fresh := s.cache, time.Since(s.loadedAt) < rolePermissionTTL
+	s.mu.RUnlock()
+
+	if cache == nil || !fresh {
+		loaded, err := s.repo.PermissionMap(ctx)
+		if err != nil {
+			return false, err
+		}
+		s.mu.Lock()
+		s.cache, s.loadedAt = loaded, time.Now()
+		s.mu.Unlock()
+		cache = loaded
+	}
+
+	return slices.Contains(cache[role], permission), nil
+}
+
+func (s *roleService) invalidate() {
+	s.mu.Lock()
+	s.cache = nil
+	s.mu.Unlock()
+}
+
+func isBuiltInRole(name string) bool {
+	return name == entity.RoleAdmin || name == entity.RoleUser
+}
+
+// rolePermissions mengembalikan permission yang dipegang role; admin memegang semuanya
+func rolePermissions(ctx context.Context, repo repository.RoleRepository, name string) ([]string, error) {
+	if name == entity.RoleAdmin {
+		return entity.Permissions, nil
+	}
+	role, err := repo.FindByName(ctx, name)
+	if err != nil {
+		if apperror.Is(err, apperror.CodeNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	perms := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		perms = append(perms, p.Permission)
+	}
+	return perms, nil
+}
+
+// ensureHolds menolak jika perms berisi permission yang tidak dipegang actorRole, agar staff tidak bisa
+// menaikkan haknya sendiri lewat role baru atau akun lain
+func ensureHolds(ctx context.Context, repo repository.RoleRepository, actorRole string, perms []string) error {
+	if actorRole == entity.RoleAdmin {
+		return nil
+	}
+	held, err := rolePermissions(ctx, repo, actorRole)
+	if err != nil {
+		return err
+	}
+	for _, p := range perms {
+		if !slices.Contains(held, p) {
+			return apperror.New(apperror.CodeForbidden, "you cannot grant permission "+p+" that you do not hold", nil)
+		}
+	}
+	return nil
+}
+
+// validatePermissions menolak permission di luar katalog dan membuang duplikat
+func validatePermissions(perms []string) ([]string, error) {
+	out := make([]string, 0, len(perms))
+	for _, p := range perms {
+		if !slices.Contains(entity.Permissions, p) {
+			return nil, apperror.New(apperror.CodeBadRequest, "unknown permission "+p, nil)
+		}
+		if !slices.Contains(out, p) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func toRoleResponse(role *entity.Role) dto.RoleResponse {
+	perms := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		perms = append(perms, p.Permission)
+	}
+	if role.Name == entity.RoleAdmin {
+		perms = entity.Permissions
+	}
+	return dto.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: perms,
+		BuiltIn:     isBuiltInRole(role.Name),
+	}
+}
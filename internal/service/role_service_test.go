@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+)
+
+func TestRoleServiceCannotGrantUnheldPermissions(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRoleRepo(map[string][]string{
+		"rolemgr": {entity.PermRolesManage, entity.PermOrdersRead},
+		"support": {entity.PermOrdersRead},
+		"finance": {entity.PermBalanceAdjust},
+	})
+	audit := &fakeAudit{}
+	svc := NewRoleService(repo, audit)
+	id := func(name string) uint64 {
+		r, err := repo.FindByName(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r.ID
+	}
+
+	cases := []struct {
+		name string
+		call func() error
+	}{
+		{"create with unheld permission", func() error {
+			_, err := svc.Create(ctx, "rolemgr", &dto.CreateRoleRequest{Name: "ops", Permissions: []string{entity.PermOrdersRead, entity.PermUsersManage}})
+			return err
+		}},
+		{"extend own role", func() error {
+			_, err := svc.Update(ctx, "rolemgr", id("rolemgr"), &dto.UpdateRoleRequest{Permissions: []string{entity.PermRolesManage, entity.PermSettingsManage}})
+			return err
+		}},
+		{"add unheld permission to another role", func() error {
+			_, err := svc.Update(ctx, "rolemgr", id("support"), &dto.UpdateRoleRequest{Permissions: []string{entity.PermOrdersRead, entity.PermSettingsManage}})
+			return err
+		}},
+		{"edit role holding unheld permission", func() error {
+			_, err := svc.Update(ctx, "rolemgr", id("finance"), &dto.UpdateRoleRequest{Permissions: []string{}})
+			return err
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.call(); !apperror.Is(err, apperror.CodeForbidden) {
+				t.Fatalf("got %v, want forbidden", err)
+			}
+		})
+	}
+	if perms := repo.roles["rolemgr"]; len(perms) != 2 {
+		t.Fatalf("own role changed to %v", perms)
+	}
+	if got := audit.recorded(); len(got) != 0 {
+		t.Fatalf("rejected changes audited: %v", got)
+	}
+
+	if _, err := svc.Create(ctx, "rolemgr", &dto.CreateRoleRequest{Name: "viewer", Permissions: []string{entity.PermOrdersRead}}); err != nil {
+		t.Fatalf("create with held permissions: %v", err)
+	}
+	if _, err := svc.Update(ctx, entity.RoleAdmin, id("support"), &dto.UpdateRoleRequest{Permissions: []string{entity.PermSettingsManage}}); err != nil {
+		t.Fatalf("admin update: %v", err)
+	}
+	if err := svc.Delete(ctx, id("viewer")); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"create role:4", "update role:3", "delete role:4"}
+	if got := audit.recorded(); !slices.Equal(got, want) {
+		t.Fatalf("audit entries %v, want %v", got, want)
+	}
+}
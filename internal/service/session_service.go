@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors" // <-- Import
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
@@ -14,14 +15,17 @@ import (
 type SessionService interface {
 	GetByUserID(ctx context.Context, userID uint64) ([]*entity.UserSession, error)
 	RevokeSession(ctx context.Context, userID uint64, sessionToRevokeID string) error
+	// CheckAccess dipakai middleware Auth: token ditolak jika sesinya di-revoke, user di-suspend, atau token version berubah
+	CheckAccess(ctx context.Context, userID uint64, sessionID string, tokenVersion int) error
 }
 
 type sessionService struct {
 	sessionRepo repository.SessionRepository
+	accessCache AccessCache
 }
 
-func NewSessionService(sessionRepo repository.SessionRepository) SessionService {
-	return &sessionService{sessionRepo: sessionRepo}
+func NewSessionService(sessionRepo repository.SessionRepository, accessCache AccessCache) SessionService {
+	return &sessionService{sessionRepo: sessionRepo, accessCache: accessCache}
 }
 
 func (s *sessionService) GetByUserID(ctx context.Context, userID uint64) ([]*entity.UserSession, error) {
@@ -48,6 +52,41 @@ func (s *sessionService) RevokeSession(ctx context.Context, userID uint64, sessi
 		return apperror.ErrForbidden
 	}
 
-	// Kita tandai sebagai revoked, bukan dihapus
-	return s.sessionRepo.Revoke(ctx, sessionID)
+	// Kita tandai sebagai revoked, bukan dihapus. Seluruh family ikut di-revoke
+	// agar access token yang diterbitkan sebelum rotasi terakhir juga berhenti berlaku.
+	familyID := session.FamilyID
+	if familyID == uuid.Nil {
+		familyID = session.ID
+	}
+	if err := s.sessionRepo.RevokeFamily(ctx, familyID); err != nil {
+		return err
+	}
+	s.accessCache.InvalidateUser(ctx, userID)
+	return nil
+}
+
+func (s *sessionService) CheckAccess(ctx context.Context, userID uint64, sessionID string, tokenVersion int) error {
+	id, err := uuid.Parse(sessionID)
+	if err != nil {
+		return apperror.ErrUnauthorized
+	}
+
+	access, err := s.accessCache.Access(id, func() (*repository.SessionAccess, error) {
+		return s.sessionRepo.FindAccess(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+
+	if access.UserID != userID || access.IsRevoked || time.Now().After(access.ExpiresAt) {
+		return apperror.New(apperror.CodeUnauthorized, "session has been revoked", nil)
+	}
+	if access.IsSuspended {
+		return apperror.New(apperror.CodeForbidden, "account is suspended", nil)
+	}
+	if access.TokenVersion != tokenVersion {
+		// Role atau status user berubah sejak token diterbitkan, client harus refresh
+		return apperror.New(apperror.CodeUnauthorized, "token is outdated", nil)
+	}
+	return nil
 }
@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+)
+
+type accessHarness struct {
+	users    *fakeUserRepo
+	sessions *fakeSessionRepo
+	svc      SessionService
+	userSvc  UserService
+	user     *entity.User
+	session  *entity.UserSession
+}
+
+func newAccessHarness(t *testing.T) *accessHarness {
+	t.Helper()
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	sessions := newFakeSessionRepo()
+	sessions.users = users
+	cache := NewAccessCache(nil, testLogger())
+
+	h := &accessHarness{
+		users:    users,
+		sessions: sessions,
+		svc:      NewSessionService(sessions, cache),
+		userSvc:  NewUserService(users, sessions, newFakeRoleRepo(map[string][]string{entity.RoleUser: nil, "support": {entity.PermOrdersRead}}), cache, &fakeAudit{}),
+		user:     &entity.User{Email: "s@example.com", Role: entity.RoleUser},
+	}
+	if err := users.Store(ctx, h.user); err != nil {
+		t.Fatal(err)
+	}
+	h.session = &entity.UserSession{UserID: h.user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := sessions.Create(ctx, h.session); err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func (h *accessHarness) check(version int) error {
+	return h.svc.CheckAccess(context.Background(), h.user.ID, h.session.ID.String(), version)
+}
+
+func TestCheckAccessIsCached(t *testing.T) {
+	h := newAccessHarness(t)
+	for range 5 {
+		if err := h.check(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := h.sessions.lookups(); n != 1 {
+		t.Fatalf("FindAccess called %d times, want 1", n)
+	}
+
+	// Lookup yang gagal tidak di-cache
+	other := uuid.New().String()
+	for range 2 {
+		_ = h.svc.CheckAccess(context.Background(), h.user.ID, other, 0)
+	}
+	if n := h.sessions.lookups(); n != 3 {
+		t.Fatalf("FindAccess called %d times, want 3", n)
+	}
+}
+
+func TestCheckAccessInvalidation(t *testing.T) {
+	ctx := context.Background()
+	cases := []struct {
+		name    string
+		change  func(t *testing.T, h *accessHarness)
+		version int
+		want    apperror.Code
+	}{
+		{"revoke session", func(t *testing.T, h *accessHarness) {
+			if err := h.svc.RevokeSession(ctx, h.user.ID, h.session.ID.String()); err != nil {
+				t.Fatal(err)
+			}
+		}, 0, apperror.CodeUnauthorized},
+		{"suspend", func(t *testing.T, h *accessHarness) {
+			if _, err := h.userSvc.Suspend(ctx, 0, entity.RoleAdmin, h.user.ID); err != nil {
+				t.Fatal(err)
+			}
+		}, 1, apperror.CodeUnauthorized},
+		{"change role", func(t *testing.T, h *accessHarness) {
+			if _, err := h.userSvc.ChangeRole(ctx, 0, entity.RoleAdmin, h.user.ID, &dto.UpdateUserRoleRequest{Role: "support"}); err != nil {
+				t.Fatal(err)
+			}
+		}, 0, apperror.CodeUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			h := newAccessHarness(t)
+			if err := h.check(0); err != nil {
+				t.Fatal(err)
+			}
+
+			tc.change(t, h)
+
+			if err := h.check(tc.version); !apperror.Is(err, tc.want) {
+				t.Fatalf("after %s got %v, want %s", tc.name, err, tc.want)
+			}
+			if n := h.sessions.lookups(); n != 2 {
+				t.Fatalf("FindAccess called %d times, want a fresh lookup after invalidation", n)
+			}
+		})
+	}
+}
+
+func TestAccessCacheDropsLoadStartedBeforeInvalidation(t *testing.T) {
+	cache := newAccessCache(time.Minute)
+	id := uuid.New()
+	stale := &repository.SessionAccess{UserID: 1}
+
+	// load masih berjalan (membaca data sebelum revoke) ketika user di-invalidasi
+	loading, release := make(chan struct{}), make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_, _ = cache.Access(id, func() (*repository.SessionAccess, error) {
+			close(loading)
+			<-release
+			return stale, nil
+		})
+	}()
+	<-loading
+	cache.InvalidateUser(context.Background(), 1)
+	close(release)
+	wg.Wait()
+
+	fresh := &repository.SessionAccess{UserID: 1, IsRevoked: true}
+	got, err := cache.Access(id, func() (*repository.SessionAccess, error) { return fresh, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsRevoked {
+		t.Fatal("stale access cached after invalidation")
+	}
+}
+
+func TestAccessCacheExpires(t *testing.T) {
+	cache := newAccessCache(10 * time.Millisecond)
+	id := uuid.New()
+	loads := 0
+	load := func() (*repository.SessionAccess, error) {
+		loads++
+		return &repository.SessionAccess{UserID: 1}, nil
+	}
+
+	_, _ = cache.Access(id, load)
+	_, _ = cache.Access(id, load)
+	time.Sleep(20 * time.Millisecond)
+	_, _ = cache.Access(id, load)
+	if loads != 2 {
+		t.Fatalf("loaded %d times, want 2", loads)
+	}
+}
+
+// replica adalah satu instance API dengan AccessCache-nya sendiri di atas database yang sama
+func startReplica(t *testing.T, h *accessHarness, bus *fakeInvalidationBus) (SessionService, *accessCache) {
+	t.Helper()
+	cache := NewAccessCache(bus, testLogger()).(*accessCache)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return NewSessionService(h.sessions, cache), cache
+}
+
+// waitListening menunggu Run selesai memproses perubahan koneksi
+func waitListening(t *testing.T, cache *accessCache, want bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		cache.mu.RLock()
+		got := cache.listening
+		cache.mu.RUnlock()
+		if got == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("listening = %v, want %v", got, want)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAccessCacheInvalidatesOtherReplicas(t *testing.T) {
+	h := newAccessHarness(t)
+	bus := newFakeInvalidationBus()
+	a, cacheA := startReplica(t, h, bus)
+	b, cacheB := startReplica(t, h, bus)
+	waitListening(t, cacheA, true)
+	waitListening(t, cacheB, true)
+
+	ctx := context.Background()
+	sid := h.session.ID.String()
+	for range 3 {
+		if err := b.CheckAccess(ctx, h.user.ID, sid, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := h.sessions.lookups(); n != 1 {
+		t.Fatalf("FindAccess called %d times, want the second replica to cache", n)
+	}
+
+	// Revoke lewat replica a; replica b tidak boleh menunggu TTL
+	if err := a.RevokeSession(ctx, h.user.ID, sid); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.CheckAccess(ctx, h.user.ID, sid, 0); !apperror.Is(err, apperror.CodeUnauthorized) {
+		t.Fatalf("other replica got %v after revoke, want unauthorized", err)
+	}
+}
+
+func TestAccessCacheBypassedWithoutListener(t *testing.T) {
+	h := newAccessHarness(t)
+	bus := newFakeInvalidationBus()
+	ctx := context.Background()
+	sid := h.session.ID.String()
+	checks := func(svc SessionService, n int) {
+		t.Helper()
+		for range n {
+			if err := svc.CheckAccess(ctx, h.user.ID, sid, 0); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Sebelum Run terhubung, invalidasi dari replica lain belum bisa diterima
+	idle := NewSessionService(h.sessions, NewAccessCache(bus, testLogger()))
+	checks(idle, 3)
+	if n := h.sessions.lookups(); n != 3 {
+		t.Fatalf("FindAccess called %d times, want every check to hit the database", n)
+	}
+
+	svc, cache := startReplica(t, h, bus)
+	waitListening(t, cache, true)
+	checks(svc, 3)
+	if n := h.sessions.lookups(); n != 4 {
+		t.Fatalf("FindAccess called %d times, want caching once listening", n)
+	}
+
+	// Koneksi putus: notifikasi selama itu hilang, jadi cache tidak dipakai sampai tersambung lagi
+	bus.disconnect()
+	waitListening(t, cache, false)
+	checks(svc, 2)
+	if n := h.sessions.lookups(); n != 6 {
+		t.Fatalf("FindAccess called %d times, want the cache bypassed while disconnected", n)
+	}
+
+	waitListening(t, cache, true)
+	checks(svc, 3)
+	if n := h.sessions.lookups(); n != 7 {
+		t.Fatalf("FindAccess called %d times, want caching again after reconnecting", n)
+	}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/pkg/money"
+	"github.com/wildanasyrof/backend-topup/pkg/utils"
+)
+
+// SettingType menentukan cara value setting di-parse dari kolom text.
+type SettingType string
+
+const (
+	SettingBool     SettingType = "bool"
+	SettingInt      SettingType = "int"
+	SettingMoney    SettingType = "money"    // rupiah penuh, lihat pkg/money
+	SettingDuration SettingType = "duration" // format Go: 30m, 1h30m; di public settings dikirim sebagai detik
+	SettingString   SettingType = "string"
+)
+
+// SettingDefinition mendeklarasikan satu key setting. Key yang tidak ada di registry ditolak.
+type SettingDefinition struct {
+	Key         string
+	Type        SettingType
+	Default     string
+	Public      bool // ikut dikirim di GET /public-settings
+	Description string
+	// Check validasi tambahan setelah value berhasil di-parse sesuai Type
+	Check func(v any) error
+}
+
+var settingDefinitions = []SettingDefinition{
+	{Key: entity.SettingMaintenanceMode, Type: SettingBool, Default: "false", Public: true,
+		Description: "Tolak request customer dengan 503; admin dan staff tetap bisa masuk"},
+	{Key: entity.SettingMaintenanceMessage, Type: SettingString, Default: "Sedang dalam pemeliharaan, silakan coba lagi nanti.", Public: true,
+		Description: "Pesan yang ditampilkan selama maintenance", Check: maxLen(500)},
+	{Key: entity.SettingSiteName, Type: SettingString, Default: "TopUp", Public: true,
+		Description: "Nama toko", Check: all(notEmpty, maxLen(100))},
+	{Key: entity.SettingSiteLogo, Type: SettingString, Default: "", Public: true,
+		Description: "URL logo toko", Check: optionalURL},
+	{Key: entity.SettingSupportEmail, Type: SettingString, Default: "", Public: true,
+		Description: "Email customer support", Check: optionalEmail},
+	{Key: entity.SettingContactWhatsapp, Type: SettingString, Default: "", Public: true,
+		Description: "Nomor WhatsApp customer support, disimpan dalam format 62xxx", Check: optionalPhone},
+	{Key: entity.SettingMinDeposit, Type: SettingMoney, Default: "10000", Public: true,
+		Description: "Nominal deposit saldo minimum", Check: minMoney(1000)},
+	{Key: entity.SettingOrderTTL, Type: SettingDuration, Default: "30m", Public: true,
+		Description: "Batas waktu pembayaran order sejak dibuat", Check: durationBetween(time.Minute, 7*24*time.Hour)},
+	{Key: entity.SettingFeatureRegistration, Type: SettingBool, Default: "true", Public: true,
+		Description: "Izinkan pendaftaran akun baru"},
+	{Key: entity.SettingFeatureDeposit, Type: SettingBool, Default: "true", Public: true,
+		Description: "Izinkan deposit saldo"},
+	{Key: entity.SettingFeatureGuestCheckout, Type: SettingBool, Default: "true", Public: true,
+		Description: "Izinkan order tanpa login"},
+	{Key: entity.SettingRequireAdmin2FA, Type: SettingBool, Default: "false",
+		Description: "Wajibkan 2FA untuk admin dan staff sebelum mendapat sesi"},
+}
+
+// SettingDefinitions mengembalikan seluruh registry setting.
+func SettingDefinitions() []SettingDefinition {
+	return settingDefinitions
+}
+
+// LookupSetting mencari definisi key di registry.
+func LookupSetting(key string) (SettingDefinition, bool) {
+	for _, d := range settingDefinitions {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return SettingDefinition{}, false
+}
+
+// Parse mengubah value mentah menjadi nilai terketik dan menjalankan Check.
+func (d SettingDefinition) Parse(raw string) (any, error) {
+	raw = strings.TrimSpace(raw)
+	var (
+		v   any
+		err error
+	)
+	switch d.Type {
+	case SettingBool:
+		v, err = strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+	case SettingInt:
+		v, err = strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+	case SettingMoney:
+		v, err = money.Parse(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a whole rupiah amount")
+		}
+	case SettingDuration:
+		v, err = time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("must be a duration like 30m or 2h")
+		}
+	case SettingString:
+		v = raw
+		if d.Key == entity.SettingContactWhatsapp && raw != "" {
+			v = utils.NormalizePhone(raw)
+		}
+	default:
+		return nil, fmt.Errorf("unknown setting type %q", d.Type)
+	}
+
+	if d.Check != nil {
+		if err := d.Check(v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// Format menulis nilai terketik kembali ke bentuk kanonik untuk disimpan di kolom value.
+func (d SettingDefinition) Format(v any) string {
+	switch x := v.(type) {
+	case bool:
+		return strconv.FormatBool(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case money.Money:
+		return x.String()
+	case time.Duration:
+		return x.String()
+	case string:
+		return x
+	}
+	return fmt.Sprint(v)
+}
+
+// publicValue adalah bentuk JSON untuk frontend: durasi dikirim sebagai detik.
+func publicValue(v any) any {
+	if d, ok := v.(time.Duration); ok {
+		return int64(d / time.Second)
+	}
+	return v
+}
+
+// --- Check helpers ---
+
+func all(checks ...func(any) error) func(any) error {
+	return func(v any) error {
+		for _, c := range checks {
+			if err := c(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func notEmpty(v any) error {
+	if v.(string) == "" {
+		return fmt.Errorf("cannot be empty")
+	}
+	return nil
+}
+
+func maxLen(n int) func(any) error {
+	return func(v any) error {
+		if len(v.(string)) > n {
+			return fmt.Errorf("must be at most %d characters", n)
+		}
+		return nil
+	}
+}
+
+func optionalURL(v any) error {
+	s := v.(string)
+	if s == "" {
+		return nil
+	}
+	if u, err := url.Parse(s); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an http(s) url")
+	}
+	return nil
+}
+
+func optionalEmail(v any) error {
+	s := v.(string)
+	if s == "" {
+		return nil
+	}
+	if a, err := mail.ParseAddress(s); err != nil || a.Address != s {
+		return fmt.Errorf("must be an email address")
+	}
+	return nil
+}
+
+func optionalPhone(v any) error {
+	s := v.(string)
+	if s != "" && (len(s) < 10 || len(s) > 15 || !strings.HasPrefix(s, "62")) {
+		return fmt.Errorf("must be an Indonesian phone number")
+	}
+	return nil
+}
+
+func minMoney(min money.Money) func(any) error {
+	return func(v any) error {
+		if v.(money.Money) < min {
+			return fmt.Errorf("must be at least %s", min.Format())
+		}
+		return nil
+	}
+}
+
+func durationBetween(min, max time.Duration) func(any) error {
+	return func(v any) error {
+		if d := v.(time.Duration); d < min || d > max {
+			return fmt.Errorf("must be between %s and %s", min, max)
+		}
+		return nil
+	}
+}
@@ -2,43 +2,88 @@ package service
 
 import (
 	"context" // Import the context package
+	"errors"
+	"sync"
+	"time"
 
 	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
 	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
 	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/money"
 	"github.com/wildanasyrof/backend-topup/pkg/pagination"
+	"gorm.io/gorm"
 )
 
+// settingsCacheTTL: cache di-reload langsung setelah perubahan lewat service ini,
+// TTL hanya untuk menangkap perubahan dari replica lain
+const settingsCacheTTL = 30 * time.Second
+
+// SettingsReader membaca nilai setting terketik dari cache. Key yang belum di-set memakai default registry.
+type SettingsReader interface {
+	Bool(ctx context.Context, key string) bool
+	Int(ctx context.Context, key string) int64
+	Money(ctx context.Context, key string) money.Money
+	Duration(ctx context.Context, key string) time.Duration
+	String(ctx context.Context, key string) string
+}
+
 // SettingsService interface updated to include context.Context
 type SettingsService interface {
+	SettingsReader
 	Create(ctx context.Context, req *dto.CreateSettingsRequest) (*entity.Settings, error)
 	FindByName(ctx context.Context, name string) (*entity.Settings, error)
 	Update(ctx context.Context, id int, req *dto.UpdateSettingsRequest) (*entity.Settings, error)
 	Delete(ctx context.Context, id int) (*entity.Settings, error)
 	FindAll(ctx context.Context, q dto.SettingsListQuery) ([]*entity.Settings, pagination.Meta, error)
+	// Definitions mengembalikan registry beserta nilai efektif tiap key
+	Definitions(ctx context.Context) []dto.SettingDefinitionResponse
+	// Public mengembalikan nilai key yang ditandai Public untuk storefront
+	Public(ctx context.Context) map[string]any
 }
 
 type settingsService struct {
 	settingsRepo repository.SettingsRepository
+	audit        AuditLogService
+	logger       logger.Logger
+
+	mu       sync.RWMutex
+	values   map[string]any // nilai terketik per key registry
+	loadedAt time.Time
+	loading  sync.Mutex // satu reload saja saat cache kedaluwarsa, request lain menunggu hasilnya
 }
 
-func NewSettingsService(settingsRepo repository.SettingsRepository) SettingsService {
+func NewSettingsService(settingsRepo repository.SettingsRepository, audit AuditLogService, logger logger.Logger) SettingsService {
 	return &settingsService{
 		settingsRepo: settingsRepo,
+		audit:        audit,
+		logger:       logger,
 	}
 }
 
 // Create implements SettingsService.
 func (s *settingsService) Create(ctx context.Context, req *dto.CreateSettingsRequest) (*entity.Settings, error) {
+	value, err := normalizeSetting(req.Name, req.Value)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureNameFree(ctx, req.Name); err != nil {
+		return nil, err
+	}
+
 	settings := &entity.Settings{
 		Name:  req.Name,
-		Value: req.Value,
+		Value: value,
 	}
 
 	// Pass ctx to the repository call
 	if err := s.settingsRepo.Create(ctx, settings); err != nil {
 		return nil, err
 	}
+	s.reload(ctx)
+
+	s.audit.Record(ctx, entity.AuditCreate, "settings", settings.ID, nil, settings)
 
 	return settings, nil
 }
@@ -55,6 +100,9 @@ func (s *settingsService) Delete(ctx context.Context, id int) (*entity.Settings,
 	if err := s.settingsRepo.Delete(ctx, settings); err != nil {
 		return nil, err
 	}
+	s.reload(ctx)
+
+	s.audit.Record(ctx, entity.AuditDelete, "settings", settings.ID, settings, nil)
 
 	return settings, nil
 }
@@ -78,7 +126,12 @@ func (s *settingsService) Update(ctx context.Context, id int, req *dto.UpdateSet
 		return nil, err
 	}
 
-	if req.Name != "" {
+	before := *settings
+
+	if req.Name != "" && req.Name != settings.Name {
+		if err := s.ensureNameFree(ctx, req.Name); err != nil {
+			return nil, err
+		}
 		settings.Name = req.Name
 	}
 
@@ -86,10 +139,171 @@ func (s *settingsService) Update(ctx context.Context, id int, req *dto.UpdateSet
 		settings.Value = req.Value
 	}
 
+	// Value lama divalidasi ulang jika key diganti
+	if settings.Value, err = normalizeSetting(settings.Name, settings.Value); err != nil {
+		return nil, err
+	}
+
 	// Pass ctx to the repository call
 	if err := s.settingsRepo.Update(ctx, settings); err != nil {
 		return nil, err
 	}
+	s.reload(ctx)
+
+	s.audit.Record(ctx, entity.AuditUpdate, "settings", settings.ID, before, settings)
 
 	return settings, nil
 }
+
+// Definitions implements SettingsService.
+func (s *settingsService) Definitions(ctx context.Context) []dto.SettingDefinitionResponse {
+	out := make([]dto.SettingDefinitionResponse, 0, len(settingDefinitions))
+	for _, d := range settingDefinitions {
+		out = append(out, dto.SettingDefinitionResponse{
+			Key:         d.Key,
+			Type:        string(d.Type),
+			Default:     d.Default,
+			Public:      d.Public,
+			Description: d.Description,
+			Value:       d.Format(s.get(ctx, d.Key)),
+		})
+	}
+	return out
+}
+
+// Public implements SettingsService.
+func (s *settingsService) Public(ctx context.Context) map[string]any {
+	out := make(map[string]any)
+	for _, d := range settingDefinitions {
+		if d.Public {
+			out[d.Key] = publicValue(s.get(ctx, d.Key))
+		}
+	}
+	return out
+}
+
+// Bool implements SettingsReader.
+func (s *settingsService) Bool(ctx context.Context, key string) bool {
+	v, _ := s.get(ctx, key).(bool)
+	return v
+}
+
+// Int implements SettingsReader.
+func (s *settingsService) Int(ctx context.Context, key string) int64 {
+	v, _ := s.get(ctx, key).(int64)
+	return v
+}
+
+// Money implements SettingsReader.
+func (s *settingsService) Money(ctx context.Context, key string) money.Money {
+	v, _ := s.get(ctx, key).(money.Money)
+	return v
+}
+
+// Duration implements SettingsReader.
+func (s *settingsService) Duration(ctx context.Context, key string) time.Duration {
+	v, _ := s.get(ctx, key).(time.Duration)
+	return v
+}
+
+// String implements SettingsReader.
+func (s *settingsService) String(ctx context.Context, key string) string {
+	v, _ := s.get(ctx, key).(string)
+	return v
+}
+
+func (s *settingsService) get(ctx context.Context, key string) any {
+	s.mu.RLock()
+	values, fresh := s.values, time.Since(s.loadedAt) < settingsCacheTTL
+	s.mu.RUnlock()
+
+	if !fresh {
+		s.loading.Lock()
+		s.mu.RLock()
+		values, fresh = s.values, time.Since(s.loadedAt) < settingsCacheTTL
+		s.mu.RUnlock()
+		if !fresh {
+			values = s.reload(ctx)
+		}
+		s.loading.Unlock()
+	}
+	return values[key]
+}
+
+// reload membaca ulang tabel settings ke cache. Jika database gagal dibaca, cache lama tetap dipakai
+// (atau default registry jika belum pernah berhasil) dan dicoba lagi setelah TTL.
+func (s *settingsService) reload(ctx context.Context) map[string]any {
+	// Cache dipakai bersama semua request, jadi reload tidak ikut dibatalkan bersama request pemicunya
+	loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	rows, err := s.settingsRepo.List(loadCtx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loadedAt = time.Now()
+
+	if err != nil {
+		s.logger.Error(err, "failed to load settings, using cached values")
+		if s.values == nil {
+			s.values = settingDefaults()
+		}
+		return s.values
+	}
+
+	stored := make(map[string]string, len(rows))
+	for _, r := range rows {
+		stored[r.Name] = r.Value
+	}
+
+	values := settingDefaults()
+	for _, d := range settingDefinitions {
+		raw, ok := stored[d.Key]
+		if !ok {
+			continue
+		}
+		v, err := d.Parse(raw)
+		if err != nil {
+			s.logger.With(logger.Fields{"setting": d.Key}).Warn("invalid stored setting value, using default: " + err.Error())
+			continue
+		}
+		values[d.Key] = v
+	}
+	s.values = values
+	return values
+}
+
+func (s *settingsService) ensureNameFree(ctx context.Context, name string) error {
+	_, err := s.settingsRepo.FindByName(ctx, name)
+	if err == nil {
+		return apperror.New(apperror.CodeConflict, "setting "+name+" already exists", nil)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+// normalizeSetting memvalidasi value terhadap registry dan mengembalikan bentuk kanoniknya.
+func normalizeSetting(name, raw string) (string, error) {
+	def, ok := LookupSetting(name)
+	if !ok {
+		return "", apperror.Validation(map[string]string{"name": "unknown setting " + name})
+	}
+	v, err := def.Parse(raw)
+	if err != nil {
+		return "", apperror.Validation(map[string]string{"value": err.Error()})
+	}
+	return def.Format(v), nil
+}
+
+func settingDefaults() map[string]any {
+	values := make(map[string]any, len(settingDefinitions))
+	for _, d := range settingDefinitions {
+		v, err := d.Parse(d.Default)
+		if err != nil {
+			panic("invalid default for setting " + d.Key + ": " + err.Error())
+		}
+		values[d.Key] = v
+	}
+	return values
+}
@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"strings"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/hash"
+	"github.com/wildanasyrof/backend-topup/pkg/secretbox"
+	"github.com/wildanasyrof/backend-topup/pkg/totp"
+)
+
+const (
+	totpIssuer        = "Topup"
+	recoveryCodeCount = 10
+	recoveryAlphabet  = "abcdefghjkmnpqrstuvwxyz23456789" // tanpa karakter yang mirip (0/o, 1/l/i)
+)
+
+var errInvalid2FACode = apperror.New(apperror.CodeUnauthorized, "invalid two-factor code", nil)
+
+// TwoFactorService mengelola TOTP dan recovery code user.
+type TwoFactorService interface {
+	// Setup membuat secret baru; 2FA belum aktif sampai Enable dipanggil dengan kode yang valid
+	Setup(ctx context.Context, userID uint64) (*dto.TwoFactorSetupResponse, error)
+	// Enable mengaktifkan 2FA dan mengembalikan recovery code (hanya ditampilkan sekali)
+	Enable(ctx context.Context, userID uint64, code string) ([]string, error)
+	Disable(ctx context.Context, userID uint64, code string) error
+	RegenerateRecoveryCodes(ctx context.Context, userID uint64, code string) ([]string, error)
+	// Verify menerima kode TOTP atau recovery code
+	Verify(ctx context.Context, user *entity.User, code string) error
+	// Required memberi tahu apakah user wajib 2FA karena kebijakan admin
+	Required(ctx context.Context, user *entity.User) bool
+}
+
+type twoFactorService struct {
+	userRepository repository.UserRepository
+	recoveryRepo   repository.RecoveryCodeRepository
+	settings       SettingsReader
+	secrets        secretbox.Box
+}
+
+// NewTwoFactorService: secrets mengenkripsi seed TOTP sebelum disimpan (config security.encryption_key).
+func NewTwoFactorService(userRepository repository.UserRepository, recoveryRepo repository.RecoveryCodeRepository, settings SettingsReader, secrets secretbox.Box) TwoFactorService {
+	return &twoFactorService{userRepository: userRepository, recoveryRepo: recoveryRepo, settings: settings, secrets: secrets}
+}
+
+// Setup implements TwoFactorService.
+func (s *twoFactorService) Setup(ctx context.Context, userID uint64) (*dto.TwoFactorSetupResponse, error) {
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, apperror.New(apperror.CodeConflict, "two-factor authentication is already enabled", nil)
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternal, "failed to generate secret", err)
+	}
+	sealed, err := s.secrets.Seal(secret)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternal, "failed to store secret", err)
+	}
+	user.TOTPSecret = sealed
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return &dto.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// Enable implements TwoFactorService.
+func (s *twoFactorService) Enable(ctx context.Context, userID uint64, code string) ([]string, error) {
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TOTPEnabled {
+		return nil, apperror.New(apperror.CodeConflict, "two-factor authentication is already enabled", nil)
+	}
+	if user.TOTPSecret == "" {
+		return nil, apperror.New(apperror.CodeBadRequest, "start two-factor setup first", nil)
+	}
+
+	secret, err := s.secrets.Open(user.TOTPSecret)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternal, "failed to read secret", err)
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return nil, errInvalid2FACode
+	}
+	if err := s.useStep(ctx, user, step); err != nil {
+		return nil, err
+	}
+
+	user.TOTPEnabled = true
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+// Disable implements TwoFactorService.
+func (s *twoFactorService) Disable(ctx context.Context, userID uint64, code string) error {
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.TOTPEnabled {
+		return apperror.New(apperror.CodeBadRequest, "two-factor authentication is not enabled", nil)
+	}
+	if s.Required(ctx, user) {
+		return apperror.New(apperror.CodeForbidden, "two-factor authentication is required for this account", nil)
+	}
+	if err := s.Verify(ctx, user, code); err != nil {
+		return err
+	}
+
+	// totp_last_step dibiarkan: step hanya bertambah, jadi tidak menghalangi secret baru
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return err
+	}
+	return s.recoveryRepo.DeleteAll(ctx, user.ID)
+}
+
+// RegenerateRecoveryCodes implements TwoFactorService.
+func (s *twoFactorService) RegenerateRecoveryCodes(ctx context.Context, userID uint64, code string) ([]string, error) {
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.TOTPEnabled {
+		return nil, apperror.New(apperror.CodeBadRequest, "two-factor authentication is not enabled", nil)
+	}
+	if err := s.Verify(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	return s.issueRecoveryCodes(ctx, user.ID)
+}
+
+// Verify implements TwoFactorService.
+func (s *twoFactorService) Verify(ctx context.Context, user *entity.User, code string) error {
+	code = strings.TrimSpace(code)
+
+	secret, err := s.secrets.Open(user.TOTPSecret)
+	if err != nil {
+		return apperror.New(apperror.CodeInternal, "failed to read secret", err)
+	}
+	if step, ok := totp.Validate(secret, code, time.Now()); ok {
+		if err := s.useStep(ctx, user, step); err != nil {
+			return err
+		}
+		// Seed dari sebelum enkripsi diaktifkan dienkripsi saat pertama dipakai
+		if !s.secrets.IsSealed(user.TOTPSecret) {
+			if user.TOTPSecret, err = s.secrets.Seal(secret); err != nil {
+				return apperror.New(apperror.CodeInternal, "failed to store secret", err)
+			}
+			return s.userRepository.Update(ctx, user)
+		}
+		return nil
+	}
+
+	used, err := s.recoveryRepo.Use(ctx, user.ID, hash.HashToken(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errInvalid2FACode
+	}
+	return nil
+}
+
+// useStep menandai step TOTP terpakai. Kode yang sama (atau yang lebih lama) ditolak meski
+// dua request memakainya bersamaan, karena hanya satu UPDATE ... WHERE totp_last_step < step yang berhasil.
+func (s *twoFactorService) useStep(ctx context.Context, user *entity.User, step int64) error {
+	advanced, err := s.userRepository.AdvanceTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return err
+	}
+	if !advanced {
+		return errInvalid2FACode
+	}
+	user.TOTPLastStep = step
+	return nil
+}
+
+// Required implements TwoFactorService.
+func (s *twoFactorService) Required(ctx context.Context, user *entity.User) bool {
+	if user.Role == entity.RoleUser {
+		return false
+	}
+	return s.settings.Bool(ctx, entity.SettingRequireAdmin2FA)
+}
+
+func (s *twoFactorService) issueRecoveryCodes(ctx context.Context, userID uint64) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, apperror.New(apperror.CodeInternal, "failed to generate recovery codes", err)
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, hash.HashToken(normalizeRecoveryCode(code)))
+	}
+
+	if err := s.recoveryRepo.Replace(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode menghasilkan kode berformat xxxxx-xxxxx.
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	out := make([]byte, 0, 11)
+	for i, v := range b {
+		if i == 5 {
+			out = append(out, '-')
+		}
+		out = append(out, recoveryAlphabet[int(v)%len(recoveryAlphabet)])
+	}
+	return string(out), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.ReplaceAll(code, "-", ""), " ", ""))
+}
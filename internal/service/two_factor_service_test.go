@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/secretbox"
+	"github.com/wildanasyrof/backend-topup/pkg/totp"
+)
+
+func testSecretBox(t *testing.T) secretbox.Box {
+	t.Helper()
+	box, err := secretbox.New("test-encryption-key-test-encryption-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return box
+}
+
+// enrolled membuat user dengan 2FA aktif dan mengembalikan seed TOTP serta recovery code-nya
+func enrolled(t *testing.T, users *fakeUserRepo, svc TwoFactorService, email string) (*entity.User, string, []string) {
+	t.Helper()
+	ctx := context.Background()
+	user := &entity.User{Email: email, Role: entity.RoleUser}
+	if err := users.Store(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	setup, err := svc.Setup(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Kode step sebelumnya juga diterima (skew), jadi kode berikutnya di test tetap step yang lebih baru
+	code, _ := totp.Code(setup.Secret, time.Now().Add(-30*time.Second))
+	recovery, err := svc.Enable(ctx, user.ID, code)
+	if err != nil {
+		t.Fatalf("enable: %v", err)
+	}
+	return users.get(t, user.ID), setup.Secret, recovery
+}
+
+func TestTOTPSecretStoredEncrypted(t *testing.T) {
+	users := newFakeUserRepo()
+	box := testSecretBox(t)
+	svc := NewTwoFactorService(users, newFakeRecoveryRepo(), noSettings{}, box)
+
+	user, secret, _ := enrolled(t, users, svc, "enc@example.com")
+	if user.TOTPSecret == secret || !box.IsSealed(user.TOTPSecret) {
+		t.Fatalf("seed stored in plaintext: %q", user.TOTPSecret)
+	}
+	if !user.TOTPEnabled {
+		t.Fatal("2FA not enabled")
+	}
+	if opened, _ := box.Open(user.TOTPSecret); opened != secret {
+		t.Fatal("stored seed does not decrypt to the issued seed")
+	}
+}
+
+func TestTOTPCodeAcceptedOnceUnderConcurrency(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	svc := NewTwoFactorService(users, newFakeRecoveryRepo(), noSettings{}, testSecretBox(t))
+	user, secret, _ := enrolled(t, users, svc, "race@example.com")
+	code, _ := totp.Code(secret, time.Now())
+
+	// Setiap request membaca user sendiri (last step lama), seperti request paralel sungguhan
+	var ok int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if svc.Verify(ctx, users.get(t, user.ID), code) == nil {
+				mu.Lock()
+				ok++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if ok != 1 {
+		t.Fatalf("code accepted %d times, want 1", ok)
+	}
+
+	// Update profil dengan objek user lama tidak boleh menurunkan step terakhir
+	if err := users.Update(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.Verify(ctx, users.get(t, user.ID), code); err == nil {
+		t.Fatal("code accepted again after a stale update")
+	}
+}
+
+func TestLegacyPlaintextSeedIsEncryptedOnUse(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	box := testSecretBox(t)
+	svc := NewTwoFactorService(users, newFakeRecoveryRepo(), noSettings{}, box)
+
+	secret, _ := totp.GenerateSecret()
+	user := &entity.User{Email: "legacy@example.com", TOTPSecret: secret, TOTPEnabled: true}
+	if err := users.Store(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	code, _ := totp.Code(secret, time.Now())
+	if err := svc.Verify(ctx, users.get(t, user.ID), code); err != nil {
+		t.Fatalf("legacy seed rejected: %v", err)
+	}
+	if stored := users.get(t, user.ID).TOTPSecret; !box.IsSealed(stored) {
+		t.Fatalf("legacy seed still stored in plaintext: %q", stored)
+	}
+}
+
+func TestChallengeTokenIsSingleUse(t *testing.T) {
+	ctx := context.Background()
+	h := newAuthHarness(t)
+	twoFactor := NewTwoFactorService(h.users, newFakeRecoveryRepo(), noSettings{}, testSecretBox(t))
+	h.withTwoFactor(twoFactor)
+	user, _, recovery := enrolled(t, h.users, twoFactor, "2fa@example.com")
+
+	res, err := h.svc.CompleteLogin(ctx, user, h.userAgent, h.clientIP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.TwoFactor == nil || res.Session != nil {
+		t.Fatalf("want a 2FA challenge, got %+v", res)
+	}
+	token := res.TwoFactor.ChallengeToken
+
+	// Kode salah tidak menghabiskan challenge
+	if _, err := h.svc.VerifyTwoFactor(ctx, &dto.TwoFactorVerifyRequest{ChallengeToken: token, Code: "000000"}, h.userAgent, h.clientIP); err == nil {
+		t.Fatal("wrong code accepted")
+	}
+	res, err = h.svc.VerifyTwoFactor(ctx, &dto.TwoFactorVerifyRequest{ChallengeToken: token, Code: recovery[0]}, h.userAgent, h.clientIP)
+	if err != nil || res.Session == nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	// Token yang sama dengan recovery code lain tidak boleh membuat sesi kedua
+	_, err = h.svc.VerifyTwoFactor(ctx, &dto.TwoFactorVerifyRequest{ChallengeToken: token, Code: recovery[1]}, h.userAgent, h.clientIP)
+	if !apperror.Is(err, apperror.CodeUnauthorized) {
+		t.Fatalf("reused challenge got %v, want unauthorized", err)
+	}
+	if n := h.sessions.active(user.ID); n != 1 {
+		t.Fatalf("%d sessions created, want 1", n)
+	}
+}
@@ -2,26 +2,46 @@ package service
 
 import (
 	"context" // Import the context package
+	"slices"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
 	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
 	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/hash"
+	"github.com/wildanasyrof/backend-topup/pkg/utils"
 )
 
 // UserService interface updated to include context.Context
 type UserService interface {
 	GetUserByID(ctx context.Context, userID uint64) (*entity.User, error)
-	FindUserByGoogleID(ctx context.Context, id string) (*entity.User, error)
 	FindUserByEmail(ctx context.Context, email string) (*entity.User, error)
 	Update(ctx context.Context, userID uint64, req *dto.UpdateUserRequest) (*entity.User, error)
+	// SetPassword mengganti password lalu me-revoke semua sesi lain; sesi currentSessionID tetap login
+	SetPassword(ctx context.Context, userID uint64, currentSessionID string, req *dto.SetPasswordRequest) error
+
+	// Suspend memblokir login dan langsung membatalkan semua token user. Suspend dan Unsuspend
+	// mengikuti aturan ChangeRole: bukan akun sendiri, admin hanya oleh admin, dan role user tsb
+	// tidak boleh memegang permission di luar milik actor
+	Suspend(ctx context.Context, actorID uint64, actorRole string, userID uint64) (*entity.User, error)
+	Unsuspend(ctx context.Context, actorID uint64, actorRole string, userID uint64) (*entity.User, error)
+	// ChangeRole: hanya admin (actorRole) yang boleh memberi atau mencabut role admin, actor tidak boleh
+	// mengganti role-nya sendiri, dan role lama maupun baru tidak boleh memegang permission di luar milik actor
+	ChangeRole(ctx context.Context, actorID uint64, actorRole string, userID uint64, req *dto.UpdateUserRoleRequest) (*entity.User, error)
 }
 
 type userService struct {
 	userRepository repository.UserRepository
+	sessionRepo    repository.SessionRepository
+	roleRepo       repository.RoleRepository
+	accessCache    AccessCache
+	audit          AuditLogService
 }
 
-func NewUserService(userRepositry repository.UserRepository) UserService {
-	return &userService{userRepository: userRepositry}
+func NewUserService(userRepositry repository.UserRepository, sessionRepo repository.SessionRepository, roleRepo repository.RoleRepository, accessCache AccessCache, audit AuditLogService) UserService {
+	return &userService{userRepository: userRepositry, sessionRepo: sessionRepo, roleRepo: roleRepo, accessCache: accessCache, audit: audit}
 }
 
 // FindUserByEmail implements UserService.
@@ -57,7 +77,13 @@ func (s *userService) Update(ctx context.Context, userID uint64, req *dto.Update
 	}
 
 	if req.Whatsapp != nil {
-		user.Whatsapp = *req.Whatsapp
+		// Simpan dalam format internasional agar cocok saat login via WhatsApp
+		phone := utils.NormalizePhone(*req.Whatsapp)
+		if phone != user.Whatsapp {
+			// Nomor baru harus diverifikasi ulang sebelum bisa dipakai login
+			user.Whatsapp = phone
+			user.WhatsappVerifiedAt = nil
+		}
 	}
 
 	// Pass ctx to the repository call
@@ -68,13 +94,180 @@ func (s *userService) Update(ctx context.Context, userID uint64, req *dto.Update
 	return user, nil
 }
 
-// FindUserByGoogleID implements AuthService.
-func (a *userService) FindUserByGoogleID(ctx context.Context, id string) (*entity.User, error) {
-	user, err := a.userRepository.FindByGoogleID(ctx, id)
+// SetPassword implements UserService.
+func (s *userService) SetPassword(ctx context.Context, userID uint64, currentSessionID string, req *dto.SetPasswordRequest) error {
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// User Google belum punya password; user lain wajib konfirmasi password lama
+	if user.PasswordHash != "" {
+		if req.CurrentPassword == "" {
+			return apperror.Validation(map[string]string{"current_password": "current_password is required"})
+		}
+		if err := hash.ComparePassword(user.PasswordHash, req.CurrentPassword); err != nil {
+			return apperror.New(apperror.CodeBadRequest, "current password is incorrect", err)
+		}
+	}
+
+	user.PasswordHash = hash.HashPassword(req.NewPassword)
+
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return err
+	}
+
+	// Sesi lain (mis. perangkat yang dicuri) harus login ulang dengan password baru
+	if err := s.revokeOtherSessions(ctx, userID, currentSessionID); err != nil {
+		return err
+	}
+	s.accessCache.InvalidateUser(ctx, userID)
+	return nil
+}
+
+// revokeOtherSessions me-revoke semua sesi user kecuali family sesi saat ini.
+// Jika sesi saat ini tidak dikenali, semua sesi di-revoke.
+func (s *userService) revokeOtherSessions(ctx context.Context, userID uint64, currentSessionID string) error {
+	id, err := uuid.Parse(currentSessionID)
+	if err != nil {
+		return s.sessionRepo.RevokeAllByUserID(ctx, userID)
+	}
+	session, err := s.sessionRepo.FindByID(ctx, id)
+	if err != nil || session.UserID != userID {
+		return s.sessionRepo.RevokeAllByUserID(ctx, userID)
+	}
+
+	familyID := session.FamilyID
+	if familyID == uuid.Nil {
+		familyID = session.ID
+	}
+	return s.sessionRepo.RevokeOtherFamilies(ctx, userID, familyID)
+}
+
+// Suspend implements UserService.
+func (s *userService) Suspend(ctx context.Context, actorID uint64, actorRole string, userID uint64) (*entity.User, error) {
+	user, err := s.managedUser(ctx, actorID, actorRole, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.SuspendedAt != nil {
+		return user, nil
+	}
 
+	before := *user
+	now := time.Now()
+	user.SuspendedAt = &now
+	user.TokenVersion++
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, entity.AuditUpdate, "user", user.ID, before, user)
+
+	// Refresh token juga dicabut agar user tidak bisa mendapat access token baru
+	if err := s.sessionRepo.RevokeAllByUserID(ctx, userID); err != nil {
+		return nil, err
+	}
+	s.accessCache.InvalidateUser(ctx, userID)
+	return user, nil
+}
+
+// Unsuspend implements UserService.
+func (s *userService) Unsuspend(ctx context.Context, actorID uint64, actorRole string, userID uint64) (*entity.User, error) {
+	user, err := s.managedUser(ctx, actorID, actorRole, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	return user, err
+	before := *user
+	user.SuspendedAt = nil
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, entity.AuditUpdate, "user", user.ID, before, user)
+	s.accessCache.InvalidateUser(ctx, userID)
+	return user, nil
+}
+
+// managedUser mengambil user yang akan dikelola actor. Staff dengan users:manage tidak boleh mengelola
+// akunnya sendiri, admin, atau user yang role-nya memegang permission yang tidak dimiliki actor
+func (s *userService) managedUser(ctx context.Context, actorID uint64, actorRole string, userID uint64) (*entity.User, error) {
+	if actorID == userID {
+		return nil, apperror.New(apperror.CodeForbidden, "you cannot manage your own account", nil)
+	}
+
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if actorRole == entity.RoleAdmin {
+		return user, nil
+	}
+	if user.Role == entity.RoleAdmin {
+		return nil, apperror.ErrForbidden
+	}
+
+	perms, err := rolePermissions(ctx, s.roleRepo, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	held, err := rolePermissions(ctx, s.roleRepo, actorRole)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range perms {
+		if !slices.Contains(held, p) {
+			return nil, apperror.New(apperror.CodeForbidden, "you cannot manage a user with permission "+p+" that you do not hold", nil)
+		}
+	}
+	return user, nil
+}
+
+// ChangeRole implements UserService.
+func (s *userService) ChangeRole(ctx context.Context, actorID uint64, actorRole string, userID uint64, req *dto.UpdateUserRoleRequest) (*entity.User, error) {
+	if actorID == userID {
+		return nil, apperror.New(apperror.CodeForbidden, "you cannot change your own role", nil)
+	}
+
+	user, err := s.userRepository.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == req.Role {
+		return user, nil
+	}
+
+	// Staff dengan users:manage tidak boleh menaikkan siapa pun (termasuk dirinya lewat akun lain) menjadi admin
+	if actorRole != entity.RoleAdmin && (req.Role == entity.RoleAdmin || user.Role == entity.RoleAdmin) {
+		return nil, apperror.ErrForbidden
+	}
+	role, err := s.roleRepo.FindByName(ctx, req.Role)
+	if err != nil {
+		if apperror.Is(err, apperror.CodeNotFound) {
+			return nil, apperror.New(apperror.CodeBadRequest, "role "+req.Role+" does not exist", nil)
+		}
+		return nil, err
+	}
+
+	// Staff tidak boleh memberi role yang lebih kuat dari miliknya, atau mengubah user yang lebih kuat
+	current, err := rolePermissions(ctx, s.roleRepo, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range role.Permissions {
+		current = append(current, p.Permission)
+	}
+	if err := ensureHolds(ctx, s.roleRepo, actorRole, current); err != nil {
+		return nil, err
+	}
+
+	// Access token lama masih membawa role lama, naikkan version agar client wajib refresh
+	before := *user
+	user.Role = req.Role
+	user.TokenVersion++
+	if err := s.userRepository.Update(ctx, user); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, entity.AuditUpdate, "user", user.ID, before, user)
+	s.accessCache.InvalidateUser(ctx, userID)
+	return user, nil
 }
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/hash"
+)
+
+func TestSetPasswordRevokesOtherSessions(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	sessions := newFakeSessionRepo()
+	svc := NewUserService(users, sessions, nil, NewAccessCache(nil, testLogger()), &fakeAudit{})
+
+	user := &entity.User{Email: "u@example.com", PasswordHash: hash.HashPassword("old-password")}
+	if err := users.Store(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	current := &entity.UserSession{UserID: user.ID}
+	other := &entity.UserSession{UserID: user.ID}
+	for _, s := range []*entity.UserSession{current, other} {
+		if err := sessions.Create(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Sesi saat ini sudah pernah dirotasi: token yang dipakai adalah anggota family, bukan root-nya
+	rotated := &entity.UserSession{UserID: user.ID, FamilyID: current.FamilyID}
+	if err := sessions.Create(ctx, rotated); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &dto.SetPasswordRequest{CurrentPassword: "old-password", NewPassword: "new-password"}
+	if err := svc.SetPassword(ctx, user.ID, rotated.ID.String(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := hash.ComparePassword(users.get(t, user.ID).PasswordHash, "new-password"); err != nil {
+		t.Fatal("password not changed")
+	}
+	if s, _ := sessions.FindByID(ctx, other.ID); !s.IsRevoked {
+		t.Fatal("other session still active")
+	}
+	if s, _ := sessions.FindByID(ctx, rotated.ID); s.IsRevoked {
+		t.Fatal("current session revoked")
+	}
+}
+
+func TestSetPasswordUnknownSessionRevokesAll(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	sessions := newFakeSessionRepo()
+	svc := NewUserService(users, sessions, nil, NewAccessCache(nil, testLogger()), &fakeAudit{})
+
+	user := &entity.User{Email: "u@example.com"} // akun OAuth tanpa password
+	if err := users.Store(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := sessions.Create(ctx, &entity.UserSession{UserID: user.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.SetPassword(ctx, user.ID, uuid.NewString(), &dto.SetPasswordRequest{NewPassword: "new-password"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := sessions.active(user.ID); n != 0 {
+		t.Fatalf("%d sessions still active", n)
+	}
+}
+
+func TestSetPasswordRequiresCurrentPassword(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	sessions := newFakeSessionRepo()
+	svc := NewUserService(users, sessions, nil, NewAccessCache(nil, testLogger()), &fakeAudit{})
+
+	user := &entity.User{Email: "u@example.com", PasswordHash: hash.HashPassword("old-password")}
+	if err := users.Store(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := sessions.Create(ctx, &entity.UserSession{UserID: user.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := &dto.SetPasswordRequest{CurrentPassword: "wrong-password", NewPassword: "new-password"}
+	if err := svc.SetPassword(ctx, user.ID, "", req); err == nil {
+		t.Fatal("wrong current password accepted")
+	}
+	if sessions.active(user.ID) != 1 {
+		t.Fatal("sessions revoked although password was not changed")
+	}
+}
+
+func TestChangeRoleRejectsEscalation(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	roles := newFakeRoleRepo(map[string][]string{
+		entity.RoleUser: nil,
+		"support":       {entity.PermOrdersRead},
+		"staff":         {entity.PermUsersManage, entity.PermOrdersRead},
+		"superstaff":    {entity.PermUsersManage, entity.PermRolesManage},
+	})
+	audit := &fakeAudit{}
+	svc := NewUserService(users, newFakeSessionRepo(), roles, NewAccessCache(nil, testLogger()), audit)
+
+	store := func(role string) *entity.User {
+		u := &entity.User{Email: role + "@example.com", Role: role}
+		if err := users.Store(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	actor, customer, senior := store("staff"), store(entity.RoleUser), store("superstaff")
+
+	cases := []struct {
+		name   string
+		target uint64
+		role   string
+		want   apperror.Code
+	}{
+		{"own role", actor.ID, "superstaff", apperror.CodeForbidden},
+		{"role with permissions the actor lacks", customer.ID, "superstaff", apperror.CodeForbidden},
+		{"user holding permissions the actor lacks", senior.ID, "support", apperror.CodeForbidden},
+		{"admin", customer.ID, entity.RoleAdmin, apperror.CodeForbidden},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := svc.ChangeRole(ctx, actor.ID, actor.Role, tc.target, &dto.UpdateUserRoleRequest{Role: tc.role})
+			if !apperror.Is(err, tc.want) {
+				t.Fatalf("got %v, want %s", err, tc.want)
+			}
+		})
+	}
+
+	if _, err := svc.ChangeRole(ctx, actor.ID, actor.Role, customer.ID, &dto.UpdateUserRoleRequest{Role: "support"}); err != nil {
+		t.Fatalf("assigning a subset of the actor's permissions: %v", err)
+	}
+	if _, err := svc.ChangeRole(ctx, 0, entity.RoleAdmin, customer.ID, &dto.UpdateUserRoleRequest{Role: "superstaff"}); err != nil {
+		t.Fatalf("admin assigning any role: %v", err)
+	}
+	want := fmt.Sprintf("update user:%d", customer.ID)
+	if got := audit.recorded(); len(got) != 2 || got[0] != want || got[1] != want {
+		t.Fatalf("audit entries %v, want two role changes", got)
+	}
+}
+
+func TestSuspendRequiresAuthorityOverTarget(t *testing.T) {
+	ctx := context.Background()
+	users := newFakeUserRepo()
+	roles := newFakeRoleRepo(map[string][]string{
+		entity.RoleUser: nil,
+		"staff":         {entity.PermUsersManage, entity.PermOrdersRead},
+		"superstaff":    {entity.PermUsersManage, entity.PermRolesManage},
+	})
+	sessions := newFakeSessionRepo()
+	svc := NewUserService(users, sessions, roles, NewAccessCache(nil, testLogger()), &fakeAudit{})
+
+	store := func(role string) *entity.User {
+		u := &entity.User{Email: role + "@example.com", Role: role}
+		if err := users.Store(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	actor, customer, senior, admin := store("staff"), store(entity.RoleUser), store("superstaff"), store(entity.RoleAdmin)
+
+	actions := map[string]func(context.Context, uint64, string, uint64) (*entity.User, error){
+		"suspend":   svc.Suspend,
+		"unsuspend": svc.Unsuspend,
+	}
+	for name, action := range actions {
+		for _, tc := range []struct {
+			name   string
+			target *entity.User
+		}{
+			{"own account", actor},
+			{"admin", admin},
+			{"user holding permissions the actor lacks", senior},
+		} {
+			t.Run(name+" "+tc.name, func(t *testing.T) {
+				if _, err := action(ctx, actor.ID, actor.Role, tc.target.ID); !apperror.Is(err, apperror.CodeForbidden) {
+					t.Fatalf("got %v, want forbidden", err)
+				}
+			})
+		}
+	}
+	if users.get(t, admin.ID).SuspendedAt != nil || users.get(t, senior.ID).SuspendedAt != nil {
+		t.Fatal("rejected suspension was applied")
+	}
+
+	if _, err := svc.Suspend(ctx, actor.ID, actor.Role, customer.ID); err != nil {
+		t.Fatalf("staff suspending a customer: %v", err)
+	}
+	if _, err := svc.Unsuspend(ctx, actor.ID, actor.Role, customer.ID); err != nil {
+		t.Fatalf("staff unsuspending a customer: %v", err)
+	}
+	if _, err := svc.Suspend(ctx, 0, entity.RoleAdmin, senior.ID); err != nil {
+		t.Fatalf("admin suspending staff: %v", err)
+	}
+	if _, err := svc.Suspend(ctx, admin.ID, entity.RoleAdmin, admin.ID); !apperror.Is(err, apperror.CodeForbidden) {
+		t.Fatalf("admin suspending themselves got %v, want forbidden", err)
+	}
+}
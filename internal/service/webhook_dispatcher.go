@@ -0,0 +1,171 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	"github.com/wildanasyrof/backend-topup/pkg/logger"
+	"github.com/wildanasyrof/backend-topup/pkg/signature"
+)
+
+const (
+	webhookPollInterval = 5 * time.Second
+	webhookBatchSize    = 20
+	// webhookLease harus lebih lama dari timeout HTTP client agar baris tidak diambil replica lain saat masih dikirim
+	webhookLease       = 2 * time.Minute
+	webhookMaxAttempts = 8
+	webhookBaseBackoff = 30 * time.Second // 30s, 1m, 2m, ... maksimal 6 jam
+	webhookMaxBackoff  = 6 * time.Hour
+	webhookMaxErrorLen = 500
+)
+
+// WebhookDispatcher mengirim isi outbox webhook di background.
+type WebhookDispatcher interface {
+	// Run memproses outbox sampai ctx dibatalkan
+	Run(ctx context.Context)
+}
+
+type webhookDispatcher struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+	logger logger.Logger
+}
+
+func NewWebhookDispatcher(repo repository.WebhookRepository, client *http.Client, logger logger.Logger) WebhookDispatcher {
+	return &webhookDispatcher{repo: repo, client: client, logger: logger}
+}
+
+// Run implements WebhookDispatcher.
+func (d *webhookDispatcher) Run(ctx context.Context) {
+	t := time.NewTicker(webhookPollInterval)
+	defer t.Stop()
+	for {
+		// Kosongkan semua yang jatuh tempo sebelum menunggu tick berikutnya
+		for d.dispatchBatch(ctx) == webhookBatchSize {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (d *webhookDispatcher) dispatchBatch(ctx context.Context) int {
+	deliveries, err := d.repo.ClaimDue(ctx, webhookBatchSize, webhookLease)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.Error(err, "failed to claim webhook deliveries")
+		}
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		wg.Add(1)
+		go func(delivery *entity.WebhookDelivery) {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}(&deliveries[i])
+	}
+	wg.Wait()
+	return len(deliveries)
+}
+
+func (d *webhookDispatcher) deliver(ctx context.Context, delivery *entity.WebhookDelivery) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+
+	status, err := d.send(ctx, delivery)
+	delivery.ResponseStatus = status
+	switch {
+	case err == nil:
+		delivery.Status = entity.WebhookSuccess
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts >= webhookMaxAttempts:
+		delivery.Status = entity.WebhookFailed
+		delivery.LastError = truncate(err.Error(), webhookMaxErrorLen)
+	default:
+		delivery.LastError = truncate(err.Error(), webhookMaxErrorLen)
+		delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+	}
+
+	if delivery.Status == entity.WebhookFailed {
+		d.logger.With(logger.Fields{
+			"event":       "webhook_failed",
+			"delivery_id": delivery.ID,
+			"endpoint_id": delivery.EndpointID,
+		}).Warn("webhook delivery gave up after max attempts")
+	}
+
+	// Simpan dengan context baru: hasil kirim tetap tercatat walau server sedang shutdown
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := d.repo.SaveAttempt(saveCtx, delivery); err != nil {
+		d.logger.Error(err, fmt.Sprintf("failed to save webhook delivery %d", delivery.ID))
+	}
+}
+
+// send mengirim satu delivery dan mengembalikan status HTTP (0 jika tidak ada response).
+// Header signature memakai skema yang sama dengan H2H API, dengan secret endpoint sebagai key.
+func (d *webhookDispatcher) send(ctx context.Context, delivery *entity.WebhookDelivery) (int, error) {
+	if delivery.Endpoint == nil {
+		return 0, fmt.Errorf("webhook endpoint no longer exists")
+	}
+
+	u, err := url.Parse(delivery.Endpoint.URL)
+	if err != nil {
+		return 0, err
+	}
+
+	body := []byte(delivery.Payload)
+	ts := time.Now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Topup-Webhook/1.0")
+	req.Header.Set("X-Webhook-ID", delivery.EventID)
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(ts, 10))
+	req.Header.Set("X-Webhook-Signature", signature.Sign(delivery.Endpoint.Secret, ts, http.MethodPost, u.RequestURI(), body))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(res.Body, webhookMaxErrorLen))
+		return res.StatusCode, fmt.Errorf("HTTP %d: %s", res.StatusCode, snippet)
+	}
+	return res.StatusCode, nil
+}
+
+func webhookBackoff(attempts int) time.Duration {
+	if attempts > 20 {
+		return webhookMaxBackoff
+	}
+	return min(webhookBaseBackoff<<(attempts-1), webhookMaxBackoff)
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
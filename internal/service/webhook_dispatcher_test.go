@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	"github.com/wildanasyrof/backend-topup/pkg/pagination"
+	"github.com/wildanasyrof/backend-topup/pkg/signature"
+)
+
+// fakeWebhookRepo hanya menyimpan delivery; endpoint di-attach langsung ke setiap delivery
+type fakeWebhookRepo struct {
+	repository.WebhookRepository
+	mu         sync.Mutex
+	deliveries []*entity.WebhookDelivery
+}
+
+func (r *fakeWebhookRepo) add(endpoint *entity.WebhookEndpoint, attempts int) uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d := &entity.WebhookDelivery{
+		ID:            uint64(len(r.deliveries) + 1),
+		EndpointID:    endpoint.ID,
+		EventID:       "evt-" + strconv.Itoa(len(r.deliveries)+1),
+		Event:         WebhookOrderStatusChanged,
+		Payload:       `{"event":"order.status_changed"}`,
+		Status:        entity.WebhookPending,
+		Attempts:      attempts,
+		NextAttemptAt: time.Now(),
+		Endpoint:      endpoint,
+	}
+	r.deliveries = append(r.deliveries, d)
+	return d.ID
+}
+
+func (r *fakeWebhookRepo) ClaimDue(_ context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	var out []entity.WebhookDelivery
+	for _, d := range r.deliveries {
+		if len(out) < limit && d.Status == entity.WebhookPending && !d.NextAttemptAt.After(now) {
+			out = append(out, *d)
+			d.NextAttemptAt = now.Add(lease)
+		}
+	}
+	return out, nil
+}
+
+func (r *fakeWebhookRepo) SaveAttempt(_ context.Context, d *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := *d
+	r.deliveries[d.ID-1] = &cp
+	return nil
+}
+
+func (r *fakeWebhookRepo) FindDeliveries(context.Context, dto.WebhookDeliveryListQuery) ([]entity.WebhookDelivery, pagination.Meta, error) {
+	return nil, pagination.Meta{}, nil
+}
+
+func (r *fakeWebhookRepo) Resend(_ context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d := r.deliveries[id-1]
+	d.Status, d.Attempts, d.NextAttemptAt = entity.WebhookPending, 0, time.Now()
+	return nil
+}
+
+// due memajukan jadwal semua delivery agar retry berikutnya bisa langsung diklaim
+func (r *fakeWebhookRepo) due() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range r.deliveries {
+		d.NextAttemptAt = time.Now().Add(-time.Second)
+	}
+}
+
+func (r *fakeWebhookRepo) get(id uint64) entity.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.deliveries[id-1]
+}
+
+// receiver adalah endpoint reseller lokal yang memverifikasi signature dan membalas dengan status dari respond
+type receiver struct {
+	*httptest.Server
+	secret  string
+	hits    atomic.Int32
+	failed  atomic.Int32 // jumlah request yang signature-nya tidak valid
+	respond func(n int32) int
+}
+
+func newReceiver(t *testing.T, respond func(n int32) int) *receiver {
+	t.Helper()
+	r := &receiver{secret: "whsec_test", respond: respond}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		n := r.hits.Add(1)
+		body, _ := io.ReadAll(req.Body)
+		ts, _ := strconv.ParseInt(req.Header.Get("X-Webhook-Timestamp"), 10, 64)
+		if req.Header.Get("X-Webhook-Event") == "" || req.Header.Get("X-Webhook-ID") == "" ||
+			!signature.Verify(r.secret, ts, req.Method, req.URL.RequestURI(), body, req.Header.Get("X-Webhook-Signature")) {
+			r.failed.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(r.respond(n))
+		_, _ = w.Write([]byte("receiver says hi"))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) endpoint() *entity.WebhookEndpoint {
+	return &entity.WebhookEndpoint{ID: 1, UserID: 7, URL: r.URL + "/hooks/topup?src=test", Secret: r.secret}
+}
+
+func newTestDispatcher(repo *fakeWebhookRepo, client *http.Client) *webhookDispatcher {
+	return NewWebhookDispatcher(repo, client, testLogger()).(*webhookDispatcher)
+}
+
+func TestWebhookDispatcherSignsDeliveries(t *testing.T) {
+	recv := newReceiver(t, func(int32) int { return http.StatusOK })
+	repo := &fakeWebhookRepo{}
+	id := repo.add(recv.endpoint(), 0)
+
+	if n := newTestDispatcher(repo, recv.Client()).dispatchBatch(context.Background()); n != 1 {
+		t.Fatalf("dispatched %d deliveries, want 1", n)
+	}
+	if recv.failed.Load() != 0 {
+		t.Fatal("receiver rejected the signature")
+	}
+	d := repo.get(id)
+	if d.Status != entity.WebhookSuccess || d.Attempts != 1 || d.ResponseStatus != http.StatusOK || d.DeliveredAt == nil {
+		t.Fatalf("unexpected delivery after success: %+v", d)
+	}
+}
+
+func TestWebhookDispatcherRetriesWithBackoff(t *testing.T) {
+	ctx := context.Background()
+	recv := newReceiver(t, func(n int32) int {
+		if n == 1 {
+			return http.StatusInternalServerError
+		}
+		return http.StatusNoContent
+	})
+	repo := &fakeWebhookRepo{}
+	id := repo.add(recv.endpoint(), 0)
+	d := newTestDispatcher(repo, recv.Client())
+
+	before := time.Now()
+	d.dispatchBatch(ctx)
+	got := repo.get(id)
+	if got.Status != entity.WebhookPending || got.Attempts != 1 || got.ResponseStatus != http.StatusInternalServerError {
+		t.Fatalf("unexpected delivery after failure: %+v", got)
+	}
+	if !strings.Contains(got.LastError, "HTTP 500") || !strings.Contains(got.LastError, "receiver says hi") {
+		t.Fatalf("last error %q does not describe the response", got.LastError)
+	}
+	if wait := got.NextAttemptAt.Sub(before); wait < webhookBaseBackoff || wait > webhookBaseBackoff+time.Second {
+		t.Fatalf("next attempt in %s, want about %s", wait, webhookBaseBackoff)
+	}
+
+	// Belum jatuh tempo: tidak dikirim lagi
+	if n := d.dispatchBatch(ctx); n != 0 {
+		t.Fatalf("dispatched %d deliveries before backoff elapsed", n)
+	}
+
+	repo.due()
+	d.dispatchBatch(ctx)
+	if got := repo.get(id); got.Status != entity.WebhookSuccess || got.Attempts != 2 || got.LastError != "" {
+		t.Fatalf("unexpected delivery after retry: %+v", got)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		11: webhookMaxBackoff,
+		64: webhookMaxBackoff,
+	}
+	for attempts, want := range cases {
+		if got := webhookBackoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %s, want %s", attempts, got, want)
+		}
+	}
+}
+
+func TestWebhookDispatcherGivesUpAfterMaxAttemptsAndResends(t *testing.T) {
+	ctx := context.Background()
+	var healthy atomic.Bool
+	recv := newReceiver(t, func(int32) int {
+		if healthy.Load() {
+			return http.StatusOK
+		}
+		return http.StatusBadGateway
+	})
+	repo := &fakeWebhookRepo{}
+	id := repo.add(recv.endpoint(), webhookMaxAttempts-1)
+	d := newTestDispatcher(repo, recv.Client())
+
+	d.dispatchBatch(ctx)
+	if got := repo.get(id); got.Status != entity.WebhookFailed || got.Attempts != webhookMaxAttempts {
+		t.Fatalf("unexpected delivery after last attempt: %+v", got)
+	}
+
+	// Delivery yang sudah failed tidak diambil lagi walau jadwalnya lewat
+	repo.due()
+	if n := d.dispatchBatch(ctx); n != 0 || recv.hits.Load() != 1 {
+		t.Fatalf("failed delivery dispatched again (%d claimed, %d hits)", n, recv.hits.Load())
+	}
+
+	// Resend dari admin memberi jatah retry baru
+	healthy.Store(true)
+	audit := &fakeAudit{}
+	if err := NewWebhookService(repo, audit).Resend(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if got := audit.recorded(); len(got) != 1 || got[0] != "resend webhook_delivery:1" {
+		t.Fatalf("audit entries %v", got)
+	}
+	d.dispatchBatch(ctx)
+	if got := repo.get(id); got.Status != entity.WebhookSuccess || got.Attempts != 1 {
+		t.Fatalf("unexpected delivery after resend: %+v", got)
+	}
+	if recv.failed.Load() != 0 {
+		t.Fatal("receiver rejected a signature")
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wildanasyrof/backend-topup/internal/domain/dto"
+	"github.com/wildanasyrof/backend-topup/internal/domain/entity"
+	"github.com/wildanasyrof/backend-topup/internal/repository"
+	apperror "github.com/wildanasyrof/backend-topup/pkg/apperr"
+	"github.com/wildanasyrof/backend-topup/pkg/pagination"
+)
+
+// Event yang bisa dilanggan reseller
+const (
+	WebhookOrderStatusChanged   = "order.status_changed"
+	WebhookDepositStatusChanged = "deposit.status_changed"
+
+	webhookMaxEndpoints = 5
+)
+
+// WebhookService mengelola endpoint webhook reseller dan menyusun event untuk outbox.
+type WebhookService interface {
+	CreateEndpoint(ctx context.Context, userID uint64, req *dto.CreateWebhookRequest) (*dto.WebhookCreatedResponse, error)
+	ListEndpoints(ctx context.Context, userID uint64) ([]dto.WebhookEndpointResponse, error)
+	DeleteEndpoint(ctx context.Context, userID, id uint64) error
+	ListDeliveries(ctx context.Context, q dto.WebhookDeliveryListQuery) ([]entity.WebhookDelivery, pagination.Meta, error)
+	Resend(ctx context.Context, deliveryID uint64) error
+	// NewEvent menyusun event untuk repository.Outbox; delivery ke setiap endpoint yang berlangganan
+	// ditulis dalam transaksi yang sama dengan perubahan statusnya
+	NewEvent(userID uint64, event string, data any) (*repository.WebhookEvent, error)
+}
+
+type webhookService struct {
+	repo  repository.WebhookRepository
+	audit AuditLogService
+}
+
+func NewWebhookService(repo repository.WebhookRepository, audit AuditLogService) WebhookService {
+	return &webhookService{repo: repo, audit: audit}
+}
+
+// CreateEndpoint implements WebhookService.
+func (s *webhookService) CreateEndpoint(ctx context.Context, userID uint64, req *dto.CreateWebhookRequest) (*dto.WebhookCreatedResponse, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return nil, apperror.New(apperror.CodeBadRequest, "webhook url must be an http(s) url", err)
+	}
+
+	count, err := s.repo.CountEndpoints(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= webhookMaxEndpoints {
+		return nil, apperror.New(apperror.CodeConflict, "maximum number of webhook endpoints reached", nil)
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, apperror.New(apperror.CodeInternal, "failed to generate webhook secret", err)
+	}
+
+	endpoint := &entity.WebhookEndpoint{
+		UserID: userID,
+		URL:    req.URL,
+		Secret: "whsec_" + base64.RawURLEncoding.EncodeToString(b),
+		Events: strings.Join(req.Events, ","),
+	}
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	return &dto.WebhookCreatedResponse{WebhookEndpointResponse: toWebhookEndpointResponse(endpoint), Secret: endpoint.Secret}, nil
+}
+
+// ListEndpoints implements WebhookService.
+func (s *webhookService) ListEndpoints(ctx context.Context, userID uint64) ([]dto.WebhookEndpointResponse, error) {
+	endpoints, err := s.repo.FindEndpointsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]dto.WebhookEndpointResponse, 0, len(endpoints))
+	for i := range endpoints {
+		out = append(out, toWebhookEndpointResponse(&endpoints[i]))
+	}
+	return out, nil
+}
+
+// DeleteEndpoint implements WebhookService.
+func (s *webhookService) DeleteEndpoint(ctx context.Context, userID, id uint64) error {
+	return s.repo.DeleteEndpoint(ctx, userID, id)
+}
+
+// ListDeliveries implements WebhookService.
+func (s *webhookService) ListDeliveries(ctx context.Context, q dto.WebhookDeliveryListQuery) ([]entity.WebhookDelivery, pagination.Meta, error) {
+	return s.repo.FindDeliveries(ctx, q)
+}
+
+// Resend implements WebhookService.
+func (s *webhookService) Resend(ctx context.Context, deliveryID uint64) error {
+	if err := s.repo.Resend(ctx, deliveryID); err != nil {
+		return err
+	}
+	s.audit.Record(ctx, entity.AuditResend, "webhook_delivery", deliveryID, nil, nil)
+	return nil
+}
+
+// NewEvent implements WebhookService.
+func (s *webhookService) NewEvent(userID uint64, event string, data any) (*repository.WebhookEvent, error) {
+	body := dto.WebhookEvent{ID: uuid.NewString(), Event: event, CreatedAt: time.Now(), Data: data}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, apperror.New(apperror.CodeInternal, "failed to encode webhook payload", err)
+	}
+	return &repository.WebhookEvent{UserID: userID, ID: body.ID, Event: event, Payload: string(payload), CreatedAt: body.CreatedAt}, nil
+}
+
+func toWebhookEndpointResponse(e *entity.WebhookEndpoint) dto.WebhookEndpointResponse {
+	events := []string{}
+	if e.Events != "" {
+		events = strings.Split(e.Events, ",")
+	}
+	return dto.WebhookEndpointResponse{ID: e.ID, URL: e.URL, Events: events, CreatedAt: e.CreatedAt}
+}
@@ -4,6 +4,7 @@ package apperror
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 type Code string
@@ -21,6 +22,7 @@ const (
 	// 5xx
 	CodeInternal    Code = "INTERNAL_ERROR"
 	CodeUnavailable Code = "SERVICE_UNAVAILABLE"
+	CodeMaintenance Code = "MAINTENANCE" // 503 selama maintenance mode, dibedakan agar frontend bisa menampilkan halaman khusus
 	CodeTimeout     Code = "REQUEST_TIME_OUT"
 )
 
@@ -29,6 +31,8 @@ type AppError struct {
 	Msg    string
 	Fields map[string]string // for validation; nil otherwise
 	Cause  error
+	// RetryAfter is sent as the Retry-After header; only set for CodeRateLimited
+	RetryAfter time.Duration
 }
 
 func (e *AppError) Error() string {
@@ -48,6 +52,11 @@ func Validation(fields map[string]string) *AppError {
 	return &AppError{Code: CodeUnprocessable, Msg: "Validation failed", Fields: fields}
 }
 
+// RateLimited tells the client to back off for at least retryAfter.
+func RateLimited(retryAfter time.Duration) *AppError {
+	return &AppError{Code: CodeRateLimited, Msg: "Too many requests, try again later", RetryAfter: retryAfter}
+}
+
 var (
 	ErrNotFound     = &AppError{Code: CodeNotFound, Msg: "Resource not found"}
 	ErrUnauthorized = &AppError{Code: CodeUnauthorized, Msg: "Unauthorized"}
@@ -39,6 +39,9 @@ func MapToHTTP(err error) (HTTPMapping, *AppError) {
 		case CodeUnavailable:
 			mapping.Status = fiber.StatusServiceUnavailable
 			mapping.Public = "Service unavailable"
+		case CodeMaintenance:
+			mapping.Status = fiber.StatusServiceUnavailable
+			mapping.Public = "Under maintenance"
 		case CodeTimeout:
 			mapping.Status = fiber.StatusGatewayTimeout
 			mapping.Public = "Request timeout"
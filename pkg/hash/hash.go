@@ -1,6 +1,10 @@
 package hash
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 
 	"golang.org/x/crypto/bcrypt"
@@ -21,3 +25,28 @@ func ComparePassword(hashed string, plain string) error {
 	}
 	return nil
 }
+
+// HashToken returns the hex encoded SHA-256 of a short-lived secret (OTP, API key, ...).
+// Secrets like these are random and single-purpose, so a fast hash is enough.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CompareToken checks a plain token against a HashToken digest in constant time.
+func CompareToken(hashed string, plain string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashed), []byte(HashToken(plain))) == 1
+}
+
+// HMACToken returns the hex encoded HMAC-SHA256 of token under a server-side key.
+// Dipakai untuk secret pendek (mis. OTP 6 digit) yang mudah di-brute force jika hanya di-hash biasa.
+func HMACToken(key []byte, token string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CompareHMACToken checks a plain token against a HMACToken digest in constant time.
+func CompareHMACToken(key []byte, hashed string, plain string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashed), []byte(HMACToken(key, plain))) == 1
+}
@@ -2,86 +2,180 @@ package jwt
 
 import (
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 	"github.com/wildanasyrof/backend-topup/internal/config"
 )
 
 type JWTService interface {
-	GenerateAccessToken(id uint64, role string) (string, error)
-	// GenerateRefreshToken(userID uuid.UUID) (string, error)
+	// GenerateAccessToken menyertakan session ID (sid) dan token version user agar token bisa dicabut sebelum expired
+	GenerateAccessToken(id uint64, role, sessionID string, tokenVersion int) (string, error)
 	GetRefreshTokenDuration() time.Duration
-	ValidateToken(token string) (uint64, string, error)
+	ValidateToken(token string) (*AccessTokenClaims, error)
+	// JWKS mengembalikan public key yang aktif untuk diverifikasi service lain (kosong pada mode HS256)
+	JWKS() JWKSet
+
+	// GenerateChallengeToken menerbitkan token singkat untuk langkah kedua login (2FA), bukan access token
+	GenerateChallengeToken(userID uint64, purpose string) (string, error)
+	// ValidateChallengeToken memeriksa tanda tangan, masa berlaku dan purpose. Token membawa jti unik;
+	// pemanggil yang harus mencatat jti agar token hanya bisa dipakai sekali.
+	ValidateChallengeToken(token, purpose string) (*ChallengeClaims, error)
 }
 
+// challengeAudience membedakan challenge token dari access token yang ditandatangani key yang sama
+const (
+	challengeAudience = "2fa-challenge"
+	challengeTTL      = 5 * time.Minute
+)
+
 type jwtService struct {
 	SecretKey  string
+	keys       map[string]*signingKey
+	active     *signingKey
+	jwks       JWKSet
 	accessTTL  time.Duration
 	refreshTTL time.Duration
 }
 
-func NewJWTService(cfg *config.Config) JWTService {
-	return &jwtService{
+// NewJWTService memuat key ring dari JWT_KEYS_DIR. Tanpa direktori key, token ditandatangani HS256 dengan AccessSecret.
+func NewJWTService(cfg *config.Config) (JWTService, error) {
+	svc := &jwtService{
 		SecretKey:  cfg.JWT.AccessSecret,
 		accessTTL:  time.Duration(cfg.JWT.AccessTokenMinutes) * time.Minute,
 		refreshTTL: time.Duration(cfg.JWT.RefreshTokenDays) * time.Hour * 24, // Convert days to hours
+		jwks:       JWKSet{Keys: []JWK{}},
+	}
+	if cfg.JWT.KeysDir == "" {
+		return svc, nil
+	}
+
+	keys, err := loadKeys(cfg.JWT.KeysDir)
+	if err != nil {
+		return nil, fmt.Errorf("load jwt keys: %w", err)
+	}
+	active, ok := keys[cfg.JWT.ActiveKeyID]
+	if !ok || active.private == nil {
+		return nil, fmt.Errorf("load jwt keys: no private key for JWT_ACTIVE_KID %q", cfg.JWT.ActiveKeyID)
 	}
+	svc.keys = keys
+	svc.active = active
+	svc.jwks = buildJWKS(keys)
+	return svc, nil
 }
 
 type AccessTokenClaims struct {
-	Id   uint64 `json:"user_id"`
-	Role string `json:"role"`
+	Id           uint64 `json:"user_id"`
+	Role         string `json:"role"`
+	SessionID    string `json:"sid"`
+	TokenVersion int    `json:"ver"`
 	jwt.RegisteredClaims
 }
 
-type RefreshTokenClaims struct {
-	UserID string `json:"user_id"`
+type ChallengeClaims struct {
+	Id      uint64 `json:"user_id"`
+	Purpose string `json:"purpose"`
 	jwt.RegisteredClaims
 }
 
 // GenerateAccessToken implements JWTService.
-func (j *jwtService) GenerateAccessToken(id uint64, role string) (string, error) {
+func (j *jwtService) GenerateAccessToken(id uint64, role, sessionID string, tokenVersion int) (string, error) {
 	claims := AccessTokenClaims{
-		Id:   id,
-		Role: role,
+		Id:           id,
+		Role:         role,
+		SessionID:    sessionID,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.accessTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(j.SecretKey))
+	return j.sign(claims)
+}
+
+// GenerateChallengeToken implements JWTService.
+func (j *jwtService) GenerateChallengeToken(userID uint64, purpose string) (string, error) {
+	claims := ChallengeClaims{
+		Id:      userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Audience:  jwt.ClaimStrings{challengeAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(challengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return j.sign(claims)
+}
+
+// ValidateChallengeToken implements JWTService.
+func (j *jwtService) ValidateChallengeToken(tokenStr, purpose string) (*ChallengeClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &ChallengeClaims{}, j.keyFunc,
+		jwt.WithAudience(challengeAudience), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(*ChallengeClaims)
+	if !ok || !token.Valid || claims.Purpose != purpose || claims.ID == "" {
+		return nil, errors.New("invalid challenge token")
+	}
+	return claims, nil
 }
 
-// // GenerateRefreshToken implements JWTService.
-// func (j *jwtService) GenerateRefreshToken(userID uuid.UUID) (string, error) {
-// 	claims := RefreshTokenClaims{
-// 		UserID: userID.String(),
-// 		RegisteredClaims: jwt.RegisteredClaims{
-// 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.refreshTTL)),
-// 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-// 		},
-// 	}
-// 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-// 	return token.SignedString([]byte(j.SecretKey))
-// }
+func (j *jwtService) sign(claims jwt.Claims) (string, error) {
+	if j.active == nil {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return token.SignedString([]byte(j.SecretKey))
+	}
+
+	token := jwt.NewWithClaims(j.active.method, claims)
+	token.Header["kid"] = j.active.kid
+	return token.SignedString(j.active.private)
+}
 
 // ValidateToken implements JWTService.
-// Returns userID if valid, otherwise error.
-func (j *jwtService) ValidateToken(tokenStr string) (uint64, string, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &AccessTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(j.SecretKey), nil
-	})
+// Returns the claims if the signature and expiry are valid, otherwise error.
+// Challenge token 2FA ditandatangani key yang sama, jadi audience-nya ditolak di sini.
+func (j *jwtService) ValidateToken(tokenStr string) (*AccessTokenClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &AccessTokenClaims{}, j.keyFunc, jwt.WithExpirationRequired())
 	if err != nil {
-		return 0, "", err
+		return nil, err
 	}
-	if claims, ok := token.Claims.(*AccessTokenClaims); ok && token.Valid {
-		return claims.Id, claims.Role, nil
+	if claims, ok := token.Claims.(*AccessTokenClaims); ok && token.Valid && !slices.Contains(claims.Audience, challengeAudience) {
+		return claims, nil
 	}
-	return 0, "", errors.New("invalid token")
+	return nil, errors.New("invalid token")
 }
 
 func (j *jwtService) GetRefreshTokenDuration() time.Duration {
 	return j.refreshTTL
 }
+
+// JWKS implements JWTService.
+func (j *jwtService) JWKS() JWKSet {
+	return j.jwks
+}
+
+// keyFunc memilih key verifikasi berdasarkan header kid dan memastikan alg sesuai jenis key,
+// sehingga token HS256 yang ditandatangani dengan public key tidak bisa lolos.
+func (j *jwtService) keyFunc(token *jwt.Token) (interface{}, error) {
+	if j.keys == nil {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+		return []byte(j.SecretKey), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return key.public, nil
+}
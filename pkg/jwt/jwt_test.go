@@ -0,0 +1,279 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/wildanasyrof/backend-topup/internal/config"
+)
+
+// keyDir menulis key ring ke direktori sementara: private sebagai PKCS#8, public sebagai PKIX
+type keyDir struct {
+	t   *testing.T
+	dir string
+}
+
+func newKeyDir(t *testing.T) *keyDir {
+	return &keyDir{t: t, dir: t.TempDir()}
+}
+
+func (d *keyDir) write(kid, blockType string, der []byte) {
+	d.t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(d.dir, kid+".pem"), data, 0o600); err != nil {
+		d.t.Fatal(err)
+	}
+}
+
+func (d *keyDir) private(kid string, key any) {
+	d.t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		d.t.Fatal(err)
+	}
+	d.write(kid, "PRIVATE KEY", der)
+}
+
+func (d *keyDir) public(kid string, key any) []byte {
+	d.t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		d.t.Fatal(err)
+	}
+	d.write(kid, "PUBLIC KEY", der)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func (d *keyDir) service(active string) JWTService {
+	d.t.Helper()
+	svc, err := NewJWTService(&config.Config{JWT: config.JwtConfig{
+		AccessTokenMinutes: 15, RefreshTokenDays: 30, KeysDir: d.dir, ActiveKeyID: active,
+	}})
+	if err != nil {
+		d.t.Fatal(err)
+	}
+	return svc
+}
+
+func rsaKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func edKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func headerOf(t *testing.T, token string) map[string]any {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &AccessTokenClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Header
+}
+
+func TestKeyRotation(t *testing.T) {
+	dir := newKeyDir(t)
+	oldKey := edKey(t)
+	dir.private("2026-01", oldKey)
+	before := dir.service("2026-01")
+	oldToken, err := before.GenerateAccessToken(1, "user", "sid-1", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := headerOf(t, oldToken); h["kid"] != "2026-01" || h["alg"] != "EdDSA" {
+		t.Fatalf("header %v", h)
+	}
+
+	// Rotasi: key lama tinggal public key (hanya verifikasi), key baru RSA menjadi aktif
+	dir.public("2026-01", oldKey.Public())
+	dir.private("2026-06", rsaKey(t))
+	after := dir.service("2026-06")
+
+	newToken, err := after.GenerateAccessToken(2, "admin", "sid-2", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := headerOf(t, newToken); h["kid"] != "2026-06" || h["alg"] != "RS256" {
+		t.Fatalf("new token header %v, want the active key", h)
+	}
+	claims, err := after.ValidateToken(newToken)
+	if err != nil || claims.Id != 2 || claims.Role != "admin" || claims.SessionID != "sid-2" || claims.TokenVersion != 3 {
+		t.Fatalf("new token: %+v, %v", claims, err)
+	}
+	if claims, err := after.ValidateToken(oldToken); err != nil || claims.Id != 1 {
+		t.Fatalf("token signed with the retired key rejected: %v", err)
+	}
+}
+
+func TestActiveKeyMustBePrivate(t *testing.T) {
+	dir := newKeyDir(t)
+	dir.public("retired", edKey(t).Public())
+	for _, active := range []string{"retired", "missing"} {
+		if _, err := NewJWTService(&config.Config{JWT: config.JwtConfig{KeysDir: dir.dir, ActiveKeyID: active}}); err == nil {
+			t.Errorf("active key %q accepted", active)
+		}
+	}
+}
+
+func TestRejectsUnknownKeyAndAlgorithmMismatch(t *testing.T) {
+	dir := newKeyDir(t)
+	key := rsaKey(t)
+	dir.private("current", key)
+	svc := dir.service("current")
+	valid := AccessTokenClaims{Id: 1, Role: "admin", RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}}
+
+	sign := func(method jwt.SigningMethod, kid string, secret any) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, valid)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		s, err := token.SignedString(secret)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+
+	// Key lain yang tidak ada di ring
+	otherDir := newKeyDir(t)
+	otherDir.private("unknown", rsaKey(t))
+	unknown, _ := otherDir.service("unknown").GenerateAccessToken(1, "admin", "sid", 0)
+
+	// Public key dipublikasikan di JWKS, jadi siapa pun bisa memakainya sebagai secret HMAC
+	pubPEM := newKeyDir(t).public("current", &key.PublicKey)
+
+	cases := map[string]string{
+		"unknown kid":               unknown,
+		"missing kid":               sign(jwt.SigningMethodRS256, "", key),
+		"HS256 with the public key": sign(jwt.SigningMethodHS256, "current", pubPEM),
+		"RS384 with the right key":  sign(jwt.SigningMethodRS384, "current", key),
+		"alg none":                  sign(jwt.SigningMethodNone, "current", jwt.UnsafeAllowNoneSignatureType),
+	}
+	for name, token := range cases {
+		t.Run(name, func(t *testing.T) {
+			if _, err := svc.ValidateToken(token); err == nil {
+				t.Fatal("token accepted")
+			}
+		})
+	}
+	if _, err := svc.ValidateToken(sign(jwt.SigningMethodRS256, "current", key)); err != nil {
+		t.Fatalf("control token rejected: %v", err)
+	}
+}
+
+func TestJWKSContainsOnlyPublicKeys(t *testing.T) {
+	dir := newKeyDir(t)
+	rsaPriv, edPriv := rsaKey(t), edKey(t)
+	dir.private("rsa", rsaPriv)
+	dir.private("ed", edPriv)
+	dir.public("retired", edKey(t).Public())
+	set := dir.service("rsa").JWKS()
+
+	if len(set.Keys) != 3 || set.Keys[0].Kid != "ed" || set.Keys[1].Kid != "retired" || set.Keys[2].Kid != "rsa" {
+		t.Fatalf("keys %+v", set.Keys)
+	}
+	raw, err := json.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded struct{ Keys []map[string]any }
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range decoded.Keys {
+		// Komponen private RSA (d, p, q, dp, dq, qi) dan Ed25519 (d) tidak boleh ikut
+		for _, private := range []string{"d", "p", "q", "dp", "dq", "qi"} {
+			if _, ok := k[private]; ok {
+				t.Fatalf("JWK %v exposes %q", k["kid"], private)
+			}
+		}
+		if k["use"] != "sig" {
+			t.Fatalf("JWK %v use %v", k["kid"], k["use"])
+		}
+	}
+
+	ed, rs := set.Keys[0], set.Keys[2]
+	if ed.Kty != "OKP" || ed.Crv != "Ed25519" || ed.Alg != "EdDSA" || ed.X == "" {
+		t.Fatalf("ed25519 JWK %+v", ed)
+	}
+	if rs.Kty != "RSA" || rs.Alg != "RS256" || rs.E != "AQAB" || strings.TrimSpace(rs.N) == "" {
+		t.Fatalf("rsa JWK %+v", rs)
+	}
+}
+
+func TestHS256Mode(t *testing.T) {
+	svc, err := NewJWTService(&config.Config{JWT: config.JwtConfig{AccessSecret: "secret", AccessTokenMinutes: 15}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys := svc.JWKS().Keys; len(keys) != 0 {
+		t.Fatalf("HS256 mode publishes keys %v", keys)
+	}
+	token, _ := svc.GenerateAccessToken(1, "user", "sid", 0)
+	if _, err := svc.ValidateToken(token); err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := NewJWTService(&config.Config{JWT: config.JwtConfig{AccessSecret: "other", AccessTokenMinutes: 15}})
+	if _, err := other.ValidateToken(token); err == nil {
+		t.Fatal("token signed with another secret accepted")
+	}
+}
+
+func TestChallengeAndAccessTokensAreNotInterchangeable(t *testing.T) {
+	dir := newKeyDir(t)
+	dir.private("current", edKey(t))
+	for name, svc := range map[string]JWTService{
+		"keys": dir.service("current"),
+		"hs256": func() JWTService {
+			s, _ := NewJWTService(&config.Config{JWT: config.JwtConfig{AccessSecret: "secret", AccessTokenMinutes: 15}})
+			return s
+		}(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			challenge, err := svc.GenerateChallengeToken(1, "login")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := svc.ValidateToken(challenge); err == nil {
+				t.Fatal("challenge token accepted as access token")
+			}
+			if _, err := svc.ValidateChallengeToken(challenge, "enroll"); err == nil {
+				t.Fatal("challenge token accepted for another purpose")
+			}
+			claims, err := svc.ValidateChallengeToken(challenge, "login")
+			if err != nil || claims.Id != 1 || claims.ID == "" {
+				t.Fatalf("challenge: %+v, %v", claims, err)
+			}
+
+			access, _ := svc.GenerateAccessToken(1, "user", "sid", 0)
+			if _, err := svc.ValidateChallengeToken(access, "login"); err == nil {
+				t.Fatal("access token accepted as challenge token")
+			}
+		})
+	}
+}
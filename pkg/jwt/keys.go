@@ -0,0 +1,114 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is one entry of the key ring. private is nil for retired keys that only verify.
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.PrivateKey
+	public  crypto.PublicKey
+}
+
+// JWK is a single public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet is the body of GET /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// loadKeys reads every <kid>.pem in dir. Private keys (PKCS#8) can sign and verify,
+// public keys (PKIX) only verify so tokens signed before a rotation stay valid until they expire.
+func loadKeys(dir string) (map[string]*signingKey, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*signingKey, len(files))
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		block, _ := pem.Decode(raw)
+		if block == nil {
+			return nil, fmt.Errorf("%s: no PEM block", file)
+		}
+
+		key := &signingKey{kid: strings.TrimSuffix(filepath.Base(file), ".pem")}
+		if priv, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+			key.private = priv
+			switch k := priv.(type) {
+			case *rsa.PrivateKey:
+				key.public = &k.PublicKey
+			case ed25519.PrivateKey:
+				key.public = k.Public()
+			}
+		} else if pub, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+			key.public = pub
+		} else {
+			return nil, fmt.Errorf("%s: expected a PKCS#8 private key or PKIX public key", file)
+		}
+
+		switch key.public.(type) {
+		case *rsa.PublicKey:
+			key.method = jwt.SigningMethodRS256
+		case ed25519.PublicKey:
+			key.method = jwt.SigningMethodEdDSA
+		default:
+			return nil, fmt.Errorf("%s: only RSA and Ed25519 keys are supported", file)
+		}
+		keys[key.kid] = key
+	}
+	return keys, nil
+}
+
+func (k *signingKey) jwk() JWK {
+	j := JWK{Kid: k.kid, Use: "sig", Alg: k.method.Alg()}
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		j.Kty = "RSA"
+		j.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		j.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		j.Kty = "OKP"
+		j.Crv = "Ed25519"
+		j.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return j
+}
+
+func buildJWKS(keys map[string]*signingKey) JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(keys))}
+	for _, k := range keys {
+		set.Keys = append(set.Keys, k.jwk())
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
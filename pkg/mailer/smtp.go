@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/config"
+)
+
+type smtpMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+func NewSMTPMailer(cfg *config.Config) Mailer {
+	var auth smtp.Auth
+	if cfg.Mail.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Mail.Username, cfg.Mail.Password, cfg.Mail.Host)
+	}
+	return &smtpMailer{
+		addr: net.JoinHostPort(cfg.Mail.Host, cfg.Mail.Port),
+		from: cfg.Mail.From,
+		auth: auth,
+	}
+}
+
+// Send implements Mailer.
+func (m *smtpMailer) Send(ctx context.Context, msg Message) error {
+	// net/smtp has no context support, so run it in the background and honour ctx ourselves.
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(m.addr, m.auth, m.from, []string{msg.To}, m.build(msg))
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
+		return err
+	}
+}
+
+func (m *smtpMailer) build(msg Message) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "From: %s\r\n", m.from)
+	fmt.Fprintf(&sb, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&sb, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&sb, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n\r\n")
+	sb.WriteString(msg.Body)
+	return []byte(sb.String())
+}
@@ -0,0 +1,73 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/wildanasyrof/backend-topup/internal/config"
+)
+
+// fonnteProvider talks to Fonnte-style gateways: a form POST with `target` and
+// `message`, authenticated with a device token in the Authorization header.
+type fonnteProvider struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+func NewFonnteProvider(cfg *config.Config, httpClient *http.Client) MessagingProvider {
+	return &fonnteProvider{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(cfg.Whatsapp.BaseURL, "/"),
+		token:      cfg.Whatsapp.Token,
+	}
+}
+
+type fonnteResponse struct {
+	Status bool   `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// SendText implements MessagingProvider.
+func (f *fonnteProvider) SendText(ctx context.Context, to, message string) error {
+	form := url.Values{}
+	form.Set("target", to)
+	form.Set("message", message)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.baseURL+"/send", strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("create request error: %w", err)
+	}
+	req.Header.Set("Authorization", f.token)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := f.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http execution error: %w", err)
+	}
+	defer res.Body.Close()
+
+	rawBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("read response body error: %w", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("whatsapp gateway bad response: status %d, body: %s", res.StatusCode, rawBody)
+	}
+
+	var payload fonnteResponse
+	if err := json.Unmarshal(rawBody, &payload); err != nil {
+		return fmt.Errorf("unmarshal response error: %w", err)
+	}
+	if !payload.Status {
+		return fmt.Errorf("whatsapp gateway rejected message: %s", payload.Reason)
+	}
+
+	return nil
+}
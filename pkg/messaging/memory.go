@@ -0,0 +1,52 @@
+package messaging
+
+import (
+	"context"
+	"sync"
+)
+
+// Sent is a message captured by MemoryProvider.
+type Sent struct {
+	To      string
+	Message string
+}
+
+// MemoryProvider keeps messages in memory instead of sending them. Used for
+// local development and tests.
+type MemoryProvider struct {
+	mu   sync.Mutex
+	sent []Sent
+}
+
+func NewMemoryProvider() *MemoryProvider {
+	return &MemoryProvider{}
+}
+
+// SendText implements MessagingProvider.
+func (m *MemoryProvider) SendText(ctx context.Context, to, message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, Sent{To: to, Message: message})
+	return nil
+}
+
+// Sent returns a copy of every message sent so far.
+func (m *MemoryProvider) Sent() []Sent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]Sent, len(m.sent))
+	copy(out, m.sent)
+	return out
+}
+
+// Last returns the most recent message sent to the given number.
+func (m *MemoryProvider) Last(to string) (Sent, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := len(m.sent) - 1; i >= 0; i-- {
+		if m.sent[i].To == to {
+			return m.sent[i], true
+		}
+	}
+	return Sent{}, false
+}
@@ -0,0 +1,9 @@
+package messaging
+
+import "context"
+
+// MessagingProvider sends plain text messages to a phone number through a
+// WhatsApp gateway (Fonnte, WA Business API, ...).
+type MessagingProvider interface {
+	SendText(ctx context.Context, to, message string) error
+}
@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "topup"
+
+// Metrics mencatat metrik Prometheus aplikasi. Label dibatasi ke nilai yang jumlahnya kecil
+// (route template, bukan URL mentah) agar jumlah time series tidak meledak.
+type Metrics interface {
+	ObserveHTTP(method, route string, status int, d time.Duration)
+	// ObserveSupplier mencatat satu panggilan ke API supplier; err != nil dihitung sebagai error
+	ObserveSupplier(supplier, operation string, d time.Duration, err error)
+	// OrderStatus/DepositStatus dihitung setiap kali order/deposit dibuat atau berpindah ke status tersebut
+	OrderStatus(status string)
+	DepositStatus(status string)
+	SetSupplierBalance(supplier string, balance float64)
+	SetStuckOrders(n int64)
+	// RegisterDB mengekspos statistik connection pool database/sql
+	RegisterDB(name string, db *sql.DB)
+	Handler() http.Handler
+}
+
+type metrics struct {
+	reg *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	supplierDuration *prometheus.HistogramVec
+	supplierErrors   *prometheus.CounterVec
+	supplierBalance  *prometheus.GaugeVec
+
+	orders      *prometheus.CounterVec
+	deposits    *prometheus.CounterVec
+	stuckOrders prometheus.Gauge
+}
+
+func New() Metrics {
+	m := &metrics{
+		reg: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "http_requests_total",
+			Help: "HTTP requests by method, route template and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "http_request_duration_seconds",
+			Help:    "HTTP request latency by method and route template.",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"method", "route"}),
+		supplierDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Name: "supplier_request_duration_seconds",
+			Help:    "Latency of calls to product suppliers.",
+			Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"supplier", "operation"}),
+		supplierErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "supplier_request_errors_total",
+			Help: "Failed calls to product suppliers.",
+		}, []string{"supplier", "operation"}),
+		supplierBalance: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "supplier_balance_rupiah",
+			Help: "Last known deposit balance at the supplier.",
+		}, []string{"supplier"}),
+		orders: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "orders_total",
+			Help: "Orders created or moved into a status.",
+		}, []string{"status"}),
+		deposits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Name: "deposits_total",
+			Help: "Deposits created or moved into a status.",
+		}, []string{"status"}),
+		stuckOrders: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace, Name: "orders_stuck_processing",
+			Help: "Orders that have been processing for longer than expected.",
+		}),
+	}
+
+	m.reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration,
+		m.supplierDuration, m.supplierErrors, m.supplierBalance,
+		m.orders, m.deposits, m.stuckOrders,
+	)
+	return m
+}
+
+// ObserveHTTP implements Metrics.
+func (m *metrics) ObserveHTTP(method, route string, status int, d time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(d.Seconds())
+}
+
+// ObserveSupplier implements Metrics.
+func (m *metrics) ObserveSupplier(supplier, operation string, d time.Duration, err error) {
+	m.supplierDuration.WithLabelValues(supplier, operation).Observe(d.Seconds())
+	if err != nil {
+		m.supplierErrors.WithLabelValues(supplier, operation).Inc()
+	}
+}
+
+// OrderStatus implements Metrics.
+func (m *metrics) OrderStatus(status string) {
+	m.orders.WithLabelValues(status).Inc()
+}
+
+// DepositStatus implements Metrics.
+func (m *metrics) DepositStatus(status string) {
+	m.deposits.WithLabelValues(status).Inc()
+}
+
+// SetSupplierBalance implements Metrics.
+func (m *metrics) SetSupplierBalance(supplier string, balance float64) {
+	m.supplierBalance.WithLabelValues(supplier).Set(balance)
+}
+
+// SetStuckOrders implements Metrics.
+func (m *metrics) SetStuckOrders(n int64) {
+	m.stuckOrders.Set(float64(n))
+}
+
+// RegisterDB implements Metrics.
+func (m *metrics) RegisterDB(name string, db *sql.DB) {
+	m.reg.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler implements Metrics.
+func (m *metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.reg, promhttp.HandlerOpts{Registry: m.reg})
+}
+
+// NewServer membuat listener terpisah untuk /metrics agar tidak ikut terekspos lewat port publik API.
+// Jika token tidak kosong, scraper wajib mengirim "Authorization: Bearer <token>".
+func NewServer(addr, token string, m Metrics) *http.Server {
+	h := m.Handler()
+	if token != "" {
+		next := h
+		h = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", h)
+	return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+}
@@ -0,0 +1,315 @@
+// Package migrate menjalankan migrasi SQL berversi untuk PostgreSQL.
+//
+// Setiap migrasi adalah sepasang file <version>_<name>.up.sql dan <version>_<name>.down.sql,
+// dengan version berupa timestamp UTC YYYYMMDDHHMMSS. Versi yang sudah dijalankan dicatat di
+// tabel schema_migrations. Up dan Down memegang pg advisory lock selama berjalan, sehingga
+// beberapa replica yang menjalankan migrasi bersamaan tidak saling tabrak.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// lockKey adalah key pg_advisory_lock yang dipakai bersama oleh semua instance
+const lockKey int64 = 0x746f707570 // "topup"
+
+const versionLayout = "20060102150405"
+
+var fileRe = regexp.MustCompile(`^(\d{14})_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration adalah satu versi skema beserta SQL up dan down-nya.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status adalah Migration beserta waktu dijalankan; AppliedAt nil berarti masih pending.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+type Migrator interface {
+	// Up menjalankan semua migrasi pending sesuai urutan versi
+	Up(ctx context.Context) ([]Migration, error)
+	// Down me-rollback sejumlah steps migrasi terakhir
+	Down(ctx context.Context, steps int) ([]Migration, error)
+	Status(ctx context.Context) ([]Status, error)
+	// Pending mengembalikan migrasi yang belum dijalankan, tanpa mengambil lock
+	Pending(ctx context.Context) ([]Migration, error)
+}
+
+type migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// New membaca migrasi dari fsys (biasanya embed.FS) dan menyiapkan Migrator untuk db.
+func New(db *sql.DB, fsys fs.FS) (Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &migrator{db: db, migrations: migrations}, nil
+}
+
+// Load membaca dan memvalidasi semua file migrasi di root fsys, terurut berdasarkan versi.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".sql") {
+			continue
+		}
+		m := fileRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("migrate: invalid file name %q, want <YYYYMMDDHHMMSS>_<name>.(up|down).sql", e.Name())
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migrate: version %d used by both %q and %q", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if strings.TrimSpace(mig.Up) == "" {
+			return nil, fmt.Errorf("migrate: %d_%s has no up migration", mig.Version, mig.Name)
+		}
+		out = append(out, *mig)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+// Create menulis pasangan file up/down kosong untuk migrasi baru di dir.
+func Create(dir, name string, now time.Time) (up, down string, err error) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), "_"))
+	base := now.UTC().Format(versionLayout) + "_" + name
+	if !fileRe.MatchString(base + ".up.sql") {
+		return "", "", fmt.Errorf("migrate: invalid migration name %q, use letters, digits and underscores", name)
+	}
+
+	up = filepath.Join(dir, base+".up.sql")
+	down = filepath.Join(dir, base+".down.sql")
+	if err = os.WriteFile(up, []byte("-- "+name+"\n"), 0o644); err != nil {
+		return
+	}
+	err = os.WriteFile(down, []byte("-- rollback "+name+"\n"), 0o644)
+	return
+}
+
+// Up implements Migrator.
+func (m *migrator) Up(ctx context.Context) (done []Migration, err error) {
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			if err := run(ctx, conn, mig, mig.Up, `INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, now())`, mig.Version, mig.Name); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return
+}
+
+// Down implements Migrator.
+func (m *migrator) Down(ctx context.Context, steps int) (done []Migration, err error) {
+	if steps < 1 {
+		return nil, errors.New("migrate: steps must be at least 1")
+	}
+
+	known := make(map[int64]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_migrations ORDER BY version DESC LIMIT $1`, steps)
+		if err != nil {
+			return err
+		}
+		var versions []int64
+		for rows.Next() {
+			var v int64
+			if err := rows.Scan(&v); err != nil {
+				rows.Close()
+				return err
+			}
+			versions = append(versions, v)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, v := range versions {
+			mig, ok := known[v]
+			if !ok {
+				return fmt.Errorf("migrate: version %d is applied but its files are missing from this build", v)
+			}
+			if strings.TrimSpace(mig.Down) == "" {
+				return fmt.Errorf("migrate: %d_%s has no down migration", mig.Version, mig.Name)
+			}
+			if err := run(ctx, conn, mig, mig.Down, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version); err != nil {
+				return err
+			}
+			done = append(done, mig)
+		}
+		return nil
+	})
+	return
+}
+
+// Status implements Migrator.
+func (m *migrator) Status(ctx context.Context) ([]Status, error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Migration: mig}
+		if at, ok := applied[mig.Version]; ok {
+			s.AppliedAt = &at
+		}
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// Pending implements Migrator. Seperti Status, tidak menulis apa pun ke database.
+func (m *migrator) Pending(ctx context.Context) ([]Migration, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			pending = append(pending, s.Migration)
+		}
+	}
+	return pending, nil
+}
+
+// withLock menjalankan fn pada satu koneksi yang memegang advisory lock.
+// Advisory lock terikat ke sesi, jadi semua statement harus lewat koneksi yang sama.
+func (m *migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, lockKey); err != nil {
+		return fmt.Errorf("migrate: acquire lock: %w", err)
+	}
+	defer func() { _, _ = conn.ExecContext(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock($1)`, lockKey) }()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// run menjalankan body migrasi dan pencatatan versinya dalam satu transaksi,
+// sehingga migrasi yang gagal tidak meninggalkan skema setengah jadi.
+func run(ctx context.Context, conn *sql.Conn, mig Migration, body, record string, args ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return fmt.Errorf("migrate: %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    bigint PRIMARY KEY,
+		name       text NOT NULL,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`)
+	return err
+}
+
+// appliedVersions membaca schema_migrations; tabel yang belum ada berarti belum ada migrasi yang jalan
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[int64]time.Time, error) {
+	applied := map[int64]time.Time{}
+
+	var exists bool
+	if err := conn.QueryRowContext(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var v int64
+		var at time.Time
+		if err := rows.Scan(&v, &at); err != nil {
+			return nil, err
+		}
+		applied[v] = at
+	}
+	return applied, rows.Err()
+}
@@ -0,0 +1,110 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func file(body string) *fstest.MapFile {
+	return &fstest.MapFile{Data: []byte(body)}
+}
+
+func TestLoadPairsAndOrdersMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"20261019140000_add_orders.up.sql":     file("CREATE TABLE orders ();"),
+		"20261019140000_add_orders.down.sql":   file("DROP TABLE orders;"),
+		"20261019000000_baseline.up.sql":       file("CREATE TABLE users ();"),
+		"20261019000000_baseline.down.sql":     file("DROP TABLE users;"),
+		"20261019000100_no_rollback.up.sql":    file("UPDATE users SET name = '';"),
+		"20251231235959_earlier_year.up.sql":   file("SELECT 1;"),
+		"20251231235959_earlier_year.down.sql": file("SELECT 1;"),
+		"README.md":                            file("bukan migrasi"),
+		"fixtures/20261019000200_x.up.sql":     file("direktori diabaikan"),
+	}
+	got, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Migration{
+		{Version: 20251231235959, Name: "earlier_year", Up: "SELECT 1;", Down: "SELECT 1;"},
+		{Version: 20261019000000, Name: "baseline", Up: "CREATE TABLE users ();", Down: "DROP TABLE users;"},
+		{Version: 20261019000100, Name: "no_rollback", Up: "UPDATE users SET name = '';"},
+		{Version: 20261019140000, Name: "add_orders", Up: "CREATE TABLE orders ();", Down: "DROP TABLE orders;"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("loaded %d migrations, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadRejectsInvalidFiles(t *testing.T) {
+	cases := []struct {
+		name  string
+		files fstest.MapFS
+		err   string
+	}{
+		{"short version", fstest.MapFS{"2026101900000_x.up.sql": file("SELECT 1;")}, "invalid file name"},
+		{"no name", fstest.MapFS{"20261019000000.up.sql": file("SELECT 1;")}, "invalid file name"},
+		{"upper case name", fstest.MapFS{"20261019000000_AddUsers.up.sql": file("SELECT 1;")}, "invalid file name"},
+		{"dash in name", fstest.MapFS{"20261019000000_add-users.up.sql": file("SELECT 1;")}, "invalid file name"},
+		{"no direction", fstest.MapFS{"20261019000000_add_users.sql": file("SELECT 1;")}, "invalid file name"},
+		{"unknown direction", fstest.MapFS{"20261019000000_add_users.redo.sql": file("SELECT 1;")}, "invalid file name"},
+		{"duplicate version", fstest.MapFS{
+			"20261019000000_add_users.up.sql":  file("SELECT 1;"),
+			"20261019000000_add_orders.up.sql": file("SELECT 1;"),
+		}, "version 20261019000000 used by both"},
+		{"duplicate version across directions", fstest.MapFS{
+			"20261019000000_add_users.up.sql":    file("SELECT 1;"),
+			"20261019000000_add_orders.down.sql": file("SELECT 1;"),
+		}, "used by both"},
+		{"down only", fstest.MapFS{"20261019000000_add_users.down.sql": file("SELECT 1;")}, "20261019000000_add_users has no up migration"},
+		{"empty up", fstest.MapFS{
+			"20261019000000_add_users.up.sql":   file(" \n\t"),
+			"20261019000000_add_users.down.sql": file("SELECT 1;"),
+		}, "has no up migration"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Load(tc.files); err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("err = %v, want %q", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestCreateWritesLoadableFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 19, 21, 30, 5, 0, time.FixedZone("WIB", 7*3600))
+
+	up, down, err := Create(dir, "Add  Order Notes", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Versi selalu UTC, nama dinormalisasi ke snake_case
+	if filepath.Base(up) != "20261019143005_add_order_notes.up.sql" || filepath.Base(down) != "20261019143005_add_order_notes.down.sql" {
+		t.Fatalf("created %s and %s", up, down)
+	}
+
+	got, err := Load(os.DirFS(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Version != 20261019143005 || got[0].Name != "add_order_notes" || got[0].Down == "" {
+		t.Fatalf("loaded %+v", got)
+	}
+
+	for _, name := range []string{"", "add-notes", "catatan pesanan!"} {
+		if _, _, err := Create(dir, name, now); err == nil {
+			t.Errorf("Create(%q) accepted", name)
+		}
+	}
+}
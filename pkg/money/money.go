@@ -0,0 +1,146 @@
+// Package money menyimpan nominal rupiah sebagai integer sehingga penjumlahan dan
+// perhitungan fee selalu eksak, tidak seperti float64.
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money adalah nominal dalam rupiah penuh (1 = Rp1). Rupiah tidak memakai sen dalam transaksi,
+// jadi hasil perhitungan persentase dibulatkan ke rupiah terdekat.
+type Money int64
+
+// Zero adalah Rp0
+const Zero Money = 0
+
+// Parse membaca angka desimal seperti "25000" atau "25000.00". Pecahan selain nol ditolak.
+func Parse(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	whole, frac, _ := strings.Cut(s, ".")
+	if strings.Trim(frac, "0") != "" {
+		return 0, fmt.Errorf("money: %q has a fractional rupiah amount", s)
+	}
+	n, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	}
+	return Money(n), nil
+}
+
+func (m Money) Int64() int64 { return int64(m) }
+
+func (m Money) Add(o Money) Money { return m + o }
+
+func (m Money) Sub(o Money) Money { return m - o }
+
+// Mul mengalikan dengan jumlah barang
+func (m Money) Mul(qty int64) Money { return m * Money(qty) }
+
+func (m Money) IsZero() bool { return m == 0 }
+
+func (m Money) IsNegative() bool { return m < 0 }
+
+// Percent menghitung m × r, dibulatkan setengah ke atas (menjauhi nol) ke rupiah terdekat.
+func (m Money) Percent(r Rate) Money {
+	p := int64(m) * int64(r)
+	q, rem := p/rateScale, p%rateScale
+	if rem*2 >= rateScale {
+		q++
+	} else if rem*2 <= -rateScale {
+		q--
+	}
+	return Money(q)
+}
+
+// Fee menghitung biaya flat + persentase dari amount, pola fee payment method.
+func Fee(amount, flat Money, rate Rate) Money {
+	return flat + amount.Percent(rate)
+}
+
+// String mengembalikan angka polos, mis. "25000"
+func (m Money) String() string { return strconv.FormatInt(int64(m), 10) }
+
+// Format mengembalikan format tampilan Indonesia, mis. "Rp25.000"
+func (m Money) Format() string {
+	s := strconv.FormatInt(int64(m), 10)
+	sign := ""
+	if m < 0 {
+		sign, s = "-", s[1:]
+	}
+	var b strings.Builder
+	for i, c := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(c)
+	}
+	return sign + "Rp" + b.String()
+}
+
+// MarshalJSON implements json.Marshaler: ditulis sebagai angka.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler: menerima angka atau string angka.
+func (m *Money) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if unq, err := strconv.Unquote(s); err == nil {
+		s = unq
+	} else if _, err := json.Number(s).Float64(); err != nil {
+		return fmt.Errorf("money: invalid JSON amount %s", s)
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, dipakai form/query parser dan YAML.
+func (m *Money) UnmarshalText(b []byte) error {
+	v, err := Parse(string(b))
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Scan implements sql.Scanner. Kolom numeric lama (mis. "25000.00") juga diterima, tapi nilai
+// berpecahan ditolak agar tidak dibulatkan diam-diam.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = Money(v)
+	case float64:
+		// 2^63 tepat bisa direpresentasikan float64, jadi batas atasnya eksklusif
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 {
+			return fmt.Errorf("money: cannot scan %v, not a whole rupiah amount", v)
+		}
+		*m = Money(v)
+	case []byte:
+		return m.UnmarshalText(v)
+	case string:
+		return m.UnmarshalText([]byte(v))
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
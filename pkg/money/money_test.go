@@ -0,0 +1,201 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{"25000", 25000, false},
+		{" 25000 ", 25000, false},
+		{"25000.00", 25000, false},
+		{"25000.", 25000, false},
+		{"-1500", -1500, false},
+		{"-1500.0", -1500, false},
+		{"0", 0, false},
+		{"9223372036854775807", math.MaxInt64, false},
+		{"25000.50", 0, true},
+		{"-0.5", 0, true},
+		{"9223372036854775808", 0, true},
+		{"-9223372036854775809", 0, true},
+		{"", 0, true},
+		{".00", 0, true},
+		{"25.000,00", 0, true},
+		{"1e3", 0, true},
+	}
+	for _, tc := range cases {
+		got, err := Parse(tc.in)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("Parse(%q) = %d, %v; want %d, error %v", tc.in, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestPercentRoundsHalfAwayFromZero(t *testing.T) {
+	cases := []struct {
+		amount Money
+		rate   string
+		want   Money
+	}{
+		{10000, "1", 100},
+		{10000, "0.7", 70},
+		{150, "1", 2},   // 1,5 -> 2
+		{149, "1", 1},   // 1,49 -> 1
+		{50, "1", 1},    // 0,5 -> 1
+		{-150, "1", -2}, // -1,5 -> -2
+		{-149, "1", -1}, // -1,49 -> -1
+		{-50, "1", -1},  // -0,5 -> -1
+		{333, "33.3333", 111},
+		{1, "0.0001", 0},
+		{0, "2.5", 0},
+	}
+	for _, tc := range cases {
+		r, err := ParseRate(tc.rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := tc.amount.Percent(r); got != tc.want {
+			t.Errorf("%d × %s%% = %d, want %d", tc.amount, tc.rate, got, tc.want)
+		}
+	}
+
+	if got := Fee(100000, 2500, Rate(7000)); got != 3200 {
+		t.Errorf("Fee = %d, want 3200", got)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    Rate
+		wantErr bool
+	}{
+		{"1", 10000, false},
+		{"100", Rate(rateScale), false},
+		{"0.7", 7000, false},
+		{".7", 7000, false},
+		{"2.5", 25000, false},
+		{"0.0001", 1, false},
+		{"1.50000", 15000, false},
+		{"-0.7", -7000, false},
+		{"0.00001", 0, true},
+		{"", 0, true},
+		{"-", 0, true},
+		{"abc", 0, true},
+		{"1.-5", 0, true},
+		{"--1", 0, true},
+		{"99999999999999999", 0, true},
+	}
+	for _, tc := range cases {
+		got, err := ParseRate(tc.in)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("ParseRate(%q) = %d, %v; want %d, error %v", tc.in, got, err, tc.want, tc.wantErr)
+		}
+	}
+
+	// String kebalikan dari ParseRate
+	for _, s := range []string{"1", "0.7", "2.5", "0.0001", "-0.7", "100"} {
+		r, _ := ParseRate(s)
+		if r.String() != s {
+			t.Errorf("ParseRate(%q).String() = %q", s, r.String())
+		}
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	cases := []struct {
+		src     any
+		want    Money
+		wantErr bool
+	}{
+		{int64(25000), 25000, false},
+		{[]byte("25000"), 25000, false},
+		{[]byte("25000.00"), 25000, false},
+		{"-1500", -1500, false},
+		{float64(25000), 25000, false},
+		{float64(-3), -3, false},
+		{nil, 0, false},
+		{float64(25000.4), 0, true},
+		{float64(0.5), 0, true},
+		{math.Inf(1), 0, true},
+		{math.NaN(), 0, true},
+		{float64(1 << 63), 0, true},
+		{[]byte("25000.50"), 0, true},
+		{true, 0, true},
+	}
+	for _, tc := range cases {
+		m := Money(7)
+		err := m.Scan(tc.src)
+		if (err != nil) != tc.wantErr || !tc.wantErr && m != tc.want {
+			t.Errorf("Scan(%#v) = %d, %v; want %d, error %v", tc.src, m, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestRateScan(t *testing.T) {
+	cases := []struct {
+		src  any
+		want Rate
+	}{
+		{int64(1), 10000},
+		{[]byte("0.7"), 7000},
+		{"2.50", 25000},
+		{float64(0.7), 7000},
+	}
+	for _, tc := range cases {
+		var r Rate
+		if err := r.Scan(tc.src); err != nil || r != tc.want {
+			t.Errorf("Scan(%#v) = %d, %v; want %d", tc.src, r, err, tc.want)
+		}
+	}
+	if v, _ := Rate(7000).Value(); v != "0.7" {
+		t.Errorf("Value = %v, want 0.7", v)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	type item struct {
+		Price Money `json:"price"`
+		Fee   Rate  `json:"fee"`
+	}
+	in := item{Price: 25000, Fee: 7000}
+	b, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != `{"price":25000,"fee":0.7}` {
+		t.Fatalf("marshal = %s", b)
+	}
+	var out item
+	if err := json.Unmarshal(b, &out); err != nil || out != in {
+		t.Fatalf("unmarshal = %+v, %v", out, err)
+	}
+
+	// String angka dan desimal tanpa pecahan juga diterima
+	if err := json.Unmarshal([]byte(`{"price":"25000","fee":"0.7"}`), &out); err != nil || out != in {
+		t.Fatalf("unmarshal strings = %+v, %v", out, err)
+	}
+	if err := json.Unmarshal([]byte(`{"price":25000.00}`), &out); err != nil || out.Price != 25000 {
+		t.Fatalf("unmarshal decimal = %+v, %v", out, err)
+	}
+	for _, bad := range []string{`{"price":25000.5}`, `{"price":true}`, `{"price":"abc"}`, `{"fee":"0.00001"}`} {
+		if err := json.Unmarshal([]byte(bad), &out); err == nil {
+			t.Errorf("unmarshal %s accepted", bad)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := map[Money]string{0: "Rp0", 999: "Rp999", 25000: "Rp25.000", 1234567: "Rp1.234.567", -25000: "-Rp25.000"}
+	for m, want := range cases {
+		if got := m.Format(); got != want {
+			t.Errorf("Format(%d) = %q, want %q", m, got, want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// rateScale: jumlah unit Rate untuk 100%. Presisi 4 desimal persen (0,0001%).
+const (
+	rateDecimals       = 4
+	rateScale    int64 = 1_000_000
+)
+
+// Rate adalah persentase fixed-point dengan presisi 4 desimal: 1% = 10000, 0.7% = 7000.
+// Di JSON dan database ditulis sebagai persen desimal biasa ("0.7"), jadi kolom numeric tetap dipakai.
+type Rate int64
+
+// ParseRate membaca persen desimal seperti "1", "0.7" atau "2.5".
+func ParseRate(s string) (Rate, error) {
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	whole, frac, _ := strings.Cut(strings.TrimPrefix(s, "-"), ".")
+	frac = strings.TrimRight(frac, "0")
+	if len(frac) > rateDecimals || whole == "" && frac == "" {
+		return 0, fmt.Errorf("money: invalid percentage %q (max %d decimals)", s, rateDecimals)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	if strings.Trim(frac, "0123456789") != "" {
+		return 0, fmt.Errorf("money: invalid percentage %q", s)
+	}
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil || w < 0 || w > math.MaxInt64/(rateScale/100)-1 {
+		return 0, fmt.Errorf("money: invalid percentage %q", s)
+	}
+	var f int64
+	if frac != "" {
+		if f, err = strconv.ParseInt(frac+strings.Repeat("0", rateDecimals-len(frac)), 10, 64); err != nil {
+			return 0, fmt.Errorf("money: invalid percentage %q", s)
+		}
+	}
+	r := Rate(w*rateScale/100 + f)
+	if neg {
+		r = -r
+	}
+	return r, nil
+}
+
+// String mengembalikan persen desimal tanpa nol di belakang, mis. "0.7"
+func (r Rate) String() string {
+	v := int64(r)
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	unit := rateScale / 100
+	s := sign + strconv.FormatInt(v/unit, 10)
+	if frac := v % unit; frac != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%0*d", rateDecimals, frac), "0")
+	}
+	return s
+}
+
+// MarshalJSON implements json.Marshaler: ditulis sebagai angka persen.
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler: menerima angka atau string angka.
+func (r *Rate) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if unq, err := strconv.Unquote(s); err == nil {
+		s = unq
+	}
+	return r.UnmarshalText([]byte(s))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *Rate) UnmarshalText(b []byte) error {
+	v, err := ParseRate(string(b))
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// Scan implements sql.Scanner untuk kolom numeric.
+func (r *Rate) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*r = 0
+	case int64:
+		*r = Rate(v * rateScale / 100)
+	case float64:
+		return r.UnmarshalText([]byte(strconv.FormatFloat(v, 'f', rateDecimals, 64)))
+	case []byte:
+		return r.UnmarshalText(v)
+	case string:
+		return r.UnmarshalText([]byte(v))
+	default:
+		return fmt.Errorf("money: cannot scan %T into Rate", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer: persen desimal sebagai teks agar numeric tetap eksak.
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
@@ -1,51 +1,81 @@
 package oauth
 
 import (
+	"context"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/base64"
+	"errors"
 	"sync"
 	"time"
 )
 
+// PendingAuth is what the login redirect leaves behind for the callback.
+// LinkUserID is non-zero when the flow was started by a logged-in user linking an identity.
+type PendingAuth struct {
+	Provider   string
+	Verifier   string
+	LinkUserID uint64
+}
+
+// ErrStateNotFound is returned by DevStore.Consume for unknown, expired or already used states.
+var ErrStateNotFound = errors.New("oauth: unknown or expired state")
+
+// DevStore keeps OAuth state + PKCE verifiers between the login redirect and the callback.
+// The in-memory store only works with a single API instance; use the database store behind a load balancer.
 type DevStore interface {
-	NewStateAndPKCE() (state, verifier, challenge string, err error)
-	Consume(state string) (verifier string, ok bool)
+	NewStateAndPKCE(ctx context.Context, provider string, linkUserID uint64) (state, verifier string, err error)
+	// Consume returns the pending login at most once. Expired or unknown states return ErrStateNotFound;
+	// any other error means the store itself failed.
+	Consume(ctx context.Context, state string) (*PendingAuth, error)
+}
+
+type stateEntry struct {
+	PendingAuth
+	expAt time.Time
 }
 
 type devStore struct {
 	mu  sync.Mutex
 	exp time.Duration
 	// keyed by state; stores code_verifier and expiry
-	data map[string]struct {
-		verifier string
-		expAt    time.Time
-	}
+	data map[string]stateEntry
+
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
+// NewDevStore keeps pending logins in process memory.
+// The returned DevStore also implements io.Closer; Close stops the cleanup goroutine.
 func NewDevStore(ttl time.Duration) DevStore {
-	s := &devStore{exp: ttl, data: make(map[string]struct {
-		verifier string
-		expAt    time.Time
-	})}
+	s := &devStore{exp: ttl, data: make(map[string]stateEntry), done: make(chan struct{})}
 	// simple janitor
 	go func() {
 		t := time.NewTicker(time.Minute)
 		defer t.Stop()
-		for range t.C {
-			now := time.Now()
-			s.mu.Lock()
-			for k, v := range s.data {
-				if now.After(v.expAt) {
-					delete(s.data, k)
+		for {
+			select {
+			case <-s.done:
+				return
+			case now := <-t.C:
+				s.mu.Lock()
+				for k, v := range s.data {
+					if now.After(v.expAt) {
+						delete(s.data, k)
+					}
 				}
+				s.mu.Unlock()
 			}
-			s.mu.Unlock()
 		}
 	}()
 	return s
 }
 
+// Close stops the janitor. The store keeps working, expired states are just no longer removed.
+func (s *devStore) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	return nil
+}
+
 func randURLSafe(n int) (string, error) {
 	b := make([]byte, n)
 	if _, err := rand.Read(b); err != nil {
@@ -54,34 +84,38 @@ func randURLSafe(n int) (string, error) {
 	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
-func (s *devStore) NewStateAndPKCE() (state, codeVerifier, codeChallenge string, err error) {
+// GenerateStateAndVerifier returns a random OAuth state and PKCE code verifier.
+func GenerateStateAndVerifier() (state, codeVerifier string, err error) {
 	state, err = randURLSafe(32)
 	if err != nil {
 		return
 	}
 	codeVerifier, err = randURLSafe(64) // RFC 7636: 43–128 chars
+	return
+}
+
+func (s *devStore) NewStateAndPKCE(_ context.Context, provider string, linkUserID uint64) (state, codeVerifier string, err error) {
+	state, codeVerifier, err = GenerateStateAndVerifier()
 	if err != nil {
 		return
 	}
-	h := sha256.Sum256([]byte(codeVerifier))
-	codeChallenge = base64.RawURLEncoding.EncodeToString(h[:])
 	s.mu.Lock()
-	s.data[state] = struct {
-		verifier string
-		expAt    time.Time
-	}{verifier: codeVerifier, expAt: time.Now().Add(s.exp)}
+	s.data[state] = stateEntry{
+		PendingAuth: PendingAuth{Provider: provider, Verifier: codeVerifier, LinkUserID: linkUserID},
+		expAt:       time.Now().Add(s.exp),
+	}
 	s.mu.Unlock()
 	return
 }
 
-func (s *devStore) Consume(state string) (codeVerifier string, ok bool) {
+func (s *devStore) Consume(_ context.Context, state string) (*PendingAuth, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	item, exists := s.data[state]
 	if !exists || time.Now().After(item.expAt) {
 		delete(s.data, state)
-		return "", false
+		return nil, ErrStateNotFound
 	}
 	delete(s.data, state) // one-time use
-	return item.verifier, true
+	return &item.PendingAuth, nil
 }
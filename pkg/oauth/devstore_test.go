@@ -0,0 +1,66 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestDevStoreConsume(t *testing.T) {
+	ctx := context.Background()
+	store := NewDevStore(time.Minute)
+	defer store.(io.Closer).Close()
+
+	state, verifier, err := store.NewStateAndPKCE(ctx, "google", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := store.Consume(ctx, state)
+	if err != nil || pending.Provider != "google" || pending.Verifier != verifier || pending.LinkUserID != 7 {
+		t.Fatalf("Consume = %+v, %v", pending, err)
+	}
+
+	// State hanya bisa dipakai sekali
+	if _, err := store.Consume(ctx, state); !errors.Is(err, ErrStateNotFound) {
+		t.Fatalf("second Consume err = %v, want ErrStateNotFound", err)
+	}
+	if _, err := store.Consume(ctx, "unknown"); !errors.Is(err, ErrStateNotFound) {
+		t.Fatalf("unknown state err = %v, want ErrStateNotFound", err)
+	}
+
+	expired := NewDevStore(-time.Second)
+	defer expired.(io.Closer).Close()
+	state, _, _ = expired.NewStateAndPKCE(ctx, "google", 0)
+	if _, err := expired.Consume(ctx, state); !errors.Is(err, ErrStateNotFound) {
+		t.Fatalf("expired state err = %v, want ErrStateNotFound", err)
+	}
+}
+
+func TestDevStoreCloseStopsJanitor(t *testing.T) {
+	before := runtime.NumGoroutine()
+	store := NewDevStore(time.Minute)
+	for range 2 {
+		if err := store.(io.Closer).Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines running, want %d: janitor still running", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// Store tetap bisa dipakai setelah Close
+	state, _, err := store.NewStateAndPKCE(context.Background(), "google", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Consume(context.Background(), state); err != nil {
+		t.Fatal(err)
+	}
+}
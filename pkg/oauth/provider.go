@@ -0,0 +1,375 @@
+package oauth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/config"
+	"golang.org/x/oauth2"
+)
+
+// UserInfo is the normalized identity returned by every provider.
+type UserInfo struct {
+	Provider      string `json:"provider"`
+	Subject       string `json:"sub"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+}
+
+// ClaimMapping tells which userinfo (or id_token) claim holds each UserInfo field.
+// Nested claims use dots, e.g. "picture.data.url".
+type ClaimMapping struct {
+	Subject       string
+	Email         string
+	EmailVerified string
+	Name          string
+	Picture       string
+	// TrustEmail marks emails as verified when the provider only ever returns confirmed addresses
+	TrustEmail bool
+}
+
+// oidcMapping is the standard OpenID Connect claim set.
+var oidcMapping = ClaimMapping{
+	Subject:       "sub",
+	Email:         "email",
+	EmailVerified: "email_verified",
+	Name:          "name",
+	Picture:       "picture",
+}
+
+// Provider drives the authorization code flow against a single identity provider.
+type Provider interface {
+	Name() string
+	// AuthCodeURL returns the redirect URL; verifier is ignored when the provider has PKCE disabled.
+	AuthCodeURL(ctx context.Context, state, verifier string) (string, error)
+	// Exchange trades the callback code for the user's identity.
+	Exchange(ctx context.Context, code, verifier string) (*UserInfo, error)
+}
+
+// preset holds the built-in defaults of a well known provider.
+type preset struct {
+	authURL     string
+	tokenURL    string
+	userInfoURL string
+	issuer      string
+	scopes      []string
+	mapping     ClaimMapping
+	authParams  []oauth2.AuthCodeOption
+}
+
+type provider struct {
+	name        string
+	oauth       *oauth2.Config
+	issuer      string
+	userInfoURL string
+	mapping     ClaimMapping
+	pkce        bool
+	authParams  []oauth2.AuthCodeOption
+	httpClient  *http.Client
+
+	mu         sync.Mutex
+	discovered bool
+}
+
+func newProvider(cfg config.OAuthProviderConfig, p preset, httpClient *http.Client) *provider {
+	pick := func(v, def string) string {
+		if v != "" {
+			return v
+		}
+		return def
+	}
+
+	scopes := cfg.Scopes
+	if len(scopes) == 0 {
+		scopes = p.scopes
+	}
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email", "profile"}
+	}
+	mapping := p.mapping
+	if mapping.Subject == "" {
+		mapping = oidcMapping
+	}
+
+	pr := &provider{
+		name: cfg.Name,
+		oauth: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.CallbackUrl,
+			Scopes:       scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  pick(cfg.AuthURL, p.authURL),
+				TokenURL: pick(cfg.TokenURL, p.tokenURL),
+			},
+		},
+		issuer:      strings.TrimRight(pick(cfg.Issuer, p.issuer), "/"),
+		userInfoURL: pick(cfg.UserInfoURL, p.userInfoURL),
+		mapping:     mapping,
+		pkce:        cfg.PKCE,
+		authParams:  p.authParams,
+		httpClient:  httpClient,
+	}
+	// Endpoint lengkap tidak perlu discovery
+	pr.discovered = pr.oauth.Endpoint.AuthURL != "" && pr.oauth.Endpoint.TokenURL != ""
+	return pr
+}
+
+func (p *provider) Name() string { return p.name }
+
+// AuthCodeURL implements Provider.
+func (p *provider) AuthCodeURL(ctx context.Context, state, verifier string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+	opts := append([]oauth2.AuthCodeOption{}, p.authParams...)
+	if p.pkce {
+		opts = append(opts, oauth2.S256ChallengeOption(verifier))
+	}
+	return p.oauth.AuthCodeURL(state, opts...), nil
+}
+
+// Exchange implements Provider.
+func (p *provider) Exchange(ctx context.Context, code, verifier string) (*UserInfo, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.httpClient)
+	var opts []oauth2.AuthCodeOption
+	if p.pkce {
+		opts = append(opts, oauth2.VerifierOption(verifier))
+	}
+	tok, err := p.oauth.Exchange(ctx, code, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("exchange code: %w", err)
+	}
+
+	var claims map[string]any
+	if p.userInfoURL != "" {
+		claims, err = p.fetchUserInfo(ctx, tok)
+	} else {
+		claims, err = p.idTokenClaims(tok)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	info := &UserInfo{
+		Provider: p.name,
+		Subject:  claimString(claims, p.mapping.Subject),
+		Email:    strings.ToLower(claimString(claims, p.mapping.Email)),
+		Name:     claimString(claims, p.mapping.Name),
+		Picture:  claimString(claims, p.mapping.Picture),
+	}
+	if p.mapping.TrustEmail {
+		info.EmailVerified = info.Email != ""
+	} else {
+		info.EmailVerified = claimBool(claims, p.mapping.EmailVerified)
+	}
+	if info.Subject == "" {
+		return nil, errors.New("identity has no subject")
+	}
+	return info, nil
+}
+
+func (p *provider) fetchUserInfo(ctx context.Context, tok *oauth2.Token) (map[string]any, error) {
+	// Request dibuat dengan ctx agar span-nya tersambung ke trace request callback
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.userInfoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch userinfo: %w", err)
+	}
+	resp, err := p.oauth.Client(ctx, tok).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch userinfo: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("fetch userinfo: status %d", resp.StatusCode)
+	}
+
+	var claims map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, fmt.Errorf("decode userinfo: %w", err)
+	}
+	return claims, nil
+}
+
+// idTokenLeeway tolerates clock drift between us and the provider when checking exp.
+const idTokenLeeway = time.Minute
+
+// idTokenClaims reads the claims of the id_token returned next to the access token.
+// The token came straight from the token endpoint over TLS, so per OIDC Core 3.1.3.7
+// the signature check can be skipped for providers without a userinfo endpoint (e.g. Apple),
+// but iss, aud and exp must still be validated.
+func (p *provider) idTokenClaims(tok *oauth2.Token) (map[string]any, error) {
+	raw, _ := tok.Extra("id_token").(string)
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("provider returned no id_token")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decode id_token: %w", err)
+	}
+
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("decode id_token: %w", err)
+	}
+
+	if p.issuer == "" {
+		return nil, fmt.Errorf("oauth provider %s needs an issuer to validate its id_token", p.name)
+	}
+	if iss := strings.TrimRight(claimString(claims, "iss"), "/"); iss != p.issuer {
+		return nil, fmt.Errorf("id_token issuer %q does not match %q", iss, p.issuer)
+	}
+	if !hasAudience(claims["aud"], p.oauth.ClientID) {
+		return nil, errors.New("id_token was not issued for this client")
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return nil, errors.New("id_token has no exp")
+	}
+	if time.Now().After(time.Unix(int64(exp), 0).Add(idTokenLeeway)) {
+		return nil, errors.New("id_token has expired")
+	}
+	return claims, nil
+}
+
+// hasAudience checks the aud claim, which is either a single string or an array.
+func hasAudience(aud any, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []any:
+		for _, a := range v {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// discover fills missing endpoints from {issuer}/.well-known/openid-configuration.
+// The request runs without holding p.mu so a slow issuer does not block logins of this provider
+// that only need the lock to read the result; concurrent first logins may each fetch the document.
+// Failures are not cached so a temporarily unreachable issuer recovers on the next login.
+func (p *provider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	done := p.discovered
+	p.mu.Unlock()
+	if done {
+		return nil
+	}
+	if p.issuer == "" {
+		return fmt.Errorf("oauth provider %s has no endpoints or issuer configured", p.name)
+	}
+
+	doc, err := p.fetchDiscovery(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovered {
+		return nil
+	}
+	if p.oauth.Endpoint.AuthURL == "" {
+		p.oauth.Endpoint.AuthURL = doc.AuthorizationEndpoint
+	}
+	if p.oauth.Endpoint.TokenURL == "" {
+		p.oauth.Endpoint.TokenURL = doc.TokenEndpoint
+	}
+	if p.userInfoURL == "" {
+		p.userInfoURL = doc.UserinfoEndpoint
+	}
+	p.discovered = true
+	return nil
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+func (p *provider) fetchDiscovery(ctx context.Context) (*discoveryDocument, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("oidc discovery: status %d", resp.StatusCode)
+	}
+
+	var doc discoveryDocument
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	// OIDC Discovery 4.3: the document must describe the issuer we asked for
+	if strings.TrimRight(doc.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match %q", doc.Issuer, p.issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" {
+		return nil, errors.New("oidc discovery: document has no authorization or token endpoint")
+	}
+	return &doc, nil
+}
+
+func claimValue(claims map[string]any, path string) any {
+	if path == "" {
+		return nil
+	}
+	var cur any = claims
+	for _, key := range strings.Split(path, ".") {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[key]
+	}
+	return cur
+}
+
+func claimString(claims map[string]any, path string) string {
+	switch v := claimValue(claims, path).(type) {
+	case string:
+		return v
+	case float64:
+		// id numerik (mis. GitHub) tetap disimpan sebagai string
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+func claimBool(claims map[string]any, path string) bool {
+	switch v := claimValue(claims, path).(type) {
+	case bool:
+		return v
+	case string:
+		// Apple mengirim "true"/"false" sebagai string
+		return v == "true"
+	default:
+		return false
+	}
+}
@@ -0,0 +1,283 @@
+package oauth
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wildanasyrof/backend-topup/internal/config"
+)
+
+const testClientID = "client-123"
+
+// fakeOIDC is a minimal OpenID provider: discovery, token and userinfo endpoints.
+type fakeOIDC struct {
+	*httptest.Server
+
+	mu sync.Mutex
+	// idClaims is returned as the (unsigned) id_token; userinfo is served when userInfo is true
+	idClaims  map[string]any
+	userInfo  bool
+	issuer    string // overrides the issuer in the discovery document
+	verifiers []string
+	// hold, when set, blocks the discovery response until it is closed
+	hold chan struct{}
+}
+
+func newFakeOIDC(t *testing.T) *fakeOIDC {
+	f := &fakeOIDC{userInfo: true}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		hold, issuer, withUserInfo := f.hold, f.issuer, f.userInfo
+		f.mu.Unlock()
+		if hold != nil {
+			select {
+			case <-hold:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if issuer == "" {
+			issuer = f.URL
+		}
+		doc := map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": f.URL + "/authorize",
+			"token_endpoint":         f.URL + "/token",
+		}
+		if withUserInfo {
+			doc["userinfo_endpoint"] = f.URL + "/userinfo"
+		}
+		_ = json.NewEncoder(w).Encode(doc)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.PostForm.Get("code") != "good-code" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		f.mu.Lock()
+		f.verifiers = append(f.verifiers, r.PostForm.Get("code_verifier"))
+		claims := f.idClaims
+		f.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "access-123",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     unsignedJWT(t, claims),
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"sub": "user-1", "email": "User@Example.com", "email_verified": true, "name": "User One",
+		})
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func unsignedJWT(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return header + "." + base64.RawURLEncoding.EncodeToString(payload) + ".sig"
+}
+
+func (f *fakeOIDC) provider() *provider {
+	cfg := config.OAuthProviderConfig{
+		Name:         "acme",
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		CallbackUrl:  "https://app.example.com/auth/acme/callback",
+		Issuer:       f.URL,
+		PKCE:         true,
+	}
+	return newProvider(cfg, preset{}, f.Client())
+}
+
+func (f *fakeOIDC) validClaims() map[string]any {
+	return map[string]any{
+		"iss":            f.URL,
+		"aud":            testClientID,
+		"exp":            float64(time.Now().Add(time.Hour).Unix()),
+		"sub":            "apple-1",
+		"email":          "relay@privaterelay.example",
+		"email_verified": "true",
+	}
+}
+
+func TestDiscoveryAndUserInfo(t *testing.T) {
+	f := newFakeOIDC(t)
+	p := f.provider()
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(authURL)
+	q := u.Query()
+	if u.Path != "/authorize" || q.Get("state") != "state-1" || q.Get("client_id") != testClientID {
+		t.Fatalf("unexpected auth url %s", authURL)
+	}
+	if q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		t.Fatalf("PKCE challenge missing from %s", authURL)
+	}
+
+	info, err := p.Exchange(ctx, "good-code", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := UserInfo{Provider: "acme", Subject: "user-1", Email: "user@example.com", EmailVerified: true, Name: "User One"}
+	if *info != want {
+		t.Fatalf("got %+v, want %+v", *info, want)
+	}
+	if len(f.verifiers) != 1 || f.verifiers[0] != "verifier-1" {
+		t.Fatalf("code_verifier not sent: %v", f.verifiers)
+	}
+
+	if _, err := p.Exchange(ctx, "bad-code", "verifier-1"); err == nil {
+		t.Fatal("bad code accepted")
+	}
+}
+
+func TestDiscoveryIssuerMismatch(t *testing.T) {
+	f := newFakeOIDC(t)
+	f.issuer = "https://evil.example.com"
+
+	if _, err := f.provider().AuthCodeURL(context.Background(), "s", "v"); err == nil || !strings.Contains(err.Error(), "issuer") {
+		t.Fatalf("want issuer mismatch error, got %v", err)
+	}
+}
+
+func TestIDTokenClaimsValidation(t *testing.T) {
+	cases := []struct {
+		name    string
+		mutate  func(f *fakeOIDC, c map[string]any)
+		wantErr string
+	}{
+		{"valid", func(*fakeOIDC, map[string]any) {}, ""},
+		{"issuer with trailing slash", func(f *fakeOIDC, c map[string]any) { c["iss"] = f.URL + "/" }, ""},
+		{"audience array", func(_ *fakeOIDC, c map[string]any) { c["aud"] = []any{"other", testClientID} }, ""},
+		{"wrong issuer", func(_ *fakeOIDC, c map[string]any) { c["iss"] = "https://evil.example.com" }, "issuer"},
+		{"wrong audience", func(_ *fakeOIDC, c map[string]any) { c["aud"] = "other-client" }, "not issued for this client"},
+		{"missing audience", func(_ *fakeOIDC, c map[string]any) { delete(c, "aud") }, "not issued for this client"},
+		{"expired", func(_ *fakeOIDC, c map[string]any) { c["exp"] = float64(time.Now().Add(-time.Hour).Unix()) }, "expired"},
+		{"missing exp", func(_ *fakeOIDC, c map[string]any) { delete(c, "exp") }, "no exp"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newFakeOIDC(t)
+			f.userInfo = false
+			claims := f.validClaims()
+			tc.mutate(f, claims)
+			f.idClaims = claims
+
+			info, err := f.provider().Exchange(context.Background(), "good-code", "v")
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if info.Subject != "apple-1" || !info.EmailVerified {
+					t.Fatalf("unexpected info %+v", info)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("want error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestIDTokenRequiresIssuer(t *testing.T) {
+	f := newFakeOIDC(t)
+	f.idClaims = f.validClaims()
+	// Endpoint lengkap tanpa issuer dan tanpa userinfo: id_token tidak bisa divalidasi
+	p := newProvider(config.OAuthProviderConfig{
+		Name: "custom", ClientID: testClientID, AuthURL: f.URL + "/authorize", TokenURL: f.URL + "/token",
+	}, preset{}, f.Client())
+
+	if _, err := p.Exchange(context.Background(), "good-code", ""); err == nil || !strings.Contains(err.Error(), "needs an issuer") {
+		t.Fatalf("want missing issuer error, got %v", err)
+	}
+}
+
+func TestSlowDiscoveryDoesNotBlockOtherLogins(t *testing.T) {
+	f := newFakeOIDC(t)
+	f.hold = make(chan struct{})
+	p := f.provider()
+
+	// Login pertama menggantung di discovery
+	first := make(chan error, 1)
+	go func() {
+		_, err := p.AuthCodeURL(context.Background(), "s1", "v1")
+		first <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	// Login kedua harus menyerah sesuai deadline-nya sendiri, bukan menunggu lock milik login pertama
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	second := make(chan error, 1)
+	go func() {
+		_, err := p.AuthCodeURL(ctx, "s2", "v2")
+		second <- err
+	}()
+	select {
+	case err := <-second:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("want deadline exceeded, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		close(f.hold)
+		t.Fatal("second login blocked behind the first discovery")
+	}
+
+	close(f.hold)
+	if err := <-first; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentFirstLogins(t *testing.T) {
+	f := newFakeOIDC(t)
+	p := f.provider()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := p.Exchange(context.Background(), "good-code", "v"); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,88 @@
+package oauth
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/wildanasyrof/backend-topup/internal/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/facebook"
+	"golang.org/x/oauth2/google"
+)
+
+// presets are the defaults for providers that can be enabled with just a client id/secret.
+// Any other name is treated as a generic OIDC issuer and needs OAUTH_<NAME>_ISSUER or explicit endpoints.
+var presets = map[string]preset{
+	"google": {
+		authURL:     google.Endpoint.AuthURL,
+		tokenURL:    google.Endpoint.TokenURL,
+		userInfoURL: "https://openidconnect.googleapis.com/v1/userinfo",
+		scopes:      []string{"openid", "email", "profile"},
+		mapping:     oidcMapping,
+		authParams:  []oauth2.AuthCodeOption{oauth2.AccessTypeOffline},
+	},
+	"facebook": {
+		authURL:     facebook.Endpoint.AuthURL,
+		tokenURL:    facebook.Endpoint.TokenURL,
+		userInfoURL: "https://graph.facebook.com/me?fields=id,name,email,picture",
+		scopes:      []string{"email", "public_profile"},
+		mapping: ClaimMapping{
+			Subject:    "id",
+			Email:      "email",
+			Name:       "name",
+			Picture:    "picture.data.url",
+			TrustEmail: true, // Graph API hanya mengembalikan email yang sudah dikonfirmasi
+		},
+	},
+	"discord": {
+		authURL:     "https://discord.com/oauth2/authorize",
+		tokenURL:    "https://discord.com/api/oauth2/token",
+		userInfoURL: "https://discord.com/api/users/@me",
+		scopes:      []string{"identify", "email"},
+		mapping: ClaimMapping{
+			Subject:       "id",
+			Email:         "email",
+			EmailVerified: "verified",
+			Name:          "global_name",
+		},
+	},
+	// Apple tidak punya userinfo endpoint, identitas dibaca dari id_token.
+	// Client secret Apple adalah JWT ES256 yang dibuat di luar aplikasi ini.
+	"apple": {
+		authURL:    "https://appleid.apple.com/auth/authorize",
+		tokenURL:   "https://appleid.apple.com/auth/token",
+		issuer:     "https://appleid.apple.com",
+		scopes:     []string{"name", "email"},
+		mapping:    oidcMapping,
+		authParams: []oauth2.AuthCodeOption{oauth2.SetAuthURLParam("response_mode", "form_post")},
+	},
+}
+
+// Registry holds the enabled providers keyed by name.
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry(cfg *config.Config, httpClient *http.Client) *Registry {
+	r := &Registry{providers: make(map[string]Provider)}
+	for _, pc := range cfg.Oauth.Providers {
+		r.providers[pc.Name] = newProvider(pc, presets[pc.Name], httpClient)
+	}
+	return r
+}
+
+// Get returns the provider registered under name.
+func (r *Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names returns the enabled provider names in a stable order.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,332 @@
+package openapi
+
+import (
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Generator membuat schema dari tipe Go. Struct bernama didaftarkan sekali di components/schemas
+// dan dirujuk lewat $ref; tag `validate` (go-playground/validator) diterjemahkan menjadi constraint.
+type Generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+	custom  map[reflect.Type]*Schema
+}
+
+func NewGenerator() *Generator {
+	g := &Generator{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+		custom:  map[reflect.Type]*Schema{},
+	}
+	g.Register(time.Time{}, &Schema{Type: "string", Format: "date-time"})
+	g.Register(time.Duration(0), &Schema{Type: "integer", Format: "int64", Description: "Duration in nanoseconds"})
+	return g
+}
+
+// Register memakai s untuk tipe milik v, untuk tipe dengan MarshalJSON sendiri (mis. money.Money).
+func (g *Generator) Register(v any, s *Schema) {
+	g.custom[reflect.TypeOf(v)] = s
+}
+
+// Schemas mengembalikan semua schema bernama yang sudah dirujuk.
+func (g *Generator) Schemas() map[string]*Schema {
+	return g.schemas
+}
+
+// Schema mengembalikan schema body JSON untuk tipe milik v. v nil menghasilkan nil.
+func (g *Generator) Schema(v any) *Schema {
+	if v == nil {
+		return nil
+	}
+	return g.schemaOf(reflect.TypeOf(v))
+}
+
+// Parameters mengubah field struct query DTO (tag `query`) menjadi parameter query.
+func (g *Generator) Parameters(v any) []Parameter {
+	var params []Parameter
+	for _, f := range fieldsOf(derefType(reflect.TypeOf(v)), "query") {
+		s := g.schemaOf(f.field.Type)
+		params = append(params, Parameter{
+			Name:     f.name,
+			In:       "query",
+			Required: applyValidate(s, f.field.Tag.Get("validate")),
+			Schema:   s,
+		})
+	}
+	return params
+}
+
+// FormSchema membuat schema multipart/form-data dari tag `form` milik v (boleh nil) ditambah field file.
+func (g *Generator) FormSchema(v any, files ...string) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	if v != nil {
+		for _, f := range fieldsOf(derefType(reflect.TypeOf(v)), "form") {
+			fs := g.schemaOf(f.field.Type)
+			if applyValidate(fs, f.field.Tag.Get("validate")) {
+				s.Required = append(s.Required, f.name)
+			}
+			s.Properties[f.name] = fs
+		}
+	}
+	for _, name := range files {
+		s.Properties[name] = &Schema{Type: "string", Format: "binary"}
+	}
+	return s
+}
+
+func (g *Generator) schemaOf(t reflect.Type) *Schema {
+	nullable := t.Kind() == reflect.Pointer
+	t = derefType(t)
+
+	if c, ok := g.custom[t]; ok {
+		cp := *c
+		cp.Nullable = cp.Nullable || nullable
+		return &cp
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean", Nullable: nullable}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32", Nullable: nullable}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64", Nullable: nullable}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		zero := 0.0
+		return &Schema{Type: "integer", Format: "int64", Minimum: &zero, Nullable: nullable}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Nullable: nullable}
+	case reflect.String:
+		return &Schema{Type: "string", Nullable: nullable}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte", Nullable: nullable}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem()), Nullable: nullable}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem()), Nullable: nullable}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.objectOf(t)
+		}
+		return Ref(g.register(t))
+	}
+	// interface{} dan tipe lain: nilai apa pun
+	return &Schema{}
+}
+
+// register menambahkan struct bernama ke components. Nama diawali nama package jika bentrok.
+func (g *Generator) register(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := exportName(sanitizeName(t.Name()))
+	if _, taken := g.schemas[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = exportName(pkg) + name
+	}
+	g.names[t] = name
+	g.schemas[name] = &Schema{} // placeholder untuk tipe rekursif
+	*g.schemas[name] = *g.objectOf(t)
+	return name
+}
+
+func (g *Generator) objectOf(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, f := range fieldsOf(t, "json") {
+		fs := g.schemaOf(f.field.Type)
+		if applyValidate(fs, f.field.Tag.Get("validate")) {
+			s.Required = append(s.Required, f.name)
+		}
+		s.Properties[f.name] = fs
+	}
+	return s
+}
+
+type namedField struct {
+	name  string
+	field reflect.StructField
+}
+
+// fieldsOf mengikuti aturan encoding/json: field tanpa export dan tag "-" dilewati, struct embedded
+// tanpa nama ikut diratakan. Untuk tag selain json, nama dari tag json dipakai jika tag itu kosong.
+func fieldsOf(t reflect.Type, tag string) []namedField {
+	var out []namedField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := fieldName(f, tag)
+		if !ok {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			if ft := derefType(f.Type); ft.Kind() == reflect.Struct {
+				out = append(out, fieldsOf(ft, tag)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		out = append(out, namedField{name: name, field: f})
+	}
+	return out
+}
+
+func fieldName(f reflect.StructField, tag string) (string, bool) {
+	for _, key := range []string{tag, "json"} {
+		v, ok := f.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(v, ",")
+		if name == "-" {
+			return "", false
+		}
+		if name != "" || key == tag {
+			return name, true
+		}
+	}
+	return "", true
+}
+
+// applyValidate menerapkan rule validator ke s dan melaporkan apakah field wajib diisi.
+func applyValidate(s *Schema, rules string) (required bool) {
+	if rules == "" {
+		return false
+	}
+	target := s
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		if name == "dive" {
+			// Rule setelah dive berlaku untuk elemen slice
+			if target.Items == nil {
+				return required
+			}
+			target = target.Items
+			continue
+		}
+		if target.Ref != "" {
+			// Constraint tidak bisa ditempel ke $ref di OpenAPI 3.0
+			if name == "required" && target == s {
+				required = true
+			}
+			continue
+		}
+		switch name {
+		case "required":
+			if target == s {
+				required = true
+			}
+		case "min", "gte":
+			setBound(target, param, true, false)
+		case "max", "lte":
+			setBound(target, param, false, false)
+		case "gt":
+			setBound(target, param, true, true)
+		case "lt":
+			setBound(target, param, false, true)
+		case "len":
+			setBound(target, param, true, false)
+			setBound(target, param, false, false)
+		case "email":
+			target.Format = "email"
+		case "url", "http_url":
+			target.Format = "uri"
+		case "uuid", "uuid4":
+			target.Format = "uuid"
+		case "numeric":
+			target.Pattern = `^[-+]?[0-9]+(?:\.[0-9]+)?$`
+		case "lowercase":
+			target.Pattern = `^[^A-Z]*$`
+		case "startswith":
+			target.Pattern = "^" + regexp.QuoteMeta(param)
+		case "oneof":
+			target.Enum = nil
+			for _, v := range strings.Fields(param) {
+				target.Enum = append(target.Enum, enumValue(target.Type, strings.Trim(v, "'")))
+			}
+		default:
+			if strings.Contains(name, "|") {
+				target.Description = strings.TrimSpace(target.Description + " Must be one of: " + strings.ReplaceAll(rule, "|", ", ") + ".")
+			}
+		}
+	}
+	return required
+}
+
+// setBound menerjemahkan min/max sesuai tipe: panjang string, jumlah item, atau nilai angka.
+func setBound(s *Schema, param string, lower, exclusive bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch s.Type {
+	case "string":
+		s.MinLength, s.MaxLength = countBound(s.MinLength, s.MaxLength, int(n), lower, exclusive)
+	case "array", "object":
+		s.MinItems, s.MaxItems = countBound(s.MinItems, s.MaxItems, int(n), lower, exclusive)
+	case "integer", "number":
+		if lower {
+			s.Minimum, s.ExclusiveMinimum = &n, exclusive
+		} else {
+			s.Maximum, s.ExclusiveMaximum = &n, exclusive
+		}
+	}
+}
+
+// countBound: panjang dan jumlah item selalu bulat, jadi gt/lt cukup digeser satu.
+func countBound(min, max *int, n int, lower, exclusive bool) (*int, *int) {
+	if lower {
+		if exclusive {
+			n++
+		}
+		return &n, max
+	}
+	if exclusive {
+		n--
+	}
+	return min, &n
+}
+
+func enumValue(typ, v string) any {
+	switch typ {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return v
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+var nonNameChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+func sanitizeName(name string) string {
+	return nonNameChars.ReplaceAllString(name, "_")
+}
+
+// exportName: tipe unexported (mis. response ad-hoc) tetap tampil dengan nama berhuruf besar
+func exportName(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
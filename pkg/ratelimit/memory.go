@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	Bucket
+	expAt time.Time
+}
+
+type memoryStore struct {
+	mu   sync.Mutex
+	data map[string]*memoryEntry
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewMemoryStore keeps buckets in process memory.
+// Limits are per instance, so use the Redis or database store behind a load balancer.
+// The returned Store also implements io.Closer; Close stops the cleanup goroutine.
+func NewMemoryStore() Store {
+	s := &memoryStore{data: make(map[string]*memoryEntry), done: make(chan struct{})}
+	// simple janitor
+	go func() {
+		t := time.NewTicker(time.Minute)
+		defer t.Stop()
+		for {
+			select {
+			case <-s.done:
+				return
+			case now := <-t.C:
+				s.mu.Lock()
+				for k, v := range s.data {
+					if now.After(v.expAt) {
+						delete(s.data, k)
+					}
+				}
+				s.mu.Unlock()
+			}
+		}
+	}()
+	return s
+}
+
+// Close stops the janitor. Take keeps working, expired buckets are just no longer removed.
+func (s *memoryStore) Close() error {
+	s.closeOnce.Do(func() { close(s.done) })
+	return nil
+}
+
+// Take implements Store.
+func (s *memoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.data[key]
+	if !ok {
+		e = &memoryEntry{}
+		s.data[key] = e
+	}
+	res := Advance(&e.Bucket, limit, now)
+	e.expAt = now.Add(TTL(limit))
+	return res, nil
+}
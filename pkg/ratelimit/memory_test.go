@@ -0,0 +1,49 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// waitGoroutines menunggu jumlah goroutine kembali ke n; janitor keluar secara asinkron setelah Close
+func waitGoroutines(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines running, want %d: janitor still running", runtime.NumGoroutine(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMemoryStoreCloseStopsJanitor(t *testing.T) {
+	before := runtime.NumGoroutine()
+	store := NewMemoryStore()
+	if runtime.NumGoroutine() <= before {
+		t.Fatal("janitor not started")
+	}
+
+	closer, ok := store.(io.Closer)
+	if !ok {
+		t.Fatal("memory store does not implement io.Closer")
+	}
+	for range 2 {
+		if err := closer.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitGoroutines(t, before)
+
+	// Take tetap berjalan setelah Close
+	limit := PerMinute(1)
+	if res, err := store.Take(context.Background(), "k", limit); err != nil || !res.Allowed {
+		t.Fatalf("first Take = %+v, %v", res, err)
+	}
+	if res, _ := store.Take(context.Background(), "k", limit); res.Allowed {
+		t.Fatal("second Take allowed past the limit")
+	}
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Limit is a token bucket: Burst tokens at most, refilled at Rate tokens per Period.
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+// PerMinute returns a limit of n requests per minute with a burst of n.
+func PerMinute(n int) Limit {
+	return Limit{Rate: n, Period: time.Minute, Burst: n}
+}
+
+// PerHour returns a limit of n requests per hour with a burst of n.
+func PerHour(n int) Limit {
+	return Limit{Rate: n, Period: time.Hour, Burst: n}
+}
+
+// String renders the limit as a RateLimit-Policy value, e.g. "60;w=60".
+func (l Limit) String() string {
+	return fmt.Sprintf("%d;w=%d", l.Burst, int(l.Period.Seconds()))
+}
+
+// perSecond is the refill rate in tokens per second.
+func (l Limit) perSecond() float64 {
+	return float64(l.Rate) / l.Period.Seconds()
+}
+
+// Result is the outcome of a single Take.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until the next token is available; zero when Allowed
+	RetryAfter time.Duration
+}
+
+// Store persists buckets. Implementations must make Take atomic per key
+// so limits hold when several API instances share the same store.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Bucket is the persisted state of a single key.
+type Bucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// Advance refills b up to now, takes one token if available and returns the result.
+// Stores that keep the bucket themselves (memory, Postgres) share this so they behave identically.
+func Advance(b *Bucket, limit Limit, now time.Time) Result {
+	rate := limit.perSecond()
+	burst := float64(limit.Burst)
+
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = burst
+	} else if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(burst, b.Tokens+elapsed*rate)
+	}
+	b.UpdatedAt = now
+
+	res := Result{Limit: limit.Burst}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.Tokens) / rate)
+	}
+	res.Remaining = int(math.Floor(b.Tokens))
+	res.Reset = seconds((burst - b.Tokens) / rate)
+	return res
+}
+
+// TTL is how long an idle bucket must be kept; after that it would be full anyway.
+func TTL(limit Limit) time.Duration {
+	return seconds(float64(limit.Burst)/limit.perSecond()) + time.Second
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
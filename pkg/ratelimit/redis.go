@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript is Advance in Lua so the read-modify-write runs atomically inside Redis.
+// KEYS[1] = bucket, ARGV = rate per second, burst, now (ms), ttl (ms).
+// Returns {allowed, tokens * 1000}.
+var takeScript = redis.NewScript(`
+local rate  = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now   = tonumber(ARGV[3])
+local ttl   = tonumber(ARGV[4])
+
+local state  = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1])
+local ts     = tonumber(state[2])
+if tokens == nil then
+  tokens = burst
+elseif now > ts then
+  tokens = math.min(burst, tokens + (now - ts) / 1000 * rate)
+end
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tokens, "ts", now)
+redis.call("PEXPIRE", KEYS[1], ttl)
+return {allowed, math.floor(tokens * 1000)}
+`)
+
+type redisStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisStore keeps buckets in Redis under prefix, shared by every API instance.
+func NewRedisStore(client redis.UniversalClient, prefix string) Store {
+	return &redisStore{client: client, prefix: prefix}
+}
+
+// Take implements Store.
+func (s *redisStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	rate := limit.perSecond()
+	out, err := takeScript.Run(ctx, s.client, []string{s.prefix + key},
+		rate, limit.Burst, time.Now().UnixMilli(), TTL(limit).Milliseconds()).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	// Hitung ulang header dari sisa token dengan rumus yang sama seperti Advance
+	tokens := float64(out[1]) / 1000
+	res := Result{
+		Allowed:   out[0] == 1,
+		Limit:     limit.Burst,
+		Remaining: int(tokens),
+		Reset:     seconds((float64(limit.Burst) - tokens) / rate),
+	}
+	if !res.Allowed {
+		res.RetryAfter = seconds((1 - tokens) / rate)
+	}
+	return res, nil
+}
@@ -0,0 +1,35 @@
+// Package reqctx carries per-request metadata (who, from where) through context.Context,
+// so services can attribute changes without taking *fiber.Ctx.
+package reqctx
+
+import "context"
+
+// Info describes the current request. UserID is zero for unauthenticated requests.
+type Info struct {
+	UserID    uint64
+	Role      string
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+type ctxKey struct{}
+
+// With returns a copy of ctx carrying info.
+func With(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, ctxKey{}, info)
+}
+
+// From returns the Info stored in ctx, or the zero value.
+func From(ctx context.Context) Info {
+	info, _ := ctx.Value(ctxKey{}).(Info)
+	return info
+}
+
+// WithActor sets the authenticated user on the Info already stored in ctx.
+func WithActor(ctx context.Context, userID uint64, role string) context.Context {
+	info := From(ctx)
+	info.UserID = userID
+	info.Role = role
+	return With(ctx, info)
+}
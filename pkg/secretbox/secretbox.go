@@ -0,0 +1,80 @@
+// Package secretbox encrypts small secrets (TOTP seeds, signing secrets) before they are stored,
+// so a database dump alone is not enough to use them.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// prefix menandai nilai terenkripsi; nilai tanpa prefix dianggap plaintext lama dari sebelum enkripsi
+const prefix = "enc:v1:"
+
+var ErrMalformed = errors.New("secretbox: malformed ciphertext")
+
+// Box mengenkripsi dan mendekripsi secret dengan AES-256-GCM.
+type Box interface {
+	Seal(plain string) (string, error)
+	// Open mengembalikan plaintext; nilai tanpa prefix dikembalikan apa adanya
+	Open(sealed string) (string, error)
+	// IsSealed melaporkan apakah nilai sudah terenkripsi
+	IsSealed(value string) bool
+}
+
+type box struct {
+	aead cipher.AEAD
+}
+
+// New menurunkan kunci AES-256 dari key (config security.encryption_key).
+func New(key string) (Box, error) {
+	if key == "" {
+		return nil, errors.New("secretbox: empty key")
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &box{aead: aead}, nil
+}
+
+func (b *box) Seal(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	out := b.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return prefix + base64.RawStdEncoding.EncodeToString(out), nil
+}
+
+func (b *box) Open(sealed string) (string, error) {
+	if !b.IsSealed(sealed) {
+		return sealed, nil
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(sealed, prefix))
+	if err != nil || len(raw) < b.aead.NonceSize() {
+		return "", ErrMalformed
+	}
+	nonce, data := raw[:b.aead.NonceSize()], raw[b.aead.NonceSize():]
+	plain, err := b.aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return "", ErrMalformed
+	}
+	return string(plain), nil
+}
+
+func (b *box) IsSealed(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
@@ -0,0 +1,60 @@
+package secretbox
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	b, err := New("0123456789abcdef0123456789abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed, err := b.Seal("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, "JBSWY3DPEHPK3PXP") || !b.IsSealed(sealed) {
+		t.Fatalf("secret not encrypted: %q", sealed)
+	}
+	again, _ := b.Seal("JBSWY3DPEHPK3PXP")
+	if again == sealed {
+		t.Fatal("nonce reused")
+	}
+
+	plain, err := b.Open(sealed)
+	if err != nil || plain != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("open = %q, %v", plain, err)
+	}
+}
+
+func TestOpenRejectsTamperingAndWrongKey(t *testing.T) {
+	b, _ := New("key-one-key-one-key-one-key-one!")
+	other, _ := New("key-two-key-two-key-two-key-two!")
+	sealed, _ := b.Seal("secret")
+
+	if _, err := other.Open(sealed); err != ErrMalformed {
+		t.Fatalf("wrong key: got %v", err)
+	}
+	// Ubah satu karakter di tengah ciphertext (karakter terakhir base64 bisa berisi bit padding)
+	i := len(sealed) - 10
+	flip := byte('A')
+	if sealed[i] == 'A' {
+		flip = 'B'
+	}
+	tampered := sealed[:i] + string(flip) + sealed[i+1:]
+	if _, err := b.Open(tampered); err != ErrMalformed {
+		t.Fatalf("tampered: got %v", err)
+	}
+}
+
+func TestOpenPassesLegacyPlaintext(t *testing.T) {
+	b, _ := New("0123456789abcdef0123456789abcdef")
+	if got, err := b.Open("JBSWY3DPEHPK3PXP"); err != nil || got != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("legacy value = %q, %v", got, err)
+	}
+	if got, _ := b.Seal(""); got != "" {
+		t.Fatalf("empty secret sealed to %q", got)
+	}
+}
@@ -0,0 +1,35 @@
+// Package signature implements the HMAC-SHA256 request signing shared by the H2H API
+// and outgoing webhooks, so resellers only have to implement one scheme.
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Sign returns the hex HMAC-SHA256 of the canonical request:
+//
+//	<unix timestamp>\n<METHOD>\n<path?query>\n<hex sha256(body)>
+func Sign(secret string, timestamp int64, method, path string, body []byte) string {
+	sum := sha256.Sum256(body)
+	msg := strconv.FormatInt(timestamp, 10) + "\n" + method + "\n" + path + "\n" + hex.EncodeToString(sum[:])
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(msg))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks sig in constant time.
+func Verify(secret string, timestamp int64, method, path string, body []byte, sig string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, method, path, body)), []byte(sig))
+}
+
+// Fresh reports whether timestamp is within maxSkew of now in either direction,
+// which bounds how long a captured request can be replayed.
+func Fresh(timestamp int64, now time.Time, maxSkew time.Duration) bool {
+	d := now.Sub(time.Unix(timestamp, 0))
+	return d <= maxSkew && d >= -maxSkew
+}
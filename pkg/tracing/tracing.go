@@ -0,0 +1,106 @@
+// Package tracing menyiapkan OpenTelemetry: tracer provider global, propagator W3C
+// dan transport http.Client yang ikut membuat span serta meneruskan X-Request-ID.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/wildanasyrof/backend-topup/internal/config"
+	"github.com/wildanasyrof/backend-topup/pkg/reqctx"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName dipakai sebagai nama tracer untuk span buatan aplikasi ini.
+const InstrumentationName = "github.com/wildanasyrof/backend-topup"
+
+// RequestIDKey adalah atribut span yang berisi X-Request-ID.
+const RequestIDKey = attribute.Key("http.request_id")
+
+// Setup memasang tracer provider global sesuai cfg.Exporter dan mengembalikan fungsi untuk
+// mengirim sisa span saat shutdown. Exporter "none" tetap membuat trace ID agar bisa dikorelasikan di log.
+func Setup(ctx context.Context, cfg config.TracingConfig, env string) (func(context.Context) error, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.DeploymentEnvironmentName(env),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("build trace resource: %w", err)
+	}
+
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	}
+	switch cfg.Exporter {
+	case "none":
+	case "stdout":
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("create stdout trace exporter: %w", err)
+		}
+		// Sinkron agar urutan span di stdout sama dengan urutan kejadian (dipakai saat debug/test)
+		opts = append(opts, sdktrace.WithSyncer(exp))
+	case "otlp":
+		clientOpts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		exp, err := otlptracehttp.New(ctx, clientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("create otlp trace exporter: %w", err)
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+
+	tp := sdktrace.NewTracerProvider(opts...)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return tp.Shutdown, nil
+}
+
+// Tracer mengembalikan tracer aplikasi dari provider global.
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// NewTransport membungkus base (nil berarti http.DefaultTransport) agar setiap request keluar
+// punya span client, header traceparent dan X-Request-ID dari request yang sedang dilayani.
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(requestIDTransport{base: base},
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return "HTTP " + r.Method + " " + r.URL.Host
+		}),
+	)
+}
+
+type requestIDTransport struct {
+	base http.RoundTripper
+}
+
+func (t requestIDTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	id := reqctx.From(r.Context()).RequestID
+	if id == "" || r.Header.Get("X-Request-ID") != "" {
+		return t.base.RoundTrip(r)
+	}
+	// RoundTripper tidak boleh mengubah request milik pemanggil
+	r = r.Clone(r.Context())
+	r.Header.Set("X-Request-ID", id)
+	return t.base.RoundTrip(r)
+}
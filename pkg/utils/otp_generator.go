@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// GenerateNumericCode returns a cryptographically random numeric code of the given length,
+// e.g. "048213" for n = 6. Leading zeros are kept.
+func GenerateNumericCode(n int) (string, error) {
+	var sb strings.Builder
+	sb.Grow(n)
+	for i := 0; i < n; i++ {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(byte('0' + d.Int64()))
+	}
+	return sb.String(), nil
+}
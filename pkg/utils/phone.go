@@ -0,0 +1,25 @@
+package utils
+
+import "strings"
+
+// NormalizePhone converts Indonesian phone numbers to the international format
+// used by WhatsApp gateways: "0812-3456-789" and "+62 812 3456 789" both become
+// "628123456789". Non-digit characters are dropped.
+func NormalizePhone(phone string) string {
+	var sb strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			sb.WriteRune(r)
+		}
+	}
+	digits := sb.String()
+
+	switch {
+	case strings.HasPrefix(digits, "0"):
+		return "62" + digits[1:]
+	case strings.HasPrefix(digits, "8"):
+		return "62" + digits
+	default:
+		return digits
+	}
+}